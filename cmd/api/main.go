@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"finance/config"
 	"finance/internal/api/handlers/http"
 	"finance/internal/app"
@@ -21,5 +22,7 @@ func main() {
 
 	appContainer := app.NewMustApp(c)
 
-	log.Fatal(http.Run(appContainer, c.Server))
+	err := http.Run(appContainer, c.Server)
+	_ = appContainer.Shutdown(context.Background())
+	log.Fatal(err)
 }
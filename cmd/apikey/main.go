@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"finance/config"
+	"finance/internal/app"
+	"finance/internal/domain/entities"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+var (
+	configPath = flag.String("config", "config.yaml", "service configuration file")
+	name       = flag.String("name", "", "name of the key, shown in listings")
+	role       = flag.String("role", string(entities.RoleAdmin), "admin, finance, customer or service")
+	userID     = flag.String("user", "", "user of a customer key")
+)
+
+// apikey creates an api key from the command line, it is how the first admin key is made
+func main() {
+	flag.Parse()
+
+	if v := os.Getenv("CONFIG_PATH"); len(v) > 0 {
+		*configPath = v
+	}
+	c := config.MustReadConfig(*configPath)
+
+	var user uuid.UUID
+	if *userID != "" {
+		parsed, err := uuid.Parse(*userID)
+		if err != nil {
+			log.Fatalf("Invalid user ID: %v", err)
+		}
+		user = parsed
+	}
+
+	ctx := context.Background()
+	appContainer := app.NewMustApp(c)
+	key, secret, err := appContainer.AuthService(ctx).CreateAPIKey(ctx, *name, entities.Role(*role), user)
+	if err != nil {
+		log.Fatalf("Failed to create api key: %v", err)
+	}
+
+	fmt.Printf("Created %s key %s (%s)\n", key.Role, key.Name, key.ID)
+	fmt.Println("Secret, it is not shown again:")
+	fmt.Println(secret)
+}
@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"finance/config"
 	"finance/internal/api/handlers/messaging"
 	"finance/internal/app"
+	"finance/internal/infra/metrics"
 	"finance/pkg/logger"
 	"flag"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var configPath = flag.String("config", "config.yaml", "service configuration file")
@@ -21,15 +25,24 @@ func main() {
 		*configPath = v
 	}
 	c := config.MustReadConfig(*configPath)
-	appLogger := logger.NewLogger(logger.LogLevel("info"))
-
 	appContainer := app.NewMustApp(c)
+	appLogger := appContainer.Logger()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	ctx = logger.WithTraceID(ctx)
 
 	walletService := appContainer.WalletService(ctx)
-	consumer := messaging.NewConsumerHandler(walletService, c, appContainer.RabbitConn(), appLogger)
+	consumer := messaging.NewConsumerHandler(walletService, appContainer.FXService(ctx), c, appContainer.RabbitConn(), appLogger)
+
+	checker := appContainer.HealthChecker()
+	consumer.HealthChecks(checker)
+	healthServer := checker.NewServer(c.Consumer.HealthAddr(), metrics.Handler())
+	go func() {
+		appLogger.Info(ctx, "Starting health listener", "addr", healthServer.Addr)
+		if err := healthServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Error(ctx, "Health listener stopped", "error", err)
+		}
+	}()
 
 	// Graceful shutdown handling
 	sigChan := make(chan os.Signal, 1)
@@ -37,7 +50,7 @@ func main() {
 
 	errChan := make(chan error, 1)
 	go func() {
-		appLogger.Logger.Info("Starting SMS consumer worker")
+		appLogger.Info(ctx, "Starting SMS consumer worker")
 		if err := consumer.Run(ctx); err != nil && err != context.Canceled {
 			errChan <- err
 		}
@@ -45,13 +58,22 @@ func main() {
 
 	select {
 	case sig := <-sigChan:
-		appLogger.Logger.Info("Received shutdown signal", "signal", sig)
+		appLogger.Info(ctx, "Received shutdown signal", "signal", sig)
 		cancel()
 	case err := <-errChan:
 		appLogger.Info(ctx, "Consumer error", "error", err)
 		cancel()
 	}
 
-	appLogger.Logger.Info("SMS consumer worker shutdown complete")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := healthServer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error(ctx, "Health listener shutdown", "error", err)
+	}
+	if err := appContainer.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error(ctx, "Tracing shutdown", "error", err)
+	}
+
+	appLogger.Info(ctx, "SMS consumer worker shutdown complete")
 
 }
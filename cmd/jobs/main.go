@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"finance/config"
+	"finance/internal/app"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var (
+	configPath = flag.String("config", "config.yaml", "service configuration file")
+	job        = flag.String("job", "expire-bonus", "job to run: expire-bonus, monthly-invoices, balance-snapshots, idempotency-cleanup")
+	interval   = flag.Duration("interval", 0, "run the job every interval, 0 runs it once, e.g. for cron")
+)
+
+func main() {
+	flag.Parse()
+
+	if v := os.Getenv("CONFIG_PATH"); len(v) > 0 {
+		*configPath = v
+	}
+	c := config.MustReadConfig(*configPath)
+	appContainer := app.NewMustApp(c)
+	appLogger := appContainer.Logger()
+	defer appContainer.Shutdown(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	ctx = logger.WithTraceID(ctx)
+
+	run, ok := jobs(appContainer, appLogger)[*job]
+	if !ok {
+		appLogger.Error(ctx, "unknown job", "job", *job)
+		os.Exit(1)
+	}
+
+	if *interval <= 0 {
+		if err := run(ctx); err != nil {
+			appLogger.Error(ctx, "job failed", "job", *job, "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := run(ctx); err != nil {
+			appLogger.Error(ctx, "job failed", "job", *job, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			appLogger.Info(ctx, "jobs worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func jobs(appContainer app.App, log *logger.Logger) map[string]func(ctx context.Context) error {
+	return map[string]func(ctx context.Context) error{
+		"expire-bonus": func(ctx context.Context) error {
+			expired, err := appContainer.WalletService(ctx).ExpireBonusCredit(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			log.Info(ctx, "expired bonus credit", "wallets", expired)
+			return nil
+		},
+		// invoices the month that ended last, already invoiced users are left as they are
+		"monthly-invoices": func(ctx context.Context) error {
+			lastMonth := entities.StartOfMonth(time.Now()).AddDate(0, -1, 0)
+			generated, err := appContainer.InvoiceService(ctx).GenerateMonthlyInvoices(ctx, lastMonth)
+			if err != nil {
+				return err
+			}
+			log.Info(ctx, "generated monthly invoices", "invoices", generated)
+			return nil
+		},
+		// snapshots balances as of the last midnight, meant to run nightly
+		"balance-snapshots": func(ctx context.Context) error {
+			taken, err := appContainer.BalanceSnapshotService(ctx).TakeSnapshots(ctx, entities.StartOfDay(time.Now()))
+			if err != nil {
+				return err
+			}
+			log.Info(ctx, "took balance snapshots", "wallets", taken)
+			return nil
+		},
+		"idempotency-cleanup": func(ctx context.Context) error {
+			deleted, err := appContainer.IdempotencyService(ctx).DeleteExpired(ctx, time.Now())
+			if err != nil {
+				return err
+			}
+			log.Info(ctx, "deleted expired idempotency records", "records", deleted)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"finance/config"
+	"finance/internal/app"
+	"finance/pkg/logger"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var (
+	configPath = flag.String("config", "config.yaml", "service configuration file")
+	repair     = flag.Bool("repair", false, "set mismatched balances to the sum of their transactions, without it the run is a dry run")
+	output     = flag.String("output", "-", "file the json reports are appended to, - is stdout")
+	interval   = flag.Duration("interval", 0, "reconcile every interval, 0 runs once and exits with status 2 on discrepancies")
+)
+
+func main() {
+	flag.Parse()
+
+	if v := os.Getenv("CONFIG_PATH"); len(v) > 0 {
+		*configPath = v
+	}
+	c := config.MustReadConfig(*configPath)
+	appContainer := app.NewMustApp(c)
+	// os.Exit skips deferred calls, the container is shut down before the exit status is set
+	code := run(appContainer)
+	appContainer.Shutdown(context.Background())
+	os.Exit(code)
+}
+
+// run reconciles once or every interval and returns the exit status
+func run(appContainer app.App) int {
+	appLogger := appContainer.Logger()
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	ctx = logger.WithTraceID(ctx)
+
+	if *interval <= 0 {
+		found, err := reconcile(ctx, appContainer)
+		if err != nil {
+			appLogger.Error(ctx, "reconciliation failed", "error", err)
+			return 1
+		}
+		if found {
+			return 2
+		}
+		return 0
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if _, err := reconcile(ctx, appContainer); err != nil {
+			appLogger.Error(ctx, "reconciliation failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			appLogger.Info(ctx, "reconciliation stopped")
+			return 0
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile writes the report of one run as a json line
+func reconcile(ctx context.Context, appContainer app.App) (bool, error) {
+	report, err := appContainer.WalletService(ctx).ReconcileBalances(ctx, *repair)
+	if err != nil {
+		return false, err
+	}
+
+	out := os.Stdout
+	if *output != "-" {
+		if out, err = os.OpenFile(*output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+			return false, err
+		}
+		defer out.Close()
+	}
+	if err := json.NewEncoder(out).Encode(report); err != nil {
+		return false, err
+	}
+	return len(report.Discrepancies) > 0, nil
+}
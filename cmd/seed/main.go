@@ -40,6 +40,10 @@ func main() {
 		log.Fatalf("Failed to seed data: %v", err)
 	}
 
+	if err := seedTariff(db); err != nil {
+		log.Fatalf("Failed to seed tariff: %v", err)
+	}
+
 	fmt.Println("Database seeding completed successfully!")
 }
 
@@ -50,6 +54,14 @@ func clearData(db *gorm.DB) error {
 		return fmt.Errorf("failed to clear transactions: %w", err)
 	}
 
+	if err := db.Exec("DELETE FROM tariff_rates").Error; err != nil {
+		return fmt.Errorf("failed to clear tariff rates: %w", err)
+	}
+
+	if err := db.Exec("DELETE FROM tariffs").Error; err != nil {
+		return fmt.Errorf("failed to clear tariffs: %w", err)
+	}
+
 	if err := db.Exec("DELETE FROM wallets").Error; err != nil {
 		return fmt.Errorf("failed to clear wallets: %w", err)
 	}
@@ -125,3 +137,30 @@ func seedUsersAndWallets(db *gorm.DB) error {
 
 	return nil
 }
+
+// seedTariff activates a flat IRR tariff so debits can be priced right away
+func seedTariff(db *gorm.DB) error {
+	fmt.Println("Seeding tariff...")
+
+	tariffID := uuid.New()
+	rates := []types.TariffRate{
+		{Base: types.Base{ID: uuid.New()}, TariffID: tariffID, Prefix: "", MessageType: "normal", PricePerSegment: types.NewBigInt(big.NewInt(1000))},
+		{Base: types.Base{ID: uuid.New()}, TariffID: tariffID, Prefix: "", MessageType: "flash", PricePerSegment: types.NewBigInt(big.NewInt(1200))},
+		{Base: types.Base{ID: uuid.New()}, TariffID: tariffID, Prefix: "", MessageType: "unicode", PricePerSegment: types.NewBigInt(big.NewInt(1500))},
+	}
+
+	tariff := types.Tariff{
+		Base:     types.Base{ID: tariffID},
+		Version:  1,
+		Name:     "default",
+		Currency: "IRR",
+		Active:   true,
+		Rates:    rates,
+	}
+	if err := db.Create(&tariff).Error; err != nil {
+		return fmt.Errorf("failed to create tariff: %w", err)
+	}
+
+	fmt.Println("Created active tariff version 1 for IRR")
+	return nil
+}
@@ -1,14 +1,91 @@
 package config
 
+import "fmt"
+
 type Config struct {
 	Server   Server   `yaml:"server"`
 	DB       DB       `yaml:"database"`
 	RabbitMQ RabbitMQ `yaml:"rabbitmq"`
+	Auth     Auth     `yaml:"auth"`
+	Consumer Consumer `yaml:"consumer"`
+	Tracing  Tracing  `yaml:"tracing"`
+	Logging  Logging  `yaml:"logging"`
+}
+
+// Logging configures the logs of every binary. Level is debug, info, warn or error and can be
+// changed at runtime on /api/v1/admin/log-level, Format is json or text, Output stdout, stderr
+// or a file path
+type Logging struct {
+	Level    string      `yaml:"level"`
+	Format   string      `yaml:"format"`
+	Output   string      `yaml:"output"`
+	Sampling LogSampling `yaml:"sampling"`
+}
+
+// LogSampling keeps the first Initial debug and info records of a message per second, then only
+// every Thereafter-th. Zero Initial logs everything
+type LogSampling struct {
+	Initial    int `yaml:"initial"`
+	Thereafter int `yaml:"thereafter"`
+}
+
+// Tracing configures where spans go: otlp sends them to a collector over http, stdout and file
+// write them as json for local debugging, empty or none turns tracing off
+type Tracing struct {
+	Exporter string `yaml:"exporter"`
+	// Endpoint is the collector's host:port
+	Endpoint string `yaml:"endpoint"`
+	Insecure bool   `yaml:"insecure"`
+	File     string `yaml:"file"`
+	// SampleRatio is the share of new traces kept, zero keeps every trace
+	SampleRatio float64 `yaml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name"`
+}
+
+// Consumer configures the side listener serving the consumer's /healthz, /readyz and /metrics
+type Consumer struct {
+	HealthPort int `yaml:"health_port"`
+}
+
+const DefaultConsumerHealthPort = 8082
+
+func (c Consumer) HealthAddr() string {
+	if c.HealthPort == 0 {
+		return fmt.Sprintf(":%d", DefaultConsumerHealthPort)
+	}
+	return fmt.Sprintf(":%d", c.HealthPort)
 }
 
 type Server struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host      string    `yaml:"host"`
+	Port      int       `yaml:"port"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+}
+
+// RateLimit configures token buckets per api client. Store is memory for a single instance or
+// postgres when instances share the counters. A request gets the most specific matching rule,
+// without one the default applies, a zero limit means no limit. PerIP limits every address
+// before its requests are authenticated
+type RateLimit struct {
+	Store   string          `yaml:"store"`
+	PerIP   Limit           `yaml:"per_ip"`
+	Default Limit           `yaml:"default"`
+	Rules   []RateLimitRule `yaml:"rules"`
+}
+
+type Limit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is the bucket size, zero means RequestsPerMinute
+	Burst int `yaml:"burst"`
+}
+
+// RateLimitRule applies to the requests it matches, empty fields match anything. Path is a route
+// as registered, like /api/v1/wallet/user/:user_id, Client a principal like api-key:<id> or signing-key:<client>
+type RateLimitRule struct {
+	Client string `yaml:"client"`
+	Method string `yaml:"method"`
+	Path   string `yaml:"path"`
+	Limit  `yaml:",inline"`
 }
 
 type RabbitMQ struct {
@@ -30,3 +107,15 @@ type DB struct {
 	Database string `yaml:"database"`
 	Schema   string `yaml:"schema"`
 }
+
+// Auth configures how jwt bearer tokens are verified, with the keys of a JWKS file or a
+// single PEM public key. With neither only api keys are accepted. NonceStore keeps the nonces
+// of signed requests, memory for a single instance or postgres when a request replayed to
+// another instance must be caught too
+type Auth struct {
+	JWKSFile      string `yaml:"jwks_file"`
+	PublicKeyFile string `yaml:"public_key_file"`
+	Issuer        string `yaml:"issuer"`
+	Audience      string `yaml:"audience"`
+	NonceStore    string `yaml:"nonce_store"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required"`
+	// admin, finance, customer or service
+	Role string `json:"role" validate:"required"`
+	// required for customer keys, the only user the key can read
+	UserID string `json:"user_id,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      string     `json:"role"`
+	UserID    string     `json:"user_id,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the secret, it is not shown again
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type AuditEventResponse struct {
+	ID            string    `json:"id"`
+	WalletID      string    `json:"wallet_id"`
+	Sequence      int64     `json:"sequence"`
+	Action        string    `json:"action"`
+	Category      string    `json:"category,omitempty"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Actor         string    `json:"actor"`
+	Source        string    `json:"source"`
+	TraceID       string    `json:"trace_id,omitempty"`
+	PayloadHash   string    `json:"payload_hash,omitempty"`
+	Currency      string    `json:"currency"`
+	BalanceBefore string    `json:"balance_before"`
+	BalanceAfter  string    `json:"balance_after"`
+	PrevHash      string    `json:"prev_hash"`
+	Hash          string    `json:"hash"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AuditVerificationResponse struct {
+	WalletID string `json:"wallet_id"`
+	Events   int    `json:"events"`
+	Valid    bool   `json:"valid"`
+	// sequence of the first event that does not match, zero when the chain is intact
+	BrokenAt int64 `json:"broken_at,omitempty"`
+}
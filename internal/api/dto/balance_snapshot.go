@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type BalanceAtResponse struct {
+	WalletID string    `json:"wallet_id"`
+	Currency string    `json:"currency"`
+	At       time.Time `json:"at"`
+	Balance  int64     `json:"balance"`
+	// the snapshot the transactions were replayed from, empty when there was none before at
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type GrantBonusRequest struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+	// lower priorities are spent first, paid balance has priority 100, defaults to 0
+	Priority int `json:"priority"`
+	// optional, e.g. the end of the month for promotional credit
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason"`
+}
+
+type BalanceBucketResponse struct {
+	ID        string     `json:"id"`
+	Kind      string     `json:"kind"`
+	Granted   int64      `json:"granted"`
+	Remaining int64      `json:"remaining"`
+	Currency  string     `json:"currency"`
+	Priority  int        `json:"priority"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type CreateFXRateRequest struct {
+	Base  string `json:"base" validate:"required,len=3"`
+	Quote string `json:"quote" validate:"required,len=3"`
+	// units of quote per unit of base, a decimal like "0.0000238" or a fraction like "1/42000"
+	Rate string `json:"rate" validate:"required"`
+	// empty means now
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+type FXRateResponse struct {
+	ID         string     `json:"id"`
+	Base       string     `json:"base"`
+	Quote      string     `json:"quote"`
+	Rate       string     `json:"rate"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	Source     string     `json:"source"`
+}
+
+type OpenSubBalanceRequest struct {
+	Currency string `json:"currency" validate:"required,len=3"`
+}
+
+type SubBalanceResponse struct {
+	ID       string `json:"id"`
+	Currency string `json:"currency"`
+	Balance  int64  `json:"balance"`
+}
+
+type ConvertSubBalanceRequest struct {
+	Currency string `json:"currency" validate:"required,len=3"`
+	Amount   int64  `json:"amount" validate:"required,gt=0"`
+}
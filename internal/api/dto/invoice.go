@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+type GenerateInvoiceRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid4"`
+	// the invoiced month as YYYY-MM, it has to be over
+	Month string `json:"month" validate:"required"`
+}
+
+type InvoiceLineResponse struct {
+	Day         string `json:"day"`
+	Type        string `json:"type"`
+	Category    string `json:"category,omitempty"`
+	MessageType string `json:"message_type,omitempty"`
+	Count       int64  `json:"count"`
+	Segments    int64  `json:"segments,omitempty"`
+	Amount      int64  `json:"amount"`
+	Tax         int64  `json:"tax,omitempty"`
+}
+
+type InvoiceResponse struct {
+	ID             string                `json:"id"`
+	Number         string                `json:"number"`
+	UserID         string                `json:"user_id"`
+	WalletID       string                `json:"wallet_id"`
+	Currency       string                `json:"currency"`
+	PeriodStart    time.Time             `json:"period_start"`
+	PeriodEnd      time.Time             `json:"period_end"`
+	OpeningBalance int64                 `json:"opening_balance"`
+	Credits        int64                 `json:"credits"`
+	Refunds        int64                 `json:"refunds"`
+	Debits         int64                 `json:"debits"`
+	Tax            int64                 `json:"tax"`
+	ClosingBalance int64                 `json:"closing_balance"`
+	Lines          []InvoiceLineResponse `json:"lines,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+}
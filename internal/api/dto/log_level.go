@@ -0,0 +1,10 @@
+package dto
+
+type LogLevelRequest struct {
+	// debug, info, warn or error
+	Level string `json:"level" validate:"required"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
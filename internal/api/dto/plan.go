@@ -0,0 +1,45 @@
+package dto
+
+type PlanTierRequest struct {
+	// sms of the month covered by the tier, 0 means no upper bound and is only allowed on the last tier
+	UpTo int64 `json:"up_to" validate:"gte=0"`
+	// discount on the tariff price in basis points, 250 is 2.5%
+	DiscountBPS int64 `json:"discount_bps" validate:"gte=0,lt=10000"`
+}
+
+type CreatePlanRequest struct {
+	// matched against the plan of tariff rates
+	Code  string            `json:"code" validate:"required"`
+	Name  string            `json:"name"`
+	Tiers []PlanTierRequest `json:"tiers"`
+}
+
+type UpdatePlanRequest struct {
+	Name  string            `json:"name"`
+	Tiers []PlanTierRequest `json:"tiers"`
+}
+
+type PlanTierResponse struct {
+	UpTo        int64 `json:"up_to"`
+	DiscountBPS int64 `json:"discount_bps"`
+}
+
+type PlanResponse struct {
+	ID    string             `json:"id"`
+	Code  string             `json:"code"`
+	Name  string             `json:"name"`
+	Tiers []PlanTierResponse `json:"tiers"`
+}
+
+type AssignPlanRequest struct {
+	PlanID string `json:"plan_id" validate:"required,uuid4"`
+}
+
+type PlanAssignmentResponse struct {
+	ID        string  `json:"id"`
+	UserID    string  `json:"user_id"`
+	PlanID    string  `json:"plan_id"`
+	PlanCode  string  `json:"plan_code"`
+	StartedAt string  `json:"started_at"`
+	EndedAt   *string `json:"ended_at,omitempty"`
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+type CreateSigningKeyRequest struct {
+	Client string `json:"client" validate:"required"`
+	// service or finance, empty means service
+	Role string `json:"role,omitempty"`
+}
+
+type RotateSigningKeyRequest struct {
+	Client string `json:"client" validate:"required"`
+	// how long the current keys keep working, empty means a day
+	GraceSeconds *int64 `json:"grace_seconds,omitempty"`
+}
+
+type SigningKeyResponse struct {
+	ID        string     `json:"id"`
+	Client    string     `json:"client"`
+	Role      string     `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateSigningKeyResponse carries the secret, it is not shown again
+type CreateSigningKeyResponse struct {
+	SigningKeyResponse
+	Secret string `json:"secret"`
+}
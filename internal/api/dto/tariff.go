@@ -0,0 +1,36 @@
+package dto
+
+type TariffRateRequest struct {
+	// matched against the receiver without + or leading 00, e.g. 98912
+	Prefix   string `json:"prefix"`
+	Operator string `json:"operator,omitempty"`
+	// normal, flash or unicode
+	MessageType string `json:"message_type" validate:"required"`
+	// empty means the rate applies to every plan
+	Plan            string `json:"plan,omitempty"`
+	PricePerSegment int64  `json:"price_per_segment" validate:"required,gt=0"`
+}
+
+type CreateTariffRequest struct {
+	Name     string              `json:"name"`
+	Currency string              `json:"currency" validate:"required"`
+	Rates    []TariffRateRequest `json:"rates" validate:"required"`
+}
+
+type TariffRateResponse struct {
+	Prefix          string `json:"prefix"`
+	Operator        string `json:"operator,omitempty"`
+	MessageType     string `json:"message_type"`
+	Plan            string `json:"plan,omitempty"`
+	PricePerSegment int64  `json:"price_per_segment"`
+}
+
+type TariffResponse struct {
+	ID        string               `json:"id"`
+	Version   int64                `json:"version"`
+	Name      string               `json:"name"`
+	Currency  string               `json:"currency"`
+	Active    bool                 `json:"active"`
+	Rates     []TariffRateResponse `json:"rates"`
+	CreatedAt string               `json:"created_at"`
+}
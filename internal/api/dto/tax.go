@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+type CreateTaxRuleRequest struct {
+	Name string `json:"name"`
+	// individual or business, empty covers customers without a rule of their own
+	CustomerType string `json:"customer_type,omitempty"`
+	// debit or credit
+	AppliesTo string `json:"applies_to" validate:"required"`
+	// 900 is 9%
+	RateBPS int64 `json:"rate_bps" validate:"gte=0,lte=10000"`
+	// inclusive rules take the tax out of the amount, exclusive rules add it on top
+	Inclusive bool `json:"inclusive"`
+	// empty means now
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
+
+type TaxRuleResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	CustomerType string     `json:"customer_type,omitempty"`
+	AppliesTo    string     `json:"applies_to"`
+	RateBPS      int64      `json:"rate_bps"`
+	Inclusive    bool       `json:"inclusive"`
+	ValidFrom    time.Time  `json:"valid_from"`
+	ValidUntil   *time.Time `json:"valid_until,omitempty"`
+}
+
+type TaxTotalResponse struct {
+	Type     string `json:"type"`
+	Category string `json:"category,omitempty"`
+	Currency string `json:"currency"`
+	Count    int64  `json:"count"`
+	Net      int64  `json:"net"`
+	Tax      int64  `json:"tax"`
+	Gross    int64  `json:"gross"`
+}
+
+type TaxReportResponse struct {
+	From   time.Time          `json:"from"`
+	To     time.Time          `json:"to"`
+	Totals []TaxTotalResponse `json:"totals"`
+	// tax owed per currency, tax given back with refunds is subtracted
+	Due map[string]int64 `json:"due"`
+}
+
+type SetCustomerTypeRequest struct {
+	CustomerType string `json:"customer_type" validate:"required"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+type TopUpRuleRequest struct {
+	// the rule fires when the balance drops below threshold
+	Threshold       int64  `json:"threshold" validate:"gte=0"`
+	Amount          int64  `json:"amount" validate:"required,gt=0"`
+	MaxPerDay       int64  `json:"max_per_day" validate:"required,gt=0"`
+	FundingSourceID string `json:"funding_source_id" validate:"required"`
+	// defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+type TopUpRuleResponse struct {
+	ID              string `json:"id"`
+	WalletID        string `json:"wallet_id"`
+	Threshold       int64  `json:"threshold"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	MaxPerDay       int64  `json:"max_per_day"`
+	FundingSourceID string `json:"funding_source_id"`
+	Enabled         bool   `json:"enabled"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type TransferRequest struct {
+	FromUserID string `json:"from_user_id" validate:"required,uuid4"`
+	ToUserID   string `json:"to_user_id" validate:"required,uuid4"`
+	Amount     int64  `json:"amount" validate:"required,gt=0"`
+}
+
+type TransferResponse struct {
+	ID                  string    `json:"id"`
+	FromUserID          string    `json:"from_user_id"`
+	ToUserID            string    `json:"to_user_id"`
+	FromWalletID        string    `json:"from_wallet_id"`
+	ToWalletID          string    `json:"to_wallet_id"`
+	Amount              int64     `json:"amount"`
+	Currency            string    `json:"currency"`
+	DebitTransactionID  string    `json:"debit_transaction_id"`
+	CreditTransactionID string    `json:"credit_transaction_id"`
+	CreatedAt           time.Time `json:"created_at"`
+}
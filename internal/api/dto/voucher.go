@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+type GenerateVouchersRequest struct {
+	Count    int    `json:"count" validate:"required,gt=0,lte=10000"`
+	Amount   int64  `json:"amount" validate:"required,gt=0"`
+	Currency string `json:"currency" validate:"required,len=3"`
+	// zero means no overall limit
+	MaxRedemptions int64 `json:"max_redemptions" validate:"gte=0"`
+	// defaults to 1
+	PerUserLimit int64      `json:"per_user_limit" validate:"gte=0"`
+	ValidFrom    *time.Time `json:"valid_from,omitempty"`
+	ValidUntil   *time.Time `json:"valid_until,omitempty"`
+	Campaign     string     `json:"campaign"`
+}
+
+type VoucherResponse struct {
+	ID             string     `json:"id"`
+	Code           string     `json:"code"`
+	Amount         int64      `json:"amount"`
+	Currency       string     `json:"currency"`
+	MaxRedemptions int64      `json:"max_redemptions"`
+	PerUserLimit   int64      `json:"per_user_limit"`
+	Redeemed       int64      `json:"redeemed"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	Campaign       string     `json:"campaign,omitempty"`
+}
+
+type RedeemVoucherRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid4"`
+	Code   string `json:"code" validate:"required"`
+}
+
+type RedeemVoucherResponse struct {
+	UserID        string `json:"user_id"`
+	TransactionID string `json:"transaction_id"`
+	VoucherID     string `json:"voucher_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
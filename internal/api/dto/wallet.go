@@ -3,10 +3,22 @@ package dto
 type CreditWalletRequest struct {
 	UserID string `json:"user_id" validate:"required,uuid4"`
 	Amount int    `json:"amount" validate:"required,gt=0"`
+	// empty means the wallet currency, other currencies are converted unless the wallet has a sub-balance in them
+	Currency string `json:"currency,omitempty" validate:"omitempty,len=3"`
 }
 
 type CreditWalletResponse struct {
-	UserID string `json:"user_id"`
+	UserID        string `json:"user_id"`
+	TransactionID string `json:"transaction_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// set when the amount was converted from another currency
+	SourceAmount   int64  `json:"source_amount,omitempty"`
+	SourceCurrency string `json:"source_currency,omitempty"`
+	FXRate         string `json:"fx_rate,omitempty"`
+	// set when a tax rule applied, amount is what the wallet received
+	Tax   int64 `json:"tax,omitempty"`
+	Gross int64 `json:"gross,omitempty"`
 }
 
 type GetWalletResponse struct {
@@ -14,6 +26,56 @@ type GetWalletResponse struct {
 	UserID   string `json:"user_id"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	// postpaid wallets, balance is negative while the credit line is in use
+	CreditLimit      int64 `json:"credit_limit"`
+	AmountOwed       int64 `json:"amount_owed"`
+	AvailableBalance int64 `json:"available_balance"`
+	// balance is paid_balance plus the bonus credit of the wallet's buckets
+	PaidBalance  int64 `json:"paid_balance"`
+	BonusBalance int64 `json:"bonus_balance"`
+	// debits fall through to the parent wallet once this one is empty
+	ParentWalletID string `json:"parent_wallet_id,omitempty"`
+	ParentQuota    int64  `json:"parent_quota,omitempty"`
+	// money held in other currencies, not part of balance
+	SubBalances []SubBalanceResponse `json:"sub_balances,omitempty"`
+}
+
+type SetCreditLimitRequest struct {
+	CreditLimit int64 `json:"credit_limit" validate:"gte=0"`
+}
+
+// zero means the limit is not set
+type SpendingLimitsRequest struct {
+	// an omitted or null amount clears the limit
+	MaxDebit        *int64 `json:"max_debit,omitempty"`
+	DailyAmount     *int64 `json:"daily_amount,omitempty"`
+	MonthlyAmount   *int64 `json:"monthly_amount,omitempty"`
+	DebitsPerMinute int64  `json:"debits_per_minute" validate:"gte=0"`
+}
+
+type SpendingLimitsResponse struct {
+	WalletID        string `json:"wallet_id"`
+	UserID          string `json:"user_id"`
+	Currency        string `json:"currency"`
+	MaxDebit        int64  `json:"max_debit"`
+	DailyAmount     int64  `json:"daily_amount"`
+	MonthlyAmount   int64  `json:"monthly_amount"`
+	DebitsPerMinute int64  `json:"debits_per_minute"`
+}
+
+type CreateBalanceThresholdRequest struct {
+	Threshold int64 `json:"threshold" validate:"required,gt=0"`
+	// the balance has to climb back to threshold + hysteresis before the alert can fire again
+	Hysteresis int64 `json:"hysteresis" validate:"gte=0"`
+}
+
+type BalanceThresholdResponse struct {
+	ID         string `json:"id"`
+	WalletID   string `json:"wallet_id"`
+	Threshold  int64  `json:"threshold"`
+	Hysteresis int64  `json:"hysteresis"`
+	Currency   string `json:"currency"`
+	Armed      bool   `json:"armed"`
 }
 
 type GetUserResponse struct {
@@ -22,6 +84,8 @@ type GetUserResponse struct {
 	LastName string  `json:"last_name"`
 	Phone    string  `json:"phone"`
 	WalletID *string `json:"wallet_id,omitempty"`
+	// individual or business, selects the tax rules of the user
+	CustomerType string `json:"customer_type,omitempty"`
 }
 
 type GetAllUsersResponse struct {
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+type SetParentWalletRequest struct {
+	ParentUserID string `json:"parent_user_id" validate:"required,uuid4"`
+	// most the child may draw from the parent a month, zero means no cap
+	MonthlyQuota int64 `json:"monthly_quota" validate:"gte=0"`
+}
+
+type SpendingReportResponse struct {
+	WalletID string    `json:"wallet_id"`
+	UserID   string    `json:"user_id"`
+	Currency string    `json:"currency"`
+	Since    time.Time `json:"since"`
+	// own is the wallet's sms spending, total adds the spending of every wallet below it
+	Own      int64                    `json:"own"`
+	Total    int64                    `json:"total"`
+	Children []SpendingReportResponse `json:"children,omitempty"`
+}
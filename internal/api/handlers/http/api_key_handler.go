@@ -0,0 +1,148 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	authService *usecase.AuthService
+}
+
+func NewAPIKeyHandler(authService *usecase.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{
+		authService: authService,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary      Create api key
+// @Description  Creates an api key with a role, the secret is returned only in this response
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateAPIKeyRequest  true  "Create API Key Request"
+// @Success      201      {object}  dto.CreateAPIKeyResponse "API key created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req dto.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	var userID uuid.UUID
+	if req.UserID != "" {
+		var err error
+		if userID, err = uuid.Parse(req.UserID); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+		}
+	}
+
+	ctx := c.UserContext()
+	key, secret, err := h.authService.CreateAPIKey(ctx, req.Name, entities.Role(req.Role), userID)
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "API key created successfully",
+		Data: dto.CreateAPIKeyResponse{
+			APIKeyResponse: apiKeyResponse(key),
+			Secret:         secret,
+		},
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary      List api keys
+// @Description  Lists every api key without its secret
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Success      200      {array}   dto.APIKeyResponse "API keys"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	keys, err := h.authService.ListAPIKeys(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		res[i] = apiKeyResponse(key)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "API keys retrieved successfully",
+		Data:    res,
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke api key
+// @Description  Revokes an api key, requests with it are rejected from now on
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        key_id   path      string  true  "API Key ID"
+// @Success      200      {object}  dto.APIKeyResponse "API key revoked"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "API key not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/api-keys/{key_id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("key_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid api key ID format")
+	}
+
+	ctx := c.UserContext()
+	key, err := h.authService.RevokeAPIKey(ctx, keyID)
+	if err != nil {
+		return apiKeyError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+		Data:    apiKeyResponse(key),
+	})
+}
+
+func apiKeyResponse(key *entities.APIKey) dto.APIKeyResponse {
+	res := dto.APIKeyResponse{
+		ID:        key.ID.String(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Role:      string(key.Role),
+		RevokedAt: key.RevokedAt,
+		CreatedAt: key.CreatedAt,
+	}
+	if key.UserID != uuid.Nil {
+		res.UserID = key.UserID.String()
+	}
+	return res
+}
+
+func apiKeyError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidRole),
+		errors.Is(err, entities.ErrInvalidAPIKey),
+		errors.Is(err, entities.ErrCustomerNoUser):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrAPIKeyNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AuditHandler struct {
+	auditService *usecase.AuditService
+}
+
+func NewAuditHandler(auditService *usecase.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditEvents godoc
+// @Summary      List audit events
+// @Description  Returns the audit events of balance changes, newest first
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        wallet_id  query     string  false  "Wallet ID"
+// @Param        actor      query     string  false  "Actor ID"
+// @Param        from       query     string  false  "RFC3339 start of the period, inclusive"
+// @Param        to         query     string  false  "RFC3339 end of the period, exclusive"
+// @Param        limit      query     int     false  "Maximum number of events, at most 1000"
+// @Success      200        {object}  []dto.AuditEventResponse "Audit events"
+// @Failure      400        {object}  map[string]interface{} "Bad Request"
+// @Failure      500        {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/audit-events [get]
+func (h *AuditHandler) ListAuditEvents(c *fiber.Ctx) error {
+	filter := entities.AuditFilter{
+		Actor: c.Query("actor"),
+		Limit: c.QueryInt("limit", 100),
+	}
+	var err error
+	if raw := c.Query("wallet_id"); raw != "" {
+		if filter.WalletID, err = uuid.Parse(raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid wallet ID format")
+		}
+	}
+	if raw := c.Query("from"); raw != "" {
+		if filter.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from, expected RFC3339")
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if filter.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to, expected RFC3339")
+		}
+	}
+
+	ctx := c.UserContext()
+	events, err := h.auditService.ListEvents(ctx, filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	response := make([]dto.AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, auditEventResponse(event))
+	}
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Audit events retrieved successfully",
+		Data:    response,
+	})
+}
+
+// VerifyAuditChain godoc
+// @Summary      Verify a wallet's audit chain
+// @Description  Recomputes the hashes of the wallet's audit events and reports the first one that does not match
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        wallet_id  query     string  true  "Wallet ID"
+// @Success      200        {object}  dto.AuditVerificationResponse "Verification result"
+// @Failure      400        {object}  map[string]interface{} "Bad Request"
+// @Failure      500        {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/audit-events/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Query("wallet_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid wallet ID format")
+	}
+
+	ctx := c.UserContext()
+	result, err := h.auditService.VerifyChain(ctx, walletID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Audit chain verified",
+		Data: dto.AuditVerificationResponse{
+			WalletID: result.WalletID.String(),
+			Events:   result.Events,
+			Valid:    result.Valid,
+			BrokenAt: result.BrokenAt,
+		},
+	})
+}
+
+func auditEventResponse(event *entities.AuditEvent) dto.AuditEventResponse {
+	response := dto.AuditEventResponse{
+		ID:            event.ID.String(),
+		WalletID:      event.WalletID.String(),
+		Sequence:      event.Sequence,
+		Action:        string(event.Action),
+		Category:      string(event.Category),
+		Actor:         event.Actor,
+		Source:        string(event.Source),
+		TraceID:       event.TraceID,
+		PayloadHash:   event.PayloadHash,
+		Currency:      event.Currency,
+		BalanceBefore: event.BalanceBefore.String(),
+		BalanceAfter:  event.BalanceAfter.String(),
+		PrevHash:      event.PrevHash,
+		Hash:          event.Hash,
+		CreatedAt:     event.CreatedAt,
+	}
+	if event.TransactionID != uuid.Nil {
+		response.TransactionID = event.TransactionID.String()
+	}
+	return response
+}
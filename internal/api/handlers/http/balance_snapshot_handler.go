@@ -0,0 +1,69 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BalanceSnapshotHandler struct {
+	snapshotService *usecase.BalanceSnapshotService
+}
+
+func NewBalanceSnapshotHandler(snapshotService *usecase.BalanceSnapshotService) *BalanceSnapshotHandler {
+	return &BalanceSnapshotHandler{
+		snapshotService: snapshotService,
+	}
+}
+
+// GetBalanceAt godoc
+// @Summary      Balance at a point in time
+// @Description  Returns the wallet balance made of the completed transactions created before the given time
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        wallet_id  path      string  true   "Wallet ID"
+// @Param        at         query     string  false  "RFC3339 time, defaults to now"
+// @Success      200        {object}  dto.BalanceAtResponse "Balance at the given time"
+// @Failure      400        {object}  map[string]interface{} "Bad Request"
+// @Failure      404        {object}  map[string]interface{} "Wallet not found"
+// @Failure      500        {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/{wallet_id}/balance-at [get]
+func (h *BalanceSnapshotHandler) GetBalanceAt(c *fiber.Ctx) error {
+	walletID, err := uuid.Parse(c.Params("wallet_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid wallet ID format")
+	}
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		if at, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid at, expected RFC3339")
+		}
+	}
+
+	ctx := c.UserContext()
+	balance, err := h.snapshotService.GetBalanceAt(ctx, walletID, at)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Balance retrieved successfully",
+		Data: dto.BalanceAtResponse{
+			WalletID:   balance.WalletID.String(),
+			Currency:   balance.Currency,
+			At:         balance.At,
+			Balance:    balance.Balance.Int64(),
+			SnapshotAt: balance.SnapshotAt,
+		},
+	})
+}
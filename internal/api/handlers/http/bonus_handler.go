@@ -0,0 +1,110 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"math/big"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GrantBonusCredit godoc
+// @Summary      Grant bonus credit
+// @Description  Adds promotional credit to a user's wallet in its own bucket, it is spent by priority and removed once it expires
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                 true  "User ID"
+// @Param        request  body      dto.GrantBonusRequest  true  "Grant Bonus Request"
+// @Success      201      {object}  dto.BalanceBucketResponse "Bonus credit granted"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/bonus [post]
+func (h *WalletHandler) GrantBonusCredit(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.GrantBonusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	bucket, err := h.walletService.GrantBonusCredit(ctx, userID, usecase.BonusInput{
+		Amount:    *big.NewInt(req.Amount),
+		Priority:  req.Priority,
+		ExpiresAt: req.ExpiresAt,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidAmount), errors.Is(err, entities.ErrInvalidExpiry):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Bonus credit granted successfully",
+		Data:    balanceBucketResponse(bucket),
+	})
+}
+
+// ListBalanceBuckets godoc
+// @Summary      List balance buckets
+// @Description  Lists the bonus buckets of a user's wallet that still hold credit
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {array}   dto.BalanceBucketResponse "Balance buckets"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/buckets [get]
+func (h *WalletHandler) ListBalanceBuckets(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	buckets, err := h.walletService.ListBalanceBuckets(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	}
+
+	res := make([]dto.BalanceBucketResponse, len(buckets))
+	for i, bucket := range buckets {
+		res[i] = balanceBucketResponse(bucket)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Balance buckets retrieved successfully",
+		Data:    res,
+	})
+}
+
+func balanceBucketResponse(bucket *entities.BalanceBucket) dto.BalanceBucketResponse {
+	return dto.BalanceBucketResponse{
+		ID:        bucket.ID.String(),
+		Kind:      string(bucket.Kind),
+		Granted:   bucket.Granted.Amount().Int64(),
+		Remaining: bucket.Remaining.Amount().Int64(),
+		Currency:  bucket.Remaining.Currency(),
+		Priority:  bucket.Priority,
+		ExpiresAt: bucket.ExpiresAt,
+		Reason:    bucket.Reason,
+	}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"bufio"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExportTransactions godoc
+// @Summary      Export wallet transactions
+// @Description  Streams the user's wallet transactions as csv or json lines, oldest first
+// @Tags         wallet
+// @Produce      plain
+// @Param        user_id  path      string  true   "User ID"
+// @Param        format   query     string  false  "csv or jsonl, defaults to csv"
+// @Param        from     query     string  false  "RFC3339 start time, defaults to the first transaction"
+// @Param        to       query     string  false  "RFC3339 end time, exclusive, defaults to now"
+// @Param        columns  query     string  false  "comma separated columns, defaults to id,created_at,wallet_id,user_id,type,category,status,amount,currency"
+// @Param        tz       query     string  false  "IANA timezone of exported times, defaults to UTC"
+// @Success      200      {string}  string "Export file"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/transactions/export [get]
+func (h *WalletHandler) ExportTransactions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+	opts, err := exportOptions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.UserContext()
+	export, err := h.walletService.ExportTransactions(ctx, userID, opts)
+	if err != nil {
+		return exportError(err)
+	}
+	return streamExport(c, export, "transactions-"+userID.String())
+}
+
+// ExportAllTransactions godoc
+// @Summary      Export all transactions
+// @Description  Streams the transactions of every wallet as csv or json lines, oldest first
+// @Tags         admin
+// @Produce      plain
+// @Param        format   query     string  false  "csv or jsonl, defaults to csv"
+// @Param        from     query     string  false  "RFC3339 start time, defaults to the first transaction"
+// @Param        to       query     string  false  "RFC3339 end time, exclusive, defaults to now"
+// @Param        columns  query     string  false  "comma separated columns, defaults to id,created_at,wallet_id,user_id,type,category,status,amount,currency"
+// @Param        tz       query     string  false  "IANA timezone of exported times, defaults to UTC"
+// @Success      200      {string}  string "Export file"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/transactions/export [get]
+func (h *WalletHandler) ExportAllTransactions(c *fiber.Ctx) error {
+	opts, err := exportOptions(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.UserContext()
+	export, err := h.walletService.ExportAllTransactions(ctx, opts)
+	if err != nil {
+		return exportError(err)
+	}
+	return streamExport(c, export, "transactions")
+}
+
+func exportOptions(c *fiber.Ctx) (usecase.ExportOptions, error) {
+	opts := usecase.ExportOptions{
+		Format: entities.ExportFormat(c.Query("format", string(entities.ExportCSV))),
+		To:     time.Now(),
+	}
+
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if opts.From, err = time.Parse(time.RFC3339, raw); err != nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "invalid from, expected RFC3339")
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if opts.To, err = time.Parse(time.RFC3339, raw); err != nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "invalid to, expected RFC3339")
+		}
+	}
+	if raw := c.Query("tz"); raw != "" {
+		if opts.Location, err = time.LoadLocation(raw); err != nil {
+			return opts, fiber.NewError(fiber.StatusBadRequest, "invalid tz, expected an IANA timezone")
+		}
+	}
+	if raw := c.Query("columns"); raw != "" {
+		for _, column := range strings.Split(raw, ",") {
+			opts.Columns = append(opts.Columns, strings.TrimSpace(column))
+		}
+	}
+	return opts, nil
+}
+
+// streamExport sends the headers and leaves the rows to a body stream writer, so they go out as they are read
+func streamExport(c *fiber.Ctx, export *usecase.TransactionExport, name string) error {
+	if export.Format == entities.ExportCSV {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, export.Format))
+
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// the status is sent by now, a failed export ends up truncated and logged
+		_ = export.Stream(ctx, w)
+		_ = w.Flush()
+	})
+	return nil
+}
+
+func exportError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidExportFormat),
+		errors.Is(err, entities.ErrUnknownExportColumn),
+		errors.Is(err, entities.ErrInvalidExportPeriod):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
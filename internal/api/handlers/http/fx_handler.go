@@ -0,0 +1,212 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"math/big"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FXHandler struct {
+	fxService *usecase.FXService
+}
+
+func NewFXHandler(fxService *usecase.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+// CreateFXRate godoc
+// @Summary      Upload fx rate
+// @Description  Stores a rate for a currency pair, credits use the newest rate whose validity window covers them
+// @Tags         fx
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateFXRateRequest  true  "Create FX Rate Request"
+// @Success      201      {object}  dto.FXRateResponse "Rate stored"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /fx-rates [post]
+func (h *FXHandler) CreateFXRate(c *fiber.Ctx) error {
+	var req dto.CreateFXRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	input := usecase.FXRateInput{
+		Base:       req.Base,
+		Quote:      req.Quote,
+		Rate:       req.Rate,
+		ValidUntil: req.ValidUntil,
+		Source:     entities.FXSourceManual,
+	}
+	if req.ValidFrom != nil {
+		input.ValidFrom = *req.ValidFrom
+	}
+
+	ctx := c.UserContext()
+	rate, err := h.fxService.SaveRate(ctx, input)
+	if err != nil {
+		return fxError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "FX rate stored successfully",
+		Data:    fxRateResponse(rate),
+	})
+}
+
+// ListFXRates godoc
+// @Summary      List fx rates
+// @Description  Lists stored rates, newest validity first
+// @Tags         fx
+// @Accept       json
+// @Produce      json
+// @Param        base   query     string  false  "Base currency"
+// @Param        quote  query     string  false  "Quote currency"
+// @Success      200    {array}   dto.FXRateResponse "Rates"
+// @Failure      500    {object}  map[string]interface{} "Internal Server Error"
+// @Router       /fx-rates [get]
+func (h *FXHandler) ListFXRates(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	rates, err := h.fxService.ListRates(ctx, strings.ToUpper(c.Query("base")), strings.ToUpper(c.Query("quote")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.FXRateResponse, len(rates))
+	for i, rate := range rates {
+		res[i] = fxRateResponse(rate)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "FX rates retrieved successfully",
+		Data:    res,
+	})
+}
+
+// OpenSubBalance godoc
+// @Summary      Open currency sub-balance
+// @Description  Keeps credits in the currency apart from the wallet balance instead of converting them
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                     true  "User ID"
+// @Param        request  body      dto.OpenSubBalanceRequest  true  "Open Sub-Balance Request"
+// @Success      201      {object}  dto.SubBalanceResponse "Sub-balance opened"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      409      {object}  map[string]interface{} "Sub-balance exists"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/sub-balances [post]
+func (h *WalletHandler) OpenSubBalance(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.OpenSubBalanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	sub, err := h.walletService.OpenSubBalance(ctx, userID, req.Currency)
+	if err != nil {
+		return fxError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Sub-balance opened successfully",
+		Data:    subBalanceResponse(sub),
+	})
+}
+
+// ConvertSubBalance godoc
+// @Summary      Convert sub-balance
+// @Description  Moves an amount out of a currency sub-balance into the wallet balance at the current fx rate
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                        true  "User ID"
+// @Param        request  body      dto.ConvertSubBalanceRequest  true  "Convert Sub-Balance Request"
+// @Success      200      {object}  dto.CreditWalletResponse "Converted"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet or sub-balance not found"
+// @Failure      422      {object}  map[string]interface{} "Insufficient sub-balance or no fx rate"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/sub-balances/convert [post]
+func (h *WalletHandler) ConvertSubBalance(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.ConvertSubBalanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	transaction, err := h.walletService.ConvertSubBalance(ctx, userID, req.Currency, big.NewInt(req.Amount))
+	if err != nil {
+		return fxError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Sub-balance converted successfully",
+		Data:    creditResponse(userID, transaction),
+	})
+}
+
+func fxError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidFXRate),
+		errors.Is(err, entities.ErrInvalidAmount),
+		errors.Is(err, valueobjects.ErrCurrencyMismatch):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrSubBalanceNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	case errors.Is(err, entities.ErrSubBalanceExists):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, entities.ErrFXRateNotFound),
+		errors.Is(err, entities.ErrInsufficientBalance):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func fxRateResponse(rate *entities.FXRate) dto.FXRateResponse {
+	return dto.FXRateResponse{
+		ID:         rate.ID.String(),
+		Base:       rate.Base,
+		Quote:      rate.Quote,
+		Rate:       rate.Rate.RatString(),
+		ValidFrom:  rate.ValidFrom,
+		ValidUntil: rate.ValidUntil,
+		Source:     string(rate.Source),
+	}
+}
+
+func subBalanceResponse(sub *entities.SubBalance) dto.SubBalanceResponse {
+	return dto.SubBalanceResponse{
+		ID:       sub.ID.String(),
+		Currency: sub.Currency,
+		Balance:  sub.Balance.Amount().Int64(),
+	}
+}
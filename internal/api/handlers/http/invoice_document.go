@@ -0,0 +1,41 @@
+package http
+
+import "html/template"
+
+// invoiceDocument renders an invoice as a printable page, browsers save it as pdf
+var invoiceDocument = template.Must(template.New("invoice").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; margin-top: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.amount, th.amount { text-align: right; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>
+User: {{.UserID}}<br>
+Wallet: {{.WalletID}}<br>
+Period: {{.PeriodStart.Format "2006-01-02"}} to {{.PeriodEnd.Format "2006-01-02"}}<br>
+Issued: {{.CreatedAt.Format "2006-01-02"}}
+</p>
+<table>
+<tr><th>Opening balance</th><td class="amount">{{.OpeningBalance}} {{.Currency}}</td></tr>
+<tr><th>Credits</th><td class="amount">{{.Credits}} {{.Currency}}</td></tr>
+<tr><th>Refunds</th><td class="amount">{{.Refunds}} {{.Currency}}</td></tr>
+<tr><th>Debits</th><td class="amount">{{.Debits}} {{.Currency}}</td></tr>
+<tr><th>Tax included</th><td class="amount">{{.Tax}} {{.Currency}}</td></tr>
+<tr><th>Closing balance</th><td class="amount">{{.ClosingBalance}} {{.Currency}}</td></tr>
+</table>
+<table>
+<tr><th>Day</th><th>Type</th><th>Category</th><th>SMS type</th><th class="amount">Count</th><th class="amount">Segments</th><th class="amount">Amount</th><th class="amount">Tax</th></tr>
+{{range .Lines}}<tr><td>{{.Day}}</td><td>{{.Type}}</td><td>{{.Category}}</td><td>{{.MessageType}}</td><td class="amount">{{.Count}}</td><td class="amount">{{.Segments}}</td><td class="amount">{{.Amount}}</td><td class="amount">{{.Tax}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
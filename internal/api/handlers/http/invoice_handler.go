@@ -0,0 +1,214 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvoiceHandler struct {
+	invoiceService *usecase.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceService *usecase.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+	}
+}
+
+// GenerateInvoice godoc
+// @Summary      Generate invoice
+// @Description  Builds the monthly statement of a user's wallet, asking again for the same month returns the stored invoice
+// @Tags         invoices
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.GenerateInvoiceRequest  true  "Generate Invoice Request"
+// @Success      200      {object}  dto.InvoiceResponse "Invoice"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      409      {object}  map[string]interface{} "Month is not over"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /invoices [post]
+func (h *InvoiceHandler) GenerateInvoice(c *fiber.Ctx) error {
+	var req dto.GenerateInvoiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+	month, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid month, expected YYYY-MM")
+	}
+
+	ctx := c.UserContext()
+	invoice, err := h.invoiceService.GenerateInvoice(ctx, userID, month)
+	if err != nil {
+		return invoiceError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Invoice generated successfully",
+		Data:    invoiceResponse(invoice),
+	})
+}
+
+// GetInvoice godoc
+// @Summary      Get invoice
+// @Description  Retrieves an invoice with its lines, customers only get the invoices of their own wallet
+// @Tags         invoices
+// @Accept       json
+// @Produce      json
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {object}  dto.InvoiceResponse "Invoice"
+// @Failure      400         {object}  map[string]interface{} "Bad Request"
+// @Failure      404         {object}  map[string]interface{} "Invoice not found"
+// @Router       /invoices/{invoice_id} [get]
+func (h *InvoiceHandler) GetInvoice(c *fiber.Ctx) error {
+	invoiceID, err := uuid.Parse(c.Params("invoice_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid invoice ID format")
+	}
+
+	invoice, err := h.findInvoice(c, invoiceID)
+	if err != nil {
+		return invoiceError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Invoice retrieved successfully",
+		Data:    invoiceResponse(invoice),
+	})
+}
+
+// GetInvoiceDocument godoc
+// @Summary      Invoice document
+// @Description  Renders an invoice as a printable html page, customers only get the invoices of their own wallet
+// @Tags         invoices
+// @Produce      html
+// @Param        invoice_id  path      string  true  "Invoice ID"
+// @Success      200         {string}  string "Invoice document"
+// @Failure      400         {object}  map[string]interface{} "Bad Request"
+// @Failure      404         {object}  map[string]interface{} "Invoice not found"
+// @Router       /invoices/{invoice_id}/document [get]
+func (h *InvoiceHandler) GetInvoiceDocument(c *fiber.Ctx) error {
+	invoiceID, err := uuid.Parse(c.Params("invoice_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid invoice ID format")
+	}
+
+	invoice, err := h.findInvoice(c, invoiceID)
+	if err != nil {
+		return invoiceError(err)
+	}
+
+	var doc bytes.Buffer
+	if err := invoiceDocument.Execute(&doc, invoiceResponse(invoice)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	c.Type("html", "utf-8")
+	return c.Send(doc.Bytes())
+}
+
+// ListInvoices godoc
+// @Summary      List user invoices
+// @Description  Lists a user's invoices, newest period first, without their lines
+// @Tags         invoices
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {array}   dto.InvoiceResponse "Invoices"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /user/{user_id}/invoices [get]
+func (h *InvoiceHandler) ListInvoices(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	invoices, err := h.invoiceService.ListInvoices(ctx, userID)
+	if err != nil {
+		return invoiceError(err)
+	}
+
+	res := make([]dto.InvoiceResponse, len(invoices))
+	for i, invoice := range invoices {
+		res[i] = invoiceResponse(invoice)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Invoices retrieved successfully",
+		Data:    res,
+	})
+}
+
+// findInvoice gets the invoice for the caller, a customer only gets the invoices of their own wallet
+func (h *InvoiceHandler) findInvoice(c *fiber.Ctx, invoiceID uuid.UUID) (*entities.Invoice, error) {
+	ctx := c.UserContext()
+	if principal, ok := entities.PrincipalFrom(ctx); ok && principal.Role == entities.RoleCustomer {
+		return h.invoiceService.GetUserInvoice(ctx, principal.UserID, invoiceID)
+	}
+	return h.invoiceService.GetInvoice(ctx, invoiceID)
+}
+
+func invoiceError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidInvoicePeriod):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrInvoiceNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "invoice not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	case errors.Is(err, entities.ErrPeriodNotClosed):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func invoiceResponse(invoice *entities.Invoice) dto.InvoiceResponse {
+	res := dto.InvoiceResponse{
+		ID:             invoice.ID.String(),
+		Number:         invoice.Number,
+		UserID:         invoice.UserID.String(),
+		WalletID:       invoice.WalletID.String(),
+		Currency:       invoice.Currency,
+		PeriodStart:    invoice.PeriodStart,
+		PeriodEnd:      invoice.PeriodEnd,
+		OpeningBalance: invoice.OpeningBalance.Int64(),
+		Credits:        invoice.Credits.Int64(),
+		Refunds:        invoice.Refunds.Int64(),
+		Debits:         invoice.Debits.Int64(),
+		Tax:            invoice.Tax.Int64(),
+		ClosingBalance: invoice.ClosingBalance.Int64(),
+		CreatedAt:      invoice.CreatedAt,
+	}
+	for _, line := range invoice.Lines {
+		res.Lines = append(res.Lines, dto.InvoiceLineResponse{
+			Day:         line.Day.Format("2006-01-02"),
+			Type:        string(line.Type),
+			Category:    string(line.Category),
+			MessageType: string(line.MessageType),
+			Count:       line.Count,
+			Segments:    line.Segments,
+			Amount:      line.Amount.Int64(),
+			Tax:         line.Tax.Int64(),
+		})
+	}
+	return res
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"finance/internal/api/dto"
+	"finance/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LogLevelHandler struct {
+	log *logger.Logger
+}
+
+func NewLogLevelHandler(log *logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{
+		log: log,
+	}
+}
+
+// GetLogLevel godoc
+// @Summary      Get log level
+// @Description  Returns the level the api currently logs at
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  dto.LogLevelResponse "Log level"
+// @Router       /admin/log-level [get]
+func (h *LogLevelHandler) GetLogLevel(c *fiber.Ctx) error {
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Log level retrieved successfully",
+		Data:    dto.LogLevelResponse{Level: string(h.log.Level())},
+	})
+}
+
+// SetLogLevel godoc
+// @Summary      Set log level
+// @Description  Changes the level the api logs at until it restarts, the configured level applies again after that
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.LogLevelRequest  true  "Log Level Request"
+// @Success      200      {object}  dto.LogLevelResponse "Log level changed"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Router       /admin/log-level [put]
+func (h *LogLevelHandler) SetLogLevel(c *fiber.Ctx) error {
+	var req dto.LogLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	previous := h.log.Level()
+	if err := h.log.SetLevel(logger.LogLevel(req.Level)); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	h.log.Warn(ctx, "log level changed", "from", previous, "to", h.log.Level())
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Log level changed successfully",
+		Data:    dto.LogLevelResponse{Level: string(h.log.Level())},
+	})
+}
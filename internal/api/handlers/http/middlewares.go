@@ -1,14 +1,28 @@
 package http
 
 import (
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/metrics"
+	"finance/internal/usecase"
 	"finance/pkg/logger"
+	"finance/pkg/signing"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func setTraceID() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := logger.WithTraceID(c.Context())
+		ctx := logger.WithTraceID(c.UserContext())
 		c.SetUserContext(ctx)
 
 		traceID := logger.GetTraceID(ctx)
@@ -16,3 +30,293 @@ func setTraceID() fiber.Handler {
 		return c.Next()
 	}
 }
+
+var tracer = otel.Tracer("finance/internal/api/handlers/http")
+
+// requestCarrier reads the w3c trace context of the caller from the request headers
+type requestCarrier struct {
+	c *fiber.Ctx
+}
+
+func (r requestCarrier) Get(key string) string {
+	return r.c.Get(key)
+}
+
+func (r requestCarrier) Set(key, value string) {
+	r.c.Request().Header.Set(key, value)
+}
+
+func (r requestCarrier) Keys() []string {
+	keys := make([]string, 0)
+	for key := range r.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// traceRequests starts the server span of a request, continuing the trace of a traceparent header.
+// The span is named after the route once it is matched
+func traceRequests() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), requestCarrier{c})
+		ctx, span := tracer.Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+			))
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", status),
+		)
+		if err != nil {
+			span.RecordError(err)
+		}
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+		return err
+	}
+}
+
+// observeRequests counts requests per route template so path params don't become labels, errors
+// are rendered here to know the status they end with
+func observeRequests() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		own := c.Route()
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		if c.Route() == own {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Response().StatusCode())
+		metrics.HTTPRequests.WithLabelValues(c.Method(), route, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Method(), route, status).Observe(time.Since(started).Seconds())
+		return nil
+	}
+}
+
+// verifySignature checks HMAC signed requests of other services, requests without a signature
+// key header are left to authenticate
+func verifySignature(signingService *usecase.SigningService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keyID := c.Get(signing.HeaderKeyID)
+		if keyID == "" {
+			return c.Next()
+		}
+
+		principal, err := signingService.Verify(c.UserContext(), entities.SignedRequest{
+			KeyID:     keyID,
+			Timestamp: c.Get(signing.HeaderTimestamp),
+			Nonce:     c.Get(signing.HeaderNonce),
+			Digest:    c.Get(signing.HeaderDigest),
+			Signature: c.Get(signing.HeaderSignature),
+			Method:    c.Method(),
+			URI:       c.OriginalURL(),
+			Body:      c.Body(),
+		})
+		if err != nil {
+			if errors.Is(err, entities.ErrInvalidSignature) ||
+				errors.Is(err, entities.ErrSignatureExpired) ||
+				errors.Is(err, entities.ErrReplayedRequest) {
+				return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		c.SetUserContext(entities.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
+
+// authenticate resolves the X-API-Key header or the bearer token to the request's principal,
+// a request already verified by its signature passes as is
+func authenticate(authService *usecase.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := entities.PrincipalFrom(c.UserContext()); ok {
+			return c.Next()
+		}
+
+		credential := c.Get("X-API-Key")
+		if credential == "" {
+			if header := c.Get(fiber.HeaderAuthorization); len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+				credential = strings.TrimSpace(header[7:])
+			}
+		}
+
+		principal, err := authService.Authenticate(c.UserContext(), credential)
+		if err != nil {
+			if errors.Is(err, entities.ErrUnauthenticated) {
+				c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+				return fiber.NewError(fiber.StatusUnauthorized, entities.ErrUnauthenticated.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+		c.SetUserContext(entities.WithPrincipal(c.UserContext(), principal))
+		return c.Next()
+	}
+}
+
+// allow lets the given roles and admins through, a customer only for the user in the user_id param
+func allow(roles ...entities.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := entities.PrincipalFrom(c.UserContext())
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, entities.ErrUnauthenticated.Error())
+		}
+		if !principal.HasRole(roles...) {
+			return fiber.NewError(fiber.StatusForbidden, entities.ErrForbidden.Error())
+		}
+		if principal.Role == entities.RoleCustomer {
+			userID, err := uuid.Parse(c.Params("user_id"))
+			if err != nil || !principal.CanAccessUser(userID) {
+				return fiber.NewError(fiber.StatusForbidden, entities.ErrForbidden.Error())
+			}
+		}
+		return c.Next()
+	}
+}
+
+// allowOwner is allow for routes without a user in the path, their handler checks that
+// a customer owns the resource asked for
+func allowOwner(roles ...entities.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := entities.PrincipalFrom(c.UserContext())
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, entities.ErrUnauthenticated.Error())
+		}
+		if !principal.HasRole(roles...) {
+			return fiber.NewError(fiber.StatusForbidden, entities.ErrForbidden.Error())
+		}
+		return c.Next()
+	}
+}
+
+// setAuditActor records who made the request, balance changes it causes are audited under this actor
+func setAuditActor() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		actorID := "anonymous"
+		if principal, ok := entities.PrincipalFrom(c.UserContext()); ok {
+			actorID = principal.ID
+		}
+		ctx := entities.WithAuditActor(c.UserContext(), entities.AuditActor{
+			ID:          actorID,
+			Source:      entities.AuditSourceHTTP,
+			PayloadHash: entities.PayloadHash(c.Body()),
+		})
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// idempotency makes mutating requests with an Idempotency-Key run once, repeats get the stored
+// response and a repeat sent while the first one runs gets 409. Keys are scoped to the route and
+// the caller, a request failing with an error or a 5xx status is not stored so it can be retried
+func idempotency(idempotencyService *usecase.IdempotencyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("Idempotency-Key")
+		if key == "" || !isMutating(c.Method()) {
+			return c.Next()
+		}
+
+		caller := "anonymous"
+		if principal, ok := entities.PrincipalFrom(c.UserContext()); ok {
+			caller = principal.ID
+		}
+		requestHash := entities.PayloadHash([]byte(c.Method() + "\n" + c.OriginalURL() + "\n" + string(c.Body())))
+		record, err := entities.NewIdempotencyRecord(key, c.Method()+" "+c.Path(), caller, requestHash)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		response, replayed, err := idempotencyService.Execute(c.UserContext(), record, func() (*entities.IdempotentResponse, error) {
+			if err := c.Next(); err != nil {
+				return nil, err
+			}
+			if c.Response().StatusCode() >= fiber.StatusInternalServerError {
+				return nil, nil
+			}
+			return &entities.IdempotentResponse{
+				StatusCode:  c.Response().StatusCode(),
+				ContentType: string(c.Response().Header.ContentType()),
+				Body:        append([]byte(nil), c.Response().Body()...),
+			}, nil
+		})
+		if err != nil {
+			if errors.Is(err, entities.ErrIdempotencyKeyReused) || errors.Is(err, entities.ErrIdempotencyKeyInProgress) {
+				return fiber.NewError(fiber.StatusConflict, err.Error())
+			}
+			return err
+		}
+		if replayed {
+			c.Set("Idempotent-Replayed", "true")
+			c.Set(fiber.HeaderContentType, response.ContentType)
+			return c.Status(response.StatusCode).Send(response.Body)
+		}
+		return nil
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// rateLimitIP takes a token from the bucket of the request's address, it runs ahead of the
+// signature and credential checks so requests failing them are limited too
+func rateLimitIP(rateLimitService *usecase.RateLimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, limited := rateLimitService.AllowIP(c.UserContext(), c.IP())
+		return applyRateLimit(c, result, limited)
+	}
+}
+
+// rateLimit takes a token from the caller's bucket for the route. It is set on each route rather
+// than the group, in a group middleware c.Route() is the group and not the matched route
+func rateLimit(rateLimitService *usecase.RateLimitService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := entities.PrincipalFrom(c.UserContext())
+		if !ok {
+			return c.Next()
+		}
+
+		result, limited := rateLimitService.Allow(c.UserContext(), principal.ID, c.Method(), c.Route().Path)
+		return applyRateLimit(c, result, limited)
+	}
+}
+
+// applyRateLimit sets the rate limit headers, a client out of tokens gets 429 until the time in Retry-After
+func applyRateLimit(c *fiber.Ctx, result entities.RateLimitResult, limited bool) error {
+	if !limited {
+		return c.Next()
+	}
+	c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Set("X-RateLimit-Reset", wholeSeconds(result.Reset))
+	if !result.Allowed {
+		c.Set(fiber.HeaderRetryAfter, wholeSeconds(result.RetryAfter))
+		return fiber.NewError(fiber.StatusTooManyRequests, "rate limit exceeded")
+	}
+	return c.Next()
+}
+
+// wholeSeconds rounds up, a client waiting the rounded down time would be limited again
+func wholeSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,306 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlanHandler struct {
+	planService *usecase.PlanService
+}
+
+func NewPlanHandler(planService *usecase.PlanService) *PlanHandler {
+	return &PlanHandler{
+		planService: planService,
+	}
+}
+
+// CreatePlan godoc
+// @Summary      Create plan
+// @Description  Creates a customer plan with volume discount tiers
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreatePlanRequest  true  "Create Plan Request"
+// @Success      201      {object}  dto.PlanResponse "Plan created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /plans [post]
+func (h *PlanHandler) CreatePlan(c *fiber.Ctx) error {
+	var req dto.CreatePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	plan, err := h.planService.CreatePlan(ctx, req.Code, req.Name, planTiers(req.Tiers))
+	if err != nil {
+		return planError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan created successfully",
+		Data:    planResponse(plan),
+	})
+}
+
+// ListPlans godoc
+// @Summary      List plans
+// @Description  Lists every customer plan
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Success      200      {array}   dto.PlanResponse "Plans"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /plans [get]
+func (h *PlanHandler) ListPlans(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	plans, err := h.planService.ListPlans(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.PlanResponse, len(plans))
+	for i, plan := range plans {
+		res[i] = planResponse(plan)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plans retrieved successfully",
+		Data:    res,
+	})
+}
+
+// GetPlan godoc
+// @Summary      Get plan
+// @Description  Retrieves a customer plan with its tiers
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        plan_id  path      string  true  "Plan ID"
+// @Success      200      {object}  dto.PlanResponse "Plan"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Plan not found"
+// @Router       /plans/{plan_id} [get]
+func (h *PlanHandler) GetPlan(c *fiber.Ctx) error {
+	planID, err := uuid.Parse(c.Params("plan_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid plan ID format")
+	}
+
+	ctx := c.UserContext()
+	plan, err := h.planService.GetPlan(ctx, planID)
+	if err != nil {
+		return planError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan retrieved successfully",
+		Data:    planResponse(plan),
+	})
+}
+
+// UpdatePlan godoc
+// @Summary      Update plan
+// @Description  Replaces the name and tiers of a plan, past transactions keep the discount they got
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        plan_id  path      string                 true  "Plan ID"
+// @Param        request  body      dto.UpdatePlanRequest  true  "Update Plan Request"
+// @Success      200      {object}  dto.PlanResponse "Plan updated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Plan not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /plans/{plan_id} [put]
+func (h *PlanHandler) UpdatePlan(c *fiber.Ctx) error {
+	planID, err := uuid.Parse(c.Params("plan_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid plan ID format")
+	}
+
+	var req dto.UpdatePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	plan, err := h.planService.UpdatePlan(ctx, planID, req.Name, planTiers(req.Tiers))
+	if err != nil {
+		return planError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan updated successfully",
+		Data:    planResponse(plan),
+	})
+}
+
+// DeletePlan godoc
+// @Summary      Delete plan
+// @Description  Deletes a plan that no user is currently on
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        plan_id  path      string  true  "Plan ID"
+// @Success      200      {object}  dto.BaseResponse "Plan deleted"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Plan not found"
+// @Failure      409      {object}  map[string]interface{} "Plan is assigned to users"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /plans/{plan_id} [delete]
+func (h *PlanHandler) DeletePlan(c *fiber.Ctx) error {
+	planID, err := uuid.Parse(c.Params("plan_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid plan ID format")
+	}
+
+	ctx := c.UserContext()
+	if err := h.planService.DeletePlan(ctx, planID); err != nil {
+		return planError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan deleted successfully",
+	})
+}
+
+// AssignPlan godoc
+// @Summary      Assign plan to user
+// @Description  Moves a user to a plan, the previous plan is kept in the user's plan history
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                 true  "User ID"
+// @Param        request  body      dto.AssignPlanRequest  true  "Assign Plan Request"
+// @Success      200      {object}  dto.PlanAssignmentResponse "Plan assigned"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "User or plan not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /user/{user_id}/plan [put]
+func (h *PlanHandler) AssignPlan(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.AssignPlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	planID, err := uuid.Parse(req.PlanID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid plan ID format")
+	}
+
+	ctx := c.UserContext()
+	assignment, err := h.planService.AssignPlan(ctx, userID, planID)
+	if err != nil {
+		return planError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan assigned successfully",
+		Data:    planAssignmentResponse(assignment),
+	})
+}
+
+// GetPlanHistory godoc
+// @Summary      Get user plan history
+// @Description  Lists the plans a user has been on, newest first
+// @Tags         plan
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {array}   dto.PlanAssignmentResponse "Plan history"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /user/{user_id}/plan-history [get]
+func (h *PlanHandler) GetPlanHistory(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	assignments, err := h.planService.GetPlanHistory(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.PlanAssignmentResponse, len(assignments))
+	for i, assignment := range assignments {
+		res[i] = planAssignmentResponse(assignment)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Plan history retrieved successfully",
+		Data:    res,
+	})
+}
+
+func planTiers(req []dto.PlanTierRequest) []entities.PlanTier {
+	tiers := make([]entities.PlanTier, len(req))
+	for i, t := range req {
+		tiers[i] = entities.PlanTier{UpTo: t.UpTo, DiscountBPS: t.DiscountBPS}
+	}
+	return tiers
+}
+
+func planError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidPlan):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrPlanNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "plan not found")
+	case errors.Is(err, entities.ErrPlanInUse):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "user not found")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func planResponse(plan *entities.Plan) dto.PlanResponse {
+	tiers := make([]dto.PlanTierResponse, len(plan.Tiers))
+	for i, t := range plan.Tiers {
+		tiers[i] = dto.PlanTierResponse{UpTo: t.UpTo, DiscountBPS: t.DiscountBPS}
+	}
+	return dto.PlanResponse{
+		ID:    plan.ID.String(),
+		Code:  plan.Code,
+		Name:  plan.Name,
+		Tiers: tiers,
+	}
+}
+
+func planAssignmentResponse(assignment *entities.PlanAssignment) dto.PlanAssignmentResponse {
+	res := dto.PlanAssignmentResponse{
+		ID:        assignment.ID.String(),
+		UserID:    assignment.UserID.String(),
+		PlanID:    assignment.PlanID.String(),
+		PlanCode:  assignment.PlanCode,
+		StartedAt: assignment.StartedAt.Format(time.RFC3339),
+	}
+	if assignment.EndedAt != nil {
+		endedAt := assignment.EndedAt.Format(time.RFC3339)
+		res.EndedAt = &endedAt
+	}
+	return res
+}
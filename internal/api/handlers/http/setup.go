@@ -4,6 +4,8 @@ import (
 	"context"
 	"finance/config"
 	"finance/internal/app"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/metrics"
 	"fmt"
 
 	"finance/docs"
@@ -20,8 +22,15 @@ func Run(appContainer app.App, cfg config.Server) error {
 	docs.SwaggerInfo.Host = ""
 	docs.SwaggerInfo.Schemes = []string{}
 	docs.SwaggerInfo.BasePath = "/api/v1"
+	router.Use(traceRequests(), observeRequests())
 	registerSMSRoutes(appContainer, router)
 
+	// probes stay outside /api/v1 so they need no credentials
+	checker := appContainer.HealthChecker()
+	router.Get("/healthz", adaptor.HTTPHandler(checker.LivenessHandler()))
+	router.Get("/readyz", adaptor.HTTPHandler(checker.ReadinessHandler()))
+	router.Get("/metrics", adaptor.HTTPHandler(metrics.Handler()))
+
 	router.Get("/swagger/*", adaptor.HTTPHandler(httpSwagger.Handler()))
 
 	return router.Listen(fmt.Sprintf(":%d", cfg.Port))
@@ -30,21 +39,128 @@ func registerSMSRoutes(appContainer app.App, router fiber.Router) {
 	ctx := context.Background()
 	walletUsecase := appContainer.WalletService(ctx)
 	walletHandler := NewWalletHandler(walletUsecase)
+	tariffHandler := NewTariffHandler(appContainer.TariffService(ctx))
+	planHandler := NewPlanHandler(appContainer.PlanService(ctx))
+	fxHandler := NewFXHandler(appContainer.FXService(ctx))
+	taxHandler := NewTaxHandler(appContainer.TaxService(ctx))
+	invoiceHandler := NewInvoiceHandler(appContainer.InvoiceService(ctx))
+	snapshotHandler := NewBalanceSnapshotHandler(appContainer.BalanceSnapshotService(ctx))
+	auditHandler := NewAuditHandler(appContainer.AuditService(ctx))
+	authService := appContainer.AuthService(ctx)
+	apiKeyHandler := NewAPIKeyHandler(authService)
+	signingKeyHandler := NewSigningKeyHandler(appContainer.SigningService(ctx))
+	logLevelHandler := NewLogLevelHandler(appContainer.Logger())
+
+	// every route needs credentials, the role check on the route decides who may call it,
+	// admins pass all of them and customers only reach their own user's routes
+	finance := allow(entities.RoleFinance)
+	internal := allow(entities.RoleFinance, entities.RoleService)
+	readOwn := allow(entities.RoleFinance, entities.RoleService, entities.RoleCustomer)
+	readOwned := allowOwner(entities.RoleFinance, entities.RoleService, entities.RoleCustomer)
+	adminOnly := allow()
+
+	// the caller's limit is set on every route, its rules match the route the request was routed to
+	rateLimitService := appContainer.RateLimitService(ctx)
+	limit := rateLimit(rateLimitService)
 
 	v1 := router.Group("/api/v1")
+	v1.Use(rateLimitIP(rateLimitService), verifySignature(appContainer.SigningService(ctx)), authenticate(authService),
+		setAuditActor(), idempotency(appContainer.IdempotencyService(ctx)))
 
 	// Wallet routes
 	wallet := v1.Group("/wallet")
-	wallet.Post("/", setTraceID(), walletHandler.Credit)
-	wallet.Get("/user/:user_id", setTraceID(), walletHandler.GetWalletByUserID)
+	wallet.Post("/", setTraceID(), limit, internal, walletHandler.Credit)
+	wallet.Post("/redeem", setTraceID(), limit, internal, walletHandler.RedeemVoucher)
+	wallet.Get("/user/:user_id", setTraceID(), limit, readOwn, walletHandler.GetWalletByUserID)
+	wallet.Get("/user/:user_id/limits", setTraceID(), limit, readOwn, walletHandler.GetSpendingLimits)
+	wallet.Put("/user/:user_id/limits", setTraceID(), limit, finance, walletHandler.SetSpendingLimits)
+	wallet.Put("/user/:user_id/credit-limit", setTraceID(), limit, finance, walletHandler.SetCreditLimit)
+	wallet.Get("/user/:user_id/thresholds", setTraceID(), limit, readOwn, walletHandler.ListBalanceThresholds)
+	wallet.Post("/user/:user_id/thresholds", setTraceID(), limit, internal, walletHandler.AddBalanceThreshold)
+	wallet.Delete("/user/:user_id/thresholds/:threshold_id", setTraceID(), limit, internal, walletHandler.RemoveBalanceThreshold)
+	wallet.Get("/user/:user_id/topup-rules", setTraceID(), limit, readOwn, walletHandler.ListTopUpRules)
+	wallet.Post("/user/:user_id/topup-rules", setTraceID(), limit, internal, walletHandler.CreateTopUpRule)
+	wallet.Put("/user/:user_id/topup-rules/:rule_id", setTraceID(), limit, internal, walletHandler.UpdateTopUpRule)
+	wallet.Delete("/user/:user_id/topup-rules/:rule_id", setTraceID(), limit, internal, walletHandler.DeleteTopUpRule)
+	wallet.Post("/user/:user_id/bonus", setTraceID(), limit, finance, walletHandler.GrantBonusCredit)
+	wallet.Get("/user/:user_id/buckets", setTraceID(), limit, readOwn, walletHandler.ListBalanceBuckets)
+	wallet.Put("/user/:user_id/parent", setTraceID(), limit, finance, walletHandler.SetParentWallet)
+	wallet.Delete("/user/:user_id/parent", setTraceID(), limit, finance, walletHandler.DetachParentWallet)
+	wallet.Get("/user/:user_id/children", setTraceID(), limit, readOwn, walletHandler.ListChildWallets)
+	wallet.Get("/user/:user_id/spending", setTraceID(), limit, readOwn, walletHandler.GetSpendingReport)
+	wallet.Post("/user/:user_id/sub-balances", setTraceID(), limit, internal, walletHandler.OpenSubBalance)
+	wallet.Post("/user/:user_id/sub-balances/convert", setTraceID(), limit, internal, walletHandler.ConvertSubBalance)
+	wallet.Get("/user/:user_id/transactions/export", setTraceID(), limit, readOwn, walletHandler.ExportTransactions)
+	wallet.Get("/:wallet_id/balance-at", setTraceID(), limit, internal, snapshotHandler.GetBalanceAt)
+
+	// Tariff routes
+	tariffs := v1.Group("/tariffs")
+	tariffs.Post("/", setTraceID(), limit, finance, tariffHandler.CreateTariff)
+	tariffs.Get("/", setTraceID(), limit, internal, tariffHandler.ListTariffs)
+	tariffs.Get("/:version", setTraceID(), limit, internal, tariffHandler.GetTariff)
+	tariffs.Post("/:version/activate", setTraceID(), limit, finance, tariffHandler.ActivateTariff)
+
+	// Transfer routes
+	transfers := v1.Group("/transfers")
+	transfers.Post("/", setTraceID(), limit, internal, walletHandler.Transfer)
+	transfers.Get("/:transfer_id", setTraceID(), limit, internal, walletHandler.GetTransfer)
+
+	// FX rate routes
+	fxRates := v1.Group("/fx-rates")
+	fxRates.Post("/", setTraceID(), limit, finance, fxHandler.CreateFXRate)
+	fxRates.Get("/", setTraceID(), limit, internal, fxHandler.ListFXRates)
+
+	// Tax routes
+	tax := v1.Group("/tax")
+	tax.Post("/rules", setTraceID(), limit, finance, taxHandler.CreateTaxRule)
+	tax.Get("/rules", setTraceID(), limit, finance, taxHandler.ListTaxRules)
+	tax.Get("/report", setTraceID(), limit, finance, taxHandler.GetTaxReport)
+
+	// Invoice routes
+	invoices := v1.Group("/invoices")
+	invoices.Post("/", setTraceID(), limit, finance, invoiceHandler.GenerateInvoice)
+	invoices.Get("/:invoice_id", setTraceID(), limit, readOwned, invoiceHandler.GetInvoice)
+	invoices.Get("/:invoice_id/document", setTraceID(), limit, readOwned, invoiceHandler.GetInvoiceDocument)
+
+	// Voucher routes
+	vouchers := v1.Group("/vouchers")
+	vouchers.Post("/", setTraceID(), limit, finance, walletHandler.GenerateVouchers)
+	vouchers.Get("/", setTraceID(), limit, finance, walletHandler.ListVouchers)
+
+	// Plan routes
+	plans := v1.Group("/plans")
+	plans.Post("/", setTraceID(), limit, finance, planHandler.CreatePlan)
+	plans.Get("/", setTraceID(), limit, internal, planHandler.ListPlans)
+	plans.Get("/:plan_id", setTraceID(), limit, internal, planHandler.GetPlan)
+	plans.Put("/:plan_id", setTraceID(), limit, finance, planHandler.UpdatePlan)
+	plans.Delete("/:plan_id", setTraceID(), limit, finance, planHandler.DeletePlan)
 
 	// User routes
 	user := v1.Group("/user")
-	user.Get("/:user_id", setTraceID(), walletHandler.GetUser)
+	user.Get("/:user_id", setTraceID(), limit, readOwn, walletHandler.GetUser)
+	user.Put("/:user_id/plan", setTraceID(), limit, finance, planHandler.AssignPlan)
+	user.Get("/:user_id/plan-history", setTraceID(), limit, readOwn, planHandler.GetPlanHistory)
+	user.Put("/:user_id/customer-type", setTraceID(), limit, finance, taxHandler.SetCustomerType)
+	user.Get("/:user_id/invoices", setTraceID(), limit, readOwn, invoiceHandler.ListInvoices)
+
+	// Admin routes
+	admin := v1.Group("/admin")
+	admin.Get("/transactions/export", setTraceID(), limit, adminOnly, walletHandler.ExportAllTransactions)
+	admin.Get("/audit-events", setTraceID(), limit, adminOnly, auditHandler.ListAuditEvents)
+	admin.Get("/audit-events/verify", setTraceID(), limit, adminOnly, auditHandler.VerifyAuditChain)
+	admin.Post("/api-keys", setTraceID(), limit, adminOnly, apiKeyHandler.CreateAPIKey)
+	admin.Get("/api-keys", setTraceID(), limit, adminOnly, apiKeyHandler.ListAPIKeys)
+	admin.Delete("/api-keys/:key_id", setTraceID(), limit, adminOnly, apiKeyHandler.RevokeAPIKey)
+	admin.Post("/signing-keys", setTraceID(), limit, adminOnly, signingKeyHandler.CreateSigningKey)
+	admin.Post("/signing-keys/rotate", setTraceID(), limit, adminOnly, signingKeyHandler.RotateSigningKey)
+	admin.Get("/signing-keys", setTraceID(), limit, adminOnly, signingKeyHandler.ListSigningKeys)
+	admin.Delete("/signing-keys/:key_id", setTraceID(), limit, adminOnly, signingKeyHandler.RevokeSigningKey)
+	admin.Get("/log-level", setTraceID(), limit, adminOnly, logLevelHandler.GetLogLevel)
+	admin.Put("/log-level", setTraceID(), limit, adminOnly, logLevelHandler.SetLogLevel)
 
 	// Users routes (plural for getting all users)
 	users := v1.Group("/users")
-	users.Get("/", setTraceID(), walletHandler.GetAllUsers)
+	users.Get("/", setTraceID(), limit, adminOnly, walletHandler.GetAllUsers)
 }
 
 func customErrorHandler(c *fiber.Ctx, err error) error {
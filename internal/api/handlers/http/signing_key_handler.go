@@ -0,0 +1,181 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SigningKeyHandler struct {
+	signingService *usecase.SigningService
+}
+
+func NewSigningKeyHandler(signingService *usecase.SigningService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		signingService: signingService,
+	}
+}
+
+// CreateSigningKey godoc
+// @Summary      Create signing key
+// @Description  Creates the HMAC signing key of a calling service, the secret is returned only in this response
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateSigningKeyRequest  true  "Create Signing Key Request"
+// @Success      201      {object}  dto.CreateSigningKeyResponse "Signing key created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/signing-keys [post]
+func (h *SigningKeyHandler) CreateSigningKey(c *fiber.Ctx) error {
+	var req dto.CreateSigningKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	role := entities.Role(req.Role)
+	if role == "" {
+		role = entities.RoleService
+	}
+
+	ctx := c.UserContext()
+	key, err := h.signingService.CreateClientKey(ctx, req.Client, role)
+	if err != nil {
+		return signingKeyError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Signing key created successfully",
+		Data:    createdSigningKeyResponse(key),
+	})
+}
+
+// RotateSigningKey godoc
+// @Summary      Rotate signing key
+// @Description  Creates a new signing key for the client, its current keys keep working for the grace period
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.RotateSigningKeyRequest  true  "Rotate Signing Key Request"
+// @Success      201      {object}  dto.CreateSigningKeyResponse "Signing key rotated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Client has no usable key"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/signing-keys/rotate [post]
+func (h *SigningKeyHandler) RotateSigningKey(c *fiber.Ctx) error {
+	var req dto.RotateSigningKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+	grace := entities.DefaultRotationGrace
+	if req.GraceSeconds != nil {
+		grace = time.Duration(*req.GraceSeconds) * time.Second
+	}
+
+	ctx := c.UserContext()
+	key, err := h.signingService.RotateClientKey(ctx, req.Client, grace)
+	if err != nil {
+		return signingKeyError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Signing key rotated successfully",
+		Data:    createdSigningKeyResponse(key),
+	})
+}
+
+// ListSigningKeys godoc
+// @Summary      List signing keys
+// @Description  Lists the signing keys without their secrets
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        client   query     string  false  "Client name"
+// @Success      200      {array}   dto.SigningKeyResponse "Signing keys"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/signing-keys [get]
+func (h *SigningKeyHandler) ListSigningKeys(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	keys, err := h.signingService.ListClientKeys(ctx, c.Query("client"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.SigningKeyResponse, len(keys))
+	for i, key := range keys {
+		res[i] = signingKeyResponse(key)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Signing keys retrieved successfully",
+		Data:    res,
+	})
+}
+
+// RevokeSigningKey godoc
+// @Summary      Revoke signing key
+// @Description  Revokes a signing key at once, without a grace period
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        key_id   path      string  true  "Signing Key ID"
+// @Success      200      {object}  dto.SigningKeyResponse "Signing key revoked"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Signing key not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /admin/signing-keys/{key_id} [delete]
+func (h *SigningKeyHandler) RevokeSigningKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("key_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid signing key ID format")
+	}
+
+	ctx := c.UserContext()
+	key, err := h.signingService.RevokeKey(ctx, keyID)
+	if err != nil {
+		return signingKeyError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Signing key revoked successfully",
+		Data:    signingKeyResponse(key),
+	})
+}
+
+func signingKeyResponse(key *entities.SigningKey) dto.SigningKeyResponse {
+	return dto.SigningKeyResponse{
+		ID:        key.ID.String(),
+		Client:    key.Client,
+		Role:      string(key.Role),
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+		CreatedAt: key.CreatedAt,
+	}
+}
+
+func createdSigningKeyResponse(key *entities.SigningKey) dto.CreateSigningKeyResponse {
+	return dto.CreateSigningKeyResponse{
+		SigningKeyResponse: signingKeyResponse(key),
+		Secret:             key.Secret,
+	}
+}
+
+func signingKeyError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidSigningKey),
+		errors.Is(err, entities.ErrInvalidRole):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrSigningKeyNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TariffHandler struct {
+	tariffService *usecase.TariffService
+}
+
+func NewTariffHandler(tariffService *usecase.TariffService) *TariffHandler {
+	return &TariffHandler{
+		tariffService: tariffService,
+	}
+}
+
+// CreateTariff godoc
+// @Summary      Create tariff
+// @Description  Creates a new inactive tariff version, tariffs are immutable so price changes are a new version
+// @Tags         tariff
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateTariffRequest  true  "Create Tariff Request"
+// @Success      201      {object}  dto.TariffResponse "Tariff created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tariffs [post]
+func (h *TariffHandler) CreateTariff(c *fiber.Ctx) error {
+	var req dto.CreateTariffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	rates := make([]entities.TariffRate, len(req.Rates))
+	for i, r := range req.Rates {
+		rates[i] = entities.TariffRate{
+			Prefix:          r.Prefix,
+			Operator:        r.Operator,
+			MessageType:     entities.MessageType(r.MessageType),
+			Plan:            r.Plan,
+			PricePerSegment: big.NewInt(r.PricePerSegment),
+		}
+	}
+
+	ctx := c.UserContext()
+	tariff, err := h.tariffService.CreateTariff(ctx, req.Name, req.Currency, rates)
+	if err != nil {
+		return tariffError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tariff created successfully",
+		Data:    tariffResponse(tariff),
+	})
+}
+
+// ListTariffs godoc
+// @Summary      List tariffs
+// @Description  Lists every tariff version, newest first
+// @Tags         tariff
+// @Accept       json
+// @Produce      json
+// @Success      200      {array}   dto.TariffResponse "Tariffs"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tariffs [get]
+func (h *TariffHandler) ListTariffs(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	tariffs, err := h.tariffService.ListTariffs(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.TariffResponse, len(tariffs))
+	for i, tariff := range tariffs {
+		res[i] = tariffResponse(tariff)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tariffs retrieved successfully",
+		Data:    res,
+	})
+}
+
+// GetTariff godoc
+// @Summary      Get tariff
+// @Description  Retrieves a tariff version with its rates
+// @Tags         tariff
+// @Accept       json
+// @Produce      json
+// @Param        version  path      int  true  "Tariff version"
+// @Success      200      {object}  dto.TariffResponse "Tariff"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Tariff not found"
+// @Router       /tariffs/{version} [get]
+func (h *TariffHandler) GetTariff(c *fiber.Ctx) error {
+	version, err := strconv.ParseInt(c.Params("version"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid tariff version")
+	}
+
+	ctx := c.UserContext()
+	tariff, err := h.tariffService.GetTariff(ctx, version)
+	if err != nil {
+		return tariffError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tariff retrieved successfully",
+		Data:    tariffResponse(tariff),
+	})
+}
+
+// ActivateTariff godoc
+// @Summary      Activate tariff
+// @Description  Makes a tariff version the one that prices debits in its currency
+// @Tags         tariff
+// @Accept       json
+// @Produce      json
+// @Param        version  path      int  true  "Tariff version"
+// @Success      200      {object}  dto.TariffResponse "Tariff activated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Tariff not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tariffs/{version}/activate [post]
+func (h *TariffHandler) ActivateTariff(c *fiber.Ctx) error {
+	version, err := strconv.ParseInt(c.Params("version"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid tariff version")
+	}
+
+	ctx := c.UserContext()
+	tariff, err := h.tariffService.ActivateTariff(ctx, version)
+	if err != nil {
+		return tariffError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tariff activated successfully",
+		Data:    tariffResponse(tariff),
+	})
+}
+
+func tariffError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidTariff):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrTariffNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "tariff not found")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func tariffResponse(tariff *entities.Tariff) dto.TariffResponse {
+	rates := make([]dto.TariffRateResponse, len(tariff.Rates))
+	for i, r := range tariff.Rates {
+		rates[i] = dto.TariffRateResponse{
+			Prefix:          r.Prefix,
+			Operator:        r.Operator,
+			MessageType:     string(r.MessageType),
+			Plan:            r.Plan,
+			PricePerSegment: r.PricePerSegment.Int64(),
+		}
+	}
+	return dto.TariffResponse{
+		ID:        tariff.ID.String(),
+		Version:   tariff.Version,
+		Name:      tariff.Name,
+		Currency:  tariff.Currency,
+		Active:    tariff.Active,
+		Rates:     rates,
+		CreatedAt: tariff.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxHandler struct {
+	taxService *usecase.TaxService
+}
+
+func NewTaxHandler(taxService *usecase.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+// CreateTaxRule godoc
+// @Summary      Create tax rule
+// @Description  Stores a tax rate for a customer type on debits or top-ups, a rate change is a new rule with its own validity window
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.CreateTaxRuleRequest  true  "Create Tax Rule Request"
+// @Success      201      {object}  dto.TaxRuleResponse "Tax rule created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tax/rules [post]
+func (h *TaxHandler) CreateTaxRule(c *fiber.Ctx) error {
+	var req dto.CreateTaxRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	input := usecase.TaxRuleInput{
+		Name:         req.Name,
+		CustomerType: entities.CustomerType(req.CustomerType),
+		Base:         entities.TaxBase(req.AppliesTo),
+		RateBPS:      req.RateBPS,
+		Inclusive:    req.Inclusive,
+		ValidUntil:   req.ValidUntil,
+	}
+	if req.ValidFrom != nil {
+		input.ValidFrom = *req.ValidFrom
+	}
+
+	ctx := c.UserContext()
+	rule, err := h.taxService.CreateRule(ctx, input)
+	if err != nil {
+		return taxError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tax rule created successfully",
+		Data:    taxRuleResponse(rule),
+	})
+}
+
+// ListTaxRules godoc
+// @Summary      List tax rules
+// @Description  Lists every tax rule, newest validity first
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Success      200      {array}   dto.TaxRuleResponse "Tax rules"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tax/rules [get]
+func (h *TaxHandler) ListTaxRules(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	rules, err := h.taxService.ListRules(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.TaxRuleResponse, len(rules))
+	for i, rule := range rules {
+		res[i] = taxRuleResponse(rule)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tax rules retrieved successfully",
+		Data:    res,
+	})
+}
+
+// GetTaxReport godoc
+// @Summary      Tax report
+// @Description  Totals net, tax and gross of the taxed transactions of a period by type and category
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param        from  query     string  false  "RFC3339 start time, defaults to the start of the month"
+// @Param        to    query     string  false  "RFC3339 end time, exclusive, defaults to now"
+// @Success      200   {object}  dto.TaxReportResponse "Tax report"
+// @Failure      400   {object}  map[string]interface{} "Bad Request"
+// @Failure      500   {object}  map[string]interface{} "Internal Server Error"
+// @Router       /tax/report [get]
+func (h *TaxHandler) GetTaxReport(c *fiber.Ctx) error {
+	now := time.Now()
+	from, to := entities.StartOfMonth(now), now
+	var err error
+	if raw := c.Query("from"); raw != "" {
+		if from, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid from, expected RFC3339")
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid to, expected RFC3339")
+		}
+	}
+
+	ctx := c.UserContext()
+	report, err := h.taxService.Report(ctx, from, to)
+	if err != nil {
+		return taxError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Tax report retrieved successfully",
+		Data:    taxReportResponse(report),
+	})
+}
+
+// SetCustomerType godoc
+// @Summary      Set customer type
+// @Description  Sets whether the user is taxed as an individual or a business
+// @Tags         user
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                      true  "User ID"
+// @Param        request  body      dto.SetCustomerTypeRequest  true  "Customer Type Request"
+// @Success      200      {object}  dto.GetUserResponse "User with the new customer type"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "User not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /user/{user_id}/customer-type [put]
+func (h *TaxHandler) SetCustomerType(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.SetCustomerTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	user, err := h.taxService.SetCustomerType(ctx, userID, entities.CustomerType(req.CustomerType))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return taxError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Customer type updated successfully",
+		Data: dto.GetUserResponse{
+			ID:           user.ID.String(),
+			Name:         user.Name,
+			LastName:     user.LastName,
+			Phone:        user.Phone,
+			WalletID:     user.WalletID,
+			CustomerType: string(user.CustomerType),
+		},
+	})
+}
+
+func taxError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidTaxRule),
+		errors.Is(err, entities.ErrInvalidCustomerType),
+		errors.Is(err, entities.ErrInvalidReportPeriod):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func taxRuleResponse(rule *entities.TaxRule) dto.TaxRuleResponse {
+	return dto.TaxRuleResponse{
+		ID:           rule.ID.String(),
+		Name:         rule.Name,
+		CustomerType: string(rule.CustomerType),
+		AppliesTo:    string(rule.Base),
+		RateBPS:      rule.RateBPS,
+		Inclusive:    rule.Inclusive,
+		ValidFrom:    rule.ValidFrom,
+		ValidUntil:   rule.ValidUntil,
+	}
+}
+
+func taxReportResponse(report *entities.TaxReport) dto.TaxReportResponse {
+	res := dto.TaxReportResponse{
+		From:   report.From,
+		To:     report.To,
+		Totals: make([]dto.TaxTotalResponse, len(report.Totals)),
+		Due:    make(map[string]int64, len(report.Due)),
+	}
+	for i, total := range report.Totals {
+		res.Totals[i] = dto.TaxTotalResponse{
+			Type:     string(total.Type),
+			Category: string(total.Category),
+			Currency: total.Currency,
+			Count:    total.Count,
+			Net:      total.Net.Int64(),
+			Tax:      total.Tax.Int64(),
+			Gross:    total.Gross.Int64(),
+		}
+	}
+	for currency, due := range report.Due {
+		res.Due[currency] = due.Int64()
+	}
+	return res
+}
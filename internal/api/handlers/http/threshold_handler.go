@@ -0,0 +1,145 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListBalanceThresholds godoc
+// @Summary      List low balance thresholds
+// @Description  Lists the low balance alert thresholds of a user's wallet
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {array}   dto.BalanceThresholdResponse "Balance thresholds"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/thresholds [get]
+func (h *WalletHandler) ListBalanceThresholds(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	thresholds, err := h.walletService.ListBalanceThresholds(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	}
+
+	res := make([]dto.BalanceThresholdResponse, len(thresholds))
+	for i, threshold := range thresholds {
+		res[i] = balanceThresholdResponse(threshold)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Balance thresholds retrieved successfully",
+		Data:    res,
+	})
+}
+
+// AddBalanceThreshold godoc
+// @Summary      Add low balance threshold
+// @Description  Adds a threshold that emits a WalletBalanceLow event once when the balance drops below it
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                             true  "User ID"
+// @Param        request  body      dto.CreateBalanceThresholdRequest  true  "Balance Threshold Request"
+// @Success      201      {object}  dto.BalanceThresholdResponse "Balance threshold created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/thresholds [post]
+func (h *WalletHandler) AddBalanceThreshold(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.CreateBalanceThresholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	threshold, err := h.walletService.AddBalanceThreshold(ctx, userID, *big.NewInt(req.Threshold), *big.NewInt(req.Hysteresis))
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidAmount), errors.Is(err, valueobjects.ErrCurrencyMismatch):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Balance threshold created successfully",
+		Data:    balanceThresholdResponse(threshold),
+	})
+}
+
+// RemoveBalanceThreshold godoc
+// @Summary      Remove low balance threshold
+// @Description  Removes a low balance alert threshold from a user's wallet
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id       path      string  true  "User ID"
+// @Param        threshold_id  path      string  true  "Threshold ID"
+// @Success      200           {object}  dto.BaseResponse "Balance threshold removed"
+// @Failure      400           {object}  map[string]interface{} "Bad Request"
+// @Failure      404           {object}  map[string]interface{} "Threshold not found"
+// @Failure      500           {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/thresholds/{threshold_id} [delete]
+func (h *WalletHandler) RemoveBalanceThreshold(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	thresholdID, err := uuid.Parse(c.Params("threshold_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid threshold ID format")
+	}
+
+	ctx := c.UserContext()
+	if err := h.walletService.RemoveBalanceThreshold(ctx, userID, thresholdID); err != nil {
+		switch {
+		case errors.Is(err, entities.ErrThresholdNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "threshold not found")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Balance threshold removed successfully",
+	})
+}
+
+func balanceThresholdResponse(threshold *entities.BalanceThreshold) dto.BalanceThresholdResponse {
+	return dto.BalanceThresholdResponse{
+		ID:         threshold.ID.String(),
+		WalletID:   threshold.WalletID.String(),
+		Threshold:  threshold.Amount.Amount().Int64(),
+		Hysteresis: threshold.Hysteresis.Amount().Int64(),
+		Currency:   threshold.Amount.Currency(),
+		Armed:      threshold.Armed,
+	}
+}
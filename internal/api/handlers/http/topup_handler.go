@@ -0,0 +1,206 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"math/big"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListTopUpRules godoc
+// @Summary      List auto top-up rules
+// @Description  Lists the auto top-up rules of a user's wallet
+// @Tags         topup
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {array}   dto.TopUpRuleResponse "Top-up rules"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/topup-rules [get]
+func (h *WalletHandler) ListTopUpRules(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	rules, err := h.walletService.ListTopUpRules(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	}
+
+	res := make([]dto.TopUpRuleResponse, len(rules))
+	for i, rule := range rules {
+		res[i] = topUpRuleResponse(rule)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Top-up rules retrieved successfully",
+		Data:    res,
+	})
+}
+
+// CreateTopUpRule godoc
+// @Summary      Create auto top-up rule
+// @Description  Creates a rule that requests a top-up from the saved funding source when the balance drops below the threshold
+// @Tags         topup
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                true  "User ID"
+// @Param        request  body      dto.TopUpRuleRequest  true  "Top-up Rule Request"
+// @Success      201      {object}  dto.TopUpRuleResponse "Top-up rule created"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/topup-rules [post]
+func (h *WalletHandler) CreateTopUpRule(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.TopUpRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	rule, err := h.walletService.CreateTopUpRule(ctx, userID, topUpRuleInput(req))
+	if err != nil {
+		return topUpRuleError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Top-up rule created successfully",
+		Data:    topUpRuleResponse(rule),
+	})
+}
+
+// UpdateTopUpRule godoc
+// @Summary      Update auto top-up rule
+// @Description  Replaces the settings of an auto top-up rule
+// @Tags         topup
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                true  "User ID"
+// @Param        rule_id  path      string                true  "Rule ID"
+// @Param        request  body      dto.TopUpRuleRequest  true  "Top-up Rule Request"
+// @Success      200      {object}  dto.TopUpRuleResponse "Top-up rule updated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Rule not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/topup-rules/{rule_id} [put]
+func (h *WalletHandler) UpdateTopUpRule(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ruleID, err := uuid.Parse(c.Params("rule_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid rule ID format")
+	}
+
+	var req dto.TopUpRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	rule, err := h.walletService.UpdateTopUpRule(ctx, userID, ruleID, topUpRuleInput(req))
+	if err != nil {
+		return topUpRuleError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Top-up rule updated successfully",
+		Data:    topUpRuleResponse(rule),
+	})
+}
+
+// DeleteTopUpRule godoc
+// @Summary      Delete auto top-up rule
+// @Description  Deletes an auto top-up rule from a user's wallet
+// @Tags         topup
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Param        rule_id  path      string  true  "Rule ID"
+// @Success      200      {object}  dto.BaseResponse "Top-up rule deleted"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Rule not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/topup-rules/{rule_id} [delete]
+func (h *WalletHandler) DeleteTopUpRule(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ruleID, err := uuid.Parse(c.Params("rule_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid rule ID format")
+	}
+
+	ctx := c.UserContext()
+	if err := h.walletService.DeleteTopUpRule(ctx, userID, ruleID); err != nil {
+		return topUpRuleError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Top-up rule deleted successfully",
+	})
+}
+
+func topUpRuleInput(req dto.TopUpRuleRequest) usecase.TopUpRuleInput {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return usecase.TopUpRuleInput{
+		Threshold:       *big.NewInt(req.Threshold),
+		Amount:          *big.NewInt(req.Amount),
+		MaxPerDay:       req.MaxPerDay,
+		FundingSourceID: req.FundingSourceID,
+		Enabled:         enabled,
+	}
+}
+
+func topUpRuleError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidAmount),
+		errors.Is(err, entities.ErrInvalidTopUpRule),
+		errors.Is(err, valueobjects.ErrCurrencyMismatch):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrTopUpRuleNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "top-up rule not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func topUpRuleResponse(rule *entities.TopUpRule) dto.TopUpRuleResponse {
+	return dto.TopUpRuleResponse{
+		ID:              rule.ID.String(),
+		WalletID:        rule.WalletID.String(),
+		Threshold:       rule.Threshold.Amount().Int64(),
+		Amount:          rule.Amount.Amount().Int64(),
+		Currency:        rule.Amount.Currency(),
+		MaxPerDay:       rule.MaxPerDay,
+		FundingSourceID: rule.FundingSourceID,
+		Enabled:         rule.Enabled,
+	}
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"math/big"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transfer godoc
+// @Summary      Transfer credit
+// @Description  Moves paid balance from one user's wallet to another's, a retry with the same Idempotency-Key returns the first transfer
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        Idempotency-Key  header    string               true  "Unique key of the transfer, scoped to the sender"
+// @Param        request          body      dto.TransferRequest  true  "Transfer Request"
+// @Success      201              {object}  dto.TransferResponse "Transfer made"
+// @Failure      400              {object}  map[string]interface{} "Bad Request"
+// @Failure      404              {object}  map[string]interface{} "Wallet not found"
+// @Failure      409              {object}  map[string]interface{} "Idempotency key reused"
+// @Failure      422              {object}  map[string]interface{} "Insufficient balance"
+// @Failure      500              {object}  map[string]interface{} "Internal Server Error"
+// @Router       /transfers [post]
+func (h *WalletHandler) Transfer(c *fiber.Ctx) error {
+	key := c.Get("Idempotency-Key")
+	if key == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key header is required")
+	}
+
+	var req dto.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	fromUserID, err := uuid.Parse(req.FromUserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+	toUserID, err := uuid.Parse(req.ToUserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	transfer, err := h.walletService.Transfer(ctx, usecase.TransferInput{
+		FromUserID:     fromUserID,
+		ToUserID:       toUserID,
+		Amount:         *big.NewInt(req.Amount),
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Transfer completed successfully",
+		Data:    transferResponse(transfer),
+	})
+}
+
+// GetTransfer godoc
+// @Summary      Get transfer
+// @Description  Gets a transfer with the IDs of its debit and credit transactions
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        transfer_id  path      string  true  "Transfer ID"
+// @Success      200          {object}  dto.TransferResponse "Transfer"
+// @Failure      400          {object}  map[string]interface{} "Bad Request"
+// @Failure      404          {object}  map[string]interface{} "Transfer not found"
+// @Router       /transfers/{transfer_id} [get]
+func (h *WalletHandler) GetTransfer(c *fiber.Ctx) error {
+	transferID, err := uuid.Parse(c.Params("transfer_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid transfer ID format")
+	}
+
+	ctx := c.UserContext()
+	transfer, err := h.walletService.GetTransfer(ctx, transferID)
+	if err != nil {
+		return transferError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Transfer retrieved successfully",
+		Data:    transferResponse(transfer),
+	})
+}
+
+func transferError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidTransfer),
+		errors.Is(err, entities.ErrInvalidAmount),
+		errors.Is(err, entities.ErrSameWallet),
+		errors.Is(err, valueobjects.ErrCurrencyMismatch):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrTransferNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "transfer not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	case errors.Is(err, entities.ErrIdempotencyKeyReused):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, entities.ErrInsufficientBalance):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func transferResponse(transfer *entities.Transfer) dto.TransferResponse {
+	return dto.TransferResponse{
+		ID:                  transfer.ID.String(),
+		FromUserID:          transfer.FromUserID.String(),
+		ToUserID:            transfer.ToUserID.String(),
+		FromWalletID:        transfer.FromWalletID.String(),
+		ToWalletID:          transfer.ToWalletID.String(),
+		Amount:              transfer.Amount.Amount().Int64(),
+		Currency:            transfer.Amount.Currency(),
+		DebitTransactionID:  transfer.DebitTransactionID.String(),
+		CreditTransactionID: transfer.CreditTransactionID.String(),
+		CreatedAt:           transfer.CreatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"math/big"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GenerateVouchers godoc
+// @Summary      Generate vouchers
+// @Description  Generates a batch of voucher codes with the same credit and terms
+// @Tags         vouchers
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.GenerateVouchersRequest  true  "Generate Vouchers Request"
+// @Success      201      {array}   dto.VoucherResponse "Vouchers generated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /vouchers [post]
+func (h *WalletHandler) GenerateVouchers(c *fiber.Ctx) error {
+	var req dto.GenerateVouchersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	perUserLimit := req.PerUserLimit
+	if perUserLimit == 0 {
+		perUserLimit = 1
+	}
+
+	ctx := c.UserContext()
+	vouchers, err := h.walletService.GenerateVouchers(ctx, usecase.VoucherInput{
+		Count:          req.Count,
+		Amount:         *big.NewInt(req.Amount),
+		Currency:       strings.ToUpper(req.Currency),
+		MaxRedemptions: req.MaxRedemptions,
+		PerUserLimit:   perUserLimit,
+		ValidFrom:      req.ValidFrom,
+		ValidUntil:     req.ValidUntil,
+		Campaign:       req.Campaign,
+	})
+	if err != nil {
+		return voucherError(err)
+	}
+
+	res := make([]dto.VoucherResponse, len(vouchers))
+	for i, voucher := range vouchers {
+		res[i] = voucherResponse(voucher)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Vouchers generated successfully",
+		Data:    res,
+	})
+}
+
+// ListVouchers godoc
+// @Summary      List vouchers
+// @Description  Lists vouchers with their redemption counts, optionally of one campaign
+// @Tags         vouchers
+// @Accept       json
+// @Produce      json
+// @Param        campaign  query     string  false  "Campaign tag"
+// @Success      200       {array}   dto.VoucherResponse "Vouchers"
+// @Failure      500       {object}  map[string]interface{} "Internal Server Error"
+// @Router       /vouchers [get]
+func (h *WalletHandler) ListVouchers(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	vouchers, err := h.walletService.ListVouchers(ctx, c.Query("campaign"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.VoucherResponse, len(vouchers))
+	for i, voucher := range vouchers {
+		res[i] = voucherResponse(voucher)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Vouchers retrieved successfully",
+		Data:    res,
+	})
+}
+
+// RedeemVoucher godoc
+// @Summary      Redeem voucher
+// @Description  Credits the voucher amount to the user's wallet
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        request  body      dto.RedeemVoucherRequest  true  "Redeem Voucher Request"
+// @Success      200      {object}  dto.RedeemVoucherResponse "Voucher redeemed"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Voucher or wallet not found"
+// @Failure      409      {object}  map[string]interface{} "Voucher can not be redeemed"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/redeem [post]
+func (h *WalletHandler) RedeemVoucher(c *fiber.Ctx) error {
+	var req dto.RedeemVoucherRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	transaction, err := h.walletService.RedeemVoucher(ctx, userID, req.Code)
+	if err != nil {
+		return voucherError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Voucher redeemed successfully",
+		Data: dto.RedeemVoucherResponse{
+			UserID:        userID.String(),
+			TransactionID: transaction.ID.String(),
+			VoucherID:     transaction.ReferenceID.String(),
+			Amount:        transaction.Amount.Amount().Int64(),
+			Currency:      transaction.Amount.Currency(),
+		},
+	})
+}
+
+func voucherError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidVoucher), errors.Is(err, valueobjects.ErrCurrencyMismatch):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, entities.ErrVoucherNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "voucher not found")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	case errors.Is(err, entities.ErrVoucherNotActive),
+		errors.Is(err, entities.ErrVoucherExhausted),
+		errors.Is(err, entities.ErrVoucherLimitReached):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func voucherResponse(voucher *entities.Voucher) dto.VoucherResponse {
+	return dto.VoucherResponse{
+		ID:             voucher.ID.String(),
+		Code:           voucher.Code,
+		Amount:         voucher.Amount.Amount().Int64(),
+		Currency:       voucher.Amount.Currency(),
+		MaxRedemptions: voucher.MaxRedemptions,
+		PerUserLimit:   voucher.PerUserLimit,
+		Redeemed:       voucher.Redeemed,
+		ValidFrom:      voucher.ValidFrom,
+		ValidUntil:     voucher.ValidUntil,
+		Campaign:       voucher.Campaign,
+	}
+}
@@ -1,12 +1,16 @@
 package http
 
 import (
+	"errors"
 	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
 	"finance/internal/usecase"
 	"math/big"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type WalletHandler struct {
@@ -21,13 +25,16 @@ func NewWalletHandler(walletService *usecase.WalletService) *WalletHandler {
 
 // Credit godoc
 // @Summary      Credit user wallet
-// @Description  Credits a user's wallet with a specified amount
+// @Description  Credits a user's wallet with a specified amount, an amount in another currency is converted with the current fx rate unless the wallet has a sub-balance in it
 // @Tags         wallet
 // @Accept       json
 // @Produce      json
-// @Param        request  body      dto.CreditWalletRequest  true  "Credit Wallet Request"
+// @Param        request          body      dto.CreditWalletRequest  true   "Credit Wallet Request"
+// @Param        Idempotency-Key  header    string                   false  "A retry with the same key replays the first response instead of crediting again"
 // @Success      200      {object}  dto.CreditWalletResponse "Wallet credited successfully"
 // @Failure      400      {object}  map[string]interface{}   "Bad Request"
+// @Failure      409      {object}  map[string]interface{}   "Idempotency key reused with another request"
+// @Failure      422      {object}  map[string]interface{}   "No fx rate for the currency"
 // @Failure      500      {object}  map[string]interface{}   "Internal Server Error"
 // @Router       /wallet [post]
 func (h *WalletHandler) Credit(c *fiber.Ctx) error {
@@ -44,18 +51,36 @@ func (h *WalletHandler) Credit(c *fiber.Ctx) error {
 	amount := big.NewInt(int64(req.Amount))
 
 	ctx := c.UserContext()
-	if err := h.walletService.CreditUserBalance(ctx, userID, *amount); err != nil {
-		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	transaction, err := h.walletService.CreditUserBalanceIn(ctx, userID, amount, req.Currency)
+	if err != nil {
+		return fxError(err)
 	}
 	return c.JSON(dto.BaseResponse{
 		Success: true,
 		Message: "Wallet credited successfully",
-		Data: dto.CreditWalletResponse{
-			UserID: userID.String(),
-		},
+		Data:    creditResponse(userID, transaction),
 	})
 }
 
+func creditResponse(userID uuid.UUID, transaction *entities.Transaction) dto.CreditWalletResponse {
+	res := dto.CreditWalletResponse{
+		UserID:        userID.String(),
+		TransactionID: transaction.ID.String(),
+		Amount:        transaction.Amount.Amount().Int64(),
+		Currency:      transaction.Amount.Currency(),
+		FXRate:        transaction.FXRate,
+	}
+	if transaction.SourceAmount.Currency() != "" {
+		res.SourceAmount = transaction.SourceAmount.Amount().Int64()
+		res.SourceCurrency = transaction.SourceAmount.Currency()
+	}
+	if transaction.IsTaxed() {
+		res.Tax = transaction.TaxAmount.Amount().Int64()
+		res.Gross = transaction.GrossAmount.Amount().Int64()
+	}
+	return res
+}
+
 // GetWalletByUserID godoc
 // @Summary      Get user wallet
 // @Description  Gets a user's wallet information by user ID
@@ -88,15 +113,82 @@ func (h *WalletHandler) GetWalletByUserID(c *fiber.Ctx) error {
 	return c.JSON(dto.BaseResponse{
 		Success: true,
 		Message: "Wallet retrieved successfully",
-		Data: dto.GetWalletResponse{
-			ID:       wallet.ID.String(),
-			UserID:   wallet.UserID.String(),
-			Balance:  wallet.Balance.Amount().Int64(),
-			Currency: wallet.Currency,
-		},
+		Data:    walletResponse(wallet),
+	})
+}
+
+// SetCreditLimit godoc
+// @Summary      Set wallet credit limit
+// @Description  Sets how far below zero a postpaid wallet's balance may go, zero disables the credit line
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                     true  "User ID"
+// @Param        request  body      dto.SetCreditLimitRequest  true  "Credit Limit Request"
+// @Success      200      {object}  dto.GetWalletResponse "Wallet with the new credit limit"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/credit-limit [put]
+func (h *WalletHandler) SetCreditLimit(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.SetCreditLimitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.UserContext()
+	wallet, err := h.walletService.SetCreditLimit(ctx, userID, *big.NewInt(req.CreditLimit))
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidCreditLimit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Credit limit updated successfully",
+		Data:    walletResponse(wallet),
 	})
 }
 
+func walletResponse(wallet *entities.Wallet) dto.GetWalletResponse {
+	res := dto.GetWalletResponse{
+		ID:           wallet.ID.String(),
+		UserID:       wallet.UserID.String(),
+		Balance:      wallet.Balance.Amount().Int64(),
+		Currency:     wallet.Currency,
+		AmountOwed:   wallet.AmountOwed().Amount().Int64(),
+		PaidBalance:  wallet.PaidBalance().Amount().Int64(),
+		BonusBalance: wallet.BonusBalance(time.Now()).Amount().Int64(),
+	}
+	if wallet.CreditLimit.Currency() != "" {
+		res.CreditLimit = wallet.CreditLimit.Amount().Int64()
+	}
+	if available, err := wallet.AvailableBalance(); err == nil {
+		res.AvailableBalance = available.Amount().Int64()
+	}
+	if wallet.HasParent() {
+		res.ParentWalletID = wallet.ParentID.String()
+	}
+	if wallet.ParentQuota != nil {
+		res.ParentQuota = wallet.ParentQuota.Int64()
+	}
+	for _, sub := range wallet.SubBalances {
+		res.SubBalances = append(res.SubBalances, subBalanceResponse(sub))
+	}
+	return res
+}
+
 // GetUser godoc
 // @Summary      Get user information
 // @Description  Gets user information by user ID
@@ -130,11 +222,12 @@ func (h *WalletHandler) GetUser(c *fiber.Ctx) error {
 		Success: true,
 		Message: "User retrieved successfully",
 		Data: dto.GetUserResponse{
-			ID:       user.ID.String(),
-			Name:     user.Name,
-			LastName: user.LastName,
-			Phone:    user.Phone,
-			WalletID: user.WalletID,
+			ID:           user.ID.String(),
+			Name:         user.Name,
+			LastName:     user.LastName,
+			Phone:        user.Phone,
+			WalletID:     user.WalletID,
+			CustomerType: string(user.CustomerType),
 		},
 	})
 }
@@ -158,11 +251,12 @@ func (h *WalletHandler) GetAllUsers(c *fiber.Ctx) error {
 	userResponses := make([]dto.GetUserResponse, len(users))
 	for i, user := range users {
 		userResponses[i] = dto.GetUserResponse{
-			ID:       user.ID.String(),
-			Name:     user.Name,
-			LastName: user.LastName,
-			Phone:    user.Phone,
-			WalletID: user.WalletID,
+			ID:           user.ID.String(),
+			Name:         user.Name,
+			LastName:     user.LastName,
+			Phone:        user.Phone,
+			WalletID:     user.WalletID,
+			CustomerType: string(user.CustomerType),
 		}
 	}
 
@@ -175,3 +269,111 @@ func (h *WalletHandler) GetAllUsers(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetSpendingLimits godoc
+// @Summary      Get wallet spending limits
+// @Description  Gets the spending limits and velocity controls of a user's wallet
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "User ID"
+// @Success      200      {object}  dto.SpendingLimitsResponse "Spending limits"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/limits [get]
+func (h *WalletHandler) GetSpendingLimits(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	wallet, err := h.walletService.GetWalletByUserID(ctx, userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Spending limits retrieved successfully",
+		Data:    spendingLimitsResponse(wallet),
+	})
+}
+
+// SetSpendingLimits godoc
+// @Summary      Set wallet spending limits
+// @Description  Replaces the spending limits and velocity controls of a user's wallet, an omitted amount limit and zero debits per minute disable a limit
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                     true  "User ID"
+// @Param        request  body      dto.SpendingLimitsRequest  true  "Spending Limits Request"
+// @Success      200      {object}  dto.SpendingLimitsResponse "Spending limits updated"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/limits [put]
+func (h *WalletHandler) SetSpendingLimits(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.SpendingLimitsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	limits := entities.SpendingLimits{
+		MaxDebit:        bigOrNil(req.MaxDebit),
+		DailyAmount:     bigOrNil(req.DailyAmount),
+		MonthlyAmount:   bigOrNil(req.MonthlyAmount),
+		DebitsPerMinute: req.DebitsPerMinute,
+	}
+
+	ctx := c.UserContext()
+	wallet, err := h.walletService.SetSpendingLimits(ctx, userID, limits)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidLimit):
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+		default:
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Spending limits updated successfully",
+		Data:    spendingLimitsResponse(wallet),
+	})
+}
+
+func spendingLimitsResponse(wallet *entities.Wallet) dto.SpendingLimitsResponse {
+	return dto.SpendingLimitsResponse{
+		WalletID:        wallet.ID.String(),
+		UserID:          wallet.UserID.String(),
+		Currency:        wallet.Currency,
+		MaxDebit:        int64OrZero(wallet.Limits.MaxDebit),
+		DailyAmount:     int64OrZero(wallet.Limits.DailyAmount),
+		MonthlyAmount:   int64OrZero(wallet.Limits.MonthlyAmount),
+		DebitsPerMinute: wallet.Limits.DebitsPerMinute,
+	}
+}
+
+// bigOrNil keeps non-positive values, Validate rejects them, only a missing value clears a limit
+func bigOrNil(v *int64) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return big.NewInt(*v)
+}
+
+func int64OrZero(v *big.Int) int64 {
+	if v == nil {
+		return 0
+	}
+	return v.Int64()
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"errors"
+	"finance/internal/api/dto"
+	"finance/internal/domain/entities"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SetParentWallet godoc
+// @Summary      Set parent wallet
+// @Description  Makes debits of the user's wallet fall through to the parent user's wallet once it is empty, up to a monthly quota. The parent can not have a parent and the child can not have children
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string                      true  "Child User ID"
+// @Param        request  body      dto.SetParentWalletRequest  true  "Set Parent Wallet Request"
+// @Success      200      {object}  dto.GetWalletResponse "Parent wallet set"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/parent [put]
+func (h *WalletHandler) SetParentWallet(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	var req dto.SetParentWalletRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid request body")
+	}
+
+	parentUserID, err := uuid.Parse(req.ParentUserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid parent user ID format")
+	}
+
+	ctx := c.UserContext()
+	wallet, err := h.walletService.SetParentWallet(ctx, userID, parentUserID, *big.NewInt(req.MonthlyQuota))
+	if err != nil {
+		return walletHierarchyError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Parent wallet set successfully",
+		Data:    walletResponse(wallet),
+	})
+}
+
+// DetachParentWallet godoc
+// @Summary      Detach parent wallet
+// @Description  Stops debits of the user's wallet from falling through to its parent
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "Child User ID"
+// @Success      200      {object}  dto.GetWalletResponse "Parent wallet detached"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/parent [delete]
+func (h *WalletHandler) DetachParentWallet(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	wallet, err := h.walletService.DetachParentWallet(ctx, userID)
+	if err != nil {
+		return walletHierarchyError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Parent wallet detached successfully",
+		Data:    walletResponse(wallet),
+	})
+}
+
+// ListChildWallets godoc
+// @Summary      List child wallets
+// @Description  Lists the wallets that draw from the user's wallet
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true  "Parent User ID"
+// @Success      200      {array}   dto.GetWalletResponse "Child wallets"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Router       /wallet/user/{user_id}/children [get]
+func (h *WalletHandler) ListChildWallets(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	ctx := c.UserContext()
+	children, err := h.walletService.ListChildWallets(ctx, userID)
+	if err != nil {
+		return walletHierarchyError(err)
+	}
+
+	res := make([]dto.GetWalletResponse, len(children))
+	for i, child := range children {
+		res[i] = walletResponse(child)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Child wallets retrieved successfully",
+		Data:    res,
+	})
+}
+
+// GetSpendingReport godoc
+// @Summary      Get spending report
+// @Description  Gets the sms spending of the user's wallet with the spending of its child wallets rolled up
+// @Tags         wallet
+// @Accept       json
+// @Produce      json
+// @Param        user_id  path      string  true   "User ID"
+// @Param        since    query     string  false  "RFC3339 start time, defaults to the start of the month"
+// @Success      200      {object}  dto.SpendingReportResponse "Spending report"
+// @Failure      400      {object}  map[string]interface{} "Bad Request"
+// @Failure      404      {object}  map[string]interface{} "Wallet not found"
+// @Failure      500      {object}  map[string]interface{} "Internal Server Error"
+// @Router       /wallet/user/{user_id}/spending [get]
+func (h *WalletHandler) GetSpendingReport(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("user_id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid user ID format")
+	}
+
+	since := entities.StartOfMonth(time.Now())
+	if raw := c.Query("since"); raw != "" {
+		if since, err = time.Parse(time.RFC3339, raw); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid since, expected RFC3339")
+		}
+	}
+
+	ctx := c.UserContext()
+	report, err := h.walletService.GetSpendingReport(ctx, userID, since)
+	if err != nil {
+		return walletHierarchyError(err)
+	}
+
+	return c.JSON(dto.BaseResponse{
+		Success: true,
+		Message: "Spending report retrieved successfully",
+		Data:    spendingReportResponse(report, since),
+	})
+}
+
+func walletHierarchyError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrInvalidParent):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "wallet not found")
+	default:
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+}
+
+func spendingReportResponse(report *entities.SpendingReport, since time.Time) dto.SpendingReportResponse {
+	res := dto.SpendingReportResponse{
+		WalletID: report.WalletID.String(),
+		UserID:   report.UserID.String(),
+		Currency: report.Currency,
+		Since:    since,
+		Own:      report.Own.Int64(),
+		Total:    report.Total.Int64(),
+	}
+	for _, child := range report.Children {
+		res.Children = append(res.Children, spendingReportResponse(child, since))
+	}
+	return res
+}
@@ -3,28 +3,36 @@ package messaging
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"finance/config"
+	"finance/internal/domain/entities"
 	"finance/internal/domain/events"
+	"finance/internal/infra/metrics"
 	"finance/internal/usecase"
+	"finance/pkg/health"
 	"finance/pkg/logger"
 	"finance/pkg/rabbit"
-	"math/big"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ConsumerHandler struct {
 	walletService *usecase.WalletService
+	fxService     *usecase.FXService
 	cfg           config.Config
 	consumer      *rabbit.Consumer
 	log           *logger.Logger
 }
 
-func NewConsumerHandler(walletService *usecase.WalletService, cfg config.Config, rabbitConn *rabbit.RabbitConn, logger *logger.Logger) *ConsumerHandler {
+func NewConsumerHandler(walletService *usecase.WalletService, fxService *usecase.FXService, cfg config.Config, rabbitConn *rabbit.RabbitConn, logger *logger.Logger) *ConsumerHandler {
 	return &ConsumerHandler{
 		walletService: walletService,
+		fxService:     fxService,
 		cfg:           cfg,
-		consumer:      rabbit.NewConsumer(rabbitConn),
+		consumer:      rabbit.NewConsumer(rabbitConn, logger),
 		log:           logger,
 	}
 }
@@ -33,80 +41,228 @@ func (h *ConsumerHandler) HandleDebitWallet(ctx context.Context, message []byte)
 	var msg events.RequestSMSBilling
 	err := json.Unmarshal(message, &msg)
 	if err != nil {
-		h.log.Error("Error unmarshaling message:", "error", err)
-		return err
+		h.log.Error(ctx, "Error unmarshaling message:", "error", err)
+		return rabbit.Drop(err)
 	}
 	userID, err := uuid.Parse(msg.UserID)
 	if err != nil {
-		h.log.Error("Invalid user ID:", "error", err)
-		return err
+		h.log.Error(ctx, "Invalid user ID:", "error", err)
+		return rabbit.Drop(err)
 	}
 	smsID, err := uuid.Parse(msg.SMSID)
 	if err != nil {
-		h.log.Error("Invalid SMS ID:", "error", err)
-		return err
+		h.log.Error(ctx, "Invalid SMS ID:", "error", err)
+		return rabbit.Drop(err)
 	}
 
-	amount := big.NewInt(msg.Amount)
+	sms := entities.SMSMetadata{
+		Receiver:    msg.Receiver,
+		Segments:    msg.Segments,
+		Encoding:    msg.Encoding,
+		MessageType: entities.MessageType(msg.MessageType),
+		Operator:    msg.Operator,
+	}
 
-	test, err := h.walletService.DebitUserbalance(ctx, userID, smsID, *amount)
+	test, err := h.walletService.DebitUserbalance(ctx, userID, smsID, sms)
 	if err != nil {
-		h.log.Error("Error debiting user balance:", "error", err)
+		h.log.Error(ctx, "Error debiting user balance:", "error", err)
+		if isDebitRejection(err) {
+			h.publishDebitFailed(ctx, msg, err)
+			return rabbit.Drop(err)
+		}
 		return err
 	}
 
-	err = h.walletService.Publisher.PublishEvent(ctx, test)
+	err = h.walletService.Publish(ctx, test)
 	if err != nil {
-		h.log.Error("Error publishing event:", "error", err)
-		return err
+		h.log.Error(ctx, "Error publishing event:", "error", err)
+		// the debit is committed, handling the message again would charge the sms twice
+		return rabbit.Drop(err)
 	}
 
-	h.log.Info(ctx, "Successfully debited user", "user_id", msg.UserID, "sms_id", msg.SMSID, "amount", msg.Amount)
+	h.log.Info(ctx, "Successfully debited user", "user_id", msg.UserID, "sms_id", msg.SMSID, "amount", test.Amount, "tariff_version", test.TariffVersion)
 	return nil
 }
 
+// publishDebitFailed lets the sms service know why a debit was rejected
+func (h *ConsumerHandler) publishDebitFailed(ctx context.Context, msg events.RequestSMSBilling, reason error) {
+	failed := &events.SMSDebitFailed{
+		UserID:    msg.UserID,
+		SMSID:     msg.SMSID,
+		Reason:    reason.Error(),
+		TimeStamp: time.Now(),
+	}
+	if err := h.walletService.Publish(ctx, failed); err != nil {
+		h.log.Error(ctx, "Error publishing debit failure:", "error", err)
+	}
+}
+
+func isDebitRejection(err error) bool {
+	return errors.Is(err, entities.ErrLimitExceeded) ||
+		errors.Is(err, entities.ErrInsufficientBalance) ||
+		errors.Is(err, entities.ErrInvalidAmount) ||
+		errors.Is(err, entities.ErrInvalidSMSMetadata) ||
+		errors.Is(err, entities.ErrNoMatchingRate) ||
+		errors.Is(err, entities.ErrTariffNotFound) ||
+		errors.Is(err, entities.ErrParentQuotaReached)
+}
+
 func (h *ConsumerHandler) HandleRefundTransaction(ctx context.Context, message []byte) error {
 	var msg events.RequestBillingRefund
 	err := json.Unmarshal(message, &msg)
 	if err != nil {
-		h.log.Error("Error unmarshaling message:", "error", err)
-		return err
+		h.log.Error(ctx, "Error unmarshaling message:", "error", err)
+		return rabbit.Drop(err)
 	}
 	err = h.walletService.RefundTransaction(ctx, msg.TransactionID)
 	if err != nil {
-		h.log.Error("Error refunding transaction:", "error", err)
-		return err
+		h.log.Error(ctx, "Error refunding transaction:", "error", err)
+		return dropWhen(err, gorm.ErrRecordNotFound)
 	}
 	h.log.Info(ctx, "Successfully refunded transaction", "transaction_id", msg.TransactionID)
 	return nil
 }
 
+func (h *ConsumerHandler) HandleTopUpSucceeded(ctx context.Context, message []byte) error {
+	var msg events.TopUpSucceeded
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		h.log.Error(ctx, "Error unmarshaling message:", "error", err)
+		return rabbit.Drop(err)
+	}
+	requestID, err := uuid.Parse(msg.RequestID)
+	if err != nil {
+		h.log.Error(ctx, "Invalid top-up request ID:", "error", err)
+		return rabbit.Drop(err)
+	}
+	err = h.walletService.CompleteTopUp(ctx, requestID)
+	if err != nil {
+		h.log.Error(ctx, "Error completing top-up:", "error", err)
+		return dropWhen(err, entities.ErrTopUpRequestNotFound, gorm.ErrRecordNotFound)
+	}
+	h.log.Info(ctx, "Successfully completed top-up", "request_id", msg.RequestID, "payment_id", msg.PaymentID)
+	return nil
+}
+
+func (h *ConsumerHandler) HandleTopUpFailed(ctx context.Context, message []byte) error {
+	var msg events.TopUpFailed
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		h.log.Error(ctx, "Error unmarshaling message:", "error", err)
+		return rabbit.Drop(err)
+	}
+	requestID, err := uuid.Parse(msg.RequestID)
+	if err != nil {
+		h.log.Error(ctx, "Invalid top-up request ID:", "error", err)
+		return rabbit.Drop(err)
+	}
+	err = h.walletService.FailTopUp(ctx, requestID)
+	if err != nil {
+		h.log.Error(ctx, "Error failing top-up:", "error", err)
+		return dropWhen(err, entities.ErrTopUpRequestNotFound, gorm.ErrRecordNotFound)
+	}
+	h.log.Warn(ctx, "Top-up failed", "request_id", msg.RequestID, "payment_id", msg.PaymentID, "reason", msg.Reason)
+	return nil
+}
+
+func (h *ConsumerHandler) HandleFXRateUpdated(ctx context.Context, message []byte) error {
+	var msg events.FXRateUpdated
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		h.log.Error(ctx, "Error unmarshaling message:", "error", err)
+		return rabbit.Drop(err)
+	}
+	rate, err := h.fxService.SaveRate(ctx, usecase.FXRateInput{
+		Base:       msg.Base,
+		Quote:      msg.Quote,
+		Rate:       msg.Rate,
+		ValidFrom:  msg.ValidFrom,
+		ValidUntil: msg.ValidUntil,
+		Source:     entities.FXSourceFeed,
+	})
+	if err != nil {
+		h.log.Error(ctx, "Error saving fx rate:", "error", err)
+		return dropWhen(err, entities.ErrInvalidFXRate)
+	}
+	h.log.Info(ctx, "Successfully saved fx rate", "base", rate.Base, "quote", rate.Quote, "rate", rate.Rate.RatString())
+	return nil
+}
+
+// dropWhen drops the message when err is one of the permanent errors, anything else is requeued
+func dropWhen(err error, permanent ...error) error {
+	for _, target := range permanent {
+		if errors.Is(err, target) {
+			return rabbit.Drop(err)
+		}
+	}
+	return err
+}
+
+// auditActor attributes balance changes caused by a message to the queue it came from
+func auditActor(ctx context.Context, queue string, message []byte) context.Context {
+	return entities.WithAuditActor(ctx, entities.AuditActor{
+		ID:          queue,
+		Source:      entities.AuditSourceAMQP,
+		PayloadHash: entities.PayloadHash(message),
+	})
+}
+
+// HealthChecks adds a readiness check per configured queue, failing until its consumer runs
+func (h *ConsumerHandler) HealthChecks(checker *health.Checker) {
+	for _, queue := range h.cfg.RabbitMQ.Queues {
+		name := queue.Name
+		checker.Add("queue:"+name, func(ctx context.Context) error {
+			status, ok := h.consumer.Status()[name]
+			if !ok {
+				return errors.New("no consumer subscribed")
+			}
+			if status.State != rabbit.QueueConsuming {
+				if status.Error != "" {
+					return fmt.Errorf("consumer %s: %s", status.State, status.Error)
+				}
+				return fmt.Errorf("consumer %s", status.State)
+			}
+			return nil
+		})
+	}
+}
+
 func (h *ConsumerHandler) Run(ctx context.Context) error {
 	if err := h.consumer.SetQos(1); err != nil {
-		h.log.Error("Failed to set QoS", "error", err)
+		h.log.Error(ctx, "Failed to set QoS", "error", err)
 		return err
 	}
 	for _, queue := range h.cfg.RabbitMQ.Queues {
+		var handle func(ctx context.Context, message []byte) error
 		switch queue.Name {
 		case rabbit.DebitQueueName:
-			h.consumer.Subscribe(queue.Name, func(message []byte) error {
-				return h.HandleDebitWallet(ctx, message)
-			})
+			handle = h.HandleDebitWallet
 		case rabbit.RefundQueueName:
-			h.consumer.Subscribe(queue.Name, func(message []byte) error {
-				return h.HandleRefundTransaction(ctx, message)
-			})
+			handle = h.HandleRefundTransaction
+		case rabbit.TopUpSucceededQueueName:
+			handle = h.HandleTopUpSucceeded
+		case rabbit.TopUpFailedQueueName:
+			handle = h.HandleTopUpFailed
+		case rabbit.FXRateUpdatedQueueName:
+			handle = h.HandleFXRateUpdated
 		default:
-			h.log.Logger.Warn("unknown queue in configuration", "queue", queue.Name)
+			h.log.Warn(ctx, "unknown queue in configuration", "queue", queue.Name)
+			continue
 		}
+		h.consumer.Subscribe(queue.Name, func(msgCtx context.Context, message []byte) error {
+			started := time.Now()
+			err := handle(auditActor(msgCtx, queue.Name, message), message)
+			metrics.HandlerDuration.WithLabelValues(queue.Name, metrics.Outcome(err)).Observe(time.Since(started).Seconds())
+			return err
+		})
 	}
-	h.log.Logger.Info("starting SMS consumer")
-	if err := h.consumer.StartConsume(); err != nil {
+	h.log.Info(ctx, "starting SMS consumer")
+	if err := h.consumer.StartConsume(ctx); err != nil {
 		h.log.Info(ctx, "failed to start consumer", "error", err)
 		return err
 	}
 
 	<-ctx.Done()
-	h.log.Logger.Info("SMS consumer stopped")
+	h.log.Info(ctx, "SMS consumer stopped")
 	return ctx.Err()
 }
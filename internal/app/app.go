@@ -2,24 +2,46 @@ package app
 
 import (
 	"context"
+	"errors"
 	"finance/config"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/auth"
 	"finance/internal/infra/messaging"
+	"finance/internal/infra/ratelimit"
 	"finance/internal/infra/storage"
 	"finance/internal/infra/storage/types"
 	"finance/internal/usecase"
+	"finance/pkg/health"
 	"finance/pkg/logger"
 	"finance/pkg/postgres"
 	"finance/pkg/rabbit"
+	"finance/pkg/tracing"
+	"fmt"
 
 	"gorm.io/gorm"
 )
 
+// schemaVersion is the version the models migrate to, bump it when a model changes
+const schemaVersion = 3
+
 type app struct {
-	db            *gorm.DB
-	cfg           config.Config
-	rabbitConn    *rabbit.RabbitConn
-	walletService *usecase.WalletService
-	logger        *logger.Logger
+	db                 *gorm.DB
+	cfg                config.Config
+	rabbitConn         *rabbit.RabbitConn
+	walletService      *usecase.WalletService
+	tariffService      *usecase.TariffService
+	planService        *usecase.PlanService
+	fxService          *usecase.FXService
+	taxService         *usecase.TaxService
+	invoiceService     *usecase.InvoiceService
+	snapshotService    *usecase.BalanceSnapshotService
+	auditService       *usecase.AuditService
+	authService        *usecase.AuthService
+	signingService     *usecase.SigningService
+	idempotencyService *usecase.IdempotencyService
+	rateLimitService   *usecase.RateLimitService
+	shutdownTracing    func(context.Context) error
+	logger             *logger.Logger
 }
 
 func (a *app) Config() config.Config {
@@ -38,11 +60,88 @@ func (a *app) WalletService(ctx context.Context) *usecase.WalletService {
 	return a.walletService
 }
 
+func (a *app) TariffService(ctx context.Context) *usecase.TariffService {
+	return a.tariffService
+}
+
+func (a *app) PlanService(ctx context.Context) *usecase.PlanService {
+	return a.planService
+}
+
+func (a *app) FXService(ctx context.Context) *usecase.FXService {
+	return a.fxService
+}
+
+func (a *app) TaxService(ctx context.Context) *usecase.TaxService {
+	return a.taxService
+}
+
+func (a *app) InvoiceService(ctx context.Context) *usecase.InvoiceService {
+	return a.invoiceService
+}
+
+func (a *app) BalanceSnapshotService(ctx context.Context) *usecase.BalanceSnapshotService {
+	return a.snapshotService
+}
+
+func (a *app) AuditService(ctx context.Context) *usecase.AuditService {
+	return a.auditService
+}
+
+func (a *app) AuthService(ctx context.Context) *usecase.AuthService {
+	return a.authService
+}
+
+func (a *app) SigningService(ctx context.Context) *usecase.SigningService {
+	return a.signingService
+}
+
+func (a *app) IdempotencyService(ctx context.Context) *usecase.IdempotencyService {
+	return a.idempotencyService
+}
+
+func (a *app) RateLimitService(ctx context.Context) *usecase.RateLimitService {
+	return a.rateLimitService
+}
+
+// HealthChecker checks the database, the rabbitmq connection and the schema version
+func (a *app) HealthChecker() *health.Checker {
+	checker := health.NewChecker()
+	checker.Add("database", func(ctx context.Context) error {
+		sqlDB, err := a.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checker.Add("rabbitmq", func(ctx context.Context) error {
+		return a.rabbitConn.Check()
+	})
+	checker.Add("migrations", func(ctx context.Context) error {
+		version, err := postgres.SchemaVersion(ctx, a.db)
+		if err != nil {
+			return err
+		}
+		if version < schemaVersion {
+			return fmt.Errorf("schema version %d, want %d", version, schemaVersion)
+		}
+		return nil
+	})
+	return checker
+}
+
 func NewApp(cfg config.Config) (App, error) {
 	a := &app{
-		cfg:    cfg,
-		logger: logger.NewLogger(""),
+		cfg: cfg,
+	}
+	if err := a.setLogger(); err != nil {
+		return nil, err
 	}
+
+	if err := a.setTracing(); err != nil {
+		return nil, err
+	}
+
 	if err := a.setDB(); err != nil {
 		return nil, err
 	}
@@ -56,6 +155,14 @@ func NewApp(cfg config.Config) (App, error) {
 	}
 
 	a.setWalletService(a.db, a.rabbitConn)
+
+	if err := a.setAuthService(a.db); err != nil {
+		return nil, err
+	}
+
+	if err := a.setRateLimitService(a.db); err != nil {
+		return nil, err
+	}
 	return a, nil
 }
 
@@ -66,6 +173,48 @@ func NewMustApp(cfg config.Config) App {
 	}
 	return app
 }
+func (a *app) Logger() *logger.Logger {
+	return a.logger
+}
+
+// Shutdown flushes the spans and closes a log file
+func (a *app) Shutdown(ctx context.Context) error {
+	return errors.Join(a.shutdownTracing(ctx), a.logger.Close())
+}
+
+func (a *app) setLogger() error {
+	log, err := logger.New(logger.Options{
+		Level:  logger.LogLevel(a.cfg.Logging.Level),
+		Format: a.cfg.Logging.Format,
+		Output: a.cfg.Logging.Output,
+		Sampling: logger.Sampling{
+			Initial:    a.cfg.Logging.Sampling.Initial,
+			Thereafter: a.cfg.Logging.Sampling.Thereafter,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	a.logger = log
+	return nil
+}
+
+func (a *app) setTracing() error {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    a.cfg.Tracing.Exporter,
+		Endpoint:    a.cfg.Tracing.Endpoint,
+		Insecure:    a.cfg.Tracing.Insecure,
+		File:        a.cfg.Tracing.File,
+		SampleRatio: a.cfg.Tracing.SampleRatio,
+		ServiceName: a.cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return err
+	}
+	a.shutdownTracing = shutdown
+	return nil
+}
+
 func (a *app) setDB() error {
 	db, err := postgres.NewPsqlGormConnection(postgres.DBConnOptions{
 		User:   a.cfg.DB.User,
@@ -79,7 +228,13 @@ func (a *app) setDB() error {
 		return err
 	}
 	// Auto migrate
-	err = postgres.Migrate(db, &types.Wallet{}, &types.Transaction{}, &types.User{})
+	err = postgres.MigrateVersion(db, schemaVersion, &types.Wallet{}, &types.Transaction{}, &types.User{}, &types.BalanceThreshold{},
+		&types.TopUpRule{}, &types.TopUpRequest{}, &types.Tariff{}, &types.TariffRate{},
+		&types.Plan{}, &types.PlanTier{}, &types.PlanAssignment{}, &types.BalanceBucket{}, &types.TransactionFunding{},
+		&types.Voucher{}, &types.VoucherRedemption{}, &types.Transfer{}, &types.FXRate{}, &types.SubBalance{}, &types.TaxRule{},
+		&types.Invoice{}, &types.InvoiceLine{}, &types.InvoiceCounter{}, &types.BalanceSnapshot{},
+		&types.AuditEvent{}, &types.APIKey{}, &types.SigningKey{},
+		&types.IdempotencyRecord{}, &types.RateLimitBucket{}, &types.SignatureNonce{})
 	if err != nil {
 		return err
 	}
@@ -108,7 +263,79 @@ func (a *app) setWalletService(db *gorm.DB, rabbitConn *rabbit.RabbitConn) {
 	walletRepo := storage.NewWalletRepository(db)
 	transactionRepo := storage.NewTransactionRepo(db)
 	userRepo := storage.NewUserRepository(db)
+	thresholdRepo := storage.NewBalanceThresholdRepository(db)
+	topUpRepo := storage.NewTopUpRepository(db)
+	tariffRepo := storage.NewTariffRepository(db)
+	planRepo := storage.NewPlanRepository(db)
+	voucherRepo := storage.NewVoucherRepository(db)
+	transferRepo := storage.NewTransferRepository(db)
+	fxRateRepo := storage.NewFXRateRepository(db)
+	taxRuleRepo := storage.NewTaxRuleRepository(db)
+	invoiceRepo := storage.NewInvoiceRepository(db)
+	snapshotRepo := storage.NewBalanceSnapshotRepository(db)
+	auditRepo := storage.NewAuditRepository(db)
 	txManager := storage.NewGormTransactionManager(db)
 	walletPublisher := messaging.NewWalletPublisher(rabbitConn, a.logger)
-	a.walletService = usecase.NewWalletService(walletRepo, userRepo, transactionRepo, txManager, walletPublisher, a.logger)
+	a.walletService = usecase.NewWalletService(walletRepo, userRepo, transactionRepo, thresholdRepo, topUpRepo, tariffRepo, planRepo, voucherRepo, transferRepo, fxRateRepo, taxRuleRepo, txManager, walletPublisher, a.logger)
+	a.tariffService = usecase.NewTariffService(tariffRepo, txManager, a.logger)
+	a.planService = usecase.NewPlanService(planRepo, userRepo, txManager, a.logger)
+	a.fxService = usecase.NewFXService(fxRateRepo, txManager, a.logger)
+	a.taxService = usecase.NewTaxService(taxRuleRepo, transactionRepo, userRepo, txManager, a.logger)
+	a.invoiceService = usecase.NewInvoiceService(invoiceRepo, walletRepo, userRepo, transactionRepo, txManager, a.logger)
+	a.snapshotService = usecase.NewBalanceSnapshotService(snapshotRepo, walletRepo, transactionRepo, a.logger)
+	a.auditService = usecase.NewAuditService(auditRepo, a.logger)
+	a.idempotencyService = usecase.NewIdempotencyService(storage.NewIdempotencyRepository(db), txManager, a.logger)
+}
+
+func (a *app) setAuthService(db *gorm.DB) error {
+	verifier, err := auth.NewJWTVerifier(a.cfg.Auth)
+	if err != nil {
+		return err
+	}
+	// a nil *JWTVerifier must not end up as a non nil interface
+	var tokenVerifier entities.TokenVerifier
+	if verifier != nil {
+		tokenVerifier = verifier
+	}
+	a.authService = usecase.NewAuthService(storage.NewAPIKeyRepository(db), tokenVerifier, a.logger)
+
+	var nonces entities.NonceCache
+	switch a.cfg.Auth.NonceStore {
+	case "", "memory":
+		nonces = auth.NewMemoryNonceCache()
+	case "postgres":
+		nonces = storage.NewNonceCache(db)
+	default:
+		return fmt.Errorf("unknown nonce store %q, expected memory or postgres", a.cfg.Auth.NonceStore)
+	}
+	a.signingService = usecase.NewSigningService(storage.NewSigningKeyRepository(db), nonces, storage.NewGormTransactionManager(db), a.logger)
+	return nil
+}
+
+func (a *app) setRateLimitService(db *gorm.DB) error {
+	cfg := a.cfg.Server.RateLimit
+
+	var store entities.RateLimitStore
+	switch cfg.Store {
+	case "", "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = storage.NewRateLimitStore(db)
+	default:
+		return fmt.Errorf("unknown rate limit store %q, expected memory or postgres", cfg.Store)
+	}
+
+	rules := make([]entities.RateLimitRule, len(cfg.Rules))
+	for i, rule := range cfg.Rules {
+		rules[i] = entities.RateLimitRule{
+			Client: rule.Client,
+			Method: rule.Method,
+			Path:   rule.Path,
+			Limit:  entities.RateLimit{RequestsPerMinute: rule.RequestsPerMinute, Burst: rule.Burst},
+		}
+	}
+	defaultLimit := entities.RateLimit{RequestsPerMinute: cfg.Default.RequestsPerMinute, Burst: cfg.Default.Burst}
+	perIP := entities.RateLimit{RequestsPerMinute: cfg.PerIP.RequestsPerMinute, Burst: cfg.PerIP.Burst}
+	a.rateLimitService = usecase.NewRateLimitService(store, defaultLimit, perIP, rules, a.logger)
+	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"finance/config"
 	"finance/internal/usecase"
+	"finance/pkg/health"
+	"finance/pkg/logger"
 	"finance/pkg/rabbit"
 
 	"gorm.io/gorm"
@@ -14,4 +16,19 @@ type App interface {
 	DB() *gorm.DB
 	RabbitConn() *rabbit.RabbitConn
 	WalletService(ctx context.Context) *usecase.WalletService
+	TariffService(ctx context.Context) *usecase.TariffService
+	PlanService(ctx context.Context) *usecase.PlanService
+	FXService(ctx context.Context) *usecase.FXService
+	TaxService(ctx context.Context) *usecase.TaxService
+	InvoiceService(ctx context.Context) *usecase.InvoiceService
+	BalanceSnapshotService(ctx context.Context) *usecase.BalanceSnapshotService
+	AuditService(ctx context.Context) *usecase.AuditService
+	AuthService(ctx context.Context) *usecase.AuthService
+	SigningService(ctx context.Context) *usecase.SigningService
+	IdempotencyService(ctx context.Context) *usecase.IdempotencyService
+	RateLimitService(ctx context.Context) *usecase.RateLimitService
+	HealthChecker() *health.Checker
+	Logger() *logger.Logger
+	// Shutdown flushes the spans that are not exported yet and closes a log file
+	Shutdown(ctx context.Context) error
 }
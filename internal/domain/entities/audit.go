@@ -0,0 +1,183 @@
+package entities
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditSource string
+
+const (
+	AuditSourceHTTP   AuditSource = "http"
+	AuditSourceAMQP   AuditSource = "amqp"
+	AuditSourceSystem AuditSource = "system"
+)
+
+type AuditAction string
+
+const (
+	AuditDebit  AuditAction = "debit"
+	AuditCredit AuditAction = "credit"
+	// AuditRepair is reconciliation setting a stored balance to the sum of its transactions
+	AuditRepair AuditAction = "repair"
+)
+
+// AuditRepo reads the audit chains, they are written by WalletRepo with the balance they describe
+type AuditRepo interface {
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, error)
+	// Chain returns the audit events of the wallet in sequence order
+	Chain(ctx context.Context, walletID uuid.UUID) ([]*AuditEvent, error)
+	WithTx(tx *gorm.DB) AuditRepo
+}
+
+// AuditFilter selects audit events created in [From, To), zero values leave that part open
+type AuditFilter struct {
+	WalletID uuid.UUID
+	Actor    string
+	From     time.Time
+	To       time.Time
+	Limit    int
+}
+
+// AuditActor tells who asked for a change and how, it travels in the context from the api handlers
+type AuditActor struct {
+	ID          string
+	Source      AuditSource
+	PayloadHash string
+}
+
+type auditActorKey struct{}
+
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor of the context, changes made outside a request are the system's
+func AuditActorFrom(ctx context.Context) AuditActor {
+	if actor, ok := ctx.Value(auditActorKey{}).(AuditActor); ok {
+		return actor
+	}
+	return AuditActor{ID: "system", Source: AuditSourceSystem}
+}
+
+// PayloadHash is the hex sha256 of a request body or message
+func PayloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditEvent records one balance change of a wallet, each event hashes the one before it in the
+// wallet's chain so an edited or removed event breaks every hash after it
+type AuditEvent struct {
+	ID            uuid.UUID
+	WalletID      uuid.UUID
+	Sequence      int64
+	Action        AuditAction
+	Category      TransactionCategory
+	TransactionID uuid.UUID
+	Actor         string
+	Source        AuditSource
+	TraceID       string
+	PayloadHash   string
+	Currency      string
+	BalanceBefore *big.Int
+	BalanceAfter  *big.Int
+	PrevHash      string
+	Hash          string
+	CreatedAt     time.Time
+}
+
+// NewAuditEvent describes the change of the wallet balance from before to its current value,
+// transaction is nil for changes that have none, like a repair
+func NewAuditEvent(ctx context.Context, traceID string, wallet *Wallet, before *big.Int, action AuditAction, transaction *Transaction) *AuditEvent {
+	actor := AuditActorFrom(ctx)
+	event := &AuditEvent{
+		ID:            uuid.New(),
+		WalletID:      wallet.ID,
+		Action:        action,
+		Actor:         actor.ID,
+		Source:        actor.Source,
+		TraceID:       traceID,
+		PayloadHash:   actor.PayloadHash,
+		Currency:      wallet.Currency,
+		BalanceBefore: new(big.Int).Set(before),
+		BalanceAfter:  wallet.Balance.Amount(),
+		// the db keeps microseconds, the hash has to survive the round trip
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if transaction != nil {
+		event.Category = transaction.Category
+		event.TransactionID = transaction.ID
+	}
+	return event
+}
+
+// Chain links the event after previous, the wallet's last event, nil when it is the first
+func (e *AuditEvent) Chain(previous *AuditEvent) {
+	e.Sequence = 1
+	e.PrevHash = ""
+	if previous != nil {
+		e.Sequence = previous.Sequence + 1
+		e.PrevHash = previous.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// ComputeHash hashes every recorded field together with the previous hash
+func (e *AuditEvent) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		e.ID.String(),
+		e.WalletID.String(),
+		strconv.FormatInt(e.Sequence, 10),
+		string(e.Action),
+		string(e.Category),
+		e.TransactionID.String(),
+		e.Actor,
+		string(e.Source),
+		e.TraceID,
+		e.PayloadHash,
+		e.Currency,
+		e.BalanceBefore.String(),
+		e.BalanceAfter.String(),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditVerification is the result of checking a wallet's audit chain, BrokenAt is the sequence of the
+// first event that does not match, zero when the chain is intact
+type AuditVerification struct {
+	WalletID uuid.UUID
+	Events   int
+	Valid    bool
+	BrokenAt int64
+}
+
+// VerifyAuditChain checks the hashes and links of a wallet's events given in sequence order
+func VerifyAuditChain(walletID uuid.UUID, chain []*AuditEvent) AuditVerification {
+	result := AuditVerification{WalletID: walletID, Events: len(chain), Valid: true}
+	var previous *AuditEvent
+	for _, event := range chain {
+		expectedSequence, expectedPrev := int64(1), ""
+		if previous != nil {
+			expectedSequence, expectedPrev = previous.Sequence+1, previous.Hash
+		}
+		if event.Sequence != expectedSequence || event.PrevHash != expectedPrev || event.Hash != event.ComputeHash() {
+			result.Valid = false
+			result.BrokenAt = event.Sequence
+			return result
+		}
+		previous = event
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package entities
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+	ErrForbidden       = errors.New("not allowed to access this resource")
+	ErrInvalidRole     = errors.New("invalid role")
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrInvalidAPIKey   = errors.New("invalid api key")
+	ErrCustomerNoUser  = errors.New("customer credentials need a user")
+)
+
+// APIKeyPrefix starts every api key, it tells keys apart from jwt bearer tokens
+const APIKeyPrefix = "fk_"
+
+type Role string
+
+const (
+	RoleAdmin   Role = "admin"
+	RoleFinance Role = "finance"
+	// RoleCustomer can only read the wallet of its own user
+	RoleCustomer Role = "customer"
+	// RoleService is another internal service calling the api
+	RoleService Role = "service"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleFinance, RoleCustomer, RoleService:
+		return true
+	}
+	return false
+}
+
+// Principal is the authenticated caller of a request, UserID is set for customers
+type Principal struct {
+	ID     string
+	Role   Role
+	UserID uuid.UUID
+}
+
+// NewPrincipal checks that a customer is tied to a user
+func NewPrincipal(id string, role Role, userID uuid.UUID) (Principal, error) {
+	if !role.Valid() {
+		return Principal{}, ErrInvalidRole
+	}
+	if role == RoleCustomer && userID == uuid.Nil {
+		return Principal{}, ErrCustomerNoUser
+	}
+	return Principal{ID: id, Role: role, UserID: userID}, nil
+}
+
+// HasRole tells if the principal has one of roles, admins have every role
+func (p Principal) HasRole(roles ...Role) bool {
+	if p.Role == RoleAdmin {
+		return true
+	}
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessUser tells if the principal may act on the user's resources, customers only on their own
+func (p Principal) CanAccessUser(userID uuid.UUID) bool {
+	return p.Role != RoleCustomer || p.UserID == userID
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// TokenVerifier turns a bearer token into the principal it was issued to
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (Principal, error)
+}
+
+type APIKeyRepo interface {
+	Save(ctx context.Context, key *APIKey) error
+	FindByHash(ctx context.Context, hash string) (*APIKey, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, key *APIKey) error
+	WithTx(tx *gorm.DB) APIKeyRepo
+}
+
+// APIKey grants its role to whoever presents the secret, only the hash of the secret is stored
+type APIKey struct {
+	ID   uuid.UUID
+	Name string
+	// Prefix is the start of the secret, enough to recognise a key in a listing
+	Prefix    string
+	Hash      string
+	Role      Role
+	UserID    uuid.UUID
+	RevokedAt *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewAPIKey creates a key and returns its secret, the secret can not be recovered later
+func NewAPIKey(name string, role Role, userID uuid.UUID) (*APIKey, string, error) {
+	if _, err := NewPrincipal(name, role, userID); err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil, "", ErrInvalidAPIKey
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	now := time.Now()
+	return &APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    secret[:len(APIKeyPrefix)+6],
+		Hash:      HashAPIKey(secret),
+		Role:      role,
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, secret, nil
+}
+
+// HashAPIKey is a plain sha256, the secrets are random so they need no salt or stretching
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+func (k *APIKey) Revoke() {
+	if k.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	k.UpdatedAt = now
+}
+
+func (k *APIKey) Principal() Principal {
+	return Principal{ID: "api-key:" + k.ID.String(), Role: k.Role, UserID: k.UserID}
+}
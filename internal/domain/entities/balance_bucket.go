@@ -0,0 +1,347 @@
+package entities
+
+import (
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBucketNotFound = errors.New("balance bucket not found")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+)
+
+type BucketKind string
+
+const (
+	BucketPaid  BucketKind = "paid"
+	BucketBonus BucketKind = "bonus"
+	// BucketParent is the part of a debit the parent wallet paid, BucketID is the parent wallet
+	BucketParent BucketKind = "parent"
+)
+
+// PaidBucketPriority is where paid balance sits among bonus buckets, lower priorities are spent first
+const PaidBucketPriority = 100
+
+// BalanceBucket is bonus credit inside the wallet balance, what is not in a bucket is paid balance
+type BalanceBucket struct {
+	ID        uuid.UUID
+	WalletID  uuid.UUID
+	Kind      BucketKind
+	Granted   valueobjects.Money
+	Remaining valueobjects.Money
+	Priority  int
+	ExpiresAt *time.Time
+	Reason    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Funding tells how much of a transaction came from, or went to, a bucket, BucketID is nil for paid balance
+type Funding struct {
+	BucketID uuid.UUID
+	Kind     BucketKind
+	Amount   valueobjects.Money
+}
+
+func (b *BalanceBucket) Expired(now time.Time) bool {
+	return b.ExpiresAt != nil && !now.Before(*b.ExpiresAt)
+}
+
+// GrantBonus adds bonus credit to the balance in a new bucket
+func (w *Wallet) GrantBonus(amount valueobjects.Money, priority int, expiresAt *time.Time, reason string) (*BalanceBucket, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidExpiry
+	}
+
+	if err := w.Credit(amount); err != nil {
+		return nil, err
+	}
+
+	bucket := &BalanceBucket{
+		ID:        uuid.New(),
+		WalletID:  w.ID,
+		Kind:      BucketBonus,
+		Granted:   amount,
+		Remaining: amount,
+		Priority:  priority,
+		ExpiresAt: expiresAt,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	w.Buckets = append(w.Buckets, bucket)
+	return bucket, nil
+}
+
+// BonusBalance is the unexpired bonus credit of the loaded buckets
+func (w *Wallet) BonusBalance(now time.Time) valueobjects.Money {
+	total := big.NewInt(0)
+	for _, b := range w.Buckets {
+		if !b.Expired(now) {
+			total.Add(total, b.Remaining.Amount())
+		}
+	}
+	bonus, _ := valueobjects.NewMoney(total, w.Currency)
+	return bonus
+}
+
+// PaidBalance is the balance without any bonus credit, it is negative while a credit line is in use
+func (w *Wallet) PaidBalance() valueobjects.Money {
+	paid := new(big.Int).Set(w.Balance.Amount())
+	for _, b := range w.Buckets {
+		paid.Sub(paid, b.Remaining.Amount())
+	}
+	balance, _ := valueobjects.NewSignedMoney(paid, w.Balance.Currency())
+	return balance
+}
+
+// DebitFunded debits the wallet drawing from unexpired bonus buckets and paid balance by priority,
+// a shortfall is taken from paid balance and so from the credit line
+func (w *Wallet) DebitFunded(amount valueobjects.Money) ([]Funding, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	now := time.Now()
+	if expired := w.expiredBonus(now); expired.Sign() > 0 {
+		// expired credit stays in Balance until the expiry job removes it, it can not be spent
+		required, err := valueobjects.NewMoney(new(big.Int).Add(amount.Amount(), expired), amount.Currency())
+		if err != nil {
+			return nil, err
+		}
+		if err := w.HasSufficientBalance(required); err != nil {
+			return nil, err
+		}
+	}
+
+	paid := w.PaidBalance().Amount()
+	if err := w.Debit(amount); err != nil {
+		return nil, err
+	}
+
+	var funding []Funding
+	left := new(big.Int).Set(amount.Amount())
+	for _, b := range w.spendOrder(now) {
+		if left.Sign() == 0 {
+			break
+		}
+		if b == nil {
+			if take := minPositive(paid, left); take.Sign() > 0 {
+				funding = append(funding, w.funding(uuid.Nil, BucketPaid, take))
+				left.Sub(left, take)
+			}
+			continue
+		}
+		take := minPositive(b.Remaining.Amount(), left)
+		if take.Sign() == 0 {
+			continue
+		}
+		b.Remaining, _ = valueobjects.NewMoney(new(big.Int).Sub(b.Remaining.Amount(), take), w.Currency)
+		b.UpdatedAt = now
+		funding = append(funding, w.funding(b.ID, BucketBonus, take))
+		left.Sub(left, take)
+	}
+
+	if left.Sign() > 0 {
+		funding = mergePaid(funding, w.funding(uuid.Nil, BucketPaid, left))
+	}
+	return funding, nil
+}
+
+// Refund credits a debit back to the buckets it was drawn from, bonus credit of buckets that expired
+// in the meantime is not refunded. It returns the split of what was refunded, parent funding included.
+func (w *Wallet) Refund(amount valueobjects.Money, funding []Funding) ([]Funding, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if len(funding) == 0 {
+		// debits from before buckets existed were paid balance only
+		funding = []Funding{{Kind: BucketPaid, Amount: amount}}
+	}
+
+	now := time.Now()
+	var refunded []Funding
+	for _, f := range funding {
+		if f.Kind == BucketBonus {
+			bucket := w.bucket(f.BucketID)
+			if bucket == nil || bucket.Expired(now) {
+				continue
+			}
+			remaining, err := bucket.Remaining.Add(f.Amount)
+			if err != nil {
+				return nil, err
+			}
+			bucket.Remaining = remaining
+			bucket.UpdatedAt = now
+		}
+		refunded = append(refunded, f)
+	}
+
+	if len(refunded) == 0 {
+		return nil, nil
+	}
+
+	// what the parent paid goes back to the parent, not to this wallet
+	own := FundingTotal(OwnFunding(refunded), amount.Currency())
+	if !own.IsZero() {
+		if err := w.Credit(own); err != nil {
+			return nil, err
+		}
+	}
+	return refunded, nil
+}
+
+func (w *Wallet) HasBucket(ID uuid.UUID) bool {
+	return w.bucket(ID) != nil
+}
+
+// ExpireBuckets removes the remaining credit of expired buckets from the balance
+func (w *Wallet) ExpireBuckets(now time.Time) ([]Funding, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	var funding []Funding
+	for _, b := range w.Buckets {
+		if !b.Expired(now) || b.Remaining.IsZero() {
+			continue
+		}
+
+		balance, err := w.Balance.SubtractSigned(b.Remaining)
+		if err != nil {
+			return nil, err
+		}
+		funding = append(funding, Funding{BucketID: b.ID, Kind: BucketBonus, Amount: b.Remaining})
+
+		w.Balance = balance
+		b.Remaining, _ = valueobjects.NewMoney(big.NewInt(0), w.Currency)
+		b.UpdatedAt = now
+	}
+	if len(funding) > 0 {
+		w.UpdatedAt = now
+	}
+	return funding, nil
+}
+
+// FundingTotal sums the amounts of a funding split
+func FundingTotal(funding []Funding, currency string) valueobjects.Money {
+	total := big.NewInt(0)
+	for _, f := range funding {
+		total.Add(total, f.Amount.Amount())
+	}
+	money, _ := valueobjects.NewMoney(total, currency)
+	return money
+}
+
+// OwnFunding leaves out what a parent wallet paid
+func OwnFunding(funding []Funding) []Funding {
+	var own []Funding
+	for _, f := range funding {
+		if f.Kind != BucketParent {
+			own = append(own, f)
+		}
+	}
+	return own
+}
+
+// ParentFunding is what the parent wallet paid of a debit, zero when it paid nothing
+func ParentFunding(funding []Funding, currency string) valueobjects.Money {
+	var parent []Funding
+	for _, f := range funding {
+		if f.Kind == BucketParent {
+			parent = append(parent, f)
+		}
+	}
+	return FundingTotal(parent, currency)
+}
+
+// spendOrder returns the unexpired buckets by priority, nil stands for paid balance
+func (w *Wallet) spendOrder(now time.Time) []*BalanceBucket {
+	order := []*BalanceBucket{nil}
+	for _, b := range w.Buckets {
+		if !b.Expired(now) {
+			order = append(order, b)
+		}
+	}
+
+	priority := func(b *BalanceBucket) int {
+		if b == nil {
+			return PaidBucketPriority
+		}
+		return b.Priority
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		pi, pj := priority(order[i]), priority(order[j])
+		if pi != pj {
+			return pi < pj
+		}
+		// buckets that expire first are spent first, paid balance never expires
+		return expiresBefore(order[i], order[j])
+	})
+	return order
+}
+
+func (w *Wallet) expiredBonus(now time.Time) *big.Int {
+	total := big.NewInt(0)
+	for _, b := range w.Buckets {
+		if b.Expired(now) {
+			total.Add(total, b.Remaining.Amount())
+		}
+	}
+	return total
+}
+
+func (w *Wallet) bucket(ID uuid.UUID) *BalanceBucket {
+	for _, b := range w.Buckets {
+		if b.ID == ID {
+			return b
+		}
+	}
+	return nil
+}
+
+func (w *Wallet) funding(bucketID uuid.UUID, kind BucketKind, amount *big.Int) Funding {
+	money, _ := valueobjects.NewMoney(amount, w.Currency)
+	return Funding{BucketID: bucketID, Kind: kind, Amount: money}
+}
+
+func expiresBefore(a, b *BalanceBucket) bool {
+	if a == nil || a.ExpiresAt == nil {
+		return false
+	}
+	if b == nil || b.ExpiresAt == nil {
+		return true
+	}
+	return a.ExpiresAt.Before(*b.ExpiresAt)
+}
+
+func mergePaid(funding []Funding, paid Funding) []Funding {
+	for i, f := range funding {
+		if f.Kind == BucketPaid {
+			total, _ := f.Amount.Add(paid.Amount)
+			funding[i].Amount = total
+			return funding
+		}
+	}
+	return append(funding, paid)
+}
+
+func minPositive(a, b *big.Int) *big.Int {
+	if a.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSnapshotNotFound = errors.New("balance snapshot not found")
+)
+
+type BalanceSnapshotRepo interface {
+	// Save keeps the first snapshot of a wallet at a given time, saving it again is a no-op
+	Save(ctx context.Context, snapshot *BalanceSnapshot) error
+	// FindLatest returns the newest snapshot of the wallet taken at or before at
+	FindLatest(ctx context.Context, walletID uuid.UUID, at time.Time) (*BalanceSnapshot, error)
+	WithTx(tx *gorm.DB) BalanceSnapshotRepo
+}
+
+// BalanceSnapshot is the balance of a wallet made of the completed transactions created before TakenAt
+type BalanceSnapshot struct {
+	ID        uuid.UUID
+	WalletID  uuid.UUID
+	Currency  string
+	Balance   *big.Int
+	TakenAt   time.Time
+	CreatedAt time.Time
+}
+
+func NewBalanceSnapshot(wallet *Wallet, balance *big.Int, takenAt time.Time) *BalanceSnapshot {
+	return &BalanceSnapshot{
+		ID:        uuid.New(),
+		WalletID:  wallet.ID,
+		Currency:  wallet.Currency,
+		Balance:   new(big.Int).Set(balance),
+		TakenAt:   takenAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+// PointInTimeBalance is a wallet balance as it was at At, SnapshotAt tells which snapshot the
+// transactions were replayed from, nil means they were summed from the first one
+type PointInTimeBalance struct {
+	WalletID   uuid.UUID
+	Currency   string
+	At         time.Time
+	Balance    *big.Int
+	SnapshotAt *time.Time
+}
@@ -0,0 +1,90 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrThresholdNotFound = errors.New("balance threshold not found")
+)
+
+type BalanceThresholdRepo interface {
+	Create(ctx context.Context, threshold *BalanceThreshold) error
+	FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*BalanceThreshold, error)
+	Delete(ctx context.Context, walletID, ID uuid.UUID) error
+
+	// SetArmed only updates thresholds that are not in the given state yet, it reports whether a row changed
+	// so concurrent debits can not alert twice for the same crossing
+	SetArmed(ctx context.Context, ID uuid.UUID, armed bool) (bool, error)
+	WithTx(tx *gorm.DB) BalanceThresholdRepo
+}
+
+// BalanceThreshold alerts once when the balance drops below Amount and is armed again
+// only after the balance climbs back to Amount + Hysteresis
+type BalanceThreshold struct {
+	ID         uuid.UUID
+	WalletID   uuid.UUID
+	Amount     valueobjects.Money
+	Hysteresis valueobjects.Money
+	Armed      bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewBalanceThreshold(wallet *Wallet, amount, hysteresis valueobjects.Money) (*BalanceThreshold, error) {
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if amount.IsZero() || amount.IsNegative() || hysteresis.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
+
+	if amount.Currency() != wallet.Currency || hysteresis.Currency() != wallet.Currency {
+		return nil, valueobjects.ErrCurrencyMismatch
+	}
+
+	// a wallet that is already below the threshold alerts only after it recovers and drops again
+	armed, err := wallet.Balance.GreaterThanOrEqual(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BalanceThreshold{
+		ID:         uuid.New(),
+		WalletID:   wallet.ID,
+		Amount:     amount,
+		Hysteresis: hysteresis,
+		Armed:      armed,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}, nil
+}
+
+// Crossed reports whether the balance fell below an armed threshold
+func (t *BalanceThreshold) Crossed(balance valueobjects.Money) bool {
+	if !t.Armed {
+		return false
+	}
+	above, err := balance.GreaterThanOrEqual(t.Amount)
+	return err == nil && !above
+}
+
+// Recovered reports whether a disarmed threshold should be armed again
+func (t *BalanceThreshold) Recovered(balance valueobjects.Money) bool {
+	if t.Armed {
+		return false
+	}
+	rearmAt, err := t.Amount.Add(t.Hysteresis)
+	if err != nil {
+		return false
+	}
+	recovered, err := balance.GreaterThanOrEqual(rearmAt)
+	return err == nil && recovered
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidExportFormat = errors.New("invalid export format, expected csv or jsonl")
+	ErrUnknownExportColumn = errors.New("unknown export column")
+	ErrInvalidExportPeriod = errors.New("invalid export period")
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+func (f ExportFormat) Valid() bool {
+	return f == ExportCSV || f == ExportJSONL
+}
+
+// TransactionFilter selects the transactions created in [From, To), a nil WalletID selects every wallet
+type TransactionFilter struct {
+	WalletID *uuid.UUID
+	From     time.Time
+	To       time.Time
+}
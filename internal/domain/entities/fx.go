@@ -0,0 +1,95 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFXRateNotFound = errors.New("no fx rate for the currency pair")
+	ErrInvalidFXRate  = errors.New("invalid fx rate")
+)
+
+type FXRateSource string
+
+const (
+	FXSourceManual FXRateSource = "manual"
+	FXSourceFeed   FXRateSource = "feed"
+)
+
+type FXRateRepo interface {
+	Create(ctx context.Context, rate *FXRate) error
+	// FindValid returns the newest rate of the pair whose validity window covers at
+	FindValid(ctx context.Context, base, quote string, at time.Time) (*FXRate, error)
+	List(ctx context.Context, base, quote string) ([]*FXRate, error)
+	WithTx(tx *gorm.DB) FXRateRepo
+}
+
+// FXRate says one unit of Base is worth Rate units of Quote between ValidFrom and ValidUntil,
+// a nil ValidUntil keeps the rate valid until a newer one starts
+type FXRate struct {
+	ID         uuid.UUID
+	Base       string
+	Quote      string
+	Rate       *big.Rat
+	ValidFrom  time.Time
+	ValidUntil *time.Time
+	Source     FXRateSource
+	CreatedAt  time.Time
+}
+
+// NewFXRate parses rate as a decimal or a fraction, a zero validFrom starts the rate now
+func NewFXRate(base, quote, rate string, validFrom time.Time, validUntil *time.Time, source FXRateSource) (*FXRate, error) {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	if len(base) != 3 || len(quote) != 3 || base == quote {
+		return nil, ErrInvalidFXRate
+	}
+
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidFXRate
+	}
+
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	if validUntil != nil && !validUntil.After(validFrom) {
+		return nil, ErrInvalidFXRate
+	}
+
+	return &FXRate{
+		ID:         uuid.New(),
+		Base:       base,
+		Quote:      quote,
+		Rate:       r,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+		Source:     source,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Inverse is the same rate read from Quote to Base
+func (r *FXRate) Inverse() *FXRate {
+	inverse := *r
+	inverse.Base, inverse.Quote = r.Quote, r.Base
+	inverse.Rate = new(big.Rat).Inv(r.Rate)
+	return &inverse
+}
+
+// Convert turns money in Base into Quote, rounding down to the smallest currency unit
+func (r *FXRate) Convert(money valueobjects.Money) (valueobjects.Money, error) {
+	if money.Currency() != r.Base {
+		return valueobjects.Money{}, valueobjects.ErrCurrencyMismatch
+	}
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt(money.Amount()), r.Rate)
+	amount := new(big.Int).Quo(converted.Num(), converted.Denom())
+	return valueobjects.NewMoney(amount, r.Quote)
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidIdempotencyKey    = errors.New("invalid idempotency key")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+)
+
+const (
+	// IdempotencyTTL is how long a stored response is replayed for its key
+	IdempotencyTTL = 24 * time.Hour
+	// IdempotencyLease is how long a claimed key waits for its request to store a response,
+	// after it another request with the key may take the claim over
+	IdempotencyLease = time.Minute
+	// MaxIdempotencyKeyLength bounds the Idempotency-Key header
+	MaxIdempotencyKeyLength = 255
+)
+
+type IdempotencyRepo interface {
+	// Acquire inserts the record's key, route and caller when they are new and locks the row until
+	// the db transaction ends. It returns the stored record, which has no response yet when the key is new
+	Acquire(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, error)
+	// Save stores the request hash, claim and response of an acquired record
+	Save(ctx context.Context, record *IdempotencyRecord) error
+	// Release removes a claimed record that stored no response, a retry with the key runs again
+	Release(ctx context.Context, record *IdempotencyRecord) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+	WithTx(tx *gorm.DB) IdempotencyRepo
+}
+
+// IdempotencyRecord is the response to a request sent with an Idempotency-Key, the key is
+// scoped to the route and the caller so two callers can not see each other's responses
+type IdempotencyRecord struct {
+	ID          uuid.UUID
+	Key         string
+	Route       string
+	Caller      string
+	RequestHash string
+	Response    *IdempotentResponse
+	// ClaimedUntil is when the claim of the request running for the key lapses
+	ClaimedUntil time.Time
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func NewIdempotencyRecord(key, route, caller, requestHash string) (*IdempotencyRecord, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, ErrInvalidIdempotencyKey
+	}
+	return &IdempotencyRecord{
+		ID:          uuid.New(),
+		Key:         key,
+		Route:       route,
+		Caller:      caller,
+		RequestHash: requestHash,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// Replayable tells if the record holds a response that is still valid at the given time
+func (r *IdempotencyRecord) Replayable(at time.Time) bool {
+	return r.Response != nil && at.Before(r.ExpiresAt)
+}
+
+// InProgress tells if a request claimed the key and has not stored its response yet
+func (r *IdempotencyRecord) InProgress(at time.Time) bool {
+	return r.Response == nil && at.Before(r.ClaimedUntil)
+}
+
+// Claim takes the key for the request with the given hash, an expired response is dropped
+func (r *IdempotencyRecord) Claim(requestHash string, at time.Time) {
+	r.RequestHash = requestHash
+	r.Response = nil
+	r.ClaimedUntil = at.Add(IdempotencyLease)
+}
+
+// Complete stores the response of the request with the given hash
+func (r *IdempotencyRecord) Complete(requestHash string, response *IdempotentResponse, at time.Time) {
+	r.RequestHash = requestHash
+	r.Response = response
+	r.ExpiresAt = at.Add(IdempotencyTTL)
+}
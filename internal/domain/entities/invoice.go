@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound      = errors.New("invoice not found")
+	ErrPeriodNotClosed      = errors.New("invoice period has not ended yet")
+	ErrInvalidInvoicePeriod = errors.New("invalid invoice period")
+)
+
+type InvoiceRepo interface {
+	// Create stores the invoice with its lines, invoices are never updated afterwards
+	Create(ctx context.Context, invoice *Invoice) error
+	FindByID(ctx context.Context, ID uuid.UUID) (*Invoice, error)
+	FindByPeriod(ctx context.Context, walletID uuid.UUID, periodStart time.Time) (*Invoice, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*Invoice, error)
+	// NextSequence hands out invoice numbers without gaps, the number is taken back if the db transaction rolls back
+	NextSequence(ctx context.Context) (int64, error)
+	WithTx(tx *gorm.DB) InvoiceRepo
+}
+
+// Invoice is the statement of a wallet for [PeriodStart, PeriodEnd), amounts are in Currency
+type Invoice struct {
+	ID             uuid.UUID
+	Number         string
+	Sequence       int64
+	UserID         uuid.UUID
+	WalletID       uuid.UUID
+	Currency       string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	OpeningBalance *big.Int
+	Credits        *big.Int
+	Refunds        *big.Int
+	Debits         *big.Int
+	// Tax is the tax charged in the period, less what refunds gave back
+	Tax            *big.Int
+	ClosingBalance *big.Int
+	Lines          []InvoiceLine
+	CreatedAt      time.Time
+}
+
+// InvoiceLine sums the completed transactions of one day with the same type, category and sms type
+type InvoiceLine struct {
+	Day         time.Time
+	Type        TransactionType
+	Category    TransactionCategory
+	MessageType MessageType
+	Count       int64
+	Segments    int64
+	Amount      *big.Int
+	Tax         *big.Int
+}
+
+// NewInvoice builds the statement of the period from the balance at its start and its transaction lines
+func NewInvoice(wallet *Wallet, periodStart, periodEnd time.Time, opening *big.Int, lines []InvoiceLine) (*Invoice, error) {
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+	if !periodEnd.After(periodStart) {
+		return nil, ErrInvalidInvoicePeriod
+	}
+
+	invoice := &Invoice{
+		ID:             uuid.New(),
+		UserID:         wallet.UserID,
+		WalletID:       wallet.ID,
+		Currency:       wallet.Currency,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: new(big.Int).Set(opening),
+		Credits:        new(big.Int),
+		Refunds:        new(big.Int),
+		Debits:         new(big.Int),
+		Tax:            new(big.Int),
+		ClosingBalance: new(big.Int).Set(opening),
+		Lines:          lines,
+		CreatedAt:      time.Now(),
+	}
+
+	for _, line := range lines {
+		switch {
+		case line.Type == TransactionDebit:
+			invoice.Debits.Add(invoice.Debits, line.Amount)
+			invoice.ClosingBalance.Sub(invoice.ClosingBalance, line.Amount)
+			invoice.Tax.Add(invoice.Tax, line.Tax)
+		case line.Category == CategoryRefund:
+			invoice.Refunds.Add(invoice.Refunds, line.Amount)
+			invoice.ClosingBalance.Add(invoice.ClosingBalance, line.Amount)
+			invoice.Tax.Sub(invoice.Tax, line.Tax)
+		default:
+			invoice.Credits.Add(invoice.Credits, line.Amount)
+			invoice.ClosingBalance.Add(invoice.ClosingBalance, line.Amount)
+			invoice.Tax.Add(invoice.Tax, line.Tax)
+		}
+	}
+	return invoice, nil
+}
+
+// AssignNumber gives the invoice its place in the invoice sequence
+func (i *Invoice) AssignNumber(sequence int64) {
+	i.Sequence = sequence
+	i.Number = fmt.Sprintf("INV-%08d", sequence)
+}
+
+// MonthPeriod returns the calendar month containing t
+func MonthPeriod(t time.Time) (time.Time, time.Time) {
+	start := StartOfMonth(t)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,141 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPlanNotFound    = errors.New("plan not found")
+	ErrPlanNotAssigned = errors.New("user has no plan")
+	ErrPlanInUse       = errors.New("plan is assigned to users")
+	ErrInvalidPlan     = errors.New("invalid plan")
+)
+
+// MaxDiscountBPS is a 100% discount in basis points, a tier must discount less than that
+const MaxDiscountBPS = 10000
+
+type PlanRepo interface {
+	Create(ctx context.Context, plan *Plan) error
+	// Update replaces the name and the tiers of the plan
+	Update(ctx context.Context, plan *Plan) error
+	Delete(ctx context.Context, ID uuid.UUID) error
+	FindByID(ctx context.Context, ID uuid.UUID) (*Plan, error)
+	List(ctx context.Context) ([]*Plan, error)
+	CountActiveAssignments(ctx context.Context, planID uuid.UUID) (int64, error)
+
+	// Assign ends the user's current assignment and starts the given one
+	Assign(ctx context.Context, assignment *PlanAssignment) error
+	FindActivePlan(ctx context.Context, userID uuid.UUID) (*Plan, error)
+	ListAssignments(ctx context.Context, userID uuid.UUID) ([]*PlanAssignment, error)
+	WithTx(tx *gorm.DB) PlanRepo
+}
+
+// Plan is a customer pricing agreement, Code selects the plan rates of the tariff
+// and Tiers discount them once the monthly volume grows
+type Plan struct {
+	ID        uuid.UUID
+	Code      string
+	Name      string
+	Tiers     []PlanTier
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PlanTier covers the sms of the month up to UpTo, counted from the start of the month,
+// zero UpTo means no upper bound
+type PlanTier struct {
+	UpTo        int64
+	DiscountBPS int64
+}
+
+// PlanAssignment is one entry of a user's plan history, EndedAt is nil for the current plan
+type PlanAssignment struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	PlanID    uuid.UUID
+	PlanCode  string
+	StartedAt time.Time
+	EndedAt   *time.Time
+}
+
+func NewPlan(code, name string, tiers []PlanTier) (*Plan, error) {
+	if code == "" {
+		return nil, ErrInvalidPlan
+	}
+
+	plan := &Plan{
+		ID:        uuid.New(),
+		Code:      code,
+		CreatedAt: time.Now(),
+	}
+	if err := plan.Update(name, tiers); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Update expects tiers in ascending UpTo order, only the last one may be unbounded
+func (p *Plan) Update(name string, tiers []PlanTier) error {
+	var previous int64
+	for i, tier := range tiers {
+		if tier.DiscountBPS < 0 || tier.DiscountBPS >= MaxDiscountBPS || tier.UpTo < 0 {
+			return ErrInvalidPlan
+		}
+		if tier.UpTo == 0 && i != len(tiers)-1 {
+			return ErrInvalidPlan
+		}
+		if tier.UpTo != 0 && tier.UpTo <= previous {
+			return ErrInvalidPlan
+		}
+		previous = tier.UpTo
+	}
+
+	p.Name = name
+	p.Tiers = tiers
+	p.UpdatedAt = time.Now()
+	return nil
+}
+
+// TierFor returns the tier of the n-th sms of the month, past the last tier the last one keeps applying
+func (p *Plan) TierFor(n int64) PlanTier {
+	if len(p.Tiers) == 0 {
+		return PlanTier{}
+	}
+	for _, tier := range p.Tiers {
+		if tier.UpTo == 0 || n <= tier.UpTo {
+			return tier
+		}
+	}
+	return p.Tiers[len(p.Tiers)-1]
+}
+
+// Apply discounts the price, rounding down to the smallest currency unit. A priced sms is never
+// discounted to nothing, at least one unit is charged
+func (t PlanTier) Apply(price valueobjects.Money) (valueobjects.Money, error) {
+	if t.DiscountBPS == 0 {
+		return price, nil
+	}
+	amount := new(big.Int).Mul(price.Amount(), big.NewInt(MaxDiscountBPS-t.DiscountBPS))
+	amount.Quo(amount, big.NewInt(MaxDiscountBPS))
+	if amount.Sign() == 0 && price.Amount().Sign() > 0 {
+		amount.SetInt64(1)
+	}
+	return valueobjects.NewMoney(amount, price.Currency())
+}
+
+func NewPlanAssignment(userID uuid.UUID, plan *Plan) *PlanAssignment {
+	return &PlanAssignment{
+		ID:        uuid.New(),
+		UserID:    userID,
+		PlanID:    plan.ID,
+		PlanCode:  plan.Code,
+		StartedAt: time.Now(),
+	}
+}
@@ -0,0 +1,133 @@
+package entities
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+// RateLimit is a token bucket refilled at RequestsPerMinute that holds at most Burst tokens
+type RateLimit struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+func (l RateLimit) Unlimited() bool {
+	return l.RequestsPerMinute <= 0
+}
+
+func (l RateLimit) size() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.RequestsPerMinute)
+}
+
+func (l RateLimit) perSecond() float64 {
+	return float64(l.RequestsPerMinute) / 60
+}
+
+// RateLimitRule limits the requests it matches, empty fields match anything. Path is matched
+// against the route a request was routed to, like /api/v1/wallet/user/:user_id, not its url
+type RateLimitRule struct {
+	Client string
+	Method string
+	Path   string
+	Limit  RateLimit
+}
+
+func (r RateLimitRule) Matches(client, method, route string) bool {
+	if r.Client != "" && r.Client != client {
+		return false
+	}
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	return r.Path == "" || matchRoute(r.Path, route)
+}
+
+// Specificity orders matching rules, a rule naming more of client, method and path wins
+func (r RateLimitRule) Specificity() int {
+	specificity := 0
+	for _, field := range []string{r.Client, r.Method, r.Path} {
+		if field != "" {
+			specificity++
+		}
+	}
+	return specificity
+}
+
+// matchRoute compares two route templates, a parameter only matches a parameter whatever its
+// name, so /invoices/:invoice_id does not match /invoices/generate
+func matchRoute(pattern, route string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	routeSegments := strings.Split(strings.Trim(route, "/"), "/")
+	if len(patternSegments) != len(routeSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if strings.HasPrefix(segment, ":") && strings.HasPrefix(routeSegments[i], ":") {
+			continue
+		}
+		if segment != routeSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RateLimitStore keeps the buckets, Take removes a token from the key's bucket if it has one
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token when the request was not allowed
+	RetryAfter time.Duration
+}
+
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Full tells if the bucket has refilled by now, it is then no different from a new one
+func (b *TokenBucket) Full(limit RateLimit, now time.Time) bool {
+	return b.Tokens+now.Sub(b.UpdatedAt).Seconds()*limit.perSecond() >= limit.size()
+}
+
+// NewTokenBucket starts full
+func NewTokenBucket(limit RateLimit, now time.Time) *TokenBucket {
+	return &TokenBucket{Tokens: limit.size(), UpdatedAt: now}
+}
+
+// Take refills the bucket for the time since its last use and takes a token if there is one
+func (b *TokenBucket) Take(limit RateLimit, now time.Time) RateLimitResult {
+	rate := limit.perSecond()
+	size := limit.size()
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(size, b.Tokens+elapsed*rate)
+		b.UpdatedAt = now
+	}
+
+	result := RateLimitResult{Limit: int(size)}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	result.Remaining = int(b.Tokens)
+	result.Reset = seconds((size - b.Tokens) / rate)
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,51 @@
+package entities
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconcileBatchSize is how many wallet ids reconciliation and snapshot runs read at a time
+const ReconcileBatchSize = 500
+
+// BalanceDiscrepancy is a wallet whose stored balance differs from the sum of its completed transactions,
+// Difference is stored minus computed
+type BalanceDiscrepancy struct {
+	WalletID   uuid.UUID `json:"wallet_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Currency   string    `json:"currency"`
+	Stored     *big.Int  `json:"stored"`
+	Computed   *big.Int  `json:"computed"`
+	Difference *big.Int  `json:"difference"`
+	Repaired   bool      `json:"repaired"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// ReconcileReport is the outcome of one reconciliation run, Failed counts wallets that could not be checked
+type ReconcileReport struct {
+	Repair        bool                  `json:"repair"`
+	Checked       int                   `json:"checked"`
+	Failed        int                   `json:"failed"`
+	Discrepancies []*BalanceDiscrepancy `json:"discrepancies"`
+	StartedAt     time.Time             `json:"started_at"`
+	FinishedAt    time.Time             `json:"finished_at"`
+}
+
+// CheckBalance compares the stored balance of the wallet with the computed one, nil means they match
+func CheckBalance(wallet *Wallet, computed *big.Int) *BalanceDiscrepancy {
+	stored := wallet.Balance.Amount()
+	if stored.Cmp(computed) == 0 {
+		return nil
+	}
+	return &BalanceDiscrepancy{
+		WalletID:   wallet.ID,
+		UserID:     wallet.UserID,
+		Currency:   wallet.Currency,
+		Stored:     stored,
+		Computed:   new(big.Int).Set(computed),
+		Difference: new(big.Int).Sub(stored, computed),
+		DetectedAt: time.Now(),
+	}
+}
@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrLimitExceeded = errors.New("spending limit exceeded")
+	ErrInvalidLimit  = errors.New("limit must be positive")
+)
+
+type LimitKind string
+
+const (
+	LimitMaxDebit        LimitKind = "max_debit"
+	LimitDailyAmount     LimitKind = "daily_amount"
+	LimitMonthlyAmount   LimitKind = "monthly_amount"
+	LimitDebitsPerMinute LimitKind = "debits_per_minute"
+)
+
+// LimitExceededError tells which limit rejected a debit, it matches ErrLimitExceeded with errors.Is
+type LimitExceededError struct {
+	Kind      LimitKind
+	Limit     *big.Int
+	Attempted *big.Int
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s limit is %s, attempted %s", ErrLimitExceeded, e.Kind, e.Limit, e.Attempted)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// SpendingLimits are per wallet debit controls, a nil amount or a zero count means no limit
+type SpendingLimits struct {
+	MaxDebit        *big.Int
+	DailyAmount     *big.Int
+	MonthlyAmount   *big.Int
+	DebitsPerMinute int64
+}
+
+// SpendingUsage is what the wallet already spent in each limit window
+type SpendingUsage struct {
+	DailyAmount      *big.Int
+	MonthlyAmount    *big.Int
+	DebitsLastMinute int64
+}
+
+func (l SpendingLimits) Validate() error {
+	for _, v := range []*big.Int{l.MaxDebit, l.DailyAmount, l.MonthlyAmount} {
+		if v != nil && v.Sign() <= 0 {
+			return ErrInvalidLimit
+		}
+	}
+	if l.DebitsPerMinute < 0 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+func (l SpendingLimits) IsZero() bool {
+	return l.MaxDebit == nil && l.DailyAmount == nil && l.MonthlyAmount == nil && l.DebitsPerMinute == 0
+}
+
+// Check returns a LimitExceededError for the first limit the debit would break
+func (l SpendingLimits) Check(amount valueobjects.Money, usage SpendingUsage) error {
+	value := amount.Amount()
+
+	if l.MaxDebit != nil && value.Cmp(l.MaxDebit) > 0 {
+		return &LimitExceededError{Kind: LimitMaxDebit, Limit: l.MaxDebit, Attempted: value}
+	}
+
+	if l.DailyAmount != nil {
+		total := new(big.Int).Add(orZero(usage.DailyAmount), value)
+		if total.Cmp(l.DailyAmount) > 0 {
+			return &LimitExceededError{Kind: LimitDailyAmount, Limit: l.DailyAmount, Attempted: total}
+		}
+	}
+
+	if l.MonthlyAmount != nil {
+		total := new(big.Int).Add(orZero(usage.MonthlyAmount), value)
+		if total.Cmp(l.MonthlyAmount) > 0 {
+			return &LimitExceededError{Kind: LimitMonthlyAmount, Limit: l.MonthlyAmount, Attempted: total}
+		}
+	}
+
+	if l.DebitsPerMinute > 0 && usage.DebitsLastMinute+1 > l.DebitsPerMinute {
+		return &LimitExceededError{
+			Kind:      LimitDebitsPerMinute,
+			Limit:     big.NewInt(l.DebitsPerMinute),
+			Attempted: big.NewInt(usage.DebitsLastMinute + 1),
+		}
+	}
+
+	return nil
+}
+
+// StartOfDay and StartOfMonth bound the daily and monthly limit windows in the local timezone
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func StartOfMonth(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return v
+}
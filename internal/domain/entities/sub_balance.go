@@ -0,0 +1,111 @@
+package entities
+
+import (
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSubBalanceNotFound = errors.New("wallet has no balance in this currency")
+	ErrSubBalanceExists   = errors.New("wallet already has a balance in this currency")
+)
+
+// SubBalance holds money in a currency other than the wallet's, it is kept apart from Balance
+// until it is converted
+type SubBalance struct {
+	ID        uuid.UUID
+	WalletID  uuid.UUID
+	Currency  string
+	Balance   valueobjects.Money
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (w *Wallet) AddSubBalance(currency string) (*SubBalance, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if currency == w.Currency {
+		return nil, ErrSubBalanceExists
+	}
+	if w.SubBalance(currency) != nil {
+		return nil, ErrSubBalanceExists
+	}
+
+	zero, err := valueobjects.NewMoney(big.NewInt(0), currency)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &SubBalance{
+		ID:        uuid.New(),
+		WalletID:  w.ID,
+		Currency:  currency,
+		Balance:   zero,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	w.SubBalances = append(w.SubBalances, sub)
+	return sub, nil
+}
+
+func (w *Wallet) SubBalance(currency string) *SubBalance {
+	for _, sub := range w.SubBalances {
+		if sub.Currency == currency {
+			return sub
+		}
+	}
+	return nil
+}
+
+// CreditInCurrency credits Balance, or the sub-balance when the amount is in its currency
+func (w *Wallet) CreditInCurrency(amount valueobjects.Money) error {
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	sub := w.SubBalance(amount.Currency())
+	if sub == nil {
+		return w.Credit(amount)
+	}
+
+	if amount.IsZero() || amount.IsNegative() {
+		return ErrInvalidAmount
+	}
+	balance, err := sub.Balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	sub.Balance = balance
+	sub.UpdatedAt = time.Now()
+	w.UpdatedAt = sub.UpdatedAt
+	return nil
+}
+
+func (w *Wallet) DebitSubBalance(amount valueobjects.Money) error {
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	sub := w.SubBalance(amount.Currency())
+	if sub == nil {
+		return ErrSubBalanceNotFound
+	}
+
+	if amount.IsZero() || amount.IsNegative() {
+		return ErrInvalidAmount
+	}
+	balance, err := sub.Balance.Subtract(amount)
+	if err != nil {
+		return ErrInsufficientBalance
+	}
+	sub.Balance = balance
+	sub.UpdatedAt = time.Now()
+	w.UpdatedAt = sub.UpdatedAt
+	return nil
+}
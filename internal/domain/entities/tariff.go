@@ -0,0 +1,178 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTariffNotFound     = errors.New("tariff not found")
+	ErrNoMatchingRate     = errors.New("no tariff rate matches the sms")
+	ErrInvalidTariff      = errors.New("invalid tariff")
+	ErrInvalidSMSMetadata = errors.New("invalid sms metadata")
+)
+
+type MessageType string
+
+const (
+	MessageNormal  MessageType = "normal"
+	MessageFlash   MessageType = "flash"
+	MessageUnicode MessageType = "unicode"
+)
+
+const (
+	EncodingGSM7 = "gsm7"
+	EncodingUCS2 = "ucs2"
+)
+
+// DefaultPlan matches tariff rates that are not bound to a customer plan
+const DefaultPlan = ""
+
+type TariffRepo interface {
+	// Create assigns the next version to the tariff
+	Create(ctx context.Context, tariff *Tariff) error
+	FindByVersion(ctx context.Context, version int64) (*Tariff, error)
+	FindActive(ctx context.Context, currency string) (*Tariff, error)
+	List(ctx context.Context) ([]*Tariff, error)
+	// Activate deactivates the other tariffs of the same currency
+	Activate(ctx context.Context, tariff *Tariff) error
+	WithTx(tx *gorm.DB) TariffRepo
+}
+
+// SMSMetadata is what the sms service knows about a message, the price is computed from it
+type SMSMetadata struct {
+	Receiver    string
+	Segments    int64
+	Encoding    string
+	MessageType MessageType
+	// Operator is the network the message is delivered through, empty when the sms service does not know it
+	Operator string
+}
+
+func (m SMSMetadata) Validate() error {
+	if m.Segments <= 0 || normalizeReceiver(m.Receiver) == "" {
+		return ErrInvalidSMSMetadata
+	}
+	switch m.Type() {
+	case MessageNormal, MessageFlash, MessageUnicode:
+		return nil
+	default:
+		return ErrInvalidSMSMetadata
+	}
+}
+
+// Type falls back to the encoding when the sms service did not send a message type
+func (m SMSMetadata) Type() MessageType {
+	if m.MessageType != "" {
+		return m.MessageType
+	}
+	if strings.EqualFold(m.Encoding, EncodingUCS2) {
+		return MessageUnicode
+	}
+	return MessageNormal
+}
+
+// Tariff is an immutable price list, changing prices means creating and activating a new version
+type Tariff struct {
+	ID        uuid.UUID
+	Version   int64
+	Name      string
+	Currency  string
+	Active    bool
+	Rates     []TariffRate
+	CreatedAt time.Time
+}
+
+type TariffRate struct {
+	// Prefix is matched against the receiver without + or leading 00, e.g. 98912
+	Prefix string
+	// Operator only matches sms delivered through that operator, empty matches any
+	Operator        string
+	MessageType     MessageType
+	Plan            string
+	PricePerSegment *big.Int
+}
+
+func NewTariff(name, currency string, rates []TariffRate) (*Tariff, error) {
+	if currency == "" || len(rates) == 0 {
+		return nil, ErrInvalidTariff
+	}
+
+	for _, rate := range rates {
+		if rate.PricePerSegment == nil || rate.PricePerSegment.Sign() <= 0 {
+			return nil, ErrInvalidTariff
+		}
+		switch rate.MessageType {
+		case MessageNormal, MessageFlash, MessageUnicode:
+		default:
+			return nil, ErrInvalidTariff
+		}
+	}
+
+	return &Tariff{
+		ID:        uuid.New(),
+		Name:      name,
+		Currency:  strings.ToUpper(currency),
+		Rates:     rates,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Price prefers a rate of the sms operator over one for any operator, then the longest matching prefix,
+// then a rate of the customer's plan over a generic one
+func (t *Tariff) Price(sms SMSMetadata, plan string) (valueobjects.Money, error) {
+	if err := sms.Validate(); err != nil {
+		return valueobjects.Money{}, err
+	}
+
+	receiver := normalizeReceiver(sms.Receiver)
+	msgType := sms.Type()
+
+	var best *TariffRate
+	for i := range t.Rates {
+		rate := &t.Rates[i]
+		if rate.MessageType != msgType || !strings.HasPrefix(receiver, rate.Prefix) {
+			continue
+		}
+		if rate.Plan != DefaultPlan && rate.Plan != plan {
+			continue
+		}
+		if rate.Operator != "" && !strings.EqualFold(rate.Operator, sms.Operator) {
+			continue
+		}
+		if best == nil || betterRate(rate, best) {
+			best = rate
+		}
+	}
+
+	if best == nil {
+		return valueobjects.Money{}, ErrNoMatchingRate
+	}
+
+	total := new(big.Int).Mul(best.PricePerSegment, big.NewInt(sms.Segments))
+	return valueobjects.NewMoney(total, t.Currency)
+}
+
+func betterRate(candidate, current *TariffRate) bool {
+	if (candidate.Operator == "") != (current.Operator == "") {
+		return candidate.Operator != ""
+	}
+	if len(candidate.Prefix) != len(current.Prefix) {
+		return len(candidate.Prefix) > len(current.Prefix)
+	}
+	return candidate.Plan != DefaultPlan && current.Plan == DefaultPlan
+}
+
+func normalizeReceiver(receiver string) string {
+	r := strings.TrimSpace(receiver)
+	r = strings.TrimPrefix(r, "+")
+	r = strings.TrimPrefix(r, "00")
+	return r
+}
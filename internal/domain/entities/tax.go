@@ -0,0 +1,242 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTaxRule      = errors.New("invalid tax rule")
+	ErrInvalidCustomerType = errors.New("invalid customer type")
+	ErrTaxRuleNotFound     = errors.New("tax rule not found")
+	ErrInvalidReportPeriod = errors.New("invalid report period")
+)
+
+// MaxTaxBPS is a 100% tax rate in basis points
+const MaxTaxBPS = 10000
+
+type CustomerType string
+
+const (
+	CustomerIndividual CustomerType = "individual"
+	CustomerBusiness   CustomerType = "business"
+)
+
+func (c CustomerType) Valid() bool {
+	return c == CustomerIndividual || c == CustomerBusiness
+}
+
+// TaxBase tells which side of the ledger a tax rule applies to
+type TaxBase string
+
+const (
+	TaxOnDebit  TaxBase = "debit"
+	TaxOnCredit TaxBase = "credit"
+)
+
+type TaxRuleRepo interface {
+	Create(ctx context.Context, rule *TaxRule) error
+	List(ctx context.Context) ([]*TaxRule, error)
+	// FindValid returns the rules of the base whose validity window covers at, for every customer type
+	FindValid(ctx context.Context, base TaxBase, at time.Time) ([]*TaxRule, error)
+	WithTx(tx *gorm.DB) TaxRuleRepo
+}
+
+// TaxRule is a tax rate for one customer type, an empty CustomerType covers customers without
+// a rule of their own. Inclusive rules take the tax out of the amount, exclusive rules add it on top
+type TaxRule struct {
+	ID           uuid.UUID
+	Name         string
+	CustomerType CustomerType
+	Base         TaxBase
+	RateBPS      int64
+	Inclusive    bool
+	ValidFrom    time.Time
+	ValidUntil   *time.Time
+	CreatedAt    time.Time
+}
+
+// TaxBreakdown is the tax a rule puts on an amount, Gross is always Net plus Tax
+type TaxBreakdown struct {
+	RuleID  uuid.UUID
+	RateBPS int64
+	Net     valueobjects.Money
+	Tax     valueobjects.Money
+	Gross   valueobjects.Money
+}
+
+// TaxTotal sums the taxed transactions of one type, category and currency
+type TaxTotal struct {
+	Type     TransactionType
+	Category TransactionCategory
+	Currency string
+	Count    int64
+	Net      *big.Int
+	Tax      *big.Int
+	Gross    *big.Int
+}
+
+// TaxReport is what taxed transactions of a period add up to
+type TaxReport struct {
+	From   time.Time
+	To     time.Time
+	Totals []TaxTotal
+	Due    map[string]*big.Int
+}
+
+func NewTaxReport(from, to time.Time, totals []TaxTotal) *TaxReport {
+	return &TaxReport{From: from, To: to, Totals: totals, Due: TaxDue(totals)}
+}
+
+func NewTaxRule(name string, customerType CustomerType, base TaxBase, rateBPS int64, inclusive bool, validFrom time.Time, validUntil *time.Time) (*TaxRule, error) {
+	if customerType != "" && !customerType.Valid() {
+		return nil, ErrInvalidCustomerType
+	}
+	if base != TaxOnDebit && base != TaxOnCredit {
+		return nil, ErrInvalidTaxRule
+	}
+	if rateBPS < 0 || rateBPS > MaxTaxBPS {
+		return nil, ErrInvalidTaxRule
+	}
+	if validFrom.IsZero() {
+		validFrom = time.Now()
+	}
+	if validUntil != nil && !validUntil.After(validFrom) {
+		return nil, ErrInvalidTaxRule
+	}
+
+	return &TaxRule{
+		ID:           uuid.New(),
+		Name:         name,
+		CustomerType: customerType,
+		Base:         base,
+		RateBPS:      rateBPS,
+		Inclusive:    inclusive,
+		ValidFrom:    validFrom,
+		ValidUntil:   validUntil,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// SelectTaxRule prefers the rule of the customer type over the catch-all one, and the newest
+// of those, nil means the customer is not taxed
+func SelectTaxRule(rules []*TaxRule, customerType CustomerType) *TaxRule {
+	var selected *TaxRule
+	for _, rule := range rules {
+		if rule.CustomerType != "" && rule.CustomerType != customerType {
+			continue
+		}
+		if selected == nil {
+			selected = rule
+			continue
+		}
+		moreSpecific := rule.CustomerType != "" && selected.CustomerType == ""
+		sameKind := rule.CustomerType == selected.CustomerType
+		if moreSpecific || (sameKind && rule.ValidFrom.After(selected.ValidFrom)) {
+			selected = rule
+		}
+	}
+	return selected
+}
+
+// Apply splits amount into net and tax, the tax is rounded half up to the smallest currency unit
+func (r *TaxRule) Apply(amount valueobjects.Money) (TaxBreakdown, error) {
+	bps := big.NewInt(r.RateBPS)
+	scale := big.NewInt(MaxTaxBPS)
+
+	var net, tax, gross *big.Int
+	if r.Inclusive {
+		gross = amount.Amount()
+		tax = divRound(new(big.Int).Mul(gross, bps), new(big.Int).Add(scale, bps))
+		net = new(big.Int).Sub(gross, tax)
+	} else {
+		net = amount.Amount()
+		tax = divRound(new(big.Int).Mul(net, bps), scale)
+		gross = new(big.Int).Add(net, tax)
+	}
+
+	breakdown := TaxBreakdown{RuleID: r.ID, RateBPS: r.RateBPS}
+	var err error
+	if breakdown.Net, err = valueobjects.NewMoney(net, amount.Currency()); err != nil {
+		return TaxBreakdown{}, err
+	}
+	if breakdown.Tax, err = valueobjects.NewMoney(tax, amount.Currency()); err != nil {
+		return TaxBreakdown{}, err
+	}
+	if breakdown.Gross, err = valueobjects.NewMoney(gross, amount.Currency()); err != nil {
+		return TaxBreakdown{}, err
+	}
+	return breakdown, nil
+}
+
+// Split divides the breakdown between two payers, the first gets the share of the tax that part
+// of the gross covers and the second the rest, so the two shares add up to the whole tax
+func (b TaxBreakdown) Split(part valueobjects.Money) (TaxBreakdown, TaxBreakdown, error) {
+	gross := b.Gross.Amount()
+	if part.Amount().Cmp(gross) > 0 {
+		return TaxBreakdown{}, TaxBreakdown{}, ErrInvalidAmount
+	}
+
+	tax := new(big.Int)
+	if gross.Sign() > 0 {
+		tax.Mul(b.Tax.Amount(), part.Amount())
+		tax.Quo(tax, gross)
+	}
+	first, err := b.share(part.Amount(), tax)
+	if err != nil {
+		return TaxBreakdown{}, TaxBreakdown{}, err
+	}
+	second, err := b.share(new(big.Int).Sub(gross, part.Amount()), new(big.Int).Sub(b.Tax.Amount(), tax))
+	if err != nil {
+		return TaxBreakdown{}, TaxBreakdown{}, err
+	}
+	return first, second, nil
+}
+
+func (b TaxBreakdown) share(gross, tax *big.Int) (TaxBreakdown, error) {
+	currency := b.Gross.Currency()
+	share := TaxBreakdown{RuleID: b.RuleID, RateBPS: b.RateBPS}
+	var err error
+	if share.Net, err = valueobjects.NewMoney(new(big.Int).Sub(gross, tax), currency); err != nil {
+		return TaxBreakdown{}, err
+	}
+	if share.Tax, err = valueobjects.NewMoney(tax, currency); err != nil {
+		return TaxBreakdown{}, err
+	}
+	if share.Gross, err = valueobjects.NewMoney(gross, currency); err != nil {
+		return TaxBreakdown{}, err
+	}
+	return share, nil
+}
+
+// TaxDue is what is owed per currency over the totals, tax given back with refunds is subtracted
+func TaxDue(totals []TaxTotal) map[string]*big.Int {
+	due := make(map[string]*big.Int)
+	for _, total := range totals {
+		sum, ok := due[total.Currency]
+		if !ok {
+			sum = new(big.Int)
+			due[total.Currency] = sum
+		}
+		if total.Category == CategoryRefund {
+			sum.Sub(sum, total.Tax)
+		} else {
+			sum.Add(sum, total.Tax)
+		}
+	}
+	return due
+}
+
+func divRound(n, d *big.Int) *big.Int {
+	q, m := new(big.Int).QuoRem(n, d, new(big.Int))
+	if new(big.Int).Mul(m, big.NewInt(2)).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	return q
+}
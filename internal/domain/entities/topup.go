@@ -0,0 +1,161 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTopUpRuleNotFound    = errors.New("top-up rule not found")
+	ErrTopUpRequestNotFound = errors.New("top-up request not found")
+	ErrInvalidTopUpRule     = errors.New("invalid top-up rule")
+)
+
+type TopUpRequestStatus string
+
+const (
+	TopUpPending   TopUpRequestStatus = "pending"
+	TopUpSucceeded TopUpRequestStatus = "succeeded"
+	// TopUpFailed frees the rule to fire again without waiting for the pending timeout
+	TopUpFailed TopUpRequestStatus = "failed"
+)
+
+// TopUpPendingTimeout is how long an unanswered request blocks its rule from firing again
+const TopUpPendingTimeout = time.Hour
+
+type TopUpRepo interface {
+	CreateRule(ctx context.Context, rule *TopUpRule) error
+	UpdateRule(ctx context.Context, rule *TopUpRule) error
+	DeleteRule(ctx context.Context, walletID, ID uuid.UUID) error
+	FindRuleByID(ctx context.Context, walletID, ID uuid.UUID) (*TopUpRule, error)
+	FindRulesByWalletID(ctx context.Context, walletID uuid.UUID) ([]*TopUpRule, error)
+
+	// uses db layer lock, so a rule fires once even when debits run concurrently
+	LockRule(ctx context.Context, ID uuid.UUID) (*TopUpRule, error)
+
+	CreateRequest(ctx context.Context, request *TopUpRequest) error
+	// uses db layer lock, so a duplicated TopUpSucceeded message credits once and a late
+	// TopUpFailed does not undo a success
+	LockRequest(ctx context.Context, ID uuid.UUID) (*TopUpRequest, error)
+	UpdateRequestStatus(ctx context.Context, request *TopUpRequest) error
+	CountRequestsSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (int64, error)
+	HasPendingRequestSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (bool, error)
+	WithTx(tx *gorm.DB) TopUpRepo
+}
+
+// TopUpRule asks the payment service for Amount from the saved funding source
+// whenever the balance drops below Threshold, at most MaxPerDay times a day
+type TopUpRule struct {
+	ID              uuid.UUID
+	WalletID        uuid.UUID
+	UserID          uuid.UUID
+	Threshold       valueobjects.Money
+	Amount          valueobjects.Money
+	MaxPerDay       int64
+	FundingSourceID string
+	Enabled         bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// TopUpRequest is one firing of a rule, waiting for the payment service to charge the funding source
+type TopUpRequest struct {
+	ID              uuid.UUID
+	RuleID          uuid.UUID
+	WalletID        uuid.UUID
+	UserID          uuid.UUID
+	Amount          valueobjects.Money
+	FundingSourceID string
+	Status          TopUpRequestStatus
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func NewTopUpRule(wallet *Wallet, threshold, amount valueobjects.Money, maxPerDay int64, fundingSourceID string) (*TopUpRule, error) {
+	if wallet == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	rule := &TopUpRule{
+		ID:        uuid.New(),
+		WalletID:  wallet.ID,
+		UserID:    wallet.UserID,
+		Enabled:   true,
+		CreatedAt: time.Now(),
+	}
+	if err := rule.Update(threshold, amount, maxPerDay, fundingSourceID, true); err != nil {
+		return nil, err
+	}
+	if rule.Amount.Currency() != wallet.Currency {
+		return nil, valueobjects.ErrCurrencyMismatch
+	}
+	return rule, nil
+}
+
+func (r *TopUpRule) Update(threshold, amount valueobjects.Money, maxPerDay int64, fundingSourceID string, enabled bool) error {
+	if amount.IsZero() || amount.IsNegative() || threshold.IsNegative() {
+		return ErrInvalidAmount
+	}
+
+	if threshold.Currency() != amount.Currency() {
+		return valueobjects.ErrCurrencyMismatch
+	}
+
+	if maxPerDay <= 0 || fundingSourceID == "" {
+		return ErrInvalidTopUpRule
+	}
+
+	r.Threshold = threshold
+	r.Amount = amount
+	r.MaxPerDay = maxPerDay
+	r.FundingSourceID = fundingSourceID
+	r.Enabled = enabled
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// ShouldFire only looks at the balance, the daily cap and pending requests are checked under the rule lock
+func (r *TopUpRule) ShouldFire(balance valueobjects.Money) bool {
+	if !r.Enabled {
+		return false
+	}
+	above, err := balance.GreaterThanOrEqual(r.Threshold)
+	return err == nil && !above
+}
+
+func NewTopUpRequest(rule *TopUpRule) *TopUpRequest {
+	return &TopUpRequest{
+		ID:              uuid.New(),
+		RuleID:          rule.ID,
+		WalletID:        rule.WalletID,
+		UserID:          rule.UserID,
+		Amount:          rule.Amount,
+		FundingSourceID: rule.FundingSourceID,
+		Status:          TopUpPending,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+}
+
+func (r *TopUpRequest) MarkSucceeded() error {
+	if r.Status != TopUpPending {
+		return ErrInvalidTransactionState
+	}
+	r.Status = TopUpSucceeded
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *TopUpRequest) MarkFailed() error {
+	if r.Status != TopUpPending {
+		return ErrInvalidTransactionState
+	}
+	r.Status = TopUpFailed
+	r.UpdatedAt = time.Now()
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"finance/internal/domain/valueobjects"
+	"math/big"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,22 +30,90 @@ const (
 	TransactionCredit TransactionType = "credit"
 )
 
+// TransactionCategory tells what caused a transaction, the type only tells the direction
+type TransactionCategory string
+
+const (
+	CategorySMS     TransactionCategory = "sms"
+	CategoryManual  TransactionCategory = "manual"
+	CategoryRefund  TransactionCategory = "refund"
+	CategoryTopUp   TransactionCategory = "topup"
+	CategoryBonus   TransactionCategory = "bonus"
+	CategoryVoucher TransactionCategory = "voucher"
+	// CategoryTransfer is either side of a wallet-to-wallet transfer, it is not spending
+	CategoryTransfer TransactionCategory = "transfer"
+	// CategoryDelegated is the part of a child wallet's sms debit that its parent paid,
+	// it is the child's spending
+	CategoryDelegated TransactionCategory = "delegated"
+	// CategoryFXConversion moves money from a sub-balance to the wallet balance
+	CategoryFXConversion TransactionCategory = "fx_conversion"
+	// CategoryBonusExpiry is the audit debit of bonus credit that expired unused, it is not spending
+	CategoryBonusExpiry TransactionCategory = "bonus_expiry"
+)
+
 type TransactionRepo interface {
 	Create(ctx context.Context, tx *Transaction) error
 	FindByID(ctx context.Context, id string) (*Transaction, error)
+	// FindByReference returns the wallet's transaction that references referenceID
+	FindByReference(ctx context.Context, walletID, referenceID uuid.UUID) (*Transaction, error)
 	UpdateStatus(ctx context.Context, tx *Transaction, status TransactionStatus) error
+
+	// SaveFunding records the bucket split of the transaction
+	SaveFunding(ctx context.Context, tx *Transaction) error
+
+	// aggregates over completed transactions of a wallet, used by spending limits, bonus expiry, transfers and
+	// delegated debits are left out
+	SumAmountSince(ctx context.Context, walletID uuid.UUID, txType TransactionType, since time.Time) (*big.Int, error)
+	CountSince(ctx context.Context, walletID uuid.UUID, txType TransactionType, since time.Time) (int64, error)
+	// SumParentFundingSince is what the wallet drew from its parent, net of refunds
+	SumParentFundingSince(ctx context.Context, walletID uuid.UUID, since time.Time) (*big.Int, error)
+	// SumTax totals the completed taxed transactions created in [from, to)
+	SumTax(ctx context.Context, from, to time.Time) ([]TaxTotal, error)
+	// BalanceAt sums the completed transactions of the wallet in currency created before at
+	BalanceAt(ctx context.Context, walletID uuid.UUID, currency string, at time.Time) (*big.Int, error)
+	// SumBetween sums the completed transactions of the wallet in currency created in [from, to), credits less debits
+	SumBetween(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) (*big.Int, error)
+	// SumBalance sums all completed transactions of the wallet in currency, credits less debits
+	SumBalance(ctx context.Context, walletID uuid.UUID, currency string) (*big.Int, error)
+	// StatementLines groups the completed transactions of the wallet in currency created in [from, to)
+	StatementLines(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) ([]InvoiceLine, error)
+	// Stream calls fn for each matching transaction in creation order, rows are read from a db cursor
+	// so the whole result is never held in memory, an error from fn stops the walk
+	Stream(ctx context.Context, filter TransactionFilter, fn func(*Transaction) error) error
 	WithTx(tx *gorm.DB) TransactionRepo
 }
 type Transaction struct {
-	ID        uuid.UUID          `json:"id"`
-	WalletID  uuid.UUID          `json:"wallet_id"`
-	UserID    uuid.UUID          `json:"user_id"`
-	Amount    valueobjects.Money `json:"amount"`
-	Type      TransactionType    `json:"type"`
-	Status    TransactionStatus  `json:"status"`
-	SMSID     uuid.UUID          `json:"sms_id"`
-	CreatedAt time.Time          `json:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at"`
+	ID       uuid.UUID           `json:"id"`
+	WalletID uuid.UUID           `json:"wallet_id"`
+	UserID   uuid.UUID           `json:"user_id"`
+	Amount   valueobjects.Money  `json:"amount"`
+	Type     TransactionType     `json:"type"`
+	Status   TransactionStatus   `json:"status"`
+	SMSID    uuid.UUID           `json:"sms_id"`
+	Category TransactionCategory `json:"category"`
+	// ReferenceID points to what caused the transaction outside of sms billing, e.g. a top-up request
+	ReferenceID uuid.UUID `json:"reference_id"`
+	// set on sms debits, the tariff version tells which price list computed the amount
+	TariffVersion int64       `json:"tariff_version,omitempty"`
+	MessageType   MessageType `json:"message_type,omitempty"`
+	Segments      int64       `json:"segments,omitempty"`
+	// plan of the user at debit time and the volume discount it gave
+	PlanID      uuid.UUID `json:"plan_id,omitempty"`
+	DiscountBPS int64     `json:"discount_bps,omitempty"`
+	// set when the credit was converted from another currency, Amount is the converted money
+	SourceAmount valueobjects.Money `json:"source_amount,omitempty"`
+	FXRate       string             `json:"fx_rate,omitempty"`
+	FXRateID     uuid.UUID          `json:"fx_rate_id,omitempty"`
+	// set when a tax rule applied, Amount is GrossAmount on debits and NetAmount on credits
+	NetAmount   valueobjects.Money `json:"net_amount,omitempty"`
+	TaxAmount   valueobjects.Money `json:"tax_amount,omitempty"`
+	GrossAmount valueobjects.Money `json:"gross_amount,omitempty"`
+	TaxRuleID   uuid.UUID          `json:"tax_rule_id,omitempty"`
+	TaxRateBPS  int64              `json:"tax_rate_bps,omitempty"`
+	// Funding tells which balance buckets paid for, or received, the amount
+	Funding   []Funding `json:"funding,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func NewTransaction(walletID, userID, smsID uuid.UUID, amount valueobjects.Money, txType TransactionType) *Transaction {
@@ -56,12 +125,77 @@ func NewTransaction(walletID, userID, smsID uuid.UUID, amount valueobjects.Money
 		Type:      txType,
 		Status:    TransactionPending,
 		SMSID:     smsID,
+		Category:  defaultCategory(txType),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 	return tx
 }
 
+// Refundable tells whether the transaction is an sms debit, rows from before categories existed have none
+func (t *Transaction) Refundable() bool {
+	return t.Type == TransactionDebit && (t.Category == CategorySMS || t.Category == "")
+}
+
+// Converted records the fx conversion that turned source into the transaction amount
+func (t *Transaction) Converted(source valueobjects.Money, rate *FXRate) {
+	t.SourceAmount = source
+	t.FXRate = rate.Rate.RatString()
+	t.FXRateID = rate.ID
+}
+
+// SetTax records the breakdown, a debit takes the gross amount off the wallet and a credit
+// adds the net amount to it
+func (t *Transaction) SetTax(tax TaxBreakdown) {
+	t.NetAmount = tax.Net
+	t.TaxAmount = tax.Tax
+	t.GrossAmount = tax.Gross
+	t.TaxRuleID = tax.RuleID
+	t.TaxRateBPS = tax.RateBPS
+	if t.Type == TransactionDebit {
+		t.Amount = tax.Gross
+	} else {
+		t.Amount = tax.Net
+	}
+}
+
+// IsTaxed tells if a tax rule applied to the transaction
+func (t *Transaction) IsTaxed() bool {
+	return t.TaxRuleID != uuid.Nil
+}
+
+// RefundTax gives back the share of original's tax that the refund amount covers, a refund of
+// an untaxed debit stays untaxed
+func (t *Transaction) RefundTax(original *Transaction) error {
+	if !original.IsTaxed() || original.GrossAmount.IsZero() {
+		return nil
+	}
+
+	gross := t.Amount.Amount()
+	tax := new(big.Int).Mul(original.TaxAmount.Amount(), gross)
+	tax.Quo(tax, original.GrossAmount.Amount())
+	net := new(big.Int).Sub(gross, tax)
+
+	var err error
+	if t.NetAmount, err = valueobjects.NewMoney(net, t.Amount.Currency()); err != nil {
+		return err
+	}
+	if t.TaxAmount, err = valueobjects.NewMoney(tax, t.Amount.Currency()); err != nil {
+		return err
+	}
+	t.GrossAmount = t.Amount
+	t.TaxRuleID = original.TaxRuleID
+	t.TaxRateBPS = original.TaxRateBPS
+	return nil
+}
+
+func defaultCategory(txType TransactionType) TransactionCategory {
+	if txType == TransactionDebit {
+		return CategorySMS
+	}
+	return CategoryManual
+}
+
 func (t *Transaction) MarkCompleted() error {
 	if t.Status != TransactionPending {
 		return ErrInvalidTransactionState
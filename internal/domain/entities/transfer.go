@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTransferNotFound     = errors.New("transfer not found")
+	ErrInvalidTransfer      = errors.New("invalid transfer")
+	ErrSameWallet           = errors.New("can not transfer to the same wallet")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+)
+
+type TransferRepo interface {
+	Create(ctx context.Context, transfer *Transfer) error
+	FindByID(ctx context.Context, ID uuid.UUID) (*Transfer, error)
+	// keys are scoped to the sending user
+	FindByIdempotencyKey(ctx context.Context, fromUserID uuid.UUID, key string) (*Transfer, error)
+	WithTx(tx *gorm.DB) TransferRepo
+}
+
+// Transfer moves paid balance between two wallets, its debit and credit transactions
+// reference the transfer ID
+type Transfer struct {
+	ID                  uuid.UUID
+	FromWalletID        uuid.UUID
+	ToWalletID          uuid.UUID
+	FromUserID          uuid.UUID
+	ToUserID            uuid.UUID
+	Amount              valueobjects.Money
+	IdempotencyKey      string
+	DebitTransactionID  uuid.UUID
+	CreditTransactionID uuid.UUID
+	CreatedAt           time.Time
+}
+
+func NewTransfer(from, to *Wallet, amount valueobjects.Money, idempotencyKey string) (*Transfer, error) {
+	if idempotencyKey == "" {
+		return nil, ErrInvalidTransfer
+	}
+	if from.ID == to.ID {
+		return nil, ErrSameWallet
+	}
+	if amount.IsZero() || amount.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
+	if from.Currency != to.Currency || amount.Currency() != from.Currency {
+		return nil, valueobjects.ErrCurrencyMismatch
+	}
+
+	return &Transfer{
+		ID:             uuid.New(),
+		FromWalletID:   from.ID,
+		ToWalletID:     to.ID,
+		FromUserID:     from.UserID,
+		ToUserID:       to.UserID,
+		Amount:         amount,
+		IdempotencyKey: idempotencyKey,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// Matches tells whether a retried request asks for the same transfer
+func (t *Transfer) Matches(toUserID uuid.UUID, amount valueobjects.Money) bool {
+	return t.ToUserID == toUserID && t.Amount.Amount().Cmp(amount.Amount()) == 0 &&
+		t.Amount.Currency() == amount.Currency()
+}
+
+// DebitPaid debits paid balance only, bonus credit and the credit line can not be moved
+// to another wallet
+func (w *Wallet) DebitPaid(amount valueobjects.Money) ([]Funding, error) {
+	if w == nil {
+		return nil, ErrWalletNotFound
+	}
+
+	if amount.IsZero() || amount.IsNegative() {
+		return nil, ErrInvalidAmount
+	}
+
+	enough, err := w.PaidBalance().GreaterThanOrEqual(amount)
+	if err != nil {
+		return nil, err
+	}
+	if !enough {
+		return nil, ErrInsufficientBalance
+	}
+
+	if err := w.Debit(amount); err != nil {
+		return nil, err
+	}
+	return []Funding{{Kind: BucketPaid, Amount: amount}}, nil
+}
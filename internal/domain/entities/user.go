@@ -11,15 +11,27 @@ import (
 type UserRepo interface {
 	GetByID(ctx context.Context, ID uuid.UUID) (*User, error)
 	GetAll(ctx context.Context) ([]*User, error)
+	UpdateCustomerType(ctx context.Context, user *User) error
 	WithTx(tx *gorm.DB) UserRepo
 }
 
 type User struct {
-	ID        uuid.UUID
-	Name      string
-	LastName  string
-	Phone     string
-	WalletID  *string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       uuid.UUID
+	Name     string
+	LastName string
+	Phone    string
+	WalletID *string
+	// CustomerType selects the tax rules of the user
+	CustomerType CustomerType
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (u *User) SetCustomerType(customerType CustomerType) error {
+	if !customerType.Valid() {
+		return ErrInvalidCustomerType
+	}
+	u.CustomerType = customerType
+	u.UpdatedAt = time.Now()
+	return nil
 }
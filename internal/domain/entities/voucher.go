@@ -0,0 +1,161 @@
+package entities
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVoucherNotFound     = errors.New("voucher not found")
+	ErrVoucherNotActive    = errors.New("voucher is not valid at this time")
+	ErrVoucherExhausted    = errors.New("voucher has no redemptions left")
+	ErrVoucherLimitReached = errors.New("voucher redemption limit reached for user")
+	ErrInvalidVoucher      = errors.New("invalid voucher")
+)
+
+const (
+	// MaxVoucherBatch bounds how many codes one generation request creates
+	MaxVoucherBatch = 10000
+
+	// codes leave out characters that are easy to misread, like 0/O and 1/I
+	voucherAlphabet   = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	voucherCodeGroups = 3
+	voucherGroupSize  = 4
+)
+
+type VoucherRepo interface {
+	CreateBatch(ctx context.Context, vouchers []*Voucher) error
+	FindByCode(ctx context.Context, code string) (*Voucher, error)
+	List(ctx context.Context, campaign string) ([]*Voucher, error)
+
+	// uses db layer lock, so concurrent redemptions can not pass MaxRedemptions
+	LockByCode(ctx context.Context, code string) (*Voucher, error)
+	CountUserRedemptions(ctx context.Context, voucherID, userID uuid.UUID) (int64, error)
+	// Redeem saves the redemption count of the voucher and records the redemption
+	Redeem(ctx context.Context, voucher *Voucher, redemption *VoucherRedemption) error
+	WithTx(tx *gorm.DB) VoucherRepo
+}
+
+// Voucher is a code worth a fixed credit, zero MaxRedemptions means no overall limit
+// and nil ValidFrom or ValidUntil leaves that side of the window open
+type Voucher struct {
+	ID             uuid.UUID
+	Code           string
+	Amount         valueobjects.Money
+	MaxRedemptions int64
+	PerUserLimit   int64
+	Redeemed       int64
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	Campaign       string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type VoucherRedemption struct {
+	ID            uuid.UUID
+	VoucherID     uuid.UUID
+	UserID        uuid.UUID
+	TransactionID uuid.UUID
+	CreatedAt     time.Time
+}
+
+// NewVoucherBatch creates count vouchers with the same terms and random codes
+func NewVoucherBatch(count int, amount valueobjects.Money, maxRedemptions, perUserLimit int64, validFrom, validUntil *time.Time, campaign string) ([]*Voucher, error) {
+	if count <= 0 || count > MaxVoucherBatch {
+		return nil, ErrInvalidVoucher
+	}
+	if amount.IsZero() || amount.IsNegative() {
+		return nil, ErrInvalidVoucher
+	}
+	if maxRedemptions < 0 || perUserLimit <= 0 {
+		return nil, ErrInvalidVoucher
+	}
+	if validFrom != nil && validUntil != nil && !validUntil.After(*validFrom) {
+		return nil, ErrInvalidVoucher
+	}
+
+	seen := make(map[string]struct{}, count)
+	vouchers := make([]*Voucher, 0, count)
+	for len(vouchers) < count {
+		code, err := NewVoucherCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+
+		vouchers = append(vouchers, &Voucher{
+			ID:             uuid.New(),
+			Code:           code,
+			Amount:         amount,
+			MaxRedemptions: maxRedemptions,
+			PerUserLimit:   perUserLimit,
+			ValidFrom:      validFrom,
+			ValidUntil:     validUntil,
+			Campaign:       campaign,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		})
+	}
+	return vouchers, nil
+}
+
+// NewVoucherCode returns a random code like ABCD-EFGH-JKLM
+func NewVoucherCode() (string, error) {
+	alphabet := big.NewInt(int64(len(voucherAlphabet)))
+	groups := make([]string, 0, voucherCodeGroups)
+	for g := 0; g < voucherCodeGroups; g++ {
+		var group strings.Builder
+		for i := 0; i < voucherGroupSize; i++ {
+			n, err := rand.Int(rand.Reader, alphabet)
+			if err != nil {
+				return "", err
+			}
+			group.WriteByte(voucherAlphabet[n.Int64()])
+		}
+		groups = append(groups, group.String())
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// NormalizeVoucherCode makes user input comparable with stored codes
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Redeem checks the validity window and both limits, userRedemptions is how many times
+// the user already redeemed the voucher
+func (v *Voucher) Redeem(userID uuid.UUID, userRedemptions int64, now time.Time) (*VoucherRedemption, error) {
+	if v.ValidFrom != nil && now.Before(*v.ValidFrom) {
+		return nil, ErrVoucherNotActive
+	}
+	if v.ValidUntil != nil && !now.Before(*v.ValidUntil) {
+		return nil, ErrVoucherNotActive
+	}
+	if v.MaxRedemptions > 0 && v.Redeemed >= v.MaxRedemptions {
+		return nil, ErrVoucherExhausted
+	}
+	if userRedemptions >= v.PerUserLimit {
+		return nil, ErrVoucherLimitReached
+	}
+
+	v.Redeemed++
+	v.UpdatedAt = now
+	return &VoucherRedemption{
+		ID:        uuid.New(),
+		VoucherID: v.ID,
+		UserID:    userID,
+		CreatedAt: now,
+	}, nil
+}
@@ -17,25 +17,56 @@ var (
 	ErrNegativeBalance     = errors.New("operation would result in negative balance")
 	ErrInvalidAmount       = errors.New("amount must be positive")
 	ErrWalletNotFound      = errors.New("wallet not found")
+	ErrInvalidCreditLimit  = errors.New("credit limit must not be negative")
 )
 
 type WalletRepo interface {
 	Save(ctx context.Context, wallet *Wallet) error
 	FindByID(ctx context.Context, ID uuid.UUID) (*Wallet, error)
 	FindByUserID(ctx context.Context, userID uuid.UUID) (*Wallet, error)
+	// LockByID reads the wallet with a db layer lock held until the transaction ends
+	LockByID(ctx context.Context, ID uuid.UUID) (*Wallet, error)
+	LockByUserID(ctx context.Context, userID uuid.UUID) (*Wallet, error)
 
-	// uses db layer lock
+	// the wallet must be read with LockByID or LockByUserID in the same transaction,
+	// it also saves the buckets and sub-balances of the wallet
 	UpdateBalance(ctx context.Context, wallet *Wallet) error
+	UpdateLimits(ctx context.Context, wallet *Wallet) error
+	UpdateCreditLimit(ctx context.Context, wallet *Wallet) error
+	UpdateParent(ctx context.Context, wallet *Wallet) error
+	FindChildren(ctx context.Context, parentID uuid.UUID) ([]*Wallet, error)
+	FindBucket(ctx context.Context, walletID, ID uuid.UUID) (*BalanceBucket, error)
+	// FindWalletIDsWithExpiredBuckets lists wallets holding credit in buckets expired before the given time
+	FindWalletIDsWithExpiredBuckets(ctx context.Context, before time.Time) ([]uuid.UUID, error)
+	// LastAuditEvent is the head of the wallet's audit chain, nil when the wallet has no events yet
+	LastAuditEvent(ctx context.Context, walletID uuid.UUID) (*AuditEvent, error)
+	// AppendAuditEvent stores a chained event, call it after UpdateBalance so the wallet row lock
+	// taken by the update keeps concurrent appends to the chain in order
+	AppendAuditEvent(ctx context.Context, event *AuditEvent) error
+	// ListIDs pages through every wallet id in id order, starting after the given id
+	ListIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
 	WithTx(tx *gorm.DB) WalletRepo
 }
 
 type Wallet struct {
-	ID        uuid.UUID
-	UserID    uuid.UUID
-	Balance   valueobjects.Money
-	Currency  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID     uuid.UUID
+	UserID uuid.UUID
+	// Balance is signed, it goes below zero when a postpaid wallet uses its credit line
+	Balance valueobjects.Money
+	// CreditLimit is how far below zero the balance may go
+	CreditLimit valueobjects.Money
+	Currency    string
+	Limits      SpendingLimits
+	// Buckets are the bonus buckets that still hold credit, their credit is part of Balance
+	Buckets []*BalanceBucket
+	// ParentID is the wallet debits fall through to once this one is empty, ParentQuota caps
+	// what is drawn from it a month, nil means no cap
+	ParentID    uuid.UUID
+	ParentQuota *big.Int
+	// SubBalances hold money in other currencies, they are not part of Balance
+	SubBalances []*SubBalance
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 func NewWallet(userID uuid.UUID, currency string) (*Wallet, error) {
@@ -44,22 +75,45 @@ func NewWallet(userID uuid.UUID, currency string) (*Wallet, error) {
 		return nil, err
 	}
 	return &Wallet{
-		ID:        uuid.New(),
-		UserID:    userID,
-		Balance:   zeroAmount,
-		Currency:  currency,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:          uuid.New(),
+		UserID:      userID,
+		Balance:     zeroAmount,
+		CreditLimit: zeroAmount,
+		Currency:    currency,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}, nil
 }
 
+// AvailableBalance is the balance plus the unused part of the credit line
+func (w *Wallet) AvailableBalance() (valueobjects.Money, error) {
+	if w.CreditLimit.Currency() == "" {
+		return w.Balance, nil
+	}
+	return w.Balance.Add(w.CreditLimit)
+}
+
+// AmountOwed is how much of the credit line is in use, zero when the balance is not negative
+func (w *Wallet) AmountOwed() valueobjects.Money {
+	if w.Balance.IsNegative() {
+		return w.Balance.Neg()
+	}
+	owed, _ := valueobjects.NewMoney(big.NewInt(0), w.Balance.Currency())
+	return owed
+}
+
 func (w *Wallet) HasSufficientBalance(amount valueobjects.Money) error {
 	if w.Balance.Currency() != amount.Currency() {
 		return fmt.Errorf("currency mismatch: wallet has %s, requested %s",
 			w.Balance.Currency(), amount.Currency())
 	}
 
-	hasEnough, err := w.Balance.GreaterThanOrEqual(amount)
+	available, err := w.AvailableBalance()
+	if err != nil {
+		return fmt.Errorf("available balance calculation failed: %w", err)
+	}
+
+	hasEnough, err := available.GreaterThanOrEqual(amount)
 	if err != nil {
 		return fmt.Errorf("balance comparison failed: %w", err)
 	}
@@ -84,12 +138,12 @@ func (w *Wallet) Debit(amount valueobjects.Money) error {
 		return err
 	}
 
-	newBalance, err := w.Balance.Subtract(amount)
+	newBalance, err := w.Balance.SubtractSigned(amount)
 	if err != nil {
 		return fmt.Errorf("debit calculation failed: %w", err)
 	}
 
-	if newBalance.IsNegative() {
+	if newBalance.IsNegative() && !w.withinCreditLimit(newBalance) {
 		return ErrNegativeBalance
 	}
 
@@ -121,3 +175,44 @@ func (w *Wallet) Credit(amount valueobjects.Money) error {
 	w.UpdatedAt = time.Now()
 	return nil
 }
+
+func (w *Wallet) SetLimits(limits SpendingLimits) error {
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	if err := limits.Validate(); err != nil {
+		return err
+	}
+
+	w.Limits = limits
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *Wallet) SetCreditLimit(limit valueobjects.Money) error {
+	if w == nil {
+		return ErrWalletNotFound
+	}
+
+	if limit.IsNegative() {
+		return ErrInvalidCreditLimit
+	}
+
+	if w.Balance.Currency() != limit.Currency() {
+		return fmt.Errorf("currency mismatch: wallet has %s, credit limit in %s",
+			w.Balance.Currency(), limit.Currency())
+	}
+
+	w.CreditLimit = limit
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *Wallet) withinCreditLimit(balance valueobjects.Money) bool {
+	if w.CreditLimit.Currency() == "" {
+		return false
+	}
+	ok, err := balance.GreaterThanOrEqual(w.CreditLimit.Neg())
+	return err == nil && ok
+}
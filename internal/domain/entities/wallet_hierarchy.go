@@ -0,0 +1,101 @@
+package entities
+
+import (
+	"errors"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidParent      = errors.New("invalid parent wallet")
+	ErrParentQuotaReached = errors.New("monthly quota on the parent wallet reached")
+)
+
+// MaxHierarchyDepth bounds how many parents a wallet may have above it, a debit only
+// falls back to the wallet's own parent
+const MaxHierarchyDepth = 1
+
+// SpendingReport is the sms spending of a wallet and of every wallet below it
+type SpendingReport struct {
+	WalletID uuid.UUID
+	UserID   uuid.UUID
+	Currency string
+	// Own is what the wallet's user sent, including what the parent paid for
+	Own      *big.Int
+	Total    *big.Int
+	Children []*SpendingReport
+}
+
+// SetParent lets the wallet draw from parent once its own funds run out, up to quota a month,
+// a nil quota does not limit the draw
+func (w *Wallet) SetParent(parent *Wallet, quota *big.Int) error {
+	if w == nil || parent == nil {
+		return ErrWalletNotFound
+	}
+
+	if parent.ID == w.ID || parent.Currency != w.Currency {
+		return ErrInvalidParent
+	}
+
+	if quota != nil && quota.Sign() < 0 {
+		return ErrInvalidParent
+	}
+
+	w.ParentID = parent.ID
+	w.ParentQuota = quota
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+func (w *Wallet) DetachParent() {
+	w.ParentID = uuid.Nil
+	w.ParentQuota = nil
+	w.UpdatedAt = time.Now()
+}
+
+func (w *Wallet) HasParent() bool {
+	return w.ParentID != uuid.Nil
+}
+
+// Spendable is what DebitFunded can take, available balance without expired bonus credit
+func (w *Wallet) Spendable(now time.Time) valueobjects.Money {
+	spendable := big.NewInt(0)
+	if available, err := w.AvailableBalance(); err == nil {
+		spendable.Sub(available.Amount(), w.expiredBonus(now))
+	}
+	if spendable.Sign() < 0 {
+		spendable.SetInt64(0)
+	}
+	money, _ := valueobjects.NewMoney(spendable, w.Currency)
+	return money
+}
+
+// SplitWithParent tells how much of amount the wallet pays itself and how much falls through
+// to its parent, quotaUsed is what was drawn from the parent this month
+func (w *Wallet) SplitWithParent(amount valueobjects.Money, quotaUsed *big.Int) (valueobjects.Money, valueobjects.Money, error) {
+	zero, _ := valueobjects.NewMoney(big.NewInt(0), w.Currency)
+
+	spendable := w.Spendable(time.Now())
+	if !w.HasParent() {
+		return amount, zero, nil
+	}
+	if enough, err := spendable.GreaterThanOrEqual(amount); err != nil || enough {
+		return amount, zero, err
+	}
+
+	fromParent, err := amount.Subtract(spendable)
+	if err != nil {
+		return zero, zero, err
+	}
+
+	if w.ParentQuota != nil && w.ParentQuota.Sign() > 0 {
+		drawn := new(big.Int).Add(orZero(quotaUsed), fromParent.Amount())
+		if drawn.Cmp(w.ParentQuota) > 0 {
+			return zero, zero, ErrParentQuotaReached
+		}
+	}
+	return spendable, fromParent, nil
+}
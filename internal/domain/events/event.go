@@ -11,6 +11,19 @@ const (
 	EventTypeDebit      EventType = "Debit"
 	EventTypeRefund     EventType = "Refund"
 	EventTypeSMSDebited EventType = "SMSDebited"
+
+	EventTypeSMSDebitFailed EventType = "SMSDebitFailed"
+
+	EventTypeWalletBalanceLow      EventType = "WalletBalanceLow"
+	EventTypeWalletBalanceDepleted EventType = "WalletBalanceDepleted"
+
+	EventTypeTopUpRequested EventType = "TopUpRequested"
+	EventTypeTopUpSucceeded EventType = "TopUpSucceeded"
+	EventTypeTopUpFailed    EventType = "TopUpFailed"
+
+	EventTypeFXRateUpdated EventType = "FXRateUpdated"
+
+	EventTypeBalanceDiscrepancyDetected EventType = "BalanceDiscrepancyDetected"
 )
 
 type Publisher interface {
@@ -22,11 +35,16 @@ type SMSEvent interface {
 	AggregateID() string
 }
 
+// RequestSMSBilling carries what finance needs to price the sms, the amount comes from the active tariff
 type RequestSMSBilling struct {
-	UserID    string    `json:"user_id"`
-	SMSID     string    `json:"sms_id"`
-	Amount    int64     `json:"amount"`
-	TimeStamp time.Time `json:"timestamp"`
+	UserID      string    `json:"user_id"`
+	SMSID       string    `json:"sms_id"`
+	Receiver    string    `json:"receiver"`
+	Segments    int64     `json:"segments"`
+	Encoding    string    `json:"encoding"`
+	MessageType string    `json:"message_type,omitempty"`
+	Operator    string    `json:"operator,omitempty"`
+	TimeStamp   time.Time `json:"timestamp"`
 }
 
 type RequestBillingRefund struct {
@@ -38,10 +56,63 @@ type SMSDebited struct {
 	UserID        string    `json:"user_id"`
 	SMSID         string    `json:"sms_id"`
 	Amount        int64     `json:"amount"`
+	TariffVersion int64     `json:"tariff_version"`
 	TransactionID string    `json:"transaction_id"`
 	TimeStamp     time.Time `json:"timestamp"`
 }
 
+type SMSDebitFailed struct {
+	UserID    string    `json:"user_id"`
+	SMSID     string    `json:"sms_id"`
+	Reason    string    `json:"reason"`
+	TimeStamp time.Time `json:"timestamp"`
+}
+
+type WalletBalanceLow struct {
+	WalletID    string    `json:"wallet_id"`
+	UserID      string    `json:"user_id"`
+	ThresholdID string    `json:"threshold_id"`
+	Threshold   int64     `json:"threshold"`
+	Balance     int64     `json:"balance"`
+	Currency    string    `json:"currency"`
+	TimeStamp   time.Time `json:"timestamp"`
+}
+
+type WalletBalanceDepleted struct {
+	WalletID  string    `json:"wallet_id"`
+	UserID    string    `json:"user_id"`
+	Balance   int64     `json:"balance"`
+	Currency  string    `json:"currency"`
+	TimeStamp time.Time `json:"timestamp"`
+}
+
+// TopUpRequested asks the payment service to charge the saved funding source
+type TopUpRequested struct {
+	RequestID       string    `json:"request_id"`
+	RuleID          string    `json:"rule_id"`
+	WalletID        string    `json:"wallet_id"`
+	UserID          string    `json:"user_id"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	FundingSourceID string    `json:"funding_source_id"`
+	TimeStamp       time.Time `json:"timestamp"`
+}
+
+// TopUpSucceeded comes back from the payment service once the funding source was charged
+type TopUpSucceeded struct {
+	RequestID string    `json:"request_id"`
+	PaymentID string    `json:"payment_id"`
+	TimeStamp time.Time `json:"timestamp"`
+}
+
+// TopUpFailed comes back from the payment service when the funding source could not be charged
+type TopUpFailed struct {
+	RequestID string    `json:"request_id"`
+	PaymentID string    `json:"payment_id"`
+	Reason    string    `json:"reason"`
+	TimeStamp time.Time `json:"timestamp"`
+}
+
 func (e *RequestSMSBilling) EventType() EventType {
 	return EventTypeDebit
 }
@@ -65,3 +136,90 @@ func (e *SMSDebited) EventType() EventType {
 func (e *SMSDebited) AggregateID() string {
 	return e.TransactionID
 }
+
+func (e *SMSDebitFailed) EventType() EventType {
+	return EventTypeSMSDebitFailed
+}
+
+func (e *SMSDebitFailed) AggregateID() string {
+	return e.SMSID
+}
+
+func (e *WalletBalanceLow) EventType() EventType {
+	return EventTypeWalletBalanceLow
+}
+
+func (e *WalletBalanceLow) AggregateID() string {
+	return e.WalletID
+}
+
+func (e *WalletBalanceDepleted) EventType() EventType {
+	return EventTypeWalletBalanceDepleted
+}
+
+func (e *WalletBalanceDepleted) AggregateID() string {
+	return e.WalletID
+}
+
+func (e *TopUpRequested) EventType() EventType {
+	return EventTypeTopUpRequested
+}
+
+func (e *TopUpRequested) AggregateID() string {
+	return e.RequestID
+}
+
+// FXRateUpdated comes from the rate feed, one unit of base is worth rate units of quote
+type FXRateUpdated struct {
+	Base       string     `json:"base"`
+	Quote      string     `json:"quote"`
+	Rate       string     `json:"rate"`
+	ValidFrom  time.Time  `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	TimeStamp  time.Time  `json:"timestamp"`
+}
+
+func (e *FXRateUpdated) EventType() EventType {
+	return EventTypeFXRateUpdated
+}
+
+func (e *FXRateUpdated) AggregateID() string {
+	return e.Base + "/" + e.Quote
+}
+
+func (e *TopUpSucceeded) EventType() EventType {
+	return EventTypeTopUpSucceeded
+}
+
+func (e *TopUpSucceeded) AggregateID() string {
+	return e.RequestID
+}
+
+func (e *TopUpFailed) EventType() EventType {
+	return EventTypeTopUpFailed
+}
+
+func (e *TopUpFailed) AggregateID() string {
+	return e.RequestID
+}
+
+// BalanceDiscrepancyDetected is raised by reconciliation when a stored balance differs from its transactions,
+// amounts are decimal strings since a broken balance can be any size
+type BalanceDiscrepancyDetected struct {
+	WalletID   string    `json:"wallet_id"`
+	UserID     string    `json:"user_id"`
+	Currency   string    `json:"currency"`
+	Stored     string    `json:"stored"`
+	Computed   string    `json:"computed"`
+	Difference string    `json:"difference"`
+	Repaired   bool      `json:"repaired"`
+	TimeStamp  time.Time `json:"timestamp"`
+}
+
+func (e *BalanceDiscrepancyDetected) EventType() EventType {
+	return EventTypeBalanceDiscrepancyDetected
+}
+
+func (e *BalanceDiscrepancyDetected) AggregateID() string {
+	return e.WalletID
+}
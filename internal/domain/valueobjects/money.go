@@ -30,6 +30,18 @@ func NewMoney(amount *big.Int, currency string) (Money, error) {
 	}, nil
 }
 
+// NewSignedMoney allows negative amounts, it is used for wallet balances that can go below zero on a credit line
+func NewSignedMoney(amount *big.Int, currency string) (Money, error) {
+	if amount == nil {
+		return Money{}, errors.New("amount cannot be nil")
+	}
+
+	return Money{
+		amount:   new(big.Int).Set(amount),
+		currency: strings.ToUpper(currency),
+	}, nil
+}
+
 func (m Money) GreaterThanOrEqual(other Money) (bool, error) {
 	if m.currency != other.currency {
 		return false, errors.New("cannot compare different currencies")
@@ -59,6 +71,20 @@ func (m Money) Subtract(other Money) (Money, error) {
 	return Money{amount: result, currency: m.currency}, nil
 }
 
+// SubtractSigned is like Subtract but the result is allowed to be negative
+func (m Money) SubtractSigned(other Money) (Money, error) {
+	if m.currency != other.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	result := new(big.Int).Sub(m.amount, other.amount)
+	return Money{amount: result, currency: m.currency}, nil
+}
+
+func (m Money) Neg() Money {
+	return Money{amount: new(big.Int).Neg(m.amount), currency: m.currency}
+}
+
 func (m Money) Add(other Money) (Money, error) {
 	if m.currency != other.currency {
 		return Money{}, ErrCurrencyMismatch
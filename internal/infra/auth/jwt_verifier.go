@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"finance/config"
+	"finance/internal/domain/entities"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var errUnknownKey = errors.New("token signed with an unknown key")
+
+// Claims are the token claims the service reads, customers carry their user in user_id or sub
+type Claims struct {
+	Role   string `json:"role"`
+	UserID string `json:"user_id"`
+	jwt.RegisteredClaims
+}
+
+// JWTVerifier checks bearer tokens against keys loaded once at start up
+type JWTVerifier struct {
+	keys   map[string]crypto.PublicKey
+	parser *jwt.Parser
+}
+
+// NewJWTVerifier loads the configured keys, it returns nil when no key is configured
+func NewJWTVerifier(cfg config.Auth) (*JWTVerifier, error) {
+	var keys map[string]crypto.PublicKey
+	var err error
+	switch {
+	case cfg.JWKSFile != "":
+		keys, err = loadJWKS(cfg.JWKSFile)
+	case cfg.PublicKeyFile != "":
+		var key crypto.PublicKey
+		key, err = loadPublicKey(cfg.PublicKeyFile)
+		keys = map[string]crypto.PublicKey{"": key}
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no usable keys in the jwks file")
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	return &JWTVerifier{keys: keys, parser: jwt.NewParser(options...)}, nil
+}
+
+// Verify checks the signature and claims of the token and returns its principal
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (entities.Principal, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.key); err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: %v", entities.ErrUnauthenticated, err)
+	}
+
+	var userID uuid.UUID
+	role := entities.Role(claims.Role)
+	if role == entities.RoleCustomer {
+		raw := claims.UserID
+		if raw == "" {
+			raw = claims.Subject
+		}
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return entities.Principal{}, fmt.Errorf("%w: customer token without a user", entities.ErrUnauthenticated)
+		}
+		userID = parsed
+	}
+	principal, err := entities.NewPrincipal(claims.Subject, role, userID)
+	if err != nil {
+		return entities.Principal{}, fmt.Errorf("%w: %v", entities.ErrUnauthenticated, err)
+	}
+	return principal, nil
+}
+
+// key picks the key named by the token's kid, a single key is used for tokens without one
+func (v *JWTVerifier) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return nil, errUnknownKey
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("public key file is not PEM encoded")
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadJWKS reads the signing keys of a JWKS file by kid, encryption keys are left out
+func loadJWKS(path string) (map[string]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// nonceSweepSize is how many nonces are kept before expired ones are swept out
+const nonceSweepSize = 10000
+
+// MemoryNonceCache keeps nonces in process memory, every api instance has its own cache so a
+// request replayed to another instance is only caught by the signature window
+type MemoryNonceCache struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	// sweepAt is the size that triggers the next sweep
+	sweepAt int
+}
+
+func NewMemoryNonceCache() *MemoryNonceCache {
+	return &MemoryNonceCache{
+		nonces:  make(map[string]time.Time),
+		sweepAt: nonceSweepSize,
+	}
+}
+
+func (c *MemoryNonceCache) Remember(_ context.Context, key string, until time.Time) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if expires, ok := c.nonces[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	c.nonces[key] = until
+
+	if len(c.nonces) >= c.sweepAt {
+		for k, expires := range c.nonces {
+			if !now.Before(expires) {
+				delete(c.nonces, k)
+			}
+		}
+		// a cache full of live nonces is not swept again until it doubles
+		c.sweepAt = max(nonceSweepSize, 2*len(c.nonces))
+	}
+	return true, nil
+}
@@ -27,5 +27,20 @@ func (p *WalletPublisher) PublishEvent(ctx context.Context, event events.SMSEven
 		"aggregate_id", event.AggregateID(),
 	)
 
-	return p.publisher.Publish(rabbit.SMSBilledRouting, rabbit.Exchange, event)
+	return p.publisher.Publish(ctx, routingKey(event), rabbit.Exchange, event)
+}
+
+func routingKey(event events.SMSEvent) string {
+	switch event.EventType() {
+	case events.EventTypeSMSDebitFailed:
+		return rabbit.SMSDebitFailedRouting
+	case events.EventTypeWalletBalanceLow, events.EventTypeWalletBalanceDepleted:
+		return rabbit.WalletBalanceAlertRouting
+	case events.EventTypeTopUpRequested:
+		return rabbit.TopUpRequestedRouting
+	case events.EventTypeBalanceDiscrepancyDetected:
+		return rabbit.BalanceDiscrepancyRouting
+	default:
+		return rabbit.SMSBilledRouting
+	}
 }
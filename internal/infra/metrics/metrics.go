@@ -0,0 +1,97 @@
+// Package metrics holds the prometheus collectors of the service, served on /metrics.
+//
+// Amounts are summed in minor units per currency, so rates of them read as money per second.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	// OutcomeSkipped is a refund of a transaction that is not refundable or has nothing left to give back,
+	// or a top-up whose result was already handled
+	OutcomeSkipped = "skipped"
+
+	OperationDebit  = "debit"
+	OperationCredit = "credit"
+	OperationRefund = "refund"
+)
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	Debits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_debits_total",
+		Help: "SMS debits by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	Credits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_credits_total",
+		Help: "Wallet credits by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	Refunds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_refunds_total",
+		Help: "Refunds of sms debits by outcome and failure reason.",
+	}, []string{"outcome", "reason"})
+
+	Amounts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_amount_minor_units_total",
+		Help: "Sum of debited, credited and refunded amounts in minor units.",
+	}, []string{"operation", "currency"})
+
+	DBTransactionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "finance_db_transaction_duration_seconds",
+		Help:    "Duration of the database transactions behind debits, credits and refunds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+
+	HandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "finance_consumer_handler_duration_seconds",
+		Help:    "Duration of handling a message per queue.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"queue", "outcome"})
+
+	PublishFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_publish_failures_total",
+		Help: "Events that could not be published by event type.",
+	}, []string{"event_type"})
+
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "finance_http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "finance_http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Debits, Credits, Refunds, Amounts,
+		DBTransactionDuration, HandlerDuration, PublishFailures,
+		HTTPRequests, HTTPRequestDuration,
+	)
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+func Outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the buckets that refilled are dropped
+const sweepInterval = time.Minute
+
+// MemoryStore keeps the buckets in process memory, for a single api instance. There is a bucket
+// per client and rule, buckets that refilled are dropped so the map only holds recent clients
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	sweptAt time.Time
+}
+
+type memoryBucket struct {
+	bucket *entities.TokenBucket
+	limit  entities.RateLimit
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit entities.RateLimit, now time.Time) (entities.RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{bucket: entities.NewTokenBucket(limit, now)}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	return b.bucket.Take(limit, now), nil
+}
+
+// Len is the number of buckets kept
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// sweep drops the full buckets at most once per sweepInterval, a new bucket starts full so
+// dropping them changes no result
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < sweepInterval {
+		return
+	}
+	s.sweptAt = now
+	for key, b := range s.buckets {
+		if b.bucket.Full(b.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
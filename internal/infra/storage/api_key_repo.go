@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	Db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) entities.APIKeyRepo {
+	return &APIKeyRepository{
+		Db: db,
+	}
+}
+
+func (r *APIKeyRepository) Save(ctx context.Context, key *entities.APIKey) error {
+	model := mapper.APIKeyDomain2Storage(key)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	return r.find(ctx, "hash = ?", hash)
+}
+
+func (r *APIKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	return r.find(ctx, "id = ?", id)
+}
+
+func (r *APIKeyRepository) find(ctx context.Context, query string, arg interface{}) (*entities.APIKey, error) {
+	var model types.APIKey
+	if err := r.Db.WithContext(ctx).First(&model, query, arg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return mapper.APIKeyStorage2Domain(model), nil
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]*entities.APIKey, error) {
+	var models []types.APIKey
+	if err := r.Db.WithContext(ctx).Order("created_at").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]*entities.APIKey, 0, len(models))
+	for _, model := range models {
+		keys = append(keys, mapper.APIKeyStorage2Domain(model))
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, key *entities.APIKey) error {
+	return r.Db.WithContext(ctx).Model(&types.APIKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"revoked_at": key.RevokedAt,
+		"updated_at": key.UpdatedAt,
+	}).Error
+}
+
+func (r *APIKeyRepository) WithTx(tx *gorm.DB) entities.APIKeyRepo {
+	return NewAPIKeyRepository(tx)
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxAuditPage bounds an audit listing that asks for no limit
+const maxAuditPage = 1000
+
+type AuditRepository struct {
+	Db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) entities.AuditRepo {
+	return &AuditRepository{
+		Db: db,
+	}
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter entities.AuditFilter) ([]*entities.AuditEvent, error) {
+	query := r.Db.WithContext(ctx).Order("created_at DESC, sequence DESC")
+	if filter.WalletID != uuid.Nil {
+		query = query.Where("wallet_id = ?", filter.WalletID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	limit := filter.Limit
+	if limit <= 0 || limit > maxAuditPage {
+		limit = maxAuditPage
+	}
+
+	var models []types.AuditEvent
+	if err := query.Limit(limit).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return auditEvents(models), nil
+}
+
+func (r *AuditRepository) Chain(ctx context.Context, walletID uuid.UUID) ([]*entities.AuditEvent, error) {
+	var models []types.AuditEvent
+	if err := r.Db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("sequence").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return auditEvents(models), nil
+}
+
+func auditEvents(models []types.AuditEvent) []*entities.AuditEvent {
+	events := make([]*entities.AuditEvent, len(models))
+	for i, model := range models {
+		events[i] = mapper.AuditEventStorage2Domain(model)
+	}
+	return events
+}
+
+func (r *AuditRepository) WithTx(tx *gorm.DB) entities.AuditRepo {
+	return NewAuditRepository(tx)
+}
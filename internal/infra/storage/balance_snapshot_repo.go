@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BalanceSnapshotRepository struct {
+	Db *gorm.DB
+}
+
+func NewBalanceSnapshotRepository(db *gorm.DB) entities.BalanceSnapshotRepo {
+	return &BalanceSnapshotRepository{
+		Db: db,
+	}
+}
+
+func (r *BalanceSnapshotRepository) Save(ctx context.Context, snapshot *entities.BalanceSnapshot) error {
+	model := mapper.BalanceSnapshotDomain2Storage(snapshot)
+	return r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "taken_at"}},
+		DoNothing: true,
+	}).Create(&model).Error
+}
+
+func (r *BalanceSnapshotRepository) FindLatest(ctx context.Context, walletID uuid.UUID, at time.Time) (*entities.BalanceSnapshot, error) {
+	var model types.BalanceSnapshot
+	err := r.Db.WithContext(ctx).
+		Where("wallet_id = ? AND taken_at <= ?", walletID, at).
+		Order("taken_at DESC").
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return mapper.BalanceSnapshotStorage2Domain(model), nil
+}
+
+func (r *BalanceSnapshotRepository) WithTx(tx *gorm.DB) entities.BalanceSnapshotRepo {
+	return NewBalanceSnapshotRepository(tx)
+}
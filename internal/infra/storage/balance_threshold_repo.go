@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BalanceThresholdRepository struct {
+	Db *gorm.DB
+}
+
+func NewBalanceThresholdRepository(db *gorm.DB) entities.BalanceThresholdRepo {
+	return &BalanceThresholdRepository{
+		Db: db,
+	}
+}
+
+func (r *BalanceThresholdRepository) Create(ctx context.Context, threshold *entities.BalanceThreshold) error {
+	model := mapper.BalanceThresholdDomain2Storage(threshold)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *BalanceThresholdRepository) FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*entities.BalanceThreshold, error) {
+	var models []types.BalanceThreshold
+	if err := r.Db.WithContext(ctx).Order("amount_amount::numeric DESC").Find(&models, "wallet_id = ?", walletID).Error; err != nil {
+		return nil, err
+	}
+
+	thresholds := make([]*entities.BalanceThreshold, 0, len(models))
+	for _, model := range models {
+		threshold, err := mapper.BalanceThresholdStorage2Domain(model)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func (r *BalanceThresholdRepository) Delete(ctx context.Context, walletID, ID uuid.UUID) error {
+	res := r.Db.WithContext(ctx).Delete(&types.BalanceThreshold{}, "id = ? AND wallet_id = ?", ID, walletID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return entities.ErrThresholdNotFound
+	}
+	return nil
+}
+
+func (r *BalanceThresholdRepository) SetArmed(ctx context.Context, ID uuid.UUID, armed bool) (bool, error) {
+	res := r.Db.WithContext(ctx).Model(&types.BalanceThreshold{}).
+		Where("id = ? AND armed = ?", ID, !armed).
+		Update("armed", armed)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *BalanceThresholdRepository) WithTx(tx *gorm.DB) entities.BalanceThresholdRepo {
+	return NewBalanceThresholdRepository(tx)
+}
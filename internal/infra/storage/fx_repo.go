@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FXRateRepository struct {
+	Db *gorm.DB
+}
+
+func NewFXRateRepository(db *gorm.DB) entities.FXRateRepo {
+	return &FXRateRepository{
+		Db: db,
+	}
+}
+
+func (r *FXRateRepository) Create(ctx context.Context, rate *entities.FXRate) error {
+	model := mapper.FXRateDomain2Storage(rate)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *FXRateRepository) FindValid(ctx context.Context, base, quote string, at time.Time) (*entities.FXRate, error) {
+	var model types.FXRate
+	err := r.Db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ?", base, quote).
+		Where("valid_from <= ? AND (valid_until IS NULL OR valid_until > ?)", at, at).
+		Order("valid_from DESC, created_at DESC").
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrFXRateNotFound
+		}
+		return nil, err
+	}
+	return mapper.FXRateStorage2Domain(model)
+}
+
+func (r *FXRateRepository) List(ctx context.Context, base, quote string) ([]*entities.FXRate, error) {
+	query := r.Db.WithContext(ctx).Order("valid_from DESC")
+	if base != "" {
+		query = query.Where("base_currency = ?", base)
+	}
+	if quote != "" {
+		query = query.Where("quote_currency = ?", quote)
+	}
+
+	var models []types.FXRate
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	rates := make([]*entities.FXRate, 0, len(models))
+	for _, model := range models {
+		rate, err := mapper.FXRateStorage2Domain(model)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (r *FXRateRepository) WithTx(tx *gorm.DB) entities.FXRateRepo {
+	return NewFXRateRepository(tx)
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	Db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) entities.IdempotencyRepo {
+	return &IdempotencyRepository{
+		Db: db,
+	}
+}
+
+// Acquire relies on the unique index, an insert of a key that another transaction inserted but
+// has not committed waits for that transaction, the row lock then covers committed keys
+func (r *IdempotencyRepository) Acquire(ctx context.Context, record *entities.IdempotencyRecord) (*entities.IdempotencyRecord, error) {
+	model := mapper.IdempotencyDomain2Storage(record)
+	err := r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}, {Name: "route"}, {Name: "caller"}},
+		DoNothing: true,
+	}).Create(&model).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stored types.IdempotencyRecord
+	err = r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&stored, "key = ? AND route = ? AND caller = ?", record.Key, record.Route, record.Caller).Error
+	if err != nil {
+		return nil, err
+	}
+	return mapper.IdempotencyStorage2Domain(stored), nil
+}
+
+func (r *IdempotencyRepository) Save(ctx context.Context, record *entities.IdempotencyRecord) error {
+	model := mapper.IdempotencyDomain2Storage(record)
+	return r.Db.WithContext(ctx).Model(&types.IdempotencyRecord{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"request_hash":  model.RequestHash,
+		"status_code":   model.StatusCode,
+		"content_type":  model.ContentType,
+		"body":          model.Body,
+		"claimed_until": model.ClaimedUntil,
+		"expires_at":    model.ExpiresAt,
+	}).Error
+}
+
+func (r *IdempotencyRepository) Release(ctx context.Context, record *entities.IdempotencyRecord) error {
+	return r.Db.WithContext(ctx).Where("id = ? AND status_code = 0", record.ID).Delete(&types.IdempotencyRecord{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.Db.WithContext(ctx).Where("expires_at < ?", before).Delete(&types.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *IdempotencyRepository) WithTx(tx *gorm.DB) entities.IdempotencyRepo {
+	return NewIdempotencyRepository(tx)
+}
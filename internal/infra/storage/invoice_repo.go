@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const invoiceSequence = "invoice"
+
+type InvoiceRepository struct {
+	Db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) entities.InvoiceRepo {
+	return &InvoiceRepository{
+		Db: db,
+	}
+}
+
+func (r *InvoiceRepository) Create(ctx context.Context, invoice *entities.Invoice) error {
+	model := mapper.InvoiceDomain2Storage(invoice)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *InvoiceRepository) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Invoice, error) {
+	return r.find(ctx, "id = ?", ID)
+}
+
+func (r *InvoiceRepository) FindByPeriod(ctx context.Context, walletID uuid.UUID, periodStart time.Time) (*entities.Invoice, error) {
+	return r.find(ctx, "wallet_id = ? AND period_start = ?", walletID, periodStart)
+}
+
+func (r *InvoiceRepository) find(ctx context.Context, query string, args ...interface{}) (*entities.Invoice, error) {
+	var model types.Invoice
+	err := r.Db.WithContext(ctx).Preload("Lines", orderedLines).Where(query, args...).First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return mapper.InvoiceStorage2Domain(model), nil
+}
+
+func (r *InvoiceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Invoice, error) {
+	var models []types.Invoice
+	if err := r.Db.WithContext(ctx).Order("period_start DESC").Find(&models, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	invoices := make([]*entities.Invoice, len(models))
+	for i, model := range models {
+		invoices[i] = mapper.InvoiceStorage2Domain(model)
+	}
+	return invoices, nil
+}
+
+func (r *InvoiceRepository) NextSequence(ctx context.Context) (int64, error) {
+	var value int64
+	err := r.Db.WithContext(ctx).Raw(
+		"INSERT INTO invoice_counters (name, value) VALUES (?, 1) "+
+			"ON CONFLICT (name) DO UPDATE SET value = invoice_counters.value + 1 RETURNING value",
+		invoiceSequence,
+	).Scan(&value).Error
+	return value, err
+}
+
+func (r *InvoiceRepository) WithTx(tx *gorm.DB) entities.InvoiceRepo {
+	return NewInvoiceRepository(tx)
+}
+
+func orderedLines(db *gorm.DB) *gorm.DB {
+	return db.Order("day, type, category, message_type")
+}
@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func APIKeyStorage2Domain(k types.APIKey) *entities.APIKey {
+	return &entities.APIKey{
+		ID:        k.ID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Hash:      k.Hash,
+		Role:      entities.Role(k.Role),
+		UserID:    k.UserID,
+		RevokedAt: k.RevokedAt,
+		CreatedAt: k.CreatedAt,
+		UpdatedAt: k.UpdatedAt,
+	}
+}
+
+func APIKeyDomain2Storage(k *entities.APIKey) types.APIKey {
+	return types.APIKey{
+		Base:      types.Base{ID: k.ID, CreatedAt: k.CreatedAt, UpdatedAt: k.UpdatedAt},
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Hash:      k.Hash,
+		Role:      string(k.Role),
+		UserID:    k.UserID,
+		RevokedAt: k.RevokedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func AuditEventStorage2Domain(e types.AuditEvent) *entities.AuditEvent {
+	return &entities.AuditEvent{
+		ID:            e.ID,
+		WalletID:      e.WalletID,
+		Sequence:      e.Sequence,
+		Action:        entities.AuditAction(e.Action),
+		Category:      entities.TransactionCategory(e.Category),
+		TransactionID: e.TransactionID,
+		Actor:         e.Actor,
+		Source:        entities.AuditSource(e.Source),
+		TraceID:       e.TraceID,
+		PayloadHash:   e.PayloadHash,
+		Currency:      e.Currency,
+		BalanceBefore: orZero(e.BalanceBefore),
+		BalanceAfter:  orZero(e.BalanceAfter),
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     e.CreatedAt,
+	}
+}
+
+func AuditEventDomain2Storage(e *entities.AuditEvent) types.AuditEvent {
+	return types.AuditEvent{
+		ID:            e.ID,
+		WalletID:      e.WalletID,
+		Sequence:      e.Sequence,
+		Action:        string(e.Action),
+		Category:      string(e.Category),
+		TransactionID: e.TransactionID,
+		Actor:         e.Actor,
+		Source:        string(e.Source),
+		TraceID:       e.TraceID,
+		PayloadHash:   e.PayloadHash,
+		Currency:      e.Currency,
+		BalanceBefore: types.NewBigInt(e.BalanceBefore),
+		BalanceAfter:  types.NewBigInt(e.BalanceAfter),
+		PrevHash:      e.PrevHash,
+		Hash:          e.Hash,
+		CreatedAt:     e.CreatedAt,
+	}
+}
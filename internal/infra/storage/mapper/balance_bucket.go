@@ -0,0 +1,75 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/storage/types"
+)
+
+func BucketStorage2Domain(b types.BalanceBucket, currency string) (*entities.BalanceBucket, error) {
+	granted, err := valueobjects.NewMoney(orZero(b.Granted), currency)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := valueobjects.NewMoney(orZero(b.Remaining), currency)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.BalanceBucket{
+		ID:        b.ID,
+		WalletID:  b.WalletID,
+		Kind:      entities.BucketKind(b.Kind),
+		Granted:   granted,
+		Remaining: remaining,
+		Priority:  b.Priority,
+		ExpiresAt: b.ExpiresAt,
+		Reason:    b.Reason,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+	}, nil
+}
+
+func BucketDomain2Storage(b *entities.BalanceBucket) types.BalanceBucket {
+	return types.BalanceBucket{
+		Base:      types.Base{ID: b.ID, CreatedAt: b.CreatedAt, UpdatedAt: b.UpdatedAt},
+		WalletID:  b.WalletID,
+		Kind:      string(b.Kind),
+		Granted:   types.NewBigInt(b.Granted.Amount()),
+		Remaining: types.NewBigInt(b.Remaining.Amount()),
+		Priority:  b.Priority,
+		ExpiresAt: b.ExpiresAt,
+		Reason:    b.Reason,
+	}
+}
+
+func FundingStorage2Domain(funding []types.TransactionFunding, currency string) ([]entities.Funding, error) {
+	if len(funding) == 0 {
+		return nil, nil
+	}
+	res := make([]entities.Funding, len(funding))
+	for i, f := range funding {
+		amount, err := valueobjects.NewMoney(orZero(f.Amount), currency)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = entities.Funding{
+			BucketID: f.BucketID,
+			Kind:     entities.BucketKind(f.Kind),
+			Amount:   amount,
+		}
+	}
+	return res, nil
+}
+
+func FundingDomain2Storage(tx *entities.Transaction) []types.TransactionFunding {
+	res := make([]types.TransactionFunding, len(tx.Funding))
+	for i, f := range tx.Funding {
+		res[i] = types.TransactionFunding{
+			TransactionID: tx.ID,
+			BucketID:      f.BucketID,
+			Kind:          string(f.Kind),
+			Amount:        types.NewBigInt(f.Amount.Amount()),
+		}
+	}
+	return res
+}
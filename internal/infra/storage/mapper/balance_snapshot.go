@@ -0,0 +1,27 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func BalanceSnapshotStorage2Domain(s types.BalanceSnapshot) *entities.BalanceSnapshot {
+	return &entities.BalanceSnapshot{
+		ID:        s.ID,
+		WalletID:  s.WalletID,
+		Currency:  s.Currency,
+		Balance:   orZero(s.Balance),
+		TakenAt:   s.TakenAt,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+func BalanceSnapshotDomain2Storage(s *entities.BalanceSnapshot) types.BalanceSnapshot {
+	return types.BalanceSnapshot{
+		Base:     types.Base{ID: s.ID, CreatedAt: s.CreatedAt, UpdatedAt: s.CreatedAt},
+		WalletID: s.WalletID,
+		Currency: s.Currency,
+		Balance:  types.NewBigInt(s.Balance),
+		TakenAt:  s.TakenAt,
+	}
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/storage/types"
+)
+
+func BalanceThresholdStorage2Domain(t types.BalanceThreshold) (*entities.BalanceThreshold, error) {
+	amount, err := moneyStorage2Domain(t.Amount)
+	if err != nil {
+		return nil, err
+	}
+	hysteresis, err := valueobjects.NewMoney(orZero(t.Hysteresis), t.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.BalanceThreshold{
+		ID:         t.ID,
+		WalletID:   t.WalletID,
+		Amount:     amount,
+		Hysteresis: hysteresis,
+		Armed:      t.Armed,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}, nil
+}
+
+func BalanceThresholdDomain2Storage(t *entities.BalanceThreshold) types.BalanceThreshold {
+	return types.BalanceThreshold{
+		Base:       types.Base{ID: t.ID, CreatedAt: t.CreatedAt, UpdatedAt: t.UpdatedAt},
+		WalletID:   t.WalletID,
+		Amount:     moneyDomain2Storage(t.Amount),
+		Hysteresis: types.NewBigInt(t.Hysteresis.Amount()),
+		Armed:      t.Armed,
+	}
+}
@@ -0,0 +1,70 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/storage/types"
+	"fmt"
+	"math/big"
+)
+
+func FXRateStorage2Domain(r types.FXRate) (*entities.FXRate, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse fx rate: %s", r.Rate)
+	}
+	return &entities.FXRate{
+		ID:         r.ID,
+		Base:       r.BaseCurrency,
+		Quote:      r.QuoteCurrency,
+		Rate:       rate,
+		ValidFrom:  r.ValidFrom,
+		ValidUntil: r.ValidUntil,
+		Source:     entities.FXRateSource(r.Source),
+		CreatedAt:  r.CreatedAt,
+	}, nil
+}
+
+func FXRateDomain2Storage(r *entities.FXRate) types.FXRate {
+	return types.FXRate{
+		Base:          types.Base{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.CreatedAt},
+		BaseCurrency:  r.Base,
+		QuoteCurrency: r.Quote,
+		Rate:          r.Rate.RatString(),
+		ValidFrom:     r.ValidFrom,
+		ValidUntil:    r.ValidUntil,
+		Source:        string(r.Source),
+	}
+}
+
+func SubBalanceStorage2Domain(s types.SubBalance) (*entities.SubBalance, error) {
+	balance, err := valueobjects.NewMoney(orZero(s.Balance), s.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.SubBalance{
+		ID:        s.ID,
+		WalletID:  s.WalletID,
+		Currency:  s.Currency,
+		Balance:   balance,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}, nil
+}
+
+func SubBalanceDomain2Storage(s *entities.SubBalance) types.SubBalance {
+	return types.SubBalance{
+		Base:     types.Base{ID: s.ID, CreatedAt: s.CreatedAt, UpdatedAt: s.UpdatedAt},
+		WalletID: s.WalletID,
+		Currency: s.Currency,
+		Balance:  types.NewBigInt(s.Balance.Amount()),
+	}
+}
+
+// sourceAmountStorage2Domain leaves the money unset for credits that were not converted
+func sourceAmountStorage2Domain(amount types.BigInt, currency string) (valueobjects.Money, error) {
+	if currency == "" {
+		return valueobjects.Money{}, nil
+	}
+	return valueobjects.NewMoney(orZero(amount), currency)
+}
@@ -0,0 +1,46 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func IdempotencyStorage2Domain(r types.IdempotencyRecord) *entities.IdempotencyRecord {
+	record := &entities.IdempotencyRecord{
+		ID:           r.ID,
+		Key:          r.Key,
+		Route:        r.Route,
+		Caller:       r.Caller,
+		RequestHash:  r.RequestHash,
+		ClaimedUntil: r.ClaimedUntil,
+		ExpiresAt:    r.ExpiresAt,
+		CreatedAt:    r.CreatedAt,
+	}
+	if r.StatusCode != 0 {
+		record.Response = &entities.IdempotentResponse{
+			StatusCode:  r.StatusCode,
+			ContentType: r.ContentType,
+			Body:        r.Body,
+		}
+	}
+	return record
+}
+
+func IdempotencyDomain2Storage(r *entities.IdempotencyRecord) types.IdempotencyRecord {
+	model := types.IdempotencyRecord{
+		ID:           r.ID,
+		Key:          r.Key,
+		Route:        r.Route,
+		Caller:       r.Caller,
+		RequestHash:  r.RequestHash,
+		ClaimedUntil: r.ClaimedUntil,
+		ExpiresAt:    r.ExpiresAt,
+		CreatedAt:    r.CreatedAt,
+	}
+	if r.Response != nil {
+		model.StatusCode = r.Response.StatusCode
+		model.ContentType = r.Response.ContentType
+		model.Body = r.Response.Body
+	}
+	return model
+}
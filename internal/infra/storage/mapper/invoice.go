@@ -0,0 +1,77 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+)
+
+func InvoiceStorage2Domain(i types.Invoice) *entities.Invoice {
+	lines := make([]entities.InvoiceLine, len(i.Lines))
+	for n, l := range i.Lines {
+		lines[n] = entities.InvoiceLine{
+			Day:         l.Day,
+			Type:        entities.TransactionType(l.Type),
+			Category:    entities.TransactionCategory(l.Category),
+			MessageType: entities.MessageType(l.MessageType),
+			Count:       l.Count,
+			Segments:    l.Segments,
+			Amount:      orZero(l.Amount),
+			Tax:         orZero(l.Tax),
+		}
+	}
+	return &entities.Invoice{
+		ID:             i.ID,
+		Number:         i.Number,
+		Sequence:       i.Sequence,
+		UserID:         i.UserID,
+		WalletID:       i.WalletID,
+		Currency:       i.Currency,
+		PeriodStart:    i.PeriodStart,
+		PeriodEnd:      i.PeriodEnd,
+		OpeningBalance: orZero(i.OpeningBalance),
+		Credits:        orZero(i.Credits),
+		Refunds:        orZero(i.Refunds),
+		Debits:         orZero(i.Debits),
+		Tax:            orZero(i.Tax),
+		ClosingBalance: orZero(i.ClosingBalance),
+		Lines:          lines,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+func InvoiceDomain2Storage(i *entities.Invoice) types.Invoice {
+	lines := make([]types.InvoiceLine, len(i.Lines))
+	for n, l := range i.Lines {
+		lines[n] = types.InvoiceLine{
+			Base:        types.Base{ID: uuid.New(), CreatedAt: i.CreatedAt, UpdatedAt: i.CreatedAt},
+			InvoiceID:   i.ID,
+			Day:         l.Day,
+			Type:        string(l.Type),
+			Category:    string(l.Category),
+			MessageType: string(l.MessageType),
+			Count:       l.Count,
+			Segments:    l.Segments,
+			Amount:      types.NewBigInt(l.Amount),
+			Tax:         types.NewBigInt(l.Tax),
+		}
+	}
+	return types.Invoice{
+		Base:           types.Base{ID: i.ID, CreatedAt: i.CreatedAt, UpdatedAt: i.CreatedAt},
+		Number:         i.Number,
+		Sequence:       i.Sequence,
+		UserID:         i.UserID,
+		WalletID:       i.WalletID,
+		Currency:       i.Currency,
+		PeriodStart:    i.PeriodStart,
+		PeriodEnd:      i.PeriodEnd,
+		OpeningBalance: types.NewBigInt(i.OpeningBalance),
+		Credits:        types.NewBigInt(i.Credits),
+		Refunds:        types.NewBigInt(i.Refunds),
+		Debits:         types.NewBigInt(i.Debits),
+		Tax:            types.NewBigInt(i.Tax),
+		ClosingBalance: types.NewBigInt(i.ClosingBalance),
+		Lines:          lines,
+	}
+}
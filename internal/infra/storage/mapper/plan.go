@@ -0,0 +1,71 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+	"sort"
+)
+
+func PlanStorage2Domain(p types.Plan) *entities.Plan {
+	sort.Slice(p.Tiers, func(i, j int) bool { return p.Tiers[i].Position < p.Tiers[j].Position })
+
+	tiers := make([]entities.PlanTier, len(p.Tiers))
+	for i, t := range p.Tiers {
+		tiers[i] = entities.PlanTier{
+			UpTo:        t.UpTo,
+			DiscountBPS: t.DiscountBPS,
+		}
+	}
+	return &entities.Plan{
+		ID:        p.ID,
+		Code:      p.Code,
+		Name:      p.Name,
+		Tiers:     tiers,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func PlanDomain2Storage(p *entities.Plan) types.Plan {
+	return types.Plan{
+		Base:  types.Base{ID: p.ID, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt},
+		Code:  p.Code,
+		Name:  p.Name,
+		Tiers: PlanTiersDomain2Storage(p),
+	}
+}
+
+func PlanTiersDomain2Storage(p *entities.Plan) []types.PlanTier {
+	tiers := make([]types.PlanTier, len(p.Tiers))
+	for i, t := range p.Tiers {
+		tiers[i] = types.PlanTier{
+			PlanID:      p.ID,
+			Position:    i,
+			UpTo:        t.UpTo,
+			DiscountBPS: t.DiscountBPS,
+		}
+	}
+	return tiers
+}
+
+func PlanAssignmentStorage2Domain(a types.PlanAssignment) *entities.PlanAssignment {
+	return &entities.PlanAssignment{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		PlanID:    a.PlanID,
+		PlanCode:  a.PlanCode,
+		StartedAt: a.StartedAt,
+		EndedAt:   a.EndedAt,
+	}
+}
+
+func PlanAssignmentDomain2Storage(a *entities.PlanAssignment) types.PlanAssignment {
+	return types.PlanAssignment{
+		Base:      types.Base{ID: a.ID},
+		UserID:    a.UserID,
+		PlanID:    a.PlanID,
+		PlanCode:  a.PlanCode,
+		StartedAt: a.StartedAt,
+		EndedAt:   a.EndedAt,
+	}
+}
@@ -0,0 +1,30 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func SigningKeyStorage2Domain(k types.SigningKey) *entities.SigningKey {
+	return &entities.SigningKey{
+		ID:        k.ID,
+		Client:    k.Client,
+		Secret:    k.Secret,
+		Role:      entities.Role(k.Role),
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+		CreatedAt: k.CreatedAt,
+		UpdatedAt: k.UpdatedAt,
+	}
+}
+
+func SigningKeyDomain2Storage(k *entities.SigningKey) types.SigningKey {
+	return types.SigningKey{
+		Base:      types.Base{ID: k.ID, CreatedAt: k.CreatedAt, UpdatedAt: k.UpdatedAt},
+		Client:    k.Client,
+		Secret:    k.Secret,
+		Role:      string(k.Role),
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+	}
+}
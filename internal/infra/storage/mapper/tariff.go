@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+	"math/big"
+)
+
+func TariffStorage2Domain(t types.Tariff) *entities.Tariff {
+	rates := make([]entities.TariffRate, len(t.Rates))
+	for i, r := range t.Rates {
+		rates[i] = entities.TariffRate{
+			Prefix:          r.Prefix,
+			Operator:        r.Operator,
+			MessageType:     entities.MessageType(r.MessageType),
+			Plan:            r.Plan,
+			PricePerSegment: new(big.Int).Set(orZero(r.PricePerSegment)),
+		}
+	}
+	return &entities.Tariff{
+		ID:        t.ID,
+		Version:   t.Version,
+		Name:      t.Name,
+		Currency:  t.Currency,
+		Active:    t.Active,
+		Rates:     rates,
+		CreatedAt: t.CreatedAt,
+	}
+}
+
+func TariffDomain2Storage(t *entities.Tariff) types.Tariff {
+	rates := make([]types.TariffRate, len(t.Rates))
+	for i, r := range t.Rates {
+		rates[i] = types.TariffRate{
+			TariffID:        t.ID,
+			Prefix:          r.Prefix,
+			Operator:        r.Operator,
+			MessageType:     string(r.MessageType),
+			Plan:            r.Plan,
+			PricePerSegment: types.NewBigInt(r.PricePerSegment),
+		}
+	}
+	return types.Tariff{
+		Base:     types.Base{ID: t.ID, CreatedAt: t.CreatedAt},
+		Version:  t.Version,
+		Name:     t.Name,
+		Currency: t.Currency,
+		Active:   t.Active,
+		Rates:    rates,
+	}
+}
@@ -0,0 +1,33 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func TaxRuleStorage2Domain(r types.TaxRule) *entities.TaxRule {
+	return &entities.TaxRule{
+		ID:           r.ID,
+		Name:         r.Name,
+		CustomerType: entities.CustomerType(r.CustomerType),
+		Base:         entities.TaxBase(r.TaxBase),
+		RateBPS:      r.RateBPS,
+		Inclusive:    r.Inclusive,
+		ValidFrom:    r.ValidFrom,
+		ValidUntil:   r.ValidUntil,
+		CreatedAt:    r.CreatedAt,
+	}
+}
+
+func TaxRuleDomain2Storage(r *entities.TaxRule) types.TaxRule {
+	return types.TaxRule{
+		Base:         types.Base{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.CreatedAt},
+		Name:         r.Name,
+		CustomerType: string(r.CustomerType),
+		TaxBase:      string(r.Base),
+		RateBPS:      r.RateBPS,
+		Inclusive:    r.Inclusive,
+		ValidFrom:    r.ValidFrom,
+		ValidUntil:   r.ValidUntil,
+	}
+}
@@ -0,0 +1,73 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/storage/types"
+)
+
+func TopUpRuleStorage2Domain(r types.TopUpRule) (*entities.TopUpRule, error) {
+	amount, err := moneyStorage2Domain(r.Amount)
+	if err != nil {
+		return nil, err
+	}
+	threshold, err := valueobjects.NewMoney(orZero(r.Threshold), r.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.TopUpRule{
+		ID:              r.ID,
+		WalletID:        r.WalletID,
+		UserID:          r.UserID,
+		Threshold:       threshold,
+		Amount:          amount,
+		MaxPerDay:       r.MaxPerDay,
+		FundingSourceID: r.FundingSourceID,
+		Enabled:         r.Enabled,
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}, nil
+}
+
+func TopUpRuleDomain2Storage(r *entities.TopUpRule) types.TopUpRule {
+	return types.TopUpRule{
+		Base:            types.Base{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt},
+		WalletID:        r.WalletID,
+		UserID:          r.UserID,
+		Threshold:       types.NewBigInt(r.Threshold.Amount()),
+		Amount:          moneyDomain2Storage(r.Amount),
+		MaxPerDay:       r.MaxPerDay,
+		FundingSourceID: r.FundingSourceID,
+		Enabled:         r.Enabled,
+	}
+}
+
+func TopUpRequestStorage2Domain(r types.TopUpRequest) (*entities.TopUpRequest, error) {
+	amount, err := moneyStorage2Domain(r.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.TopUpRequest{
+		ID:              r.ID,
+		RuleID:          r.RuleID,
+		WalletID:        r.WalletID,
+		UserID:          r.UserID,
+		Amount:          amount,
+		FundingSourceID: r.FundingSourceID,
+		Status:          entities.TopUpRequestStatus(r.Status),
+		CreatedAt:       r.CreatedAt,
+		UpdatedAt:       r.UpdatedAt,
+	}, nil
+}
+
+func TopUpRequestDomain2Storage(r *entities.TopUpRequest) types.TopUpRequest {
+	return types.TopUpRequest{
+		Base:            types.Base{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.UpdatedAt},
+		RuleID:          r.RuleID,
+		WalletID:        r.WalletID,
+		UserID:          r.UserID,
+		Amount:          moneyDomain2Storage(r.Amount),
+		FundingSourceID: r.FundingSourceID,
+		Status:          string(r.Status),
+	}
+}
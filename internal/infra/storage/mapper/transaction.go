@@ -4,6 +4,8 @@ import (
 	"finance/internal/domain/entities"
 	"finance/internal/domain/valueobjects"
 	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
 )
 
 // TODO: fix BASE
@@ -27,27 +29,92 @@ func TxStorage2Domain(tx types.Transaction) (*entities.Transaction, error) {
 	if err != nil {
 		return nil, err
 	}
+	funding, err := FundingStorage2Domain(tx.Funding, tx.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	net, tax, gross, err := taxStorage2Domain(tx)
+	if err != nil {
+		return nil, err
+	}
+	source, err := sourceAmountStorage2Domain(tx.SourceAmount, tx.SourceCurrency)
+	if err != nil {
+		return nil, err
+	}
 	return &entities.Transaction{
-		ID:        tx.ID,
-		WalletID:  tx.WalletID,
-		UserID:    tx.UserID,
-		Amount:    amount,
-		Type:      entities.TransactionType(tx.Type),
-		Status:    entities.TransactionStatus(tx.Status),
-		SMSID:     tx.SMSID,
-		CreatedAt: tx.CreatedAt,
-		UpdatedAt: tx.UpdatedAt,
+		ID:            tx.ID,
+		WalletID:      tx.WalletID,
+		UserID:        tx.UserID,
+		Amount:        amount,
+		Type:          entities.TransactionType(tx.Type),
+		Status:        entities.TransactionStatus(tx.Status),
+		SMSID:         tx.SMSID,
+		Category:      entities.TransactionCategory(tx.Category),
+		ReferenceID:   tx.ReferenceID,
+		TariffVersion: tx.TariffVersion,
+		MessageType:   entities.MessageType(tx.MessageType),
+		Segments:      tx.Segments,
+		PlanID:        tx.PlanID,
+		DiscountBPS:   tx.DiscountBPS,
+		SourceAmount:  source,
+		FXRate:        tx.FXRate,
+		FXRateID:      tx.FXRateID,
+		NetAmount:     net,
+		TaxAmount:     tax,
+		GrossAmount:   gross,
+		TaxRuleID:     tx.TaxRuleID,
+		TaxRateBPS:    tx.TaxRateBPS,
+		Funding:       funding,
+		CreatedAt:     tx.CreatedAt,
+		UpdatedAt:     tx.UpdatedAt,
 	}, nil
 }
 
 func TxDomain2Storage(tx *entities.Transaction) types.Transaction {
-	return types.Transaction{
-		Base:     types.Base{ID: tx.ID, CreatedAt: tx.CreatedAt, UpdatedAt: tx.UpdatedAt},
-		WalletID: tx.WalletID,
-		UserID:   tx.UserID,
-		Amount:   moneyDomain2Storage(tx.Amount),
-		Type:     string(tx.Type),
-		Status:   string(tx.Status),
-		SMSID:    tx.SMSID,
+	model := types.Transaction{
+		Base:           types.Base{ID: tx.ID, CreatedAt: tx.CreatedAt, UpdatedAt: tx.UpdatedAt},
+		WalletID:       tx.WalletID,
+		UserID:         tx.UserID,
+		Amount:         moneyDomain2Storage(tx.Amount),
+		Type:           string(tx.Type),
+		Status:         string(tx.Status),
+		SMSID:          tx.SMSID,
+		Category:       string(tx.Category),
+		ReferenceID:    tx.ReferenceID,
+		TariffVersion:  tx.TariffVersion,
+		MessageType:    string(tx.MessageType),
+		Segments:       tx.Segments,
+		PlanID:         tx.PlanID,
+		DiscountBPS:    tx.DiscountBPS,
+		SourceAmount:   types.NewBigInt(tx.SourceAmount.Amount()),
+		SourceCurrency: tx.SourceAmount.Currency(),
+		FXRate:         tx.FXRate,
+		FXRateID:       tx.FXRateID,
+		TaxRuleID:      tx.TaxRuleID,
+		TaxRateBPS:     tx.TaxRateBPS,
+	}
+	model.NetAmount, model.TaxAmount, model.GrossAmount = taxDomain2Storage(tx)
+	return model
+}
+
+// taxDomain2Storage stores untaxed transactions with net and gross equal to the amount
+func taxDomain2Storage(tx *entities.Transaction) (net, tax, gross types.BigInt) {
+	if !tx.IsTaxed() {
+		return types.NewBigInt(tx.Amount.Amount()), types.NewBigInt(nil), types.NewBigInt(tx.Amount.Amount())
+	}
+	return types.NewBigInt(tx.NetAmount.Amount()), types.NewBigInt(tx.TaxAmount.Amount()), types.NewBigInt(tx.GrossAmount.Amount())
+}
+
+func taxStorage2Domain(tx types.Transaction) (net, tax, gross valueobjects.Money, err error) {
+	if tx.TaxRuleID == uuid.Nil {
+		return
+	}
+	if net, err = valueobjects.NewMoney(orZero(tx.NetAmount), tx.Amount.Currency); err != nil {
+		return
+	}
+	if tax, err = valueobjects.NewMoney(orZero(tx.TaxAmount), tx.Amount.Currency); err != nil {
+		return
 	}
+	gross, err = valueobjects.NewMoney(orZero(tx.GrossAmount), tx.Amount.Currency)
+	return
 }
@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func TransferStorage2Domain(t types.Transfer) (*entities.Transfer, error) {
+	amount, err := moneyStorage2Domain(t.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.Transfer{
+		ID:                  t.ID,
+		FromWalletID:        t.FromWalletID,
+		ToWalletID:          t.ToWalletID,
+		FromUserID:          t.FromUserID,
+		ToUserID:            t.ToUserID,
+		Amount:              amount,
+		IdempotencyKey:      t.IdempotencyKey,
+		DebitTransactionID:  t.DebitTransactionID,
+		CreditTransactionID: t.CreditTransactionID,
+		CreatedAt:           t.CreatedAt,
+	}, nil
+}
+
+func TransferDomain2Storage(t *entities.Transfer) types.Transfer {
+	return types.Transfer{
+		Base:                types.Base{ID: t.ID, CreatedAt: t.CreatedAt, UpdatedAt: t.CreatedAt},
+		FromWalletID:        t.FromWalletID,
+		ToWalletID:          t.ToWalletID,
+		FromUserID:          t.FromUserID,
+		ToUserID:            t.ToUserID,
+		Amount:              moneyDomain2Storage(t.Amount),
+		IdempotencyKey:      t.IdempotencyKey,
+		DebitTransactionID:  t.DebitTransactionID,
+		CreditTransactionID: t.CreditTransactionID,
+	}
+}
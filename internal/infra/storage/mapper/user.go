@@ -12,21 +12,23 @@ func UserDomain2Storage(u *entities.User) types.User {
 			CreatedAt: u.CreatedAt,
 			UpdatedAt: u.UpdatedAt,
 		},
-		Name:     u.Name,
-		LastName: u.LastName,
-		Phone:    u.Phone,
-		WalletID: u.WalletID,
+		Name:         u.Name,
+		LastName:     u.LastName,
+		Phone:        u.Phone,
+		WalletID:     u.WalletID,
+		CustomerType: string(u.CustomerType),
 	}
 }
 
 func UserStorage2Domain(u types.User) *entities.User {
 	return &entities.User{
-		ID:        u.ID,
-		Name:      u.Name,
-		LastName:  u.LastName,
-		Phone:     u.Phone,
-		WalletID:  u.WalletID,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:           u.ID,
+		Name:         u.Name,
+		LastName:     u.LastName,
+		Phone:        u.Phone,
+		WalletID:     u.WalletID,
+		CustomerType: entities.CustomerType(u.CustomerType),
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
 	}
 }
@@ -0,0 +1,49 @@
+package mapper
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+)
+
+func VoucherStorage2Domain(v types.Voucher) (*entities.Voucher, error) {
+	amount, err := moneyStorage2Domain(v.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &entities.Voucher{
+		ID:             v.ID,
+		Code:           v.Code,
+		Amount:         amount,
+		MaxRedemptions: v.MaxRedemptions,
+		PerUserLimit:   v.PerUserLimit,
+		Redeemed:       v.Redeemed,
+		ValidFrom:      v.ValidFrom,
+		ValidUntil:     v.ValidUntil,
+		Campaign:       v.Campaign,
+		CreatedAt:      v.CreatedAt,
+		UpdatedAt:      v.UpdatedAt,
+	}, nil
+}
+
+func VoucherDomain2Storage(v *entities.Voucher) types.Voucher {
+	return types.Voucher{
+		Base:           types.Base{ID: v.ID, CreatedAt: v.CreatedAt, UpdatedAt: v.UpdatedAt},
+		Code:           v.Code,
+		Amount:         moneyDomain2Storage(v.Amount),
+		MaxRedemptions: v.MaxRedemptions,
+		PerUserLimit:   v.PerUserLimit,
+		Redeemed:       v.Redeemed,
+		ValidFrom:      v.ValidFrom,
+		ValidUntil:     v.ValidUntil,
+		Campaign:       v.Campaign,
+	}
+}
+
+func VoucherRedemptionDomain2Storage(r *entities.VoucherRedemption) types.VoucherRedemption {
+	return types.VoucherRedemption{
+		Base:          types.Base{ID: r.ID, CreatedAt: r.CreatedAt, UpdatedAt: r.CreatedAt},
+		VoucherID:     r.VoucherID,
+		UserID:        r.UserID,
+		TransactionID: r.TransactionID,
+	}
+}
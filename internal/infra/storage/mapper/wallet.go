@@ -4,20 +4,49 @@ import (
 	"finance/internal/domain/entities"
 	"finance/internal/domain/valueobjects"
 	"finance/internal/infra/storage/types"
+	"math/big"
+
+	"github.com/google/uuid"
 )
 
 func WalletStorage2Domain(w types.Wallet) (*entities.Wallet, error) {
-	money, err := valueobjects.NewMoney(w.Balance.Int, w.Currency)
+	money, err := valueobjects.NewSignedMoney(w.Balance.Int, w.Currency)
+	if err != nil {
+		return nil, err
+	}
+	creditLimit, err := valueobjects.NewMoney(orZero(w.CreditLimit), w.Currency)
 	if err != nil {
 		return nil, err
 	}
+	buckets := make([]*entities.BalanceBucket, 0, len(w.Buckets))
+	for _, b := range w.Buckets {
+		bucket, err := BucketStorage2Domain(b, w.Currency)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+	subBalances := make([]*entities.SubBalance, 0, len(w.SubBalances))
+	for _, sb := range w.SubBalances {
+		sub, err := SubBalanceStorage2Domain(sb)
+		if err != nil {
+			return nil, err
+		}
+		subBalances = append(subBalances, sub)
+	}
 	return &entities.Wallet{
-		ID:        w.ID,
-		UserID:    w.UserID,
-		Balance:   money,
-		Currency:  w.Currency,
-		CreatedAt: w.CreatedAt,
-		UpdatedAt: w.UpdatedAt,
+		ID:          w.ID,
+		UserID:      w.UserID,
+		Balance:     money,
+		CreditLimit: creditLimit,
+		Currency:    w.Currency,
+		Limits:      limitsStorage2Domain(w.Limits),
+		Buckets:     buckets,
+		ParentID:    parentIDStorage2Domain(w.ParentID),
+		ParentQuota: nilIfZero(w.ParentQuota),
+		SubBalances: subBalances,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
 	}, nil
 }
 
@@ -28,8 +57,65 @@ func WalletDomain2Storage(w *entities.Wallet) types.Wallet {
 			CreatedAt: w.CreatedAt,
 			UpdatedAt: w.UpdatedAt,
 		},
-		UserID:   w.UserID,
-		Balance:  types.NewBigInt(w.Balance.Amount()),
-		Currency: w.Balance.Currency(),
+		UserID:      w.UserID,
+		Balance:     types.NewBigInt(w.Balance.Amount()),
+		CreditLimit: creditLimitDomain2Storage(w.CreditLimit),
+		Currency:    w.Balance.Currency(),
+		Limits:      limitsDomain2Storage(w.Limits),
+		ParentID:    parentIDDomain2Storage(w.ParentID),
+		ParentQuota: types.NewBigInt(w.ParentQuota),
+	}
+}
+
+func parentIDStorage2Domain(ID *uuid.UUID) uuid.UUID {
+	if ID == nil {
+		return uuid.Nil
+	}
+	return *ID
+}
+
+func parentIDDomain2Storage(ID uuid.UUID) *uuid.UUID {
+	if ID == uuid.Nil {
+		return nil
+	}
+	return &ID
+}
+
+func creditLimitDomain2Storage(limit valueobjects.Money) types.BigInt {
+	if limit.Currency() == "" {
+		return types.NewBigInt(nil)
+	}
+	return types.NewBigInt(limit.Amount())
+}
+
+func orZero(v types.BigInt) *big.Int {
+	if v.Int == nil {
+		return big.NewInt(0)
+	}
+	return v.Int
+}
+
+func limitsStorage2Domain(l types.SpendingLimits) entities.SpendingLimits {
+	return entities.SpendingLimits{
+		MaxDebit:        nilIfZero(l.MaxDebit),
+		DailyAmount:     nilIfZero(l.DailyAmount),
+		MonthlyAmount:   nilIfZero(l.MonthlyAmount),
+		DebitsPerMinute: l.DebitsPerMinute,
+	}
+}
+
+func limitsDomain2Storage(l entities.SpendingLimits) types.SpendingLimits {
+	return types.SpendingLimits{
+		MaxDebit:        types.NewBigInt(l.MaxDebit),
+		DailyAmount:     types.NewBigInt(l.DailyAmount),
+		MonthlyAmount:   types.NewBigInt(l.MonthlyAmount),
+		DebitsPerMinute: l.DebitsPerMinute,
+	}
+}
+
+func nilIfZero(v types.BigInt) *big.Int {
+	if v.Int == nil || v.Sign() == 0 {
+		return nil
 	}
+	return new(big.Int).Set(v.Int)
 }
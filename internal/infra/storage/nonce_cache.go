@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// nonceSweepEvery is how many nonces an instance remembers between deletes of the expired ones
+const nonceSweepEvery = 1000
+
+// NonceCache shares the nonces of signed requests between api instances through postgres, the
+// key is unique so two instances can not both accept the same nonce
+type NonceCache struct {
+	Db         *gorm.DB
+	remembered atomic.Int64
+}
+
+func NewNonceCache(db *gorm.DB) entities.NonceCache {
+	return &NonceCache{
+		Db: db,
+	}
+}
+
+func (c *NonceCache) Remember(ctx context.Context, key string, until time.Time) (bool, error) {
+	now := time.Now()
+	if c.remembered.Add(1)%nonceSweepEvery == 0 {
+		if err := c.Db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&types.SignatureNonce{}).Error; err != nil {
+			return false, err
+		}
+	}
+
+	// an expired nonce is taken over, a live one leaves the row as is and nothing is affected
+	result := c.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "signature_nonces.expires_at <= ?", Vars: []interface{}{now}}}},
+	}).Create(&types.SignatureNonce{Key: key, ExpiresAt: until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlanRepository struct {
+	Db *gorm.DB
+}
+
+func NewPlanRepository(db *gorm.DB) entities.PlanRepo {
+	return &PlanRepository{
+		Db: db,
+	}
+}
+
+func (r *PlanRepository) Create(ctx context.Context, plan *entities.Plan) error {
+	model := mapper.PlanDomain2Storage(plan)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *PlanRepository) Update(ctx context.Context, plan *entities.Plan) error {
+	res := r.Db.WithContext(ctx).Model(&types.Plan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{
+		"name":       plan.Name,
+		"updated_at": plan.UpdatedAt,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return entities.ErrPlanNotFound
+	}
+
+	if err := r.Db.WithContext(ctx).Delete(&types.PlanTier{}, "plan_id = ?", plan.ID).Error; err != nil {
+		return err
+	}
+	tiers := mapper.PlanTiersDomain2Storage(plan)
+	if len(tiers) == 0 {
+		return nil
+	}
+	return r.Db.WithContext(ctx).Create(&tiers).Error
+}
+
+func (r *PlanRepository) Delete(ctx context.Context, ID uuid.UUID) error {
+	if err := r.Db.WithContext(ctx).Delete(&types.PlanTier{}, "plan_id = ?", ID).Error; err != nil {
+		return err
+	}
+	res := r.Db.WithContext(ctx).Delete(&types.Plan{}, "id = ?", ID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return entities.ErrPlanNotFound
+	}
+	return nil
+}
+
+func (r *PlanRepository) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Plan, error) {
+	var model types.Plan
+	if err := r.Db.WithContext(ctx).Preload("Tiers").First(&model, "id = ?", ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return mapper.PlanStorage2Domain(model), nil
+}
+
+func (r *PlanRepository) List(ctx context.Context) ([]*entities.Plan, error) {
+	var models []types.Plan
+	if err := r.Db.WithContext(ctx).Preload("Tiers").Order("code").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	plans := make([]*entities.Plan, len(models))
+	for i, model := range models {
+		plans[i] = mapper.PlanStorage2Domain(model)
+	}
+	return plans, nil
+}
+
+func (r *PlanRepository) CountActiveAssignments(ctx context.Context, planID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&types.PlanAssignment{}).
+		Where("plan_id = ? AND ended_at IS NULL", planID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *PlanRepository) Assign(ctx context.Context, assignment *entities.PlanAssignment) error {
+	err := r.Db.WithContext(ctx).Model(&types.PlanAssignment{}).
+		Where("user_id = ? AND ended_at IS NULL", assignment.UserID).
+		Update("ended_at", assignment.StartedAt).Error
+	if err != nil {
+		return err
+	}
+
+	model := mapper.PlanAssignmentDomain2Storage(assignment)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *PlanRepository) FindActivePlan(ctx context.Context, userID uuid.UUID) (*entities.Plan, error) {
+	var assignment types.PlanAssignment
+	err := r.Db.WithContext(ctx).
+		Where("user_id = ? AND ended_at IS NULL", userID).
+		Order("started_at DESC").
+		First(&assignment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrPlanNotAssigned
+		}
+		return nil, err
+	}
+	return r.FindByID(ctx, assignment.PlanID)
+}
+
+func (r *PlanRepository) ListAssignments(ctx context.Context, userID uuid.UUID) ([]*entities.PlanAssignment, error) {
+	var models []types.PlanAssignment
+	if err := r.Db.WithContext(ctx).Order("started_at DESC").Find(&models, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	assignments := make([]*entities.PlanAssignment, len(models))
+	for i, model := range models {
+		assignments[i] = mapper.PlanAssignmentStorage2Domain(model)
+	}
+	return assignments, nil
+}
+
+func (r *PlanRepository) WithTx(tx *gorm.DB) entities.PlanRepo {
+	return NewPlanRepository(tx)
+}
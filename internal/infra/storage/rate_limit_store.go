@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RateLimitStore shares the buckets of every api instance through postgres, each take locks the
+// bucket row for a short transaction
+type RateLimitStore struct {
+	Db *gorm.DB
+}
+
+func NewRateLimitStore(db *gorm.DB) entities.RateLimitStore {
+	return &RateLimitStore{
+		Db: db,
+	}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit entities.RateLimit, now time.Time) (entities.RateLimitResult, error) {
+	var result entities.RateLimitResult
+	err := s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fresh := entities.NewTokenBucket(limit, now)
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&types.RateLimitBucket{Key: key, Tokens: fresh.Tokens, UpdatedAt: fresh.UpdatedAt}).Error
+		if err != nil {
+			return err
+		}
+
+		var model types.RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "key = ?", key).Error; err != nil {
+			return err
+		}
+		bucket := &entities.TokenBucket{Tokens: model.Tokens, UpdatedAt: model.UpdatedAt}
+		result = bucket.Take(limit, now)
+
+		return tx.Model(&types.RateLimitBucket{}).Where("key = ?", key).Updates(map[string]interface{}{
+			"tokens":     bucket.Tokens,
+			"updated_at": bucket.UpdatedAt,
+		}).Error
+	})
+	return result, err
+}
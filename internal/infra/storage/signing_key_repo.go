@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SigningKeyRepository struct {
+	Db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) entities.SigningKeyRepo {
+	return &SigningKeyRepository{
+		Db: db,
+	}
+}
+
+func (r *SigningKeyRepository) Save(ctx context.Context, key *entities.SigningKey) error {
+	model := mapper.SigningKeyDomain2Storage(key)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *SigningKeyRepository) FindByID(ctx context.Context, id uuid.UUID) (*entities.SigningKey, error) {
+	var model types.SigningKey
+	if err := r.Db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrSigningKeyNotFound
+		}
+		return nil, err
+	}
+	return mapper.SigningKeyStorage2Domain(model), nil
+}
+
+func (r *SigningKeyRepository) ListByClient(ctx context.Context, client string) ([]*entities.SigningKey, error) {
+	query := r.Db.WithContext(ctx).Order("created_at")
+	if client != "" {
+		query = query.Where("client = ?", client)
+	}
+
+	var models []types.SigningKey
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]*entities.SigningKey, 0, len(models))
+	for _, model := range models {
+		keys = append(keys, mapper.SigningKeyStorage2Domain(model))
+	}
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) Update(ctx context.Context, key *entities.SigningKey) error {
+	return r.Db.WithContext(ctx).Model(&types.SigningKey{}).Where("id = ?", key.ID).Updates(map[string]interface{}{
+		"expires_at": key.ExpiresAt,
+		"revoked_at": key.RevokedAt,
+		"updated_at": key.UpdatedAt,
+	}).Error
+}
+
+func (r *SigningKeyRepository) WithTx(tx *gorm.DB) entities.SigningKeyRepo {
+	return NewSigningKeyRepository(tx)
+}
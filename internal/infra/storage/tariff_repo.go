@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"gorm.io/gorm"
+)
+
+type TariffRepository struct {
+	Db *gorm.DB
+}
+
+func NewTariffRepository(db *gorm.DB) entities.TariffRepo {
+	return &TariffRepository{
+		Db: db,
+	}
+}
+
+// Create holds a table lock until the transaction ends, so two tariffs created together can not read
+// the same latest version. The lock only conflicts with itself and writes, active tariffs stay readable
+func (r *TariffRepository) Create(ctx context.Context, tariff *entities.Tariff) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE tariffs IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		var version int64
+		if err := tx.Model(&types.Tariff{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+			return err
+		}
+		tariff.Version = version + 1
+
+		model := mapper.TariffDomain2Storage(tariff)
+		return tx.Create(&model).Error
+	})
+}
+
+func (r *TariffRepository) FindByVersion(ctx context.Context, version int64) (*entities.Tariff, error) {
+	var model types.Tariff
+	if err := r.Db.WithContext(ctx).Preload("Rates").First(&model, "version = ?", version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTariffNotFound
+		}
+		return nil, err
+	}
+	return mapper.TariffStorage2Domain(model), nil
+}
+
+func (r *TariffRepository) FindActive(ctx context.Context, currency string) (*entities.Tariff, error) {
+	var model types.Tariff
+	err := r.Db.WithContext(ctx).Preload("Rates").
+		Where("currency = ? AND active = ?", currency, true).
+		Order("version DESC").
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTariffNotFound
+		}
+		return nil, err
+	}
+	return mapper.TariffStorage2Domain(model), nil
+}
+
+func (r *TariffRepository) List(ctx context.Context) ([]*entities.Tariff, error) {
+	var models []types.Tariff
+	if err := r.Db.WithContext(ctx).Preload("Rates").Order("version DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	tariffs := make([]*entities.Tariff, len(models))
+	for i, model := range models {
+		tariffs[i] = mapper.TariffStorage2Domain(model)
+	}
+	return tariffs, nil
+}
+
+func (r *TariffRepository) Activate(ctx context.Context, tariff *entities.Tariff) error {
+	err := r.Db.WithContext(ctx).Model(&types.Tariff{}).
+		Where("currency = ? AND id <> ?", tariff.Currency, tariff.ID).
+		Update("active", false).Error
+	if err != nil {
+		return err
+	}
+
+	if err := r.Db.WithContext(ctx).Model(&types.Tariff{}).Where("id = ?", tariff.ID).Update("active", true).Error; err != nil {
+		return err
+	}
+	tariff.Active = true
+	return nil
+}
+
+func (r *TariffRepository) WithTx(tx *gorm.DB) entities.TariffRepo {
+	return NewTariffRepository(tx)
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TaxRuleRepository struct {
+	Db *gorm.DB
+}
+
+func NewTaxRuleRepository(db *gorm.DB) entities.TaxRuleRepo {
+	return &TaxRuleRepository{
+		Db: db,
+	}
+}
+
+func (r *TaxRuleRepository) Create(ctx context.Context, rule *entities.TaxRule) error {
+	model := mapper.TaxRuleDomain2Storage(rule)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *TaxRuleRepository) List(ctx context.Context) ([]*entities.TaxRule, error) {
+	var models []types.TaxRule
+	if err := r.Db.WithContext(ctx).Order("valid_from DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return taxRulesStorage2Domain(models), nil
+}
+
+func (r *TaxRuleRepository) FindValid(ctx context.Context, base entities.TaxBase, at time.Time) ([]*entities.TaxRule, error) {
+	var models []types.TaxRule
+	err := r.Db.WithContext(ctx).
+		Where("tax_base = ? AND valid_from <= ? AND (valid_until IS NULL OR valid_until > ?)", base, at, at).
+		Order("valid_from DESC").
+		Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	return taxRulesStorage2Domain(models), nil
+}
+
+func (r *TaxRuleRepository) WithTx(tx *gorm.DB) entities.TaxRuleRepo {
+	return NewTaxRuleRepository(tx)
+}
+
+func taxRulesStorage2Domain(models []types.TaxRule) []*entities.TaxRule {
+	rules := make([]*entities.TaxRule, len(models))
+	for i, model := range models {
+		rules[i] = mapper.TaxRuleStorage2Domain(model)
+	}
+	return rules
+}
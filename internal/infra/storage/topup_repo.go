@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TopUpRepository struct {
+	Db *gorm.DB
+}
+
+func NewTopUpRepository(db *gorm.DB) entities.TopUpRepo {
+	return &TopUpRepository{
+		Db: db,
+	}
+}
+
+func (r *TopUpRepository) CreateRule(ctx context.Context, rule *entities.TopUpRule) error {
+	model := mapper.TopUpRuleDomain2Storage(rule)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *TopUpRepository) UpdateRule(ctx context.Context, rule *entities.TopUpRule) error {
+	model := mapper.TopUpRuleDomain2Storage(rule)
+	return r.Db.WithContext(ctx).Model(&model).Updates(map[string]interface{}{
+		"threshold":         model.Threshold,
+		"amount_amount":     model.Amount.Amount,
+		"amount_currency":   model.Amount.Currency,
+		"max_per_day":       model.MaxPerDay,
+		"funding_source_id": model.FundingSourceID,
+		"enabled":           model.Enabled,
+		"updated_at":        model.UpdatedAt,
+	}).Error
+}
+
+func (r *TopUpRepository) DeleteRule(ctx context.Context, walletID, ID uuid.UUID) error {
+	res := r.Db.WithContext(ctx).Delete(&types.TopUpRule{}, "id = ? AND wallet_id = ?", ID, walletID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return entities.ErrTopUpRuleNotFound
+	}
+	return nil
+}
+
+func (r *TopUpRepository) FindRuleByID(ctx context.Context, walletID, ID uuid.UUID) (*entities.TopUpRule, error) {
+	var model types.TopUpRule
+	if err := r.Db.WithContext(ctx).First(&model, "id = ? AND wallet_id = ?", ID, walletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTopUpRuleNotFound
+		}
+		return nil, err
+	}
+	return mapper.TopUpRuleStorage2Domain(model)
+}
+
+func (r *TopUpRepository) FindRulesByWalletID(ctx context.Context, walletID uuid.UUID) ([]*entities.TopUpRule, error) {
+	var models []types.TopUpRule
+	if err := r.Db.WithContext(ctx).Order("created_at").Find(&models, "wallet_id = ?", walletID).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]*entities.TopUpRule, 0, len(models))
+	for _, model := range models {
+		rule, err := mapper.TopUpRuleStorage2Domain(model)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *TopUpRepository) LockRule(ctx context.Context, ID uuid.UUID) (*entities.TopUpRule, error) {
+	var model types.TopUpRule
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "id = ?", ID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTopUpRuleNotFound
+		}
+		return nil, err
+	}
+	return mapper.TopUpRuleStorage2Domain(model)
+}
+
+func (r *TopUpRepository) CreateRequest(ctx context.Context, request *entities.TopUpRequest) error {
+	model := mapper.TopUpRequestDomain2Storage(request)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *TopUpRepository) LockRequest(ctx context.Context, ID uuid.UUID) (*entities.TopUpRequest, error) {
+	var model types.TopUpRequest
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "id = ?", ID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTopUpRequestNotFound
+		}
+		return nil, err
+	}
+	return mapper.TopUpRequestStorage2Domain(model)
+}
+
+func (r *TopUpRepository) UpdateRequestStatus(ctx context.Context, request *entities.TopUpRequest) error {
+	model := mapper.TopUpRequestDomain2Storage(request)
+	return r.Db.WithContext(ctx).Model(&model).Updates(map[string]interface{}{
+		"status":     model.Status,
+		"updated_at": model.UpdatedAt,
+	}).Error
+}
+
+func (r *TopUpRepository) CountRequestsSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&types.TopUpRequest{}).
+		Where("rule_id = ? AND created_at >= ?", ruleID, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *TopUpRepository) HasPendingRequestSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (bool, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&types.TopUpRequest{}).
+		Where("rule_id = ? AND status = ? AND created_at >= ?", ruleID, entities.TopUpPending, since).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TopUpRepository) WithTx(tx *gorm.DB) entities.TopUpRepo {
+	return NewTopUpRepository(tx)
+}
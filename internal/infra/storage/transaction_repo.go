@@ -5,10 +5,17 @@ import (
 	"finance/internal/domain/entities"
 	"finance/internal/infra/storage/mapper"
 	"finance/internal/infra/storage/types"
+	"fmt"
+	"math/big"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// nonSpendingCategories move balance without using the service, spending aggregates leave them out
+var nonSpendingCategories = []entities.TransactionCategory{entities.CategoryBonusExpiry, entities.CategoryTransfer, entities.CategoryDelegated, entities.CategoryFXConversion}
+
 type TransactionRepo struct {
 	Db *gorm.DB
 }
@@ -26,7 +33,7 @@ func (r *TransactionRepo) Create(ctx context.Context, tx *entities.Transaction)
 
 func (r *TransactionRepo) FindByID(ctx context.Context, id string) (*entities.Transaction, error) {
 	var model types.Transaction
-	if err := r.Db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Preload("Funding").First(&model, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	tx, err := mapper.TxStorage2Domain(model)
@@ -36,12 +43,232 @@ func (r *TransactionRepo) FindByID(ctx context.Context, id string) (*entities.Tr
 	return tx, nil
 }
 
+func (r *TransactionRepo) FindByReference(ctx context.Context, walletID, referenceID uuid.UUID) (*entities.Transaction, error) {
+	var model types.Transaction
+	if err := r.Db.WithContext(ctx).Preload("Funding").First(&model, "wallet_id = ? AND reference_id = ?", walletID, referenceID).Error; err != nil {
+		return nil, err
+	}
+	return mapper.TxStorage2Domain(model)
+}
+
 func (r *TransactionRepo) UpdateStatus(ctx context.Context, tx *entities.Transaction, status entities.TransactionStatus) error {
 	tx.Status = status
 	model := mapper.TxDomain2Storage(tx)
 	return r.Db.WithContext(ctx).Model(&model).Update("status", status).Error
 }
 
+func (r *TransactionRepo) SaveFunding(ctx context.Context, tx *entities.Transaction) error {
+	funding := mapper.FundingDomain2Storage(tx)
+	if len(funding) == 0 {
+		return nil
+	}
+	return r.Db.WithContext(ctx).Create(&funding).Error
+}
+
+// SumAmountSince counts the parent-funded share of a child's debit as the child's spending, the child
+// row itself only records what left the child wallet
+func (r *TransactionRepo) SumAmountSince(ctx context.Context, walletID uuid.UUID, txType entities.TransactionType, since time.Time) (*big.Int, error) {
+	var sum string
+	err := r.Db.WithContext(ctx).Model(&types.Transaction{}).
+		Select("COALESCE(SUM(amount_amount::numeric + COALESCE((SELECT SUM(f.amount::numeric) FROM transaction_fundings AS f "+
+			"WHERE f.transaction_id = transactions.id AND f.kind = ?), 0)), 0)::text", entities.BucketParent).
+		Where("wallet_id = ? AND type = ? AND status = ? AND created_at >= ?", walletID, txType, entities.TransactionCompleted, since).
+		Where("(category IS NULL OR category NOT IN ?)", nonSpendingCategories).
+		Scan(&sum).Error
+	if err != nil {
+		return nil, err
+	}
+
+	total, ok := new(big.Int).SetString(sum, 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse transaction sum: %s", sum)
+	}
+	return total, nil
+}
+
+func (r *TransactionRepo) CountSince(ctx context.Context, walletID uuid.UUID, txType entities.TransactionType, since time.Time) (int64, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&types.Transaction{}).
+		Where("wallet_id = ? AND type = ? AND status = ? AND created_at >= ?", walletID, txType, entities.TransactionCompleted, since).
+		Where("(category IS NULL OR category NOT IN ?)", nonSpendingCategories).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *TransactionRepo) SumParentFundingSince(ctx context.Context, walletID uuid.UUID, since time.Time) (*big.Int, error) {
+	var sum string
+	err := r.Db.WithContext(ctx).Table("transaction_fundings AS f").
+		Joins("JOIN transactions AS t ON t.id = f.transaction_id").
+		Select("COALESCE(SUM(CASE WHEN t.type = ? THEN f.amount::numeric ELSE -f.amount::numeric END), 0)::text", entities.TransactionDebit).
+		Where("t.wallet_id = ? AND f.kind = ? AND t.status = ? AND t.created_at >= ?", walletID, entities.BucketParent, entities.TransactionCompleted, since).
+		Scan(&sum).Error
+	if err != nil {
+		return nil, err
+	}
+
+	total, ok := new(big.Int).SetString(sum, 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse funding sum: %s", sum)
+	}
+	if total.Sign() < 0 {
+		total.SetInt64(0)
+	}
+	return total, nil
+}
+
+func (r *TransactionRepo) SumTax(ctx context.Context, from, to time.Time) ([]entities.TaxTotal, error) {
+	var rows []struct {
+		Type     string
+		Category string
+		Currency string
+		Count    int64
+		Net      string
+		Tax      string
+		Gross    string
+	}
+	err := r.Db.WithContext(ctx).Model(&types.Transaction{}).
+		Select("type, COALESCE(category, '') AS category, amount_currency AS currency, COUNT(*) AS count, "+
+			"SUM(net_amount::numeric)::text AS net, SUM(tax_amount::numeric)::text AS tax, SUM(gross_amount::numeric)::text AS gross").
+		Where("tax_rule_id IS NOT NULL AND tax_rule_id <> ? AND status = ?", uuid.Nil, entities.TransactionCompleted).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("type, COALESCE(category, ''), amount_currency").
+		Order("amount_currency, type, category").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make([]entities.TaxTotal, 0, len(rows))
+	for _, row := range rows {
+		total := entities.TaxTotal{
+			Type:     entities.TransactionType(row.Type),
+			Category: entities.TransactionCategory(row.Category),
+			Currency: row.Currency,
+			Count:    row.Count,
+		}
+		for _, sum := range []struct {
+			dst **big.Int
+			src string
+		}{{&total.Net, row.Net}, {&total.Tax, row.Tax}, {&total.Gross, row.Gross}} {
+			v, ok := new(big.Int).SetString(sum.src, 10)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse tax sum: %s", sum.src)
+			}
+			*sum.dst = v
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+func (r *TransactionRepo) BalanceAt(ctx context.Context, walletID uuid.UUID, currency string, at time.Time) (*big.Int, error) {
+	return r.sumBalance(r.Db.WithContext(ctx).Where("created_at < ?", at), walletID, currency)
+}
+
+func (r *TransactionRepo) SumBetween(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) (*big.Int, error) {
+	return r.sumBalance(r.Db.WithContext(ctx).Where("created_at >= ? AND created_at < ?", from, to), walletID, currency)
+}
+
+func (r *TransactionRepo) SumBalance(ctx context.Context, walletID uuid.UUID, currency string) (*big.Int, error) {
+	return r.sumBalance(r.Db.WithContext(ctx), walletID, currency)
+}
+
+// sumBalance nets the completed rows of the wallet, the parent funding kept on a child's row is not
+// part of its amount, the parent's share is on the parent's own delegated row
+func (r *TransactionRepo) sumBalance(db *gorm.DB, walletID uuid.UUID, currency string) (*big.Int, error) {
+	var sum string
+	err := db.Model(&types.Transaction{}).
+		Select("COALESCE(SUM(CASE WHEN type = ? THEN amount_amount::numeric ELSE -amount_amount::numeric END), 0)::text", entities.TransactionCredit).
+		Where("wallet_id = ? AND amount_currency = ? AND status = ?", walletID, currency, entities.TransactionCompleted).
+		Scan(&sum).Error
+	if err != nil {
+		return nil, err
+	}
+
+	total, ok := new(big.Int).SetString(sum, 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse balance sum: %s", sum)
+	}
+	return total, nil
+}
+
+func (r *TransactionRepo) StatementLines(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) ([]entities.InvoiceLine, error) {
+	var rows []struct {
+		Day         time.Time
+		Type        string
+		Category    string
+		MessageType string
+		Count       int64
+		Segments    int64
+		Amount      string
+		Tax         string
+	}
+	err := r.Db.WithContext(ctx).Model(&types.Transaction{}).
+		Select("date_trunc('day', created_at) AS day, type, COALESCE(category, '') AS category, "+
+			"COALESCE(message_type, '') AS message_type, COUNT(*) AS count, COALESCE(SUM(segments), 0) AS segments, "+
+			"SUM(amount_amount::numeric)::text AS amount, SUM(tax_amount::numeric)::text AS tax").
+		Where("wallet_id = ? AND amount_currency = ? AND status = ?", walletID, currency, entities.TransactionCompleted).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("1, 2, 3, 4").
+		Order("1, 2, 3, 4").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]entities.InvoiceLine, 0, len(rows))
+	for _, row := range rows {
+		amount, ok := new(big.Int).SetString(row.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse statement amount: %s", row.Amount)
+		}
+		tax, ok := new(big.Int).SetString(row.Tax, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse statement tax: %s", row.Tax)
+		}
+		lines = append(lines, entities.InvoiceLine{
+			Day:         row.Day,
+			Type:        entities.TransactionType(row.Type),
+			Category:    entities.TransactionCategory(row.Category),
+			MessageType: entities.MessageType(row.MessageType),
+			Count:       row.Count,
+			Segments:    row.Segments,
+			Amount:      amount,
+			Tax:         tax,
+		})
+	}
+	return lines, nil
+}
+
+func (r *TransactionRepo) Stream(ctx context.Context, filter entities.TransactionFilter, fn func(*entities.Transaction) error) error {
+	query := r.Db.WithContext(ctx).Model(&types.Transaction{}).
+		Where("created_at >= ? AND created_at < ?", filter.From, filter.To)
+	if filter.WalletID != nil {
+		query = query.Where("wallet_id = ?", *filter.WalletID)
+	}
+
+	rows, err := query.Order("created_at, id").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var model types.Transaction
+		if err := r.Db.ScanRows(rows, &model); err != nil {
+			return err
+		}
+		tx, err := mapper.TxStorage2Domain(model)
+		if err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *TransactionRepo) BeginDbTx() *gorm.DB {
 	return r.Db.Begin()
 }
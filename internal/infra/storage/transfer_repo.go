@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TransferRepository struct {
+	Db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) entities.TransferRepo {
+	return &TransferRepository{
+		Db: db,
+	}
+}
+
+func (r *TransferRepository) Create(ctx context.Context, transfer *entities.Transfer) error {
+	model := mapper.TransferDomain2Storage(transfer)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *TransferRepository) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Transfer, error) {
+	var model types.Transfer
+	if err := r.Db.WithContext(ctx).First(&model, "id = ?", ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTransferNotFound
+		}
+		return nil, err
+	}
+	return mapper.TransferStorage2Domain(model)
+}
+
+func (r *TransferRepository) FindByIdempotencyKey(ctx context.Context, fromUserID uuid.UUID, key string) (*entities.Transfer, error) {
+	var model types.Transfer
+	if err := r.Db.WithContext(ctx).First(&model, "from_user_id = ? AND idempotency_key = ?", fromUserID, key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrTransferNotFound
+		}
+		return nil, err
+	}
+	return mapper.TransferStorage2Domain(model)
+}
+
+func (r *TransferRepository) WithTx(tx *gorm.DB) entities.TransferRepo {
+	return NewTransferRepository(tx)
+}
@@ -0,0 +1,17 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	Base
+	Name      string    `gorm:"type:varchar(100);not null"`
+	Prefix    string    `gorm:"type:varchar(20);not null"`
+	Hash      string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Role      string    `gorm:"type:varchar(20);not null"`
+	UserID    uuid.UUID `gorm:"type:uuid;index"`
+	RevokedAt *time.Time
+}
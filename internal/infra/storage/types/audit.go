@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent rows are only ever inserted
+type AuditEvent struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;"`
+	WalletID      uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_audit_wallet_sequence;not null"`
+	Sequence      int64     `gorm:"uniqueIndex:idx_audit_wallet_sequence;not null"`
+	Action        string    `gorm:"type:varchar(20);not null"`
+	Category      string    `gorm:"type:varchar(20)"`
+	TransactionID uuid.UUID `gorm:"type:uuid;index"`
+	Actor         string    `gorm:"type:varchar(255);index;not null"`
+	Source        string    `gorm:"type:varchar(10);not null"`
+	TraceID       string    `gorm:"type:varchar(64)"`
+	PayloadHash   string    `gorm:"type:varchar(64)"`
+	Currency      string    `gorm:"type:varchar(3);not null"`
+	BalanceBefore BigInt    `gorm:"type:text;not null"`
+	BalanceAfter  BigInt    `gorm:"type:text;not null"`
+	PrevHash      string    `gorm:"type:varchar(64)"`
+	Hash          string    `gorm:"type:varchar(64);not null"`
+	CreatedAt     time.Time `gorm:"index;not null"`
+}
@@ -0,0 +1,27 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BalanceBucket struct {
+	Base
+	WalletID  uuid.UUID  `gorm:"type:uuid;index;not null"`
+	Kind      string     `gorm:"type:varchar(20);not null"`
+	Granted   BigInt     `gorm:"type:text;not null;default:'0'"`
+	Remaining BigInt     `gorm:"type:text;not null;default:'0'"`
+	Priority  int        `gorm:"not null"`
+	ExpiresAt *time.Time `gorm:"index"`
+	Reason    string     `gorm:"type:varchar(255)"`
+}
+
+// TransactionFunding is one part of a transaction's bucket split, BucketID is nil for paid balance
+type TransactionFunding struct {
+	Base
+	TransactionID uuid.UUID `gorm:"type:uuid;index;not null"`
+	BucketID      uuid.UUID `gorm:"type:uuid;index"`
+	Kind          string    `gorm:"type:varchar(20);not null"`
+	Amount        BigInt    `gorm:"type:text;not null;default:'0'"`
+}
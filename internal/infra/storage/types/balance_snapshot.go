@@ -0,0 +1,19 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BalanceSnapshot struct {
+	Base
+	WalletID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_balance_snapshot_time;not null"`
+	Currency string    `gorm:"type:varchar(3);not null"`
+	Balance  BigInt    `gorm:"type:text;not null;default:'0'"`
+	TakenAt  time.Time `gorm:"uniqueIndex:idx_balance_snapshot_time;not null"`
+}
+
+func (BalanceSnapshot) TableName() string {
+	return "wallet_balance_snapshots"
+}
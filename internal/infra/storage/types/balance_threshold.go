@@ -0,0 +1,11 @@
+package types
+
+import "github.com/google/uuid"
+
+type BalanceThreshold struct {
+	Base
+	WalletID   uuid.UUID `gorm:"type:uuid;index;not null"`
+	Amount     Money     `gorm:"embedded;embeddedPrefix:amount_"`
+	Hysteresis BigInt    `gorm:"type:text;not null;default:'0'"`
+	Armed      bool      `gorm:"not null"`
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FXRate struct {
+	Base
+	BaseCurrency  string    `gorm:"type:varchar(3);index:idx_fx_rate_pair;not null"`
+	QuoteCurrency string    `gorm:"type:varchar(3);index:idx_fx_rate_pair;not null"`
+	Rate          string    `gorm:"type:text;not null"`
+	ValidFrom     time.Time `gorm:"index;not null"`
+	ValidUntil    *time.Time
+	Source        string `gorm:"type:varchar(20);not null"`
+}
+
+type SubBalance struct {
+	Base
+	WalletID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_sub_balance_currency;not null"`
+	Currency string    `gorm:"type:varchar(3);uniqueIndex:idx_sub_balance_currency;not null"`
+	Balance  BigInt    `gorm:"type:text;not null;default:'0'"`
+}
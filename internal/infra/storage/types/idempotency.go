@@ -0,0 +1,23 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord has no response columns set while its request runs, claimed_until tells
+// other requests with the key that it is running
+type IdempotencyRecord struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;"`
+	Key          string    `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key;not null"`
+	Route        string    `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key;not null"`
+	Caller       string    `gorm:"type:varchar(255);uniqueIndex:idx_idempotency_key;not null"`
+	RequestHash  string    `gorm:"type:varchar(64);not null"`
+	StatusCode   int
+	ContentType  string `gorm:"type:varchar(255)"`
+	Body         []byte `gorm:"type:bytea"`
+	ClaimedUntil time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
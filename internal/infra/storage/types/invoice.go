@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Invoice struct {
+	Base
+	Number         string        `gorm:"type:varchar(20);uniqueIndex;not null"`
+	Sequence       int64         `gorm:"uniqueIndex;not null"`
+	UserID         uuid.UUID     `gorm:"type:uuid;index;not null"`
+	WalletID       uuid.UUID     `gorm:"type:uuid;uniqueIndex:idx_invoice_period;not null"`
+	Currency       string        `gorm:"type:varchar(3);not null"`
+	PeriodStart    time.Time     `gorm:"uniqueIndex:idx_invoice_period;not null"`
+	PeriodEnd      time.Time     `gorm:"not null"`
+	OpeningBalance BigInt        `gorm:"type:text;not null;default:'0'"`
+	Credits        BigInt        `gorm:"type:text;not null;default:'0'"`
+	Refunds        BigInt        `gorm:"type:text;not null;default:'0'"`
+	Debits         BigInt        `gorm:"type:text;not null;default:'0'"`
+	Tax            BigInt        `gorm:"type:text;not null;default:'0'"`
+	ClosingBalance BigInt        `gorm:"type:text;not null;default:'0'"`
+	Lines          []InvoiceLine `gorm:"foreignKey:InvoiceID"`
+}
+
+type InvoiceLine struct {
+	Base
+	InvoiceID   uuid.UUID `gorm:"type:uuid;index;not null"`
+	Day         time.Time `gorm:"not null"`
+	Type        string    `gorm:"type:varchar(10);not null"`
+	Category    string    `gorm:"type:varchar(20)"`
+	MessageType string    `gorm:"type:varchar(20)"`
+	Count       int64     `gorm:"not null"`
+	Segments    int64     `gorm:"not null"`
+	Amount      BigInt    `gorm:"type:text;not null;default:'0'"`
+	Tax         BigInt    `gorm:"type:text;not null;default:'0'"`
+}
+
+// InvoiceCounter is the last number handed out by a sequence, the row lock keeps numbers gapless
+type InvoiceCounter struct {
+	Name  string `gorm:"type:varchar(50);primaryKey"`
+	Value int64  `gorm:"not null"`
+}
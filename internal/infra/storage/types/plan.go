@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Plan struct {
+	Base
+	Code  string     `gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name  string     `gorm:"type:varchar(100)"`
+	Tiers []PlanTier `gorm:"foreignKey:PlanID"`
+}
+
+type PlanTier struct {
+	Base
+	PlanID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	Position    int       `gorm:"not null"`
+	UpTo        int64     `gorm:"not null"`
+	DiscountBPS int64     `gorm:"not null"`
+}
+
+type PlanAssignment struct {
+	Base
+	UserID    uuid.UUID  `gorm:"type:uuid;index;not null"`
+	PlanID    uuid.UUID  `gorm:"type:uuid;index;not null"`
+	PlanCode  string     `gorm:"type:varchar(50);not null"`
+	StartedAt time.Time  `gorm:"not null"`
+	EndedAt   *time.Time `gorm:"index"`
+}
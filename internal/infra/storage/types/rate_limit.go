@@ -0,0 +1,9 @@
+package types
+
+import "time"
+
+type RateLimitBucket struct {
+	Key       string  `gorm:"type:varchar(512);primary_key"`
+	Tokens    float64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
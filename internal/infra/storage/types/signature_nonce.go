@@ -0,0 +1,8 @@
+package types
+
+import "time"
+
+type SignatureNonce struct {
+	Key       string    `gorm:"type:varchar(512);primary_key"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
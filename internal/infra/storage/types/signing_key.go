@@ -0,0 +1,12 @@
+package types
+
+import "time"
+
+type SigningKey struct {
+	Base
+	Client    string `gorm:"type:varchar(100);index;not null"`
+	Secret    string `gorm:"type:varchar(100);not null"`
+	Role      string `gorm:"type:varchar(20);not null"`
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+}
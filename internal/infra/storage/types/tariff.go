@@ -0,0 +1,22 @@
+package types
+
+import "github.com/google/uuid"
+
+type Tariff struct {
+	Base
+	Version  int64        `gorm:"uniqueIndex;not null"`
+	Name     string       `gorm:"type:varchar(100)"`
+	Currency string       `gorm:"type:varchar(3);index;not null"`
+	Active   bool         `gorm:"index;not null"`
+	Rates    []TariffRate `gorm:"foreignKey:TariffID"`
+}
+
+type TariffRate struct {
+	Base
+	TariffID        uuid.UUID `gorm:"type:uuid;index;not null"`
+	Prefix          string    `gorm:"type:varchar(20);not null"`
+	Operator        string    `gorm:"type:varchar(50)"`
+	MessageType     string    `gorm:"type:varchar(20);not null"`
+	Plan            string    `gorm:"type:varchar(50)"`
+	PricePerSegment BigInt    `gorm:"type:text;not null"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+type TaxRule struct {
+	Base
+	Name         string    `gorm:"type:varchar(100)"`
+	CustomerType string    `gorm:"type:varchar(20);index"`
+	TaxBase      string    `gorm:"type:varchar(10);index;not null"`
+	RateBPS      int64     `gorm:"not null"`
+	Inclusive    bool      `gorm:"not null;default:false"`
+	ValidFrom    time.Time `gorm:"index;not null"`
+	ValidUntil   *time.Time
+}
@@ -0,0 +1,24 @@
+package types
+
+import "github.com/google/uuid"
+
+type TopUpRule struct {
+	Base
+	WalletID        uuid.UUID `gorm:"type:uuid;index;not null"`
+	UserID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	Threshold       BigInt    `gorm:"type:text;not null;default:'0'"`
+	Amount          Money     `gorm:"embedded;embeddedPrefix:amount_"`
+	MaxPerDay       int64     `gorm:"not null"`
+	FundingSourceID string    `gorm:"type:varchar(100);not null"`
+	Enabled         bool      `gorm:"not null"`
+}
+
+type TopUpRequest struct {
+	Base
+	RuleID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	WalletID        uuid.UUID `gorm:"type:uuid;index;not null"`
+	UserID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	Amount          Money     `gorm:"embedded;embeddedPrefix:amount_"`
+	FundingSourceID string    `gorm:"type:varchar(100);not null"`
+	Status          string    `gorm:"type:varchar(20);index;not null;default:'pending'"`
+}
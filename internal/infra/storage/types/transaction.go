@@ -11,10 +11,29 @@ type Money struct {
 
 type Transaction struct {
 	Base
-	WalletID uuid.UUID `gorm:"type:uuid;index;not null"`
-	UserID   uuid.UUID `gorm:"type:uuid;index;not null"`
-	Amount   Money     `gorm:"embedded;embeddedPrefix:amount_"`
-	Type     string    `gorm:"type:varchar(10);index;not null"`
-	Status   string    `gorm:"type:varchar(20);index;not null;default:'pending'"`
-	SMSID    uuid.UUID `gorm:"type:uuid;index"`
+	WalletID      uuid.UUID `gorm:"type:uuid;index;not null"`
+	UserID        uuid.UUID `gorm:"type:uuid;index;not null"`
+	Amount        Money     `gorm:"embedded;embeddedPrefix:amount_"`
+	Type          string    `gorm:"type:varchar(10);index;not null"`
+	Status        string    `gorm:"type:varchar(20);index;not null;default:'pending'"`
+	SMSID         uuid.UUID `gorm:"type:uuid;index"`
+	Category      string    `gorm:"type:varchar(20);index"`
+	ReferenceID   uuid.UUID `gorm:"type:uuid;index"`
+	TariffVersion int64     `gorm:"index"`
+	MessageType   string    `gorm:"type:varchar(20)"`
+	Segments      int64
+	PlanID        uuid.UUID `gorm:"type:uuid"`
+	DiscountBPS   int64
+	// source money and rate of a converted credit, empty SourceCurrency means no conversion
+	SourceAmount   BigInt    `gorm:"type:text"`
+	SourceCurrency string    `gorm:"type:varchar(3)"`
+	FXRate         string    `gorm:"type:text"`
+	FXRateID       uuid.UUID `gorm:"type:uuid"`
+	// net, tax and gross of taxed transactions, untaxed ones have net and gross equal to the amount
+	NetAmount   BigInt    `gorm:"type:text;not null;default:'0'"`
+	TaxAmount   BigInt    `gorm:"type:text;not null;default:'0'"`
+	GrossAmount BigInt    `gorm:"type:text;not null;default:'0'"`
+	TaxRuleID   uuid.UUID `gorm:"type:uuid;index"`
+	TaxRateBPS  int64
+	Funding     []TransactionFunding `gorm:"foreignKey:TransactionID"`
 }
@@ -0,0 +1,15 @@
+package types
+
+import "github.com/google/uuid"
+
+type Transfer struct {
+	Base
+	FromWalletID        uuid.UUID `gorm:"type:uuid;index;not null"`
+	ToWalletID          uuid.UUID `gorm:"type:uuid;index;not null"`
+	FromUserID          uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_transfer_idempotency;not null"`
+	ToUserID            uuid.UUID `gorm:"type:uuid;not null"`
+	Amount              Money     `gorm:"embedded;embeddedPrefix:amount_"`
+	IdempotencyKey      string    `gorm:"type:varchar(255);uniqueIndex:idx_transfer_idempotency;not null"`
+	DebitTransactionID  uuid.UUID `gorm:"type:uuid;not null"`
+	CreditTransactionID uuid.UUID `gorm:"type:uuid;not null"`
+}
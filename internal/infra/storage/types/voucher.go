@@ -0,0 +1,26 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Voucher struct {
+	Base
+	Code           string `gorm:"type:varchar(20);uniqueIndex;not null"`
+	Amount         Money  `gorm:"embedded;embeddedPrefix:amount_"`
+	MaxRedemptions int64  `gorm:"not null"`
+	PerUserLimit   int64  `gorm:"not null"`
+	Redeemed       int64  `gorm:"not null"`
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	Campaign       string `gorm:"type:varchar(100);index"`
+}
+
+type VoucherRedemption struct {
+	Base
+	VoucherID     uuid.UUID `gorm:"type:uuid;index:idx_voucher_redemption_user;not null"`
+	UserID        uuid.UUID `gorm:"type:uuid;index:idx_voucher_redemption_user;not null"`
+	TransactionID uuid.UUID `gorm:"type:uuid;index;not null"`
+}
@@ -63,7 +63,22 @@ func (b BigInt) Value() (driver.Value, error) {
 
 type Wallet struct {
 	Base
-	UserID   uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
-	Balance  BigInt    `gorm:"type:text;not null;default:'0'"`
-	Currency string    `gorm:"type:varchar(3);index;not null;default:'IRR'"`
+	UserID      uuid.UUID       `gorm:"type:uuid;uniqueIndex;not null"`
+	Balance     BigInt          `gorm:"type:text;not null;default:'0'"`
+	CreditLimit BigInt          `gorm:"type:text;not null;default:'0'"`
+	Currency    string          `gorm:"type:varchar(3);index;not null;default:'IRR'"`
+	Limits      SpendingLimits  `gorm:"embedded;embeddedPrefix:limit_"`
+	Buckets     []BalanceBucket `gorm:"foreignKey:WalletID"`
+	ParentID    *uuid.UUID      `gorm:"type:uuid;index"`
+	// zero means the draw from the parent is not capped
+	ParentQuota BigInt       `gorm:"type:text;not null;default:'0'"`
+	SubBalances []SubBalance `gorm:"foreignKey:WalletID"`
+}
+
+// zero values mean the limit is not set
+type SpendingLimits struct {
+	MaxDebit        BigInt `gorm:"type:text;not null;default:'0'"`
+	DailyAmount     BigInt `gorm:"type:text;not null;default:'0'"`
+	MonthlyAmount   BigInt `gorm:"type:text;not null;default:'0'"`
+	DebitsPerMinute int64  `gorm:"not null;default:0"`
 }
@@ -42,6 +42,11 @@ func (r *UserRepository) GetAll(ctx context.Context) ([]*entities.User, error) {
 	return users, nil
 }
 
+func (r *UserRepository) UpdateCustomerType(ctx context.Context, user *entities.User) error {
+	model := mapper.UserDomain2Storage(user)
+	return r.Db.WithContext(ctx).Model(&model).Update("customer_type", model.CustomerType).Error
+}
+
 func (r *UserRepository) WithTx(tx *gorm.DB) entities.UserRepo {
 	return &UserRepository{
 		Db: tx,
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage/mapper"
+	"finance/internal/infra/storage/types"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// voucherInsertBatch keeps bulk generation below the postgres bind parameter limit
+const voucherInsertBatch = 500
+
+type VoucherRepository struct {
+	Db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) entities.VoucherRepo {
+	return &VoucherRepository{
+		Db: db,
+	}
+}
+
+func (r *VoucherRepository) CreateBatch(ctx context.Context, vouchers []*entities.Voucher) error {
+	models := make([]types.Voucher, 0, len(vouchers))
+	for _, v := range vouchers {
+		models = append(models, mapper.VoucherDomain2Storage(v))
+	}
+	return r.Db.WithContext(ctx).CreateInBatches(&models, voucherInsertBatch).Error
+}
+
+func (r *VoucherRepository) FindByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	var model types.Voucher
+	if err := r.Db.WithContext(ctx).First(&model, "code = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrVoucherNotFound
+		}
+		return nil, err
+	}
+	return mapper.VoucherStorage2Domain(model)
+}
+
+func (r *VoucherRepository) List(ctx context.Context, campaign string) ([]*entities.Voucher, error) {
+	query := r.Db.WithContext(ctx).Order("created_at")
+	if campaign != "" {
+		query = query.Where("campaign = ?", campaign)
+	}
+
+	var models []types.Voucher
+	if err := query.Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	vouchers := make([]*entities.Voucher, 0, len(models))
+	for _, model := range models {
+		voucher, err := mapper.VoucherStorage2Domain(model)
+		if err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, voucher)
+	}
+	return vouchers, nil
+}
+
+func (r *VoucherRepository) LockByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	var model types.Voucher
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "code = ?", code).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrVoucherNotFound
+		}
+		return nil, err
+	}
+	return mapper.VoucherStorage2Domain(model)
+}
+
+func (r *VoucherRepository) CountUserRedemptions(ctx context.Context, voucherID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&types.VoucherRedemption{}).
+		Where("voucher_id = ? AND user_id = ?", voucherID, userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *VoucherRepository) Redeem(ctx context.Context, voucher *entities.Voucher, redemption *entities.VoucherRedemption) error {
+	err := r.Db.WithContext(ctx).Model(&types.Voucher{}).Where("id = ?", voucher.ID).Updates(map[string]interface{}{
+		"redeemed":   voucher.Redeemed,
+		"updated_at": voucher.UpdatedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	model := mapper.VoucherRedemptionDomain2Storage(redemption)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *VoucherRepository) WithTx(tx *gorm.DB) entities.VoucherRepo {
+	return NewVoucherRepository(tx)
+}
@@ -2,12 +2,15 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"finance/internal/domain/entities"
 	"finance/internal/infra/storage/mapper"
 	"finance/internal/infra/storage/types"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WalletRepository struct {
@@ -27,7 +30,7 @@ func (r *WalletRepository) Save(ctx context.Context, wallet *entities.Wallet) er
 
 func (r *WalletRepository) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Wallet, error) {
 	var model types.Wallet
-	if err := r.Db.WithContext(ctx).First(&model, "id = ?", ID).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Preload("Buckets", activeBuckets).Preload("SubBalances").First(&model, "id = ?", ID).Error; err != nil {
 		return nil, err
 	}
 	res, err := mapper.WalletStorage2Domain(model)
@@ -39,7 +42,7 @@ func (r *WalletRepository) FindByID(ctx context.Context, ID uuid.UUID) (*entitie
 
 func (r *WalletRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wallet, error) {
 	var model types.Wallet
-	if err := r.Db.WithContext(ctx).First(&model, "user_id = ?", userID.String()).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Preload("Buckets", activeBuckets).Preload("SubBalances").First(&model, "user_id = ?", userID.String()).Error; err != nil {
 		return nil, err
 	}
 	res, err := mapper.WalletStorage2Domain(model)
@@ -49,14 +52,165 @@ func (r *WalletRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (
 	return res, nil
 }
 
+func (r *WalletRepository) LockByID(ctx context.Context, ID uuid.UUID) (*entities.Wallet, error) {
+	var model types.Wallet
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Buckets", activeBuckets).Preload("SubBalances").First(&model, "id = ?", ID).Error
+	if err != nil {
+		return nil, err
+	}
+	return mapper.WalletStorage2Domain(model)
+}
+
+func (r *WalletRepository) LockByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wallet, error) {
+	var model types.Wallet
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Buckets", activeBuckets).Preload("SubBalances").First(&model, "user_id = ?", userID.String()).Error
+	if err != nil {
+		return nil, err
+	}
+	return mapper.WalletStorage2Domain(model)
+}
+
 func (r *WalletRepository) UpdateBalance(ctx context.Context, wallet *entities.Wallet) error {
 	model := mapper.WalletDomain2Storage(wallet)
-	var lockModel types.Wallet
-	if err := r.Db.WithContext(ctx).Set("gorm:query_option", "FOR UPDATE").First(&lockModel, "id = ?", model.ID).Error; err != nil {
+	if err := r.Db.WithContext(ctx).Model(&model).Update("balance", model.Balance).Error; err != nil {
 		return err
 	}
+	if err := r.saveBuckets(ctx, wallet); err != nil {
+		return err
+	}
+	return r.saveSubBalances(ctx, wallet)
+}
+
+func (r *WalletRepository) saveBuckets(ctx context.Context, wallet *entities.Wallet) error {
+	if len(wallet.Buckets) == 0 {
+		return nil
+	}
+
+	buckets := make([]types.BalanceBucket, len(wallet.Buckets))
+	for i, b := range wallet.Buckets {
+		buckets[i] = mapper.BucketDomain2Storage(b)
+	}
+	return r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"remaining", "updated_at"}),
+	}).Create(&buckets).Error
+}
+
+func (r *WalletRepository) saveSubBalances(ctx context.Context, wallet *entities.Wallet) error {
+	if len(wallet.SubBalances) == 0 {
+		return nil
+	}
 
-	return r.Db.WithContext(ctx).Model(&model).Update("balance", model.Balance).Error
+	subBalances := make([]types.SubBalance, len(wallet.SubBalances))
+	for i, sb := range wallet.SubBalances {
+		subBalances[i] = mapper.SubBalanceDomain2Storage(sb)
+	}
+	return r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"balance", "updated_at"}),
+	}).Create(&subBalances).Error
+}
+
+func (r *WalletRepository) FindBucket(ctx context.Context, walletID, ID uuid.UUID) (*entities.BalanceBucket, error) {
+	var model types.BalanceBucket
+	if err := r.Db.WithContext(ctx).First(&model, "id = ? AND wallet_id = ?", ID, walletID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entities.ErrBucketNotFound
+		}
+		return nil, err
+	}
+
+	var wallet types.Wallet
+	if err := r.Db.WithContext(ctx).Select("currency").First(&wallet, "id = ?", walletID).Error; err != nil {
+		return nil, err
+	}
+	return mapper.BucketStorage2Domain(model, wallet.Currency)
+}
+
+func (r *WalletRepository) FindWalletIDsWithExpiredBuckets(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.Db.WithContext(ctx).Model(&types.BalanceBucket{}).
+		Distinct("wallet_id").
+		Where("expires_at <= ? AND remaining <> '0'", before).
+		Pluck("wallet_id", &ids).Error
+	return ids, err
+}
+
+func (r *WalletRepository) LastAuditEvent(ctx context.Context, walletID uuid.UUID) (*entities.AuditEvent, error) {
+	var model types.AuditEvent
+	err := r.Db.WithContext(ctx).
+		Where("wallet_id = ?", walletID).
+		Order("sequence DESC").
+		First(&model).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mapper.AuditEventStorage2Domain(model), nil
+}
+
+func (r *WalletRepository) AppendAuditEvent(ctx context.Context, event *entities.AuditEvent) error {
+	model := mapper.AuditEventDomain2Storage(event)
+	return r.Db.WithContext(ctx).Create(&model).Error
+}
+
+func (r *WalletRepository) ListIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.Db.WithContext(ctx).Model(&types.Wallet{}).
+		Where("id > ?", after).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// activeBuckets only loads buckets that still hold credit
+func activeBuckets(db *gorm.DB) *gorm.DB {
+	return db.Where("remaining <> '0'").Order("created_at")
+}
+
+func (r *WalletRepository) UpdateLimits(ctx context.Context, wallet *entities.Wallet) error {
+	model := mapper.WalletDomain2Storage(wallet)
+	return r.Db.WithContext(ctx).Model(&model).Updates(map[string]interface{}{
+		"limit_max_debit":         model.Limits.MaxDebit,
+		"limit_daily_amount":      model.Limits.DailyAmount,
+		"limit_monthly_amount":    model.Limits.MonthlyAmount,
+		"limit_debits_per_minute": model.Limits.DebitsPerMinute,
+	}).Error
+}
+
+func (r *WalletRepository) UpdateCreditLimit(ctx context.Context, wallet *entities.Wallet) error {
+	model := mapper.WalletDomain2Storage(wallet)
+	return r.Db.WithContext(ctx).Model(&model).Update("credit_limit", model.CreditLimit).Error
+}
+
+func (r *WalletRepository) UpdateParent(ctx context.Context, wallet *entities.Wallet) error {
+	model := mapper.WalletDomain2Storage(wallet)
+	return r.Db.WithContext(ctx).Model(&model).Updates(map[string]interface{}{
+		"parent_id":    model.ParentID,
+		"parent_quota": model.ParentQuota,
+	}).Error
+}
+
+func (r *WalletRepository) FindChildren(ctx context.Context, parentID uuid.UUID) ([]*entities.Wallet, error) {
+	var models []types.Wallet
+	if err := r.Db.WithContext(ctx).Order("created_at").Find(&models, "parent_id = ?", parentID).Error; err != nil {
+		return nil, err
+	}
+
+	wallets := make([]*entities.Wallet, 0, len(models))
+	for _, model := range models {
+		wallet, err := mapper.WalletStorage2Domain(model)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, nil
 }
 
 func (r *WalletRepository) WithTx(tx *gorm.DB) entities.WalletRepo {
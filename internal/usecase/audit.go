@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/pkg/logger"
+	"math/big"
+)
+
+// saveBalance stores the balance the transaction changed from before and appends its audit event,
+// both go through walletRepo so they commit or roll back together
+func (s *WalletService) saveBalance(ctx context.Context, walletRepo entities.WalletRepo, wallet *entities.Wallet, before valueobjects.Money, transaction *entities.Transaction) error {
+	if err := walletRepo.UpdateBalance(ctx, wallet); err != nil {
+		return err
+	}
+	return s.audit(ctx, walletRepo, wallet, before.Amount(), entities.AuditAction(transaction.Type), transaction)
+}
+
+func (s *WalletService) audit(ctx context.Context, walletRepo entities.WalletRepo, wallet *entities.Wallet, before *big.Int, action entities.AuditAction, transaction *entities.Transaction) error {
+	previous, err := walletRepo.LastAuditEvent(ctx, wallet.ID)
+	if err != nil {
+		return err
+	}
+	event := entities.NewAuditEvent(ctx, logger.GetTraceID(ctx), wallet, before, action, transaction)
+	event.Chain(previous)
+	return walletRepo.AppendAuditEvent(ctx, event)
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+type AuditService struct {
+	AuditRepo entities.AuditRepo
+	log       *logger.Logger
+}
+
+func NewAuditService(auditRepo entities.AuditRepo, log *logger.Logger) *AuditService {
+	return &AuditService{
+		AuditRepo: auditRepo,
+		log:       log,
+	}
+}
+
+func (s *AuditService) ListEvents(ctx context.Context, filter entities.AuditFilter) ([]*entities.AuditEvent, error) {
+	return s.AuditRepo.List(ctx, filter)
+}
+
+// VerifyChain recomputes the hashes of the wallet's audit chain
+func (s *AuditService) VerifyChain(ctx context.Context, walletID uuid.UUID) (entities.AuditVerification, error) {
+	chain, err := s.AuditRepo.Chain(ctx, walletID)
+	if err != nil {
+		return entities.AuditVerification{}, err
+	}
+	result := entities.VerifyAuditChain(walletID, chain)
+	if !result.Valid {
+		s.log.Error(ctx, "Audit chain is broken:", "wallet_id", walletID, "sequence", result.BrokenAt)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type AuthService struct {
+	APIKeyRepo entities.APIKeyRepo
+	// Verifier checks jwt bearer tokens, nil when no keys are configured
+	Verifier entities.TokenVerifier
+	log      *logger.Logger
+}
+
+func NewAuthService(apiKeyRepo entities.APIKeyRepo, verifier entities.TokenVerifier, log *logger.Logger) *AuthService {
+	return &AuthService{
+		APIKeyRepo: apiKeyRepo,
+		Verifier:   verifier,
+		log:        log,
+	}
+}
+
+// Authenticate resolves an api key or a jwt bearer token to its principal
+func (s *AuthService) Authenticate(ctx context.Context, credential string) (entities.Principal, error) {
+	if credential == "" {
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+	if strings.HasPrefix(credential, entities.APIKeyPrefix) {
+		return s.authenticateAPIKey(ctx, credential)
+	}
+	if s.Verifier == nil {
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+	return s.Verifier.Verify(ctx, credential)
+}
+
+func (s *AuthService) authenticateAPIKey(ctx context.Context, secret string) (entities.Principal, error) {
+	key, err := s.APIKeyRepo.FindByHash(ctx, entities.HashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, entities.ErrAPIKeyNotFound) {
+			return entities.Principal{}, entities.ErrUnauthenticated
+		}
+		s.log.Error(ctx, "Error looking up api key:", "error", err)
+		return entities.Principal{}, err
+	}
+	if key.Revoked() {
+		return entities.Principal{}, entities.ErrUnauthenticated
+	}
+	return key.Principal(), nil
+}
+
+// CreateAPIKey stores a new key and returns it with its secret, which is shown only this once
+func (s *AuthService) CreateAPIKey(ctx context.Context, name string, role entities.Role, userID uuid.UUID) (*entities.APIKey, string, error) {
+	key, secret, err := entities.NewAPIKey(name, role, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.APIKeyRepo.Save(ctx, key); err != nil {
+		s.log.Error(ctx, "Error saving api key:", "error", err)
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context) ([]*entities.APIKey, error) {
+	return s.APIKeyRepo.List(ctx)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, keyID uuid.UUID) (*entities.APIKey, error) {
+	key, err := s.APIKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.Revoked() {
+		return key, nil
+	}
+	key.Revoke()
+	if err := s.APIKeyRepo.Revoke(ctx, key); err != nil {
+		s.log.Error(ctx, "Error revoking api key:", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
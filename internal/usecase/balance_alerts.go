@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func (s *WalletService) AddBalanceThreshold(ctx context.Context, userID uuid.UUID, amount, hysteresis big.Int) (_ *entities.BalanceThreshold, err error) {
+	ctx, end := startSpan(ctx, "AddBalanceThreshold")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	thresholdAmount, err := valueobjects.NewMoney(&amount, wallet.Currency)
+	if err != nil {
+		return nil, entities.ErrInvalidAmount
+	}
+
+	hysteresisAmount, err := valueobjects.NewMoney(&hysteresis, wallet.Currency)
+	if err != nil {
+		return nil, entities.ErrInvalidAmount
+	}
+
+	threshold, err := entities.NewBalanceThreshold(wallet, thresholdAmount, hysteresisAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ThresholdRepo.Create(ctx, threshold); err != nil {
+		return nil, err
+	}
+	return threshold, nil
+}
+
+func (s *WalletService) ListBalanceThresholds(ctx context.Context, userID uuid.UUID) (_ []*entities.BalanceThreshold, err error) {
+	ctx, end := startSpan(ctx, "ListBalanceThresholds")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ThresholdRepo.FindByWalletID(ctx, wallet.ID)
+}
+
+func (s *WalletService) RemoveBalanceThreshold(ctx context.Context, userID, thresholdID uuid.UUID) (err error) {
+	ctx, end := startSpan(ctx, "RemoveBalanceThreshold")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.ThresholdRepo.Delete(ctx, wallet.ID, thresholdID)
+}
+
+// evaluateBalanceAlerts runs after a balance change is committed, failures are only logged
+// because the change itself already happened
+func (s *WalletService) evaluateBalanceAlerts(ctx context.Context, wallet *entities.Wallet, previous valueobjects.Money) {
+	balance := wallet.Balance
+
+	if isPositive(previous) && !isPositive(balance) {
+		s.publishAlert(ctx, &events.WalletBalanceDepleted{
+			WalletID:  wallet.ID.String(),
+			UserID:    wallet.UserID.String(),
+			Balance:   balance.Amount().Int64(),
+			Currency:  wallet.Currency,
+			TimeStamp: time.Now(),
+		})
+	}
+
+	thresholds, err := s.ThresholdRepo.FindByWalletID(ctx, wallet.ID)
+	if err != nil {
+		s.log.Error(ctx, "Error loading balance thresholds:", "wallet_id", wallet.ID, "error", err)
+		return
+	}
+
+	for _, threshold := range thresholds {
+		switch {
+		case threshold.Crossed(balance):
+			changed, err := s.ThresholdRepo.SetArmed(ctx, threshold.ID, false)
+			if err != nil {
+				s.log.Error(ctx, "Error disarming balance threshold:", "threshold_id", threshold.ID, "error", err)
+				continue
+			}
+			if !changed {
+				// another debit already alerted for this crossing
+				continue
+			}
+			s.publishAlert(ctx, &events.WalletBalanceLow{
+				WalletID:    wallet.ID.String(),
+				UserID:      wallet.UserID.String(),
+				ThresholdID: threshold.ID.String(),
+				Threshold:   threshold.Amount.Amount().Int64(),
+				Balance:     balance.Amount().Int64(),
+				Currency:    wallet.Currency,
+				TimeStamp:   time.Now(),
+			})
+		case threshold.Recovered(balance):
+			if _, err := s.ThresholdRepo.SetArmed(ctx, threshold.ID, true); err != nil {
+				s.log.Error(ctx, "Error arming balance threshold:", "threshold_id", threshold.ID, "error", err)
+			}
+		}
+	}
+}
+
+func (s *WalletService) publishAlert(ctx context.Context, event events.SMSEvent) {
+	if err := s.Publish(ctx, event); err != nil {
+		s.log.Error(ctx, "Error publishing balance alert:", "event_type", event.EventType(), "error", err)
+	}
+}
+
+func isPositive(m valueobjects.Money) bool {
+	return !m.IsZero() && !m.IsNegative()
+}
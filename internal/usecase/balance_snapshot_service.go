@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BalanceSnapshotService struct {
+	SnapshotRepo    entities.BalanceSnapshotRepo
+	WalletRepo      entities.WalletRepo
+	TransactionRepo entities.TransactionRepo
+	log             *logger.Logger
+}
+
+func NewBalanceSnapshotService(snapshotRepo entities.BalanceSnapshotRepo, walletRepo entities.WalletRepo, transactionRepo entities.TransactionRepo, log *logger.Logger) *BalanceSnapshotService {
+	return &BalanceSnapshotService{
+		SnapshotRepo:    snapshotRepo,
+		WalletRepo:      walletRepo,
+		TransactionRepo: transactionRepo,
+		log:             log,
+	}
+}
+
+// GetBalanceAt is the wallet balance made of the completed transactions created before at, it starts from
+// the last snapshot so only the transactions after it are summed
+func (s *BalanceSnapshotService) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (*entities.PointInTimeBalance, error) {
+	wallet, err := s.WalletRepo.FindByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, snapshot, err := s.balanceAt(ctx, wallet, at)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.PointInTimeBalance{
+		WalletID: wallet.ID,
+		Currency: wallet.Currency,
+		At:       at,
+		Balance:  balance,
+	}
+	if snapshot != nil {
+		result.SnapshotAt = &snapshot.TakenAt
+	}
+	return result, nil
+}
+
+func (s *BalanceSnapshotService) balanceAt(ctx context.Context, wallet *entities.Wallet, at time.Time) (*big.Int, *entities.BalanceSnapshot, error) {
+	snapshot, err := s.SnapshotRepo.FindLatest(ctx, wallet.ID, at)
+	if errors.Is(err, entities.ErrSnapshotNotFound) {
+		balance, err := s.TransactionRepo.BalanceAt(ctx, wallet.ID, wallet.Currency, at)
+		return balance, nil, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delta, err := s.TransactionRepo.SumBetween(ctx, wallet.ID, wallet.Currency, snapshot.TakenAt, at)
+	if err != nil {
+		return nil, nil, err
+	}
+	return new(big.Int).Add(snapshot.Balance, delta), snapshot, nil
+}
+
+// TakeSnapshots saves the balance of every wallet at takenAt, a failing wallet is logged and skipped
+// and wallets that already have a snapshot at takenAt are left as they are
+func (s *BalanceSnapshotService) TakeSnapshots(ctx context.Context, takenAt time.Time) (int, error) {
+	taken := 0
+	after := uuid.Nil
+	for {
+		ids, err := s.WalletRepo.ListIDs(ctx, after, entities.ReconcileBatchSize)
+		if err != nil {
+			return taken, err
+		}
+		for _, walletID := range ids {
+			if err := ctx.Err(); err != nil {
+				return taken, err
+			}
+			ok, err := s.takeSnapshot(ctx, walletID, takenAt)
+			if err != nil {
+				s.log.Error(ctx, "Error taking balance snapshot:", "wallet_id", walletID, "error", err)
+				continue
+			}
+			if ok {
+				taken++
+			}
+		}
+		if len(ids) < entities.ReconcileBatchSize {
+			return taken, nil
+		}
+		after = ids[len(ids)-1]
+	}
+}
+
+func (s *BalanceSnapshotService) takeSnapshot(ctx context.Context, walletID uuid.UUID, takenAt time.Time) (bool, error) {
+	wallet, err := s.WalletRepo.FindByID(ctx, walletID)
+	if err != nil {
+		return false, err
+	}
+
+	balance, previous, err := s.balanceAt(ctx, wallet, takenAt)
+	if err != nil {
+		return false, err
+	}
+	if previous != nil && previous.TakenAt.Equal(takenAt) {
+		return false, nil
+	}
+
+	if err := s.SnapshotRepo.Save(ctx, entities.NewBalanceSnapshot(wallet, balance, takenAt)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BonusInput struct {
+	Amount big.Int
+	// lower priorities are spent first, paid balance sits at entities.PaidBucketPriority
+	Priority  int
+	ExpiresAt *time.Time
+	Reason    string
+}
+
+func (s *WalletService) GrantBonusCredit(ctx context.Context, userID uuid.UUID, input BonusInput) (_ *entities.BalanceBucket, err error) {
+	ctx, end := startSpan(ctx, "GrantBonusCredit")
+	defer end(&err)
+	var bucket *entities.BalanceBucket
+	var credited *entities.Wallet
+	var previousBalance valueobjects.Money
+
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.LockByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := valueobjects.NewMoney(&input.Amount, wallet.Currency)
+		if err != nil {
+			return entities.ErrInvalidAmount
+		}
+
+		previousBalance = wallet.Balance
+		bucket, err = wallet.GrantBonus(amount, input.Priority, input.ExpiresAt, input.Reason)
+		if err != nil {
+			return err
+		}
+
+		transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), amount, entities.TransactionCredit)
+		transaction.Category = entities.CategoryBonus
+		transaction.ReferenceID = bucket.ID
+		transaction.Funding = []entities.Funding{{BucketID: bucket.ID, Kind: entities.BucketBonus, Amount: amount}}
+		if err := s.recordBucketTransaction(ctx, walletRepo, txRepo, wallet, previousBalance, transaction); err != nil {
+			return err
+		}
+
+		credited = wallet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.evaluateBalanceAlerts(ctx, credited, previousBalance)
+	return bucket, nil
+}
+
+func (s *WalletService) ListBalanceBuckets(ctx context.Context, userID uuid.UUID) (_ []*entities.BalanceBucket, err error) {
+	ctx, end := startSpan(ctx, "ListBalanceBuckets")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return wallet.Buckets, nil
+}
+
+// ExpireBonusCredit removes unused bonus credit that expired before now, each wallet gets a
+// bonus_expiry debit so the balance change can be audited. It returns how many wallets were touched.
+func (s *WalletService) ExpireBonusCredit(ctx context.Context, now time.Time) (_ int, err error) {
+	ctx, end := startSpan(ctx, "ExpireBonusCredit")
+	defer end(&err)
+	walletIDs, err := s.WalletRepo.FindWalletIDsWithExpiredBuckets(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, walletID := range walletIDs {
+		ok, err := s.expireWalletBonus(ctx, walletID, now)
+		if err != nil {
+			s.log.Error(ctx, "Error expiring bonus credit:", "wallet_id", walletID, "error", err)
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (s *WalletService) expireWalletBonus(ctx context.Context, walletID uuid.UUID, now time.Time) (bool, error) {
+	var expired *entities.Wallet
+	var previousBalance valueobjects.Money
+
+	err := s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.LockByID(ctx, walletID)
+		if err != nil {
+			return err
+		}
+
+		previousBalance = wallet.Balance
+		funding, err := wallet.ExpireBuckets(now)
+		if err != nil {
+			return err
+		}
+		if len(funding) == 0 {
+			// a debit or another run got there first
+			return nil
+		}
+
+		amount := entities.FundingTotal(funding, wallet.Currency)
+		transaction := entities.NewTransaction(wallet.ID, wallet.UserID, uuid.New(), amount, entities.TransactionDebit)
+		transaction.Category = entities.CategoryBonusExpiry
+		transaction.Funding = funding
+		if err := s.recordBucketTransaction(ctx, walletRepo, txRepo, wallet, previousBalance, transaction); err != nil {
+			return err
+		}
+
+		expired = wallet
+		return nil
+	})
+	if err != nil || expired == nil {
+		return false, err
+	}
+
+	s.evaluateBalanceAlerts(ctx, expired, previousBalance)
+	s.evaluateTopUpRules(ctx, expired)
+	return true, nil
+}
+
+// recordBucketTransaction stores a completed transaction for a bucket change already applied to the wallet,
+// before is the balance ahead of the change
+func (s *WalletService) recordBucketTransaction(ctx context.Context, walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, wallet *entities.Wallet, before valueobjects.Money, transaction *entities.Transaction) error {
+	if err := txRepo.Create(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := s.saveBalance(ctx, walletRepo, wallet, before, transaction); err != nil {
+		return err
+	}
+
+	if err := txRepo.SaveFunding(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := transaction.MarkCompleted(); err != nil {
+		return err
+	}
+
+	return txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted)
+}
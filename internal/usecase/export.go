@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/pkg/logger"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultExportColumns are exported when the request names no columns
+var DefaultExportColumns = []string{
+	"id", "created_at", "wallet_id", "user_id", "type", "category", "status", "amount", "currency",
+}
+
+// exportColumns renders each exportable field as text, times in the export timezone
+var exportColumns = map[string]func(tx *entities.Transaction, loc *time.Location) string{
+	"id": func(tx *entities.Transaction, _ *time.Location) string { return tx.ID.String() },
+	"created_at": func(tx *entities.Transaction, loc *time.Location) string {
+		return tx.CreatedAt.In(loc).Format(time.RFC3339)
+	},
+	"updated_at": func(tx *entities.Transaction, loc *time.Location) string {
+		return tx.UpdatedAt.In(loc).Format(time.RFC3339)
+	},
+	"wallet_id":    func(tx *entities.Transaction, _ *time.Location) string { return tx.WalletID.String() },
+	"user_id":      func(tx *entities.Transaction, _ *time.Location) string { return tx.UserID.String() },
+	"type":         func(tx *entities.Transaction, _ *time.Location) string { return string(tx.Type) },
+	"category":     func(tx *entities.Transaction, _ *time.Location) string { return string(tx.Category) },
+	"status":       func(tx *entities.Transaction, _ *time.Location) string { return string(tx.Status) },
+	"amount":       func(tx *entities.Transaction, _ *time.Location) string { return exportMoney(tx.Amount) },
+	"currency":     func(tx *entities.Transaction, _ *time.Location) string { return tx.Amount.Currency() },
+	"net_amount":   func(tx *entities.Transaction, _ *time.Location) string { return exportMoney(tx.NetAmount) },
+	"tax_amount":   func(tx *entities.Transaction, _ *time.Location) string { return exportMoney(tx.TaxAmount) },
+	"gross_amount": func(tx *entities.Transaction, _ *time.Location) string { return exportMoney(tx.GrossAmount) },
+	"tax_rule_id":  func(tx *entities.Transaction, _ *time.Location) string { return exportID(tx.TaxRuleID) },
+	"tax_rate_bps": func(tx *entities.Transaction, _ *time.Location) string { return strconv.FormatInt(tx.TaxRateBPS, 10) },
+	"sms_id":       func(tx *entities.Transaction, _ *time.Location) string { return exportID(tx.SMSID) },
+	"reference_id": func(tx *entities.Transaction, _ *time.Location) string { return exportID(tx.ReferenceID) },
+	"message_type": func(tx *entities.Transaction, _ *time.Location) string { return string(tx.MessageType) },
+	"segments":     func(tx *entities.Transaction, _ *time.Location) string { return strconv.FormatInt(tx.Segments, 10) },
+	"tariff_version": func(tx *entities.Transaction, _ *time.Location) string {
+		return strconv.FormatInt(tx.TariffVersion, 10)
+	},
+	"plan_id":         func(tx *entities.Transaction, _ *time.Location) string { return exportID(tx.PlanID) },
+	"discount_bps":    func(tx *entities.Transaction, _ *time.Location) string { return strconv.FormatInt(tx.DiscountBPS, 10) },
+	"source_amount":   func(tx *entities.Transaction, _ *time.Location) string { return exportMoney(tx.SourceAmount) },
+	"source_currency": func(tx *entities.Transaction, _ *time.Location) string { return tx.SourceAmount.Currency() },
+	"fx_rate":         func(tx *entities.Transaction, _ *time.Location) string { return tx.FXRate },
+}
+
+// unset money has no currency, it is exported as an empty value
+func exportMoney(money valueobjects.Money) string {
+	if money.Currency() == "" {
+		return ""
+	}
+	return money.Amount().String()
+}
+
+func exportID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+type ExportOptions struct {
+	Format entities.ExportFormat
+	// Columns in output order, empty means DefaultExportColumns
+	Columns []string
+	// Location of exported times, nil means UTC
+	Location *time.Location
+	From     time.Time
+	To       time.Time
+}
+
+// TransactionExport is a checked export request, nothing is read until Stream is called
+type TransactionExport struct {
+	Format  entities.ExportFormat
+	columns []string
+	loc     *time.Location
+	filter  entities.TransactionFilter
+	repo    entities.TransactionRepo
+	log     *logger.Logger
+}
+
+// ExportTransactions prepares the export of the user's wallet transactions
+func (s *WalletService) ExportTransactions(ctx context.Context, userID uuid.UUID, opts ExportOptions) (_ *TransactionExport, err error) {
+	ctx, end := startSpan(ctx, "ExportTransactions")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.newTransactionExport(&wallet.ID, opts)
+}
+
+// ExportAllTransactions prepares the export of the transactions of every wallet
+func (s *WalletService) ExportAllTransactions(ctx context.Context, opts ExportOptions) (_ *TransactionExport, err error) {
+	ctx, end := startSpan(ctx, "ExportAllTransactions")
+	defer end(&err)
+	return s.newTransactionExport(nil, opts)
+}
+
+func (s *WalletService) newTransactionExport(walletID *uuid.UUID, opts ExportOptions) (*TransactionExport, error) {
+	if !opts.Format.Valid() {
+		return nil, entities.ErrInvalidExportFormat
+	}
+	if !opts.To.After(opts.From) {
+		return nil, entities.ErrInvalidExportPeriod
+	}
+
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultExportColumns
+	}
+	for _, column := range columns {
+		if _, ok := exportColumns[column]; !ok {
+			return nil, fmt.Errorf("%w: %s", entities.ErrUnknownExportColumn, column)
+		}
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return &TransactionExport{
+		Format:  opts.Format,
+		columns: columns,
+		loc:     loc,
+		filter:  entities.TransactionFilter{WalletID: walletID, From: opts.From, To: opts.To},
+		repo:    s.TransactionRepo,
+		log:     s.log,
+	}, nil
+}
+
+// Stream writes the export to w row by row, the response is already under way when it fails so the
+// error is logged here as well
+func (e *TransactionExport) Stream(ctx context.Context, w io.Writer) error {
+	var err error
+	switch e.Format {
+	case entities.ExportCSV:
+		err = e.streamCSV(ctx, w)
+	default:
+		err = e.streamJSONL(ctx, w)
+	}
+	if err != nil {
+		e.log.Error(ctx, "Error streaming transaction export:", "error", err)
+	}
+	return err
+}
+
+func (e *TransactionExport) streamCSV(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(e.columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(e.columns))
+	err := e.repo.Stream(ctx, e.filter, func(tx *entities.Transaction) error {
+		for i, column := range e.columns {
+			record[i] = exportColumns[column](tx, e.loc)
+		}
+		return writer.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// values are written as json strings so big amounts keep their precision, keys follow the column order
+func (e *TransactionExport) streamJSONL(ctx context.Context, w io.Writer) error {
+	return e.repo.Stream(ctx, e.filter, func(tx *entities.Transaction) error {
+		line := []byte{'{'}
+		for i, column := range e.columns {
+			if i > 0 {
+				line = append(line, ',')
+			}
+			key, _ := json.Marshal(column)
+			value, err := json.Marshal(exportColumns[column](tx, e.loc))
+			if err != nil {
+				return err
+			}
+			line = append(line, key...)
+			line = append(line, ':')
+			line = append(line, value...)
+		}
+		line = append(line, '}', '\n')
+		_, err := w.Write(line)
+		return err
+	})
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreditUserBalanceIn credits an amount given in any currency, money in a currency the wallet
+// has a sub-balance for lands there, other currencies are converted with the rate valid now
+func (s *WalletService) CreditUserBalanceIn(ctx context.Context, userID uuid.UUID, amount *big.Int, currency string) (_ *entities.Transaction, err error) {
+	ctx, end := startSpan(ctx, "CreditUserBalanceIn")
+	defer end(&err)
+	currency = strings.ToUpper(currency)
+	return s.creditUserBalance(ctx, userID, func(tx *gorm.DB, wallet *entities.Wallet) (*entities.Transaction, error) {
+		if currency == "" {
+			currency = wallet.Currency
+		}
+		source, err := valueobjects.NewMoney(amount, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		if currency == wallet.Currency || wallet.SubBalance(currency) != nil {
+			transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), source, entities.TransactionCredit)
+			return transaction, s.taxCredit(ctx, tx, transaction)
+		}
+
+		rate, err := findFXRate(ctx, s.FXRateRepo.WithTx(tx), currency, wallet.Currency, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		converted, err := rate.Convert(source)
+		if err != nil {
+			return nil, err
+		}
+
+		transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), converted, entities.TransactionCredit)
+		transaction.Converted(source, rate)
+		return transaction, s.taxCredit(ctx, tx, transaction)
+	})
+}
+
+func (s *WalletService) OpenSubBalance(ctx context.Context, userID uuid.UUID, currency string) (_ *entities.SubBalance, err error) {
+	ctx, end := startSpan(ctx, "OpenSubBalance")
+	defer end(&err)
+	var sub *entities.SubBalance
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.LockByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if sub, err = wallet.AddSubBalance(strings.ToUpper(currency)); err != nil {
+			return err
+		}
+		return walletRepo.UpdateBalance(ctx, wallet)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ConvertSubBalance moves amount out of the currency sub-balance into the wallet balance
+func (s *WalletService) ConvertSubBalance(ctx context.Context, userID uuid.UUID, currency string, amount *big.Int) (_ *entities.Transaction, err error) {
+	ctx, end := startSpan(ctx, "ConvertSubBalance")
+	defer end(&err)
+	currency = strings.ToUpper(currency)
+	return s.creditUserBalance(ctx, userID, func(tx *gorm.DB, wallet *entities.Wallet) (*entities.Transaction, error) {
+		source, err := valueobjects.NewMoney(amount, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := findFXRate(ctx, s.FXRateRepo.WithTx(tx), currency, wallet.Currency, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		converted, err := rate.Convert(source)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := wallet.DebitSubBalance(source); err != nil {
+			return nil, err
+		}
+
+		transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), converted, entities.TransactionCredit)
+		transaction.Category = entities.CategoryFXConversion
+		transaction.Converted(source, rate)
+
+		// the sub-balance side is a debit in its own currency, it references the credit it became
+		debit := entities.NewTransaction(wallet.ID, userID, transaction.SMSID, source, entities.TransactionDebit)
+		debit.Category = entities.CategoryFXConversion
+		debit.ReferenceID = transaction.ID
+		if err := s.recordCompleted(ctx, s.TransactionRepo.WithTx(tx), debit); err != nil {
+			return nil, err
+		}
+		return transaction, nil
+	})
+}
+
+// recordCompleted stores a transaction whose balance change the caller applies itself
+func (s *WalletService) recordCompleted(ctx context.Context, txRepo entities.TransactionRepo, transaction *entities.Transaction) error {
+	if err := txRepo.Create(ctx, transaction); err != nil {
+		return err
+	}
+	if err := transaction.MarkCompleted(); err != nil {
+		return err
+	}
+	return txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted)
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type FXService struct {
+	FXRateRepo entities.FXRateRepo
+	TxManager  storage.TransactionManager
+	log        *logger.Logger
+}
+
+func NewFXService(fxRateRepo entities.FXRateRepo, txManager storage.TransactionManager, log *logger.Logger) *FXService {
+	return &FXService{
+		FXRateRepo: fxRateRepo,
+		TxManager:  txManager,
+		log:        log,
+	}
+}
+
+type FXRateInput struct {
+	Base       string
+	Quote      string
+	Rate       string
+	ValidFrom  time.Time
+	ValidUntil *time.Time
+	Source     entities.FXRateSource
+}
+
+// SaveRate stores a new rate, older rates of the pair stay for the transactions that used them
+func (s *FXService) SaveRate(ctx context.Context, input FXRateInput) (*entities.FXRate, error) {
+	rate, err := entities.NewFXRate(input.Base, input.Quote, input.Rate, input.ValidFrom, input.ValidUntil, input.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		return s.FXRateRepo.WithTx(tx).Create(ctx, rate)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *FXService) ListRates(ctx context.Context, base, quote string) ([]*entities.FXRate, error) {
+	return s.FXRateRepo.List(ctx, base, quote)
+}
+
+// GetRate returns the rate to convert base into quote at the given time
+func (s *FXService) GetRate(ctx context.Context, base, quote string, at time.Time) (*entities.FXRate, error) {
+	return findFXRate(ctx, s.FXRateRepo, base, quote, at)
+}
+
+// findFXRate falls back to the inverse of the quote/base rate when the pair is only stored the other way
+func findFXRate(ctx context.Context, repo entities.FXRateRepo, base, quote string, at time.Time) (*entities.FXRate, error) {
+	rate, err := repo.FindValid(ctx, base, quote, at)
+	if err == nil {
+		return rate, nil
+	}
+	if !errors.Is(err, entities.ErrFXRateNotFound) {
+		return nil, err
+	}
+
+	inverse, err := repo.FindValid(ctx, quote, base, at)
+	if err != nil {
+		return nil, err
+	}
+	return inverse.Inverse(), nil
+}
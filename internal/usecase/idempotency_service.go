@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IdempotencyService struct {
+	IdempotencyRepo entities.IdempotencyRepo
+	TxManager       storage.TransactionManager
+	log             *logger.Logger
+}
+
+func NewIdempotencyService(idempotencyRepo entities.IdempotencyRepo, txManager storage.TransactionManager, log *logger.Logger) *IdempotencyService {
+	return &IdempotencyService{
+		IdempotencyRepo: idempotencyRepo,
+		TxManager:       txManager,
+		log:             log,
+	}
+}
+
+// Execute runs the request once per key. A repeated request gets the stored response back with
+// replayed set, a repeated key with another request fails with ErrIdempotencyKeyReused and a key
+// whose request is still running with ErrIdempotencyKeyInProgress. The key is claimed and the
+// response stored in transactions of their own, run is not inside either. When run returns no
+// response, like for an error, the claim is released and its error is returned as is
+func (s *IdempotencyService) Execute(ctx context.Context, record *entities.IdempotencyRecord, run func() (*entities.IdempotentResponse, error)) (response *entities.IdempotentResponse, replayed bool, err error) {
+	var claimed *entities.IdempotencyRecord
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		idempotencyRepo := s.IdempotencyRepo.WithTx(tx)
+
+		stored, err := idempotencyRepo.Acquire(ctx, record)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		switch {
+		case stored.Replayable(now):
+			if stored.RequestHash != record.RequestHash {
+				return entities.ErrIdempotencyKeyReused
+			}
+			response, replayed = stored.Response, true
+			return nil
+		case stored.InProgress(now):
+			return entities.ErrIdempotencyKeyInProgress
+		}
+
+		stored.Claim(record.RequestHash, now)
+		claimed = stored
+		return idempotencyRepo.Save(ctx, stored)
+	})
+	switch {
+	case errors.Is(err, entities.ErrIdempotencyKeyReused), errors.Is(err, entities.ErrIdempotencyKeyInProgress):
+		return nil, false, err
+	case err != nil:
+		s.log.Error(ctx, "Error acquiring idempotency key:", "error", err, "key", record.Key, "route", record.Route)
+		return nil, false, err
+	case replayed:
+		return response, true, nil
+	}
+
+	response, err = run()
+	if err != nil || response == nil {
+		if releaseErr := s.IdempotencyRepo.Release(ctx, claimed); releaseErr != nil {
+			// the claim lapses after IdempotencyLease, a retry runs the request then
+			s.log.Error(ctx, "Error releasing idempotency key:", "error", releaseErr, "key", record.Key, "route", record.Route)
+		}
+		return nil, false, err
+	}
+
+	claimed.Complete(record.RequestHash, response, time.Now())
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		return s.IdempotencyRepo.WithTx(tx).Save(ctx, claimed)
+	})
+	if err != nil {
+		// the request went through, its response is sent even though a retry will run it again
+		s.log.Error(ctx, "Error storing idempotent response:", "error", err, "key", record.Key, "route", record.Route)
+	}
+	return response, false, nil
+}
+
+// DeleteExpired removes the records whose responses are no longer replayed
+func (s *IdempotencyService) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return s.IdempotencyRepo.DeleteExpired(ctx, before)
+}
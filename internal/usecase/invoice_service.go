@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type InvoiceService struct {
+	InvoiceRepo     entities.InvoiceRepo
+	WalletRepo      entities.WalletRepo
+	UserRepo        entities.UserRepo
+	TransactionRepo entities.TransactionRepo
+	TxManager       storage.TransactionManager
+	log             *logger.Logger
+}
+
+func NewInvoiceService(invoiceRepo entities.InvoiceRepo, walletRepo entities.WalletRepo, userRepo entities.UserRepo, transactionRepo entities.TransactionRepo, txManager storage.TransactionManager, log *logger.Logger) *InvoiceService {
+	return &InvoiceService{
+		InvoiceRepo:     invoiceRepo,
+		WalletRepo:      walletRepo,
+		UserRepo:        userRepo,
+		TransactionRepo: transactionRepo,
+		TxManager:       txManager,
+		log:             log,
+	}
+}
+
+// GenerateInvoice builds the invoice of the month containing month, generating it again returns
+// the stored invoice since invoices are immutable
+func (s *InvoiceService) GenerateInvoice(ctx context.Context, userID uuid.UUID, month time.Time) (*entities.Invoice, error) {
+	from, to := entities.MonthPeriod(month)
+	if to.After(time.Now()) {
+		return nil, entities.ErrPeriodNotClosed
+	}
+
+	var invoice *entities.Invoice
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		walletRepo := s.WalletRepo.WithTx(tx)
+		txRepo := s.TransactionRepo.WithTx(tx)
+		invoiceRepo := s.InvoiceRepo.WithTx(tx)
+
+		wallet, err := walletRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		// serializes generation for the wallet so the period is only numbered once
+		if wallet, err = walletRepo.LockByID(ctx, wallet.ID); err != nil {
+			return err
+		}
+
+		existing, err := invoiceRepo.FindByPeriod(ctx, wallet.ID, from)
+		if err == nil {
+			invoice = existing
+			return nil
+		}
+		if !errors.Is(err, entities.ErrInvoiceNotFound) {
+			return err
+		}
+
+		opening, err := txRepo.BalanceAt(ctx, wallet.ID, wallet.Currency, from)
+		if err != nil {
+			return err
+		}
+		lines, err := txRepo.StatementLines(ctx, wallet.ID, wallet.Currency, from, to)
+		if err != nil {
+			return err
+		}
+
+		if invoice, err = entities.NewInvoice(wallet, from, to, opening, lines); err != nil {
+			return err
+		}
+		sequence, err := invoiceRepo.NextSequence(ctx)
+		if err != nil {
+			return err
+		}
+		invoice.AssignNumber(sequence)
+		return invoiceRepo.Create(ctx, invoice)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GenerateMonthlyInvoices invoices every user with a wallet for the month, a failing user is
+// logged and skipped so one bad wallet does not hold back the rest
+func (s *InvoiceService) GenerateMonthlyInvoices(ctx context.Context, month time.Time) (int, error) {
+	users, err := s.UserRepo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, user := range users {
+		if user.WalletID == nil {
+			continue
+		}
+		if _, err := s.GenerateInvoice(ctx, user.ID, month); err != nil {
+			s.log.Error(ctx, "Error generating invoice:", "user_id", user.ID, "error", err)
+			continue
+		}
+		generated++
+	}
+	return generated, nil
+}
+
+func (s *InvoiceService) GetInvoice(ctx context.Context, ID uuid.UUID) (*entities.Invoice, error) {
+	return s.InvoiceRepo.FindByID(ctx, ID)
+}
+
+// GetUserInvoice returns the invoice only when its wallet belongs to the user, the invoice of
+// another user's wallet is not found
+func (s *InvoiceService) GetUserInvoice(ctx context.Context, userID, ID uuid.UUID) (*entities.Invoice, error) {
+	invoice, err := s.InvoiceRepo.FindByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := s.WalletRepo.FindByID(ctx, invoice.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.UserID != userID {
+		return nil, entities.ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) ListInvoices(ctx context.Context, userID uuid.UUID) ([]*entities.Invoice, error) {
+	return s.InvoiceRepo.ListByUserID(ctx, userID)
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/metrics"
+	"math/big"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// failureReasons names the rejections worth telling apart on a dashboard, anything else is "error"
+var failureReasons = []struct {
+	err    error
+	reason string
+}{
+	{entities.ErrInsufficientBalance, "insufficient_balance"},
+	{entities.ErrLimitExceeded, "limit_exceeded"},
+	{entities.ErrParentQuotaReached, "parent_quota_reached"},
+	{entities.ErrInvalidAmount, "invalid_amount"},
+	{entities.ErrInvalidSMSMetadata, "invalid_sms"},
+	{entities.ErrNoMatchingRate, "no_matching_rate"},
+	{entities.ErrTariffNotFound, "tariff_not_found"},
+	{entities.ErrWalletNotFound, "wallet_not_found"},
+	{entities.ErrFXRateNotFound, "fx_rate_not_found"},
+	{entities.ErrSubBalanceNotFound, "sub_balance_not_found"},
+	{entities.ErrVoucherNotActive, "voucher_not_active"},
+	{entities.ErrVoucherExhausted, "voucher_exhausted"},
+	{entities.ErrVoucherLimitReached, "voucher_limit_reached"},
+	{entities.ErrInvalidTransactionState, "invalid_transaction_state"},
+}
+
+func failureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, r := range failureReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "error"
+}
+
+// observeOperation counts a debit, credit or refund and times its db transaction, the amount of a
+// successful one is added to its currency
+func observeOperation(counter *prometheus.CounterVec, operation, outcome string, started time.Time, amount *valueobjects.Money, err error) {
+	if err != nil {
+		outcome = metrics.OutcomeFailure
+	}
+	counter.WithLabelValues(outcome, failureReason(err)).Inc()
+	metrics.DBTransactionDuration.WithLabelValues(operation, metrics.Outcome(err)).Observe(time.Since(started).Seconds())
+	if err != nil || amount == nil {
+		return
+	}
+	if minor, _ := new(big.Float).SetInt(amount.Amount()).Float64(); minor > 0 {
+		metrics.Amounts.WithLabelValues(operation, amount.Currency()).Add(minor)
+	}
+}
+
+// Publish sends the event and counts the ones the publisher failed to send
+func (s *WalletService) Publish(ctx context.Context, event events.SMSEvent) error {
+	err := s.Publisher.PublishEvent(ctx, event)
+	if err != nil {
+		metrics.PublishFailures.WithLabelValues(string(event.EventType())).Inc()
+	}
+	return err
+}
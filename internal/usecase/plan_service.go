@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PlanService struct {
+	PlanRepo  entities.PlanRepo
+	UserRepo  entities.UserRepo
+	TxManager storage.TransactionManager
+	log       *logger.Logger
+}
+
+func NewPlanService(planRepo entities.PlanRepo, userRepo entities.UserRepo, txManager storage.TransactionManager, log *logger.Logger) *PlanService {
+	return &PlanService{
+		PlanRepo:  planRepo,
+		UserRepo:  userRepo,
+		TxManager: txManager,
+		log:       log,
+	}
+}
+
+func (s *PlanService) CreatePlan(ctx context.Context, code, name string, tiers []entities.PlanTier) (*entities.Plan, error) {
+	plan, err := entities.NewPlan(code, name, tiers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.PlanRepo.Create(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan only changes future debits, past transactions keep the discount they got
+func (s *PlanService) UpdatePlan(ctx context.Context, planID uuid.UUID, name string, tiers []entities.PlanTier) (*entities.Plan, error) {
+	var updated *entities.Plan
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		planRepo := s.PlanRepo.WithTx(tx)
+		plan, err := planRepo.FindByID(ctx, planID)
+		if err != nil {
+			return err
+		}
+
+		if err := plan.Update(name, tiers); err != nil {
+			return err
+		}
+
+		if err := planRepo.Update(ctx, plan); err != nil {
+			return err
+		}
+		updated = plan
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *PlanService) DeletePlan(ctx context.Context, planID uuid.UUID) error {
+	return s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		planRepo := s.PlanRepo.WithTx(tx)
+		assigned, err := planRepo.CountActiveAssignments(ctx, planID)
+		if err != nil {
+			return err
+		}
+		if assigned > 0 {
+			return entities.ErrPlanInUse
+		}
+		return planRepo.Delete(ctx, planID)
+	})
+}
+
+func (s *PlanService) GetPlan(ctx context.Context, planID uuid.UUID) (*entities.Plan, error) {
+	return s.PlanRepo.FindByID(ctx, planID)
+}
+
+func (s *PlanService) ListPlans(ctx context.Context) ([]*entities.Plan, error) {
+	return s.PlanRepo.List(ctx)
+}
+
+// AssignPlan moves the user to the plan, the previous plan stays in the history
+func (s *PlanService) AssignPlan(ctx context.Context, userID, planID uuid.UUID) (*entities.PlanAssignment, error) {
+	if _, err := s.UserRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var assignment *entities.PlanAssignment
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		planRepo := s.PlanRepo.WithTx(tx)
+		plan, err := planRepo.FindByID(ctx, planID)
+		if err != nil {
+			return err
+		}
+
+		assignment = entities.NewPlanAssignment(userID, plan)
+		return planRepo.Assign(ctx, assignment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (s *PlanService) GetPlanHistory(ctx context.Context, userID uuid.UUID) ([]*entities.PlanAssignment, error) {
+	return s.PlanRepo.ListAssignments(ctx, userID)
+}
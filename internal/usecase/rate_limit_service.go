@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+	"strconv"
+	"time"
+)
+
+type RateLimitService struct {
+	Store   entities.RateLimitStore
+	Default entities.RateLimit
+	// PerIP limits the requests of an address before they are authenticated
+	PerIP entities.RateLimit
+	Rules []entities.RateLimitRule
+	log   *logger.Logger
+}
+
+func NewRateLimitService(store entities.RateLimitStore, defaultLimit, perIP entities.RateLimit, rules []entities.RateLimitRule, log *logger.Logger) *RateLimitService {
+	return &RateLimitService{
+		Store:   store,
+		Default: defaultLimit,
+		PerIP:   perIP,
+		Rules:   rules,
+		log:     log,
+	}
+}
+
+// Allow takes a token for the client's request to route, limited is false when no limit applies.
+// The default limit is one bucket per client, each rule has its own bucket per client. A store
+// failure lets the request through rather than failing the api
+func (s *RateLimitService) Allow(ctx context.Context, client, method, route string) (result entities.RateLimitResult, limited bool) {
+	limit, bucket := s.Default, "default"
+	best := -1
+	for i, rule := range s.Rules {
+		if rule.Matches(client, method, route) && rule.Specificity() > best {
+			limit, bucket, best = rule.Limit, "rule:"+strconv.Itoa(i), rule.Specificity()
+		}
+	}
+	return s.take(ctx, client+"|"+bucket, limit)
+}
+
+// AllowIP takes a token from the bucket of the address a request comes from, it runs before the
+// caller is known so requests with bad credentials are limited too
+func (s *RateLimitService) AllowIP(ctx context.Context, ip string) (result entities.RateLimitResult, limited bool) {
+	return s.take(ctx, "ip:"+ip, s.PerIP)
+}
+
+func (s *RateLimitService) take(ctx context.Context, key string, limit entities.RateLimit) (entities.RateLimitResult, bool) {
+	if limit.Unlimited() {
+		return entities.RateLimitResult{}, false
+	}
+
+	result, err := s.Store.Take(ctx, key, limit, time.Now())
+	if err != nil {
+		s.log.Error(ctx, "Error taking rate limit token:", "error", err, "key", key)
+		return entities.RateLimitResult{}, false
+	}
+	return result, true
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconcileBalances recomputes every wallet balance from its completed transactions and reports the
+// wallets that differ, with repair the stored balance is set to the computed one. Bonus buckets and
+// sub-balances are left as they are, only the main balance is checked.
+func (s *WalletService) ReconcileBalances(ctx context.Context, repair bool) (_ *entities.ReconcileReport, err error) {
+	ctx, end := startSpan(ctx, "ReconcileBalances")
+	defer end(&err)
+	report := &entities.ReconcileReport{
+		Repair:        repair,
+		Discrepancies: []*entities.BalanceDiscrepancy{},
+		StartedAt:     time.Now(),
+	}
+
+	after := uuid.Nil
+	for {
+		ids, err := s.WalletRepo.ListIDs(ctx, after, entities.ReconcileBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, walletID := range ids {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			discrepancy, err := s.reconcileWallet(ctx, walletID, repair)
+			if err != nil {
+				s.log.Error(ctx, "Error reconciling wallet balance:", "wallet_id", walletID, "error", err)
+				report.Failed++
+				continue
+			}
+			report.Checked++
+			if discrepancy != nil {
+				report.Discrepancies = append(report.Discrepancies, discrepancy)
+				s.publishDiscrepancy(ctx, discrepancy)
+			}
+		}
+		if len(ids) < entities.ReconcileBatchSize {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// reconcileWallet holds the wallet lock while summing, so no debit or credit lands between the two reads
+func (s *WalletService) reconcileWallet(ctx context.Context, walletID uuid.UUID, repair bool) (*entities.BalanceDiscrepancy, error) {
+	var discrepancy *entities.BalanceDiscrepancy
+	err := s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.LockByID(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		computed, err := txRepo.SumBalance(ctx, wallet.ID, wallet.Currency)
+		if err != nil {
+			return err
+		}
+
+		discrepancy = entities.CheckBalance(wallet, computed)
+		if discrepancy == nil || !repair {
+			return nil
+		}
+
+		if wallet.Balance, err = valueobjects.NewSignedMoney(computed, wallet.Currency); err != nil {
+			return err
+		}
+		wallet.UpdatedAt = time.Now()
+		if err := walletRepo.UpdateBalance(ctx, wallet); err != nil {
+			return err
+		}
+		if err := s.audit(ctx, walletRepo, wallet, discrepancy.Stored, entities.AuditRepair, nil); err != nil {
+			return err
+		}
+		discrepancy.Repaired = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return discrepancy, nil
+}
+
+func (s *WalletService) publishDiscrepancy(ctx context.Context, discrepancy *entities.BalanceDiscrepancy) {
+	event := &events.BalanceDiscrepancyDetected{
+		WalletID:   discrepancy.WalletID.String(),
+		UserID:     discrepancy.UserID.String(),
+		Currency:   discrepancy.Currency,
+		Stored:     discrepancy.Stored.String(),
+		Computed:   discrepancy.Computed.String(),
+		Difference: discrepancy.Difference.String(),
+		Repaired:   discrepancy.Repaired,
+		TimeStamp:  discrepancy.DetectedAt,
+	}
+	if err := s.Publish(ctx, event); err != nil {
+		s.log.Error(ctx, "Error publishing balance discrepancy:", "wallet_id", discrepancy.WalletID, "error", err)
+	}
+}
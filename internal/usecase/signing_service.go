@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+	"finance/pkg/signing"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SigningService struct {
+	SigningKeyRepo entities.SigningKeyRepo
+	Nonces         entities.NonceCache
+	TxManager      storage.TransactionManager
+	log            *logger.Logger
+}
+
+func NewSigningService(signingKeyRepo entities.SigningKeyRepo, nonces entities.NonceCache, txManager storage.TransactionManager, log *logger.Logger) *SigningService {
+	return &SigningService{
+		SigningKeyRepo: signingKeyRepo,
+		Nonces:         nonces,
+		TxManager:      txManager,
+		log:            log,
+	}
+}
+
+// Verify checks the signature of a request and returns the principal of its key. The nonce is
+// remembered only after the signature matched, so unsigned garbage can not fill the cache
+func (s *SigningService) Verify(ctx context.Context, req entities.SignedRequest) (entities.Principal, error) {
+	now := time.Now()
+	unix, err := strconv.ParseInt(req.Timestamp, 10, 64)
+	if err != nil {
+		return entities.Principal{}, entities.ErrInvalidSignature
+	}
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-entities.SignatureWindow)) || signedAt.After(now.Add(entities.SignatureWindow)) {
+		return entities.Principal{}, entities.ErrSignatureExpired
+	}
+	if req.Nonce == "" || !signing.Equal(req.Digest, signing.BodyDigest(req.Body)) {
+		return entities.Principal{}, entities.ErrInvalidSignature
+	}
+
+	keyID, err := uuid.Parse(req.KeyID)
+	if err != nil {
+		return entities.Principal{}, entities.ErrInvalidSignature
+	}
+	key, err := s.SigningKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, entities.ErrSigningKeyNotFound) {
+			return entities.Principal{}, entities.ErrInvalidSignature
+		}
+		s.log.Error(ctx, "Error loading signing key:", "error", err)
+		return entities.Principal{}, err
+	}
+	if !key.Usable(now) {
+		return entities.Principal{}, entities.ErrInvalidSignature
+	}
+
+	expected := signing.Signature([]byte(key.Secret), signing.StringToSign(req.Method, req.URI, req.Timestamp, req.Nonce, req.Digest))
+	if !signing.Equal(req.Signature, expected) {
+		return entities.Principal{}, entities.ErrInvalidSignature
+	}
+
+	// a request is only valid inside the window, its nonce does not need to be kept longer
+	fresh, err := s.Nonces.Remember(ctx, req.KeyID+":"+req.Nonce, signedAt.Add(entities.SignatureWindow))
+	if err != nil {
+		s.log.Error(ctx, "Error remembering request nonce:", "error", err)
+		return entities.Principal{}, err
+	}
+	if !fresh {
+		return entities.Principal{}, entities.ErrReplayedRequest
+	}
+	return key.Principal(), nil
+}
+
+// CreateClientKey creates the first key of a client, the secret has to be handed to the client
+func (s *SigningService) CreateClientKey(ctx context.Context, client string, role entities.Role) (*entities.SigningKey, error) {
+	key, err := entities.NewSigningKey(client, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SigningKeyRepo.Save(ctx, key); err != nil {
+		s.log.Error(ctx, "Error saving signing key:", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
+
+// RotateClientKey creates a new key for the client and lets its current keys expire after grace,
+// the client keeps working until it switches to the new secret
+func (s *SigningService) RotateClientKey(ctx context.Context, client string, grace time.Duration) (*entities.SigningKey, error) {
+	if grace < 0 {
+		return nil, entities.ErrInvalidSigningKey
+	}
+
+	var created *entities.SigningKey
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		keyRepo := s.SigningKeyRepo.WithTx(tx)
+
+		keys, err := keyRepo.ListByClient(ctx, client)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		role := entities.RoleService
+		var current []*entities.SigningKey
+		for _, key := range keys {
+			if key.Usable(now) {
+				current = append(current, key)
+				role = key.Role
+			}
+		}
+		if len(current) == 0 {
+			return entities.ErrSigningKeyNotFound
+		}
+
+		for _, key := range current {
+			key.ExpireAt(now.Add(grace))
+			if err := keyRepo.Update(ctx, key); err != nil {
+				return err
+			}
+		}
+		created, err = entities.NewSigningKey(client, role)
+		if err != nil {
+			return err
+		}
+		return keyRepo.Save(ctx, created)
+	})
+	if err != nil {
+		s.log.Error(ctx, "Error rotating signing key:", "error", err, "client", client)
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *SigningService) ListClientKeys(ctx context.Context, client string) ([]*entities.SigningKey, error) {
+	return s.SigningKeyRepo.ListByClient(ctx, client)
+}
+
+func (s *SigningService) RevokeKey(ctx context.Context, keyID uuid.UUID) (*entities.SigningKey, error) {
+	key, err := s.SigningKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+	key.Revoke()
+	if err := s.SigningKeyRepo.Update(ctx, key); err != nil {
+		s.log.Error(ctx, "Error revoking signing key:", "error", err)
+		return nil, err
+	}
+	return key, nil
+}
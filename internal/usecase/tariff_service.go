@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type TariffService struct {
+	TariffRepo entities.TariffRepo
+	TxManager  storage.TransactionManager
+	log        *logger.Logger
+}
+
+func NewTariffService(tariffRepo entities.TariffRepo, txManager storage.TransactionManager, log *logger.Logger) *TariffService {
+	return &TariffService{
+		TariffRepo: tariffRepo,
+		TxManager:  txManager,
+		log:        log,
+	}
+}
+
+// CreateTariff stores a new inactive version, it only prices debits after ActivateTariff
+func (s *TariffService) CreateTariff(ctx context.Context, name, currency string, rates []entities.TariffRate) (*entities.Tariff, error) {
+	tariff, err := entities.NewTariff(name, currency, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		return s.TariffRepo.WithTx(tx).Create(ctx, tariff)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tariff, nil
+}
+
+func (s *TariffService) GetTariff(ctx context.Context, version int64) (*entities.Tariff, error) {
+	return s.TariffRepo.FindByVersion(ctx, version)
+}
+
+func (s *TariffService) ListTariffs(ctx context.Context) ([]*entities.Tariff, error) {
+	return s.TariffRepo.List(ctx)
+}
+
+func (s *TariffService) ActivateTariff(ctx context.Context, version int64) (*entities.Tariff, error) {
+	var activated *entities.Tariff
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		tariffRepo := s.TariffRepo.WithTx(tx)
+		tariff, err := tariffRepo.FindByVersion(ctx, version)
+		if err != nil {
+			return err
+		}
+		if err := tariffRepo.Activate(ctx, tariff); err != nil {
+			return err
+		}
+		activated = tariff
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activated, nil
+}
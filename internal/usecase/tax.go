@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// taxFor prices the tax on amount with the rule that covers the user now, nil means no rule applies.
+// users without a row or a customer type are taxed as individuals
+func (s *WalletService) taxFor(ctx context.Context, taxRuleRepo entities.TaxRuleRepo, userRepo entities.UserRepo, userID uuid.UUID, base entities.TaxBase, amount valueobjects.Money) (*entities.TaxBreakdown, error) {
+	rules, err := taxRuleRepo.FindValid(ctx, base, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	customerType := entities.CustomerIndividual
+	user, err := userRepo.GetByID(ctx, userID)
+	switch {
+	case err == nil && user.CustomerType != "":
+		customerType = user.CustomerType
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	rule := entities.SelectTaxRule(rules, customerType)
+	if rule == nil {
+		return nil, nil
+	}
+	tax, err := rule.Apply(amount)
+	if err != nil {
+		return nil, err
+	}
+	return &tax, nil
+}
+
+// taxCredit applies the credit tax rule to a top-up, the wallet receives the net amount.
+// the rule and the user are read in the credit's transaction
+func (s *WalletService) taxCredit(ctx context.Context, tx *gorm.DB, transaction *entities.Transaction) error {
+	tax, err := s.taxFor(ctx, s.TaxRuleRepo.WithTx(tx), s.UserRepo.WithTx(tx), transaction.UserID, entities.TaxOnCredit, transaction.Amount)
+	if err != nil {
+		return err
+	}
+	if tax != nil {
+		transaction.SetTax(*tax)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/storage"
+	"finance/pkg/logger"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxService struct {
+	TaxRuleRepo     entities.TaxRuleRepo
+	TransactionRepo entities.TransactionRepo
+	UserRepo        entities.UserRepo
+	TxManager       storage.TransactionManager
+	log             *logger.Logger
+}
+
+func NewTaxService(taxRuleRepo entities.TaxRuleRepo, transactionRepo entities.TransactionRepo, userRepo entities.UserRepo, txManager storage.TransactionManager, log *logger.Logger) *TaxService {
+	return &TaxService{
+		TaxRuleRepo:     taxRuleRepo,
+		TransactionRepo: transactionRepo,
+		UserRepo:        userRepo,
+		TxManager:       txManager,
+		log:             log,
+	}
+}
+
+type TaxRuleInput struct {
+	Name         string
+	CustomerType entities.CustomerType
+	Base         entities.TaxBase
+	RateBPS      int64
+	Inclusive    bool
+	ValidFrom    time.Time
+	ValidUntil   *time.Time
+}
+
+// CreateRule stores a rule, a rate change is a new rule whose window starts when the old one ends
+func (s *TaxService) CreateRule(ctx context.Context, input TaxRuleInput) (*entities.TaxRule, error) {
+	rule, err := entities.NewTaxRule(input.Name, input.CustomerType, input.Base, input.RateBPS, input.Inclusive, input.ValidFrom, input.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		return s.TaxRuleRepo.WithTx(tx).Create(ctx, rule)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *TaxService) ListRules(ctx context.Context) ([]*entities.TaxRule, error) {
+	return s.TaxRuleRepo.List(ctx)
+}
+
+// Report totals the taxed transactions created in [from, to)
+func (s *TaxService) Report(ctx context.Context, from, to time.Time) (*entities.TaxReport, error) {
+	if !to.After(from) {
+		return nil, entities.ErrInvalidReportPeriod
+	}
+
+	totals, err := s.TransactionRepo.SumTax(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return entities.NewTaxReport(from, to, totals), nil
+}
+
+func (s *TaxService) SetCustomerType(ctx context.Context, userID uuid.UUID, customerType entities.CustomerType) (*entities.User, error) {
+	var updated *entities.User
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		userRepo := s.UserRepo.WithTx(tx)
+		user, err := userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := user.SetCustomerType(customerType); err != nil {
+			return err
+		}
+		if err := userRepo.UpdateCustomerType(ctx, user); err != nil {
+			return err
+		}
+
+		updated = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/metrics"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TopUpRuleInput struct {
+	Threshold       big.Int
+	Amount          big.Int
+	MaxPerDay       int64
+	FundingSourceID string
+	Enabled         bool
+}
+
+func (s *WalletService) CreateTopUpRule(ctx context.Context, userID uuid.UUID, input TopUpRuleInput) (_ *entities.TopUpRule, err error) {
+	ctx, end := startSpan(ctx, "CreateTopUpRule")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, amount, err := topUpAmounts(input, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := entities.NewTopUpRule(wallet, threshold, amount, input.MaxPerDay, input.FundingSourceID)
+	if err != nil {
+		return nil, err
+	}
+	rule.Enabled = input.Enabled
+
+	if err := s.TopUpRepo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *WalletService) UpdateTopUpRule(ctx context.Context, userID, ruleID uuid.UUID, input TopUpRuleInput) (_ *entities.TopUpRule, err error) {
+	ctx, end := startSpan(ctx, "UpdateTopUpRule")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.TopUpRepo.FindRuleByID(ctx, wallet.ID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold, amount, err := topUpAmounts(input, wallet.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rule.Update(threshold, amount, input.MaxPerDay, input.FundingSourceID, input.Enabled); err != nil {
+		return nil, err
+	}
+
+	if err := s.TopUpRepo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *WalletService) ListTopUpRules(ctx context.Context, userID uuid.UUID) (_ []*entities.TopUpRule, err error) {
+	ctx, end := startSpan(ctx, "ListTopUpRules")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.TopUpRepo.FindRulesByWalletID(ctx, wallet.ID)
+}
+
+func (s *WalletService) DeleteTopUpRule(ctx context.Context, userID, ruleID uuid.UUID) (err error) {
+	ctx, end := startSpan(ctx, "DeleteTopUpRule")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return s.TopUpRepo.DeleteRule(ctx, wallet.ID, ruleID)
+}
+
+// this usecase executes in a subsciber handler, once the payment service charged the funding source
+func (s *WalletService) CompleteTopUp(ctx context.Context, requestID uuid.UUID) (err error) {
+	ctx, end := startSpan(ctx, "CompleteTopUp")
+	defer end(&err)
+	var credited *entities.Wallet
+	var previousBalance valueobjects.Money
+	var credit *valueobjects.Money
+
+	started := time.Now()
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		walletRepo := s.WalletRepo.WithTx(tx)
+		txRepo := s.TransactionRepo.WithTx(tx)
+		topUpRepo := s.TopUpRepo.WithTx(tx)
+
+		request, err := topUpRepo.LockRequest(ctx, requestID)
+		if err != nil {
+			return err
+		}
+
+		// the payment service may deliver the same result twice
+		if request.Status != entities.TopUpPending {
+			return nil
+		}
+
+		wallet, err := walletRepo.LockByID(ctx, request.WalletID)
+		if err != nil {
+			return err
+		}
+
+		transaction := entities.NewTransaction(wallet.ID, request.UserID, uuid.New(), request.Amount, entities.TransactionCredit)
+		transaction.Category = entities.CategoryTopUp
+		transaction.ReferenceID = request.ID
+		if err := s.taxCredit(ctx, tx, transaction); err != nil {
+			return err
+		}
+
+		previousBalance = wallet.Balance
+		if err := s.creditWallet(ctx, walletRepo, txRepo, wallet, transaction); err != nil {
+			return err
+		}
+
+		if err := request.MarkSucceeded(); err != nil {
+			return err
+		}
+
+		if err := topUpRepo.UpdateRequestStatus(ctx, request); err != nil {
+			return err
+		}
+
+		credited = wallet
+		credit = &transaction.Amount
+		return nil
+	})
+	outcome := metrics.OutcomeSuccess
+	if err == nil && credited == nil {
+		outcome = metrics.OutcomeSkipped
+	}
+	observeOperation(metrics.Credits, metrics.OperationCredit, outcome, started, credit, err)
+	if err != nil {
+		return err
+	}
+
+	if credited != nil {
+		s.evaluateBalanceAlerts(ctx, credited, previousBalance)
+	}
+	return nil
+}
+
+// FailTopUp executes in a subscriber handler when the payment service could not charge the funding
+// source, the rule can fire again on the next debit below its threshold
+func (s *WalletService) FailTopUp(ctx context.Context, requestID uuid.UUID) (err error) {
+	ctx, end := startSpan(ctx, "FailTopUp")
+	defer end(&err)
+	return s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		topUpRepo := s.TopUpRepo.WithTx(tx)
+
+		request, err := topUpRepo.LockRequest(ctx, requestID)
+		if err != nil {
+			return err
+		}
+
+		// a duplicated or late result leaves a finished request as it is
+		if request.Status != entities.TopUpPending {
+			return nil
+		}
+
+		if err := request.MarkFailed(); err != nil {
+			return err
+		}
+		return topUpRepo.UpdateRequestStatus(ctx, request)
+	})
+}
+
+// evaluateTopUpRules runs after a debit is committed, failures are only logged
+func (s *WalletService) evaluateTopUpRules(ctx context.Context, wallet *entities.Wallet) {
+	rules, err := s.TopUpRepo.FindRulesByWalletID(ctx, wallet.ID)
+	if err != nil {
+		s.log.Error(ctx, "Error loading top-up rules:", "wallet_id", wallet.ID, "error", err)
+		return
+	}
+
+	for _, rule := range rules {
+		if !rule.ShouldFire(wallet.Balance) {
+			continue
+		}
+
+		request, err := s.fireTopUpRule(ctx, rule.ID)
+		if err != nil {
+			s.log.Error(ctx, "Error firing top-up rule:", "rule_id", rule.ID, "error", err)
+			continue
+		}
+		if request == nil {
+			continue
+		}
+
+		event := &events.TopUpRequested{
+			RequestID:       request.ID.String(),
+			RuleID:          request.RuleID.String(),
+			WalletID:        request.WalletID.String(),
+			UserID:          request.UserID.String(),
+			Amount:          request.Amount.Amount().Int64(),
+			Currency:        request.Amount.Currency(),
+			FundingSourceID: request.FundingSourceID,
+			TimeStamp:       time.Now(),
+		}
+		if err := s.Publish(ctx, event); err != nil {
+			s.log.Error(ctx, "Error publishing top-up request:", "request_id", request.ID, "error", err)
+		}
+	}
+}
+
+// fireTopUpRule returns nil when the rule is capped for today or still waits for a previous request
+func (s *WalletService) fireTopUpRule(ctx context.Context, ruleID uuid.UUID) (*entities.TopUpRequest, error) {
+	var request *entities.TopUpRequest
+
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		topUpRepo := s.TopUpRepo.WithTx(tx)
+
+		rule, err := topUpRepo.LockRule(ctx, ruleID)
+		if err != nil {
+			return err
+		}
+
+		if !rule.Enabled {
+			return nil
+		}
+
+		now := time.Now()
+		pending, err := topUpRepo.HasPendingRequestSince(ctx, rule.ID, now.Add(-entities.TopUpPendingTimeout))
+		if err != nil {
+			return err
+		}
+		if pending {
+			return nil
+		}
+
+		firedToday, err := topUpRepo.CountRequestsSince(ctx, rule.ID, entities.StartOfDay(now))
+		if err != nil {
+			return err
+		}
+		if firedToday >= rule.MaxPerDay {
+			return nil
+		}
+
+		request = entities.NewTopUpRequest(rule)
+		return topUpRepo.CreateRequest(ctx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func topUpAmounts(input TopUpRuleInput, currency string) (valueobjects.Money, valueobjects.Money, error) {
+	threshold, err := valueobjects.NewMoney(&input.Threshold, currency)
+	if err != nil {
+		return valueobjects.Money{}, valueobjects.Money{}, entities.ErrInvalidAmount
+	}
+
+	amount, err := valueobjects.NewMoney(&input.Amount, currency)
+	if err != nil {
+		return valueobjects.Money{}, valueobjects.Money{}, entities.ErrInvalidAmount
+	}
+
+	return threshold, amount, nil
+}
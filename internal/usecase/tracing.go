@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("finance/internal/usecase")
+
+// startSpan starts the span of a WalletService use case, end records the error it returns. When
+// tracing is off or the trace is not sampled the caller's context is kept as it is
+func startSpan(ctx context.Context, useCase string) (context.Context, func(err *error)) {
+	spanCtx, span := tracer.Start(ctx, "WalletService."+useCase)
+	if span.IsRecording() {
+		ctx = spanCtx
+	}
+	return ctx, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
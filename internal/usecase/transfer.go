@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TransferInput struct {
+	FromUserID     uuid.UUID
+	ToUserID       uuid.UUID
+	Amount         big.Int
+	IdempotencyKey string
+}
+
+// Transfer moves paid balance from one user's wallet to another's in one db transaction,
+// retrying with the same idempotency key returns the transfer that was already made
+func (s *WalletService) Transfer(ctx context.Context, input TransferInput) (_ *entities.Transfer, err error) {
+	ctx, end := startSpan(ctx, "Transfer")
+	defer end(&err)
+	if input.IdempotencyKey == "" {
+		return nil, entities.ErrInvalidTransfer
+	}
+
+	var transfer *entities.Transfer
+	var from, to *entities.Wallet
+	var fromPrevious, toPrevious valueobjects.Money
+
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		walletRepo := s.WalletRepo.WithTx(tx)
+		txRepo := s.TransactionRepo.WithTx(tx)
+		transferRepo := s.TransferRepo.WithTx(tx)
+
+		var err error
+		from, to, err = s.lockTransferWallets(ctx, walletRepo, input.FromUserID, input.ToUserID)
+		if err != nil {
+			return err
+		}
+
+		amount, err := valueobjects.NewMoney(&input.Amount, from.Currency)
+		if err != nil {
+			return entities.ErrInvalidAmount
+		}
+
+		// the sender's wallet is locked, so a concurrent retry waits here and then finds this transfer
+		existing, err := transferRepo.FindByIdempotencyKey(ctx, input.FromUserID, input.IdempotencyKey)
+		if err == nil {
+			if !existing.Matches(input.ToUserID, amount) {
+				return entities.ErrIdempotencyKeyReused
+			}
+			transfer, from, to = existing, nil, nil
+			return nil
+		}
+		if !errors.Is(err, entities.ErrTransferNotFound) {
+			return err
+		}
+
+		transfer, err = entities.NewTransfer(from, to, amount, input.IdempotencyKey)
+		if err != nil {
+			return err
+		}
+
+		fromPrevious, toPrevious = from.Balance, to.Balance
+
+		debit := entities.NewTransaction(from.ID, from.UserID, uuid.New(), amount, entities.TransactionDebit)
+		debit.Category = entities.CategoryTransfer
+		debit.ReferenceID = transfer.ID
+		if err := s.debitPaid(ctx, walletRepo, txRepo, from, debit); err != nil {
+			return err
+		}
+
+		credit := entities.NewTransaction(to.ID, to.UserID, uuid.New(), amount, entities.TransactionCredit)
+		credit.Category = entities.CategoryTransfer
+		credit.ReferenceID = transfer.ID
+		if err := s.creditWallet(ctx, walletRepo, txRepo, to, credit); err != nil {
+			return err
+		}
+
+		transfer.DebitTransactionID = debit.ID
+		transfer.CreditTransactionID = credit.ID
+		return transferRepo.Create(ctx, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if from != nil {
+		s.evaluateBalanceAlerts(ctx, from, fromPrevious)
+		s.evaluateBalanceAlerts(ctx, to, toPrevious)
+		s.evaluateTopUpRules(ctx, from)
+	}
+	return transfer, nil
+}
+
+func (s *WalletService) GetTransfer(ctx context.Context, ID uuid.UUID) (_ *entities.Transfer, err error) {
+	ctx, end := startSpan(ctx, "GetTransfer")
+	defer end(&err)
+	return s.TransferRepo.FindByID(ctx, ID)
+}
+
+// lockTransferWallets locks both wallets in wallet ID order, so transfers in opposite
+// directions between the same wallets can not deadlock
+func (s *WalletService) lockTransferWallets(ctx context.Context, walletRepo entities.WalletRepo, fromUserID, toUserID uuid.UUID) (*entities.Wallet, *entities.Wallet, error) {
+	if fromUserID == toUserID {
+		return nil, nil, entities.ErrSameWallet
+	}
+
+	from, err := walletRepo.FindByUserID(ctx, fromUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := walletRepo.FindByUserID(ctx, toUserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	locked, err := lockWallets(ctx, walletRepo, from.ID, to.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return locked[from.ID], locked[to.ID], nil
+}
+
+// lockWallets locks the wallets in wallet ID order, every path that holds more than one
+// wallet lock goes through it so two of them can not deadlock each other
+func lockWallets(ctx context.Context, walletRepo entities.WalletRepo, IDs ...uuid.UUID) (map[uuid.UUID]*entities.Wallet, error) {
+	sorted := append([]uuid.UUID(nil), IDs...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	locked := make(map[uuid.UUID]*entities.Wallet, len(sorted))
+	for _, ID := range sorted {
+		if _, ok := locked[ID]; ok {
+			continue
+		}
+		wallet, err := walletRepo.LockByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+		locked[ID] = wallet
+	}
+	return locked, nil
+}
+
+// debitPaid records a completed debit taken from paid balance only
+func (s *WalletService) debitPaid(ctx context.Context, walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, wallet *entities.Wallet, transaction *entities.Transaction) error {
+	if err := txRepo.Create(ctx, transaction); err != nil {
+		return err
+	}
+
+	before := wallet.Balance
+	funding, err := wallet.DebitPaid(transaction.Amount)
+	if err != nil {
+		return err
+	}
+	transaction.Funding = funding
+
+	if err := s.saveBalance(ctx, walletRepo, wallet, before, transaction); err != nil {
+		return err
+	}
+
+	if err := txRepo.SaveFunding(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := transaction.MarkCompleted(); err != nil {
+		return err
+	}
+
+	return txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VoucherInput struct {
+	Count          int
+	Amount         big.Int
+	Currency       string
+	MaxRedemptions int64
+	PerUserLimit   int64
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	Campaign       string
+}
+
+func (s *WalletService) GenerateVouchers(ctx context.Context, input VoucherInput) (_ []*entities.Voucher, err error) {
+	ctx, end := startSpan(ctx, "GenerateVouchers")
+	defer end(&err)
+	amount, err := valueobjects.NewMoney(&input.Amount, input.Currency)
+	if err != nil {
+		return nil, entities.ErrInvalidVoucher
+	}
+
+	vouchers, err := entities.NewVoucherBatch(input.Count, amount, input.MaxRedemptions, input.PerUserLimit,
+		input.ValidFrom, input.ValidUntil, input.Campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.VoucherRepo.CreateBatch(ctx, vouchers); err != nil {
+		return nil, err
+	}
+	return vouchers, nil
+}
+
+func (s *WalletService) ListVouchers(ctx context.Context, campaign string) (_ []*entities.Voucher, err error) {
+	ctx, end := startSpan(ctx, "ListVouchers")
+	defer end(&err)
+	return s.VoucherRepo.List(ctx, campaign)
+}
+
+// RedeemVoucher credits the voucher amount to the user's wallet, the voucher row stays locked
+// until the credit commits so concurrent redemptions are counted one by one
+func (s *WalletService) RedeemVoucher(ctx context.Context, userID uuid.UUID, code string) (_ *entities.Transaction, err error) {
+	ctx, end := startSpan(ctx, "RedeemVoucher")
+	defer end(&err)
+	code = entities.NormalizeVoucherCode(code)
+
+	return s.creditUserBalance(ctx, userID, func(tx *gorm.DB, wallet *entities.Wallet) (*entities.Transaction, error) {
+		voucherRepo := s.VoucherRepo.WithTx(tx)
+
+		voucher, err := voucherRepo.LockByCode(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+
+		if voucher.Amount.Currency() != wallet.Currency {
+			return nil, valueobjects.ErrCurrencyMismatch
+		}
+
+		redeemed, err := voucherRepo.CountUserRedemptions(ctx, voucher.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		redemption, err := voucher.Redeem(userID, redeemed, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), voucher.Amount, entities.TransactionCredit)
+		transaction.Category = entities.CategoryVoucher
+		transaction.ReferenceID = voucher.ID
+		redemption.TransactionID = transaction.ID
+
+		if err := voucherRepo.Redeem(ctx, voucher, redemption); err != nil {
+			return nil, err
+		}
+		return transaction, nil
+	})
+}
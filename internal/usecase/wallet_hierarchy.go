@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetParentWallet makes the child user's wallet fall through to the parent user's wallet,
+// zero quota does not cap the monthly draw
+func (s *WalletService) SetParentWallet(ctx context.Context, childUserID, parentUserID uuid.UUID, quota big.Int) (_ *entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "SetParentWallet")
+	defer end(&err)
+	var updated *entities.Wallet
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		child, err := walletRepo.FindByUserID(ctx, childUserID)
+		if err != nil {
+			return err
+		}
+		parent, err := walletRepo.FindByUserID(ctx, parentUserID)
+		if err != nil {
+			return err
+		}
+
+		// a debit only falls back one level, the parent can not have a parent and the child
+		// can not have children of its own
+		if parent.HasParent() {
+			return entities.ErrInvalidParent
+		}
+		children, err := walletRepo.FindChildren(ctx, child.ID)
+		if err != nil {
+			return err
+		}
+		if len(children) > 0 {
+			return entities.ErrInvalidParent
+		}
+
+		var limit *big.Int
+		if quota.Sign() != 0 {
+			limit = new(big.Int).Set(&quota)
+		}
+		if err := child.SetParent(parent, limit); err != nil {
+			return err
+		}
+
+		if err := walletRepo.UpdateParent(ctx, child); err != nil {
+			return err
+		}
+
+		updated = child
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *WalletService) DetachParentWallet(ctx context.Context, childUserID uuid.UUID) (_ *entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "DetachParentWallet")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, childUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet.DetachParent()
+	if err := s.WalletRepo.UpdateParent(ctx, wallet); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func (s *WalletService) ListChildWallets(ctx context.Context, parentUserID uuid.UUID) (_ []*entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "ListChildWallets")
+	defer end(&err)
+	parent, err := s.WalletRepo.FindByUserID(ctx, parentUserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.WalletRepo.FindChildren(ctx, parent.ID)
+}
+
+// GetSpendingReport rolls the sms spending since the given time up from the child wallets
+func (s *WalletService) GetSpendingReport(ctx context.Context, userID uuid.UUID, since time.Time) (_ *entities.SpendingReport, err error) {
+	ctx, end := startSpan(ctx, "GetSpendingReport")
+	defer end(&err)
+	wallet, err := s.WalletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.spendingReport(ctx, wallet, since, 0)
+}
+
+func (s *WalletService) spendingReport(ctx context.Context, wallet *entities.Wallet, since time.Time, depth int) (*entities.SpendingReport, error) {
+	own, err := s.TransactionRepo.SumAmountSince(ctx, wallet.ID, entities.TransactionDebit, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entities.SpendingReport{
+		WalletID: wallet.ID,
+		UserID:   wallet.UserID,
+		Currency: wallet.Currency,
+		Own:      own,
+		Total:    new(big.Int).Set(own),
+	}
+	if depth >= entities.MaxHierarchyDepth {
+		return report, nil
+	}
+
+	children, err := s.WalletRepo.FindChildren(ctx, wallet.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		childReport, err := s.spendingReport(ctx, child, since, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		report.Children = append(report.Children, childReport)
+		report.Total.Add(report.Total, childReport.Total)
+	}
+	return report, nil
+}
+
+// splitWithParent splits a debit between the wallet and its parent, within the parent quota
+func (s *WalletService) splitWithParent(ctx context.Context, txRepo entities.TransactionRepo, wallet *entities.Wallet, amount valueobjects.Money) (valueobjects.Money, valueobjects.Money, error) {
+	var used *big.Int
+	if wallet.HasParent() && wallet.ParentQuota != nil {
+		var err error
+		used, err = txRepo.SumParentFundingSince(ctx, wallet.ID, entities.StartOfMonth(time.Now()))
+		if err != nil {
+			return valueobjects.Money{}, valueobjects.Money{}, err
+		}
+	}
+	return wallet.SplitWithParent(amount, used)
+}
+
+// drawFromParent debits the parent's share of a child's sms debit, the parent transaction
+// references the child one. tax is the parent's share of the sms tax, nil when untaxed
+func (s *WalletService) drawFromParent(ctx context.Context, walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, parent *entities.Wallet, childTx *entities.Transaction, amount valueobjects.Money, tax *entities.TaxBreakdown) error {
+	transaction := entities.NewTransaction(parent.ID, parent.UserID, childTx.SMSID, amount, entities.TransactionDebit)
+	transaction.Category = entities.CategoryDelegated
+	transaction.ReferenceID = childTx.ID
+	if tax != nil {
+		transaction.SetTax(*tax)
+	}
+	if err := txRepo.Create(ctx, transaction); err != nil {
+		return err
+	}
+
+	before := parent.Balance
+	funding, err := parent.DebitFunded(amount)
+	if err != nil {
+		return err
+	}
+	transaction.Funding = funding
+
+	if err := s.saveBalance(ctx, walletRepo, parent, before, transaction); err != nil {
+		return err
+	}
+
+	if err := txRepo.SaveFunding(ctx, transaction); err != nil {
+		return err
+	}
+
+	if err := transaction.MarkCompleted(); err != nil {
+		return err
+	}
+
+	return txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted)
+}
+
+// lockWithParent locks the user's wallet and the parent it falls back to in wallet ID order,
+// parent is nil when the wallet has none
+func (s *WalletService) lockWithParent(ctx context.Context, walletRepo entities.WalletRepo, userID uuid.UUID) (*entities.Wallet, *entities.Wallet, error) {
+	wallet, err := walletRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	IDs := []uuid.UUID{wallet.ID}
+	if wallet.HasParent() {
+		IDs = append(IDs, wallet.ParentID)
+	}
+	locked, err := lockWallets(ctx, walletRepo, IDs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the parent was read before the lock, it must not have changed since
+	child := locked[wallet.ID]
+	if child.ParentID != wallet.ParentID {
+		return nil, nil, entities.ErrInvalidParent
+	}
+	return child, locked[child.ParentID], nil
+}
+
+// fundingParent is the wallet that paid the parent share of a debit, uuid.Nil when it paid none
+func fundingParent(transaction *entities.Transaction) uuid.UUID {
+	for _, f := range transaction.Funding {
+		if f.Kind == entities.BucketParent {
+			return f.BucketID
+		}
+	}
+	return uuid.Nil
+}
+
+// refundParent credits the parent's share of a refunded child debit back to the locked parent it came from
+func (s *WalletService) refundParent(ctx context.Context, walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, parent *entities.Wallet, originalTx *entities.Transaction, amount valueobjects.Money) (valueobjects.Money, error) {
+	previous := parent.Balance
+
+	transaction := entities.NewTransaction(parent.ID, parent.UserID, originalTx.SMSID, amount, entities.TransactionCredit)
+	transaction.Category = entities.CategoryRefund
+	transaction.ReferenceID = originalTx.ID
+	// the parent's delegated row carries the parent's share of the tax
+	if originalTx.IsTaxed() {
+		delegated, err := txRepo.FindByReference(ctx, parent.ID, originalTx.ID)
+		if err != nil {
+			return valueobjects.Money{}, err
+		}
+		if err := transaction.RefundTax(delegated); err != nil {
+			return valueobjects.Money{}, err
+		}
+	}
+	if err := s.creditWallet(ctx, walletRepo, txRepo, parent, transaction); err != nil {
+		return valueobjects.Money{}, err
+	}
+	return previous, nil
+}
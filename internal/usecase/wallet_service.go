@@ -2,9 +2,11 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"finance/internal/domain/entities"
 	"finance/internal/domain/events"
 	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/metrics"
 	"finance/internal/infra/storage"
 	"finance/pkg/logger"
 	"math/big"
@@ -18,6 +20,14 @@ type WalletService struct {
 	WalletRepo      entities.WalletRepo
 	UserRepo        entities.UserRepo
 	TransactionRepo entities.TransactionRepo
+	ThresholdRepo   entities.BalanceThresholdRepo
+	TopUpRepo       entities.TopUpRepo
+	TariffRepo      entities.TariffRepo
+	PlanRepo        entities.PlanRepo
+	VoucherRepo     entities.VoucherRepo
+	TransferRepo    entities.TransferRepo
+	FXRateRepo      entities.FXRateRepo
+	TaxRuleRepo     entities.TaxRuleRepo
 	TxManager       storage.TransactionManager
 	Publisher       events.Publisher
 	log             *logger.Logger
@@ -26,12 +36,28 @@ type WalletService struct {
 func NewWalletService(walletRepo entities.WalletRepo,
 	userRepo entities.UserRepo,
 	transactionRepo entities.TransactionRepo,
+	thresholdRepo entities.BalanceThresholdRepo,
+	topUpRepo entities.TopUpRepo,
+	tariffRepo entities.TariffRepo,
+	planRepo entities.PlanRepo,
+	voucherRepo entities.VoucherRepo,
+	transferRepo entities.TransferRepo,
+	fxRateRepo entities.FXRateRepo,
+	taxRuleRepo entities.TaxRuleRepo,
 	txManager storage.TransactionManager,
 	publisher events.Publisher, log *logger.Logger) *WalletService {
 	return &WalletService{
 		WalletRepo:      walletRepo,
 		UserRepo:        userRepo,
 		TransactionRepo: transactionRepo,
+		ThresholdRepo:   thresholdRepo,
+		TopUpRepo:       topUpRepo,
+		TariffRepo:      tariffRepo,
+		PlanRepo:        planRepo,
+		VoucherRepo:     voucherRepo,
+		TransferRepo:    transferRepo,
+		FXRateRepo:      fxRateRepo,
+		TaxRuleRepo:     taxRuleRepo,
 		TxManager:       txManager,
 		Publisher:       publisher,
 		log:             log,
@@ -39,30 +65,128 @@ func NewWalletService(walletRepo entities.WalletRepo,
 }
 
 // consumer handler calls this usecase
-func (s *WalletService) DebitUserbalance(ctx context.Context, userID, smsID uuid.UUID, amount big.Int) (*events.SMSDebited, error) {
+func (s *WalletService) DebitUserbalance(ctx context.Context, userID, smsID uuid.UUID, sms entities.SMSMetadata) (_ *events.SMSDebited, err error) {
+	ctx, end := startSpan(ctx, "DebitUserbalance")
+	defer end(&err)
 	var eventToPublish *events.SMSDebited
+	var debited, parent *entities.Wallet
+	var previousBalance, parentPrevious valueobjects.Money
+	var charged *valueobjects.Money
 
-	err := s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
-		wallet, err := walletRepo.FindByUserID(ctx, userID)
+	started := time.Now()
+	err = s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		walletRepo := s.WalletRepo.WithTx(tx)
+		txRepo := s.TransactionRepo.WithTx(tx)
+		userRepo := s.UserRepo.WithTx(tx)
+		// the price is read in the debit's transaction, a tariff or plan change commits before or after it
+		tariffRepo := s.TariffRepo.WithTx(tx)
+		planRepo := s.PlanRepo.WithTx(tx)
+		taxRuleRepo := s.TaxRuleRepo.WithTx(tx)
+
+		// the balance and the limit totals are read under the wallet lock, concurrent debits can not pass the checks together
+		wallet, walletParent, err := s.lockWithParent(ctx, walletRepo, userID)
 		if err != nil {
 			return err
 		}
 
-		money, err := valueobjects.NewMoney(&amount, wallet.Currency)
+		tariff, err := tariffRepo.FindActive(ctx, wallet.Currency)
 		if err != nil {
 			return err
 		}
 
-		transaction := entities.NewTransaction(wallet.ID, userID, smsID, money, entities.TransactionDebit)
+		plan, err := planRepo.FindActivePlan(ctx, userID)
+		if err != nil && !errors.Is(err, entities.ErrPlanNotAssigned) {
+			return err
+		}
+
+		planCode := entities.DefaultPlan
+		if plan != nil {
+			planCode = plan.Code
+		}
+
+		money, err := tariff.Price(sms, planCode)
+		if err != nil {
+			return err
+		}
+
+		var tier entities.PlanTier
+		if plan != nil {
+			// the tier is picked by the position of this sms in the month
+			sent, err := txRepo.CountSince(ctx, wallet.ID, entities.TransactionDebit, entities.StartOfMonth(time.Now()))
+			if err != nil {
+				return err
+			}
+			tier = plan.TierFor(sent + 1)
+			if money, err = tier.Apply(money); err != nil {
+				return err
+			}
+		}
+
+		tax, err := s.taxFor(ctx, taxRuleRepo, userRepo, userID, entities.TaxOnDebit, money)
+		if err != nil {
+			return err
+		}
+		if tax != nil {
+			money = tax.Gross
+		}
+
+		if err := s.checkSpendingLimits(ctx, txRepo, wallet, money); err != nil {
+			return err
+		}
+
+		own, fromParent, err := s.splitWithParent(ctx, txRepo, wallet, money)
+		if err != nil {
+			return err
+		}
+		if !fromParent.IsZero() {
+			parent = walletParent
+		}
+
+		// the child row only records what left the child wallet, the parent's share is its own
+		// delegated row and is kept on the child row as parent funding for the quota
+		transaction := entities.NewTransaction(wallet.ID, userID, smsID, own, entities.TransactionDebit)
+		transaction.TariffVersion = tariff.Version
+		transaction.MessageType = sms.Type()
+		transaction.Segments = sms.Segments
+		if plan != nil {
+			transaction.PlanID = plan.ID
+			transaction.DiscountBPS = tier.DiscountBPS
+		}
+		// the tax is split like the price, each row carries the tax of the share it records
+		var parentTax *entities.TaxBreakdown
+		if tax != nil {
+			ownTax, rest, err := tax.Split(own)
+			if err != nil {
+				return err
+			}
+			transaction.SetTax(ownTax)
+			parentTax = &rest
+		}
 		if err := txRepo.Create(ctx, transaction); err != nil {
 			return err
 		}
 
-		if err := wallet.Debit(money); err != nil {
+		previousBalance = wallet.Balance
+		var funding []entities.Funding
+		if !own.IsZero() || parent == nil {
+			if funding, err = wallet.DebitFunded(own); err != nil {
+				return err
+			}
+		}
+		if parent != nil {
+			parentPrevious = parent.Balance
+			if err := s.drawFromParent(ctx, walletRepo, txRepo, parent, transaction, fromParent, parentTax); err != nil {
+				return err
+			}
+			funding = append(funding, entities.Funding{BucketID: parent.ID, Kind: entities.BucketParent, Amount: fromParent})
+		}
+		transaction.Funding = funding
+
+		if err := s.saveBalance(ctx, walletRepo, wallet, previousBalance, transaction); err != nil {
 			return err
 		}
 
-		if err := walletRepo.UpdateBalance(ctx, wallet); err != nil {
+		if err := txRepo.SaveFunding(ctx, transaction); err != nil {
 			return err
 		}
 
@@ -78,96 +202,243 @@ func (s *WalletService) DebitUserbalance(ctx context.Context, userID, smsID uuid
 		eventToPublish = &events.SMSDebited{
 			UserID:        userID.String(),
 			SMSID:         transaction.SMSID.String(),
-			Amount:        transaction.Amount.Amount().Int64(),
+			Amount:        money.Amount().Int64(),
+			TariffVersion: transaction.TariffVersion,
 			TransactionID: transaction.ID.String(),
 			TimeStamp:     time.Now(),
 		}
+		debited = wallet
+		charged = &money
 		return nil
 	})
+	observeOperation(metrics.Debits, metrics.OperationDebit, metrics.OutcomeSuccess, started, charged, err)
 	if err != nil {
 		return nil, err
 	}
 
+	s.evaluateBalanceAlerts(ctx, debited, previousBalance)
+	s.evaluateTopUpRules(ctx, debited)
+	if parent != nil {
+		s.evaluateBalanceAlerts(ctx, parent, parentPrevious)
+		s.evaluateTopUpRules(ctx, parent)
+	}
 	return eventToPublish, nil
 
 }
 
 // http handler calls this usecase
-func (s *WalletService) CreditUserBalance(ctx context.Context, userID uuid.UUID, amount big.Int) error {
-	return s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
-		wallet, err := walletRepo.FindByUserID(ctx, userID)
+func (s *WalletService) CreditUserBalance(ctx context.Context, userID uuid.UUID, amount big.Int) (err error) {
+	ctx, end := startSpan(ctx, "CreditUserBalance")
+	defer end(&err)
+	_, err = s.CreditUserBalanceIn(ctx, userID, &amount, "")
+	return err
+}
+
+// creditUserBalance credits the transaction that prepare builds, prepare runs in the same
+// db transaction after the wallet is loaded, so what it writes commits with the credit
+func (s *WalletService) creditUserBalance(ctx context.Context, userID uuid.UUID, prepare func(tx *gorm.DB, wallet *entities.Wallet) (*entities.Transaction, error)) (*entities.Transaction, error) {
+	var credited *entities.Wallet
+	var transaction *entities.Transaction
+	var previousBalance valueobjects.Money
+
+	started := time.Now()
+	err := s.TxManager.WithTransaction(func(tx *gorm.DB) error {
+		walletRepo := s.WalletRepo.WithTx(tx)
+		txRepo := s.TransactionRepo.WithTx(tx)
+
+		wallet, err := walletRepo.LockByUserID(ctx, userID)
 		if err != nil {
 			return err
 		}
 
-		money, err := valueobjects.NewMoney(&amount, wallet.Currency)
+		transaction, err = prepare(tx, wallet)
 		if err != nil {
 			return err
 		}
-		transaction := entities.NewTransaction(wallet.ID, userID, uuid.New(), money, entities.TransactionCredit)
-		if err := txRepo.Create(ctx, transaction); err != nil {
+
+		previousBalance = wallet.Balance
+		if err := s.creditWallet(ctx, walletRepo, txRepo, wallet, transaction); err != nil {
 			return err
 		}
 
-		if err := wallet.Credit(money); err != nil {
+		credited = wallet
+		return nil
+	})
+	var credit *valueobjects.Money
+	if transaction != nil {
+		credit = &transaction.Amount
+	}
+	observeOperation(metrics.Credits, metrics.OperationCredit, metrics.OutcomeSuccess, started, credit, err)
+	if err != nil {
+		return nil, err
+	}
+
+	s.evaluateBalanceAlerts(ctx, credited, previousBalance)
+	return transaction, nil
+}
+
+func (s *WalletService) SetSpendingLimits(ctx context.Context, userID uuid.UUID, limits entities.SpendingLimits) (_ *entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "SetSpendingLimits")
+	defer end(&err)
+	var updated *entities.Wallet
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.FindByUserID(ctx, userID)
+		if err != nil {
 			return err
 		}
 
-		if err := walletRepo.UpdateBalance(ctx, wallet); err != nil {
+		if err := wallet.SetLimits(limits); err != nil {
 			return err
 		}
 
-		if err := transaction.MarkCompleted(); err != nil {
+		if err := walletRepo.UpdateLimits(ctx, wallet); err != nil {
 			return err
 		}
 
-		if err := txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted); err != nil {
+		updated = wallet
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// SetCreditLimit lets a postpaid wallet go below zero down to -limit
+func (s *WalletService) SetCreditLimit(ctx context.Context, userID uuid.UUID, limit big.Int) (_ *entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "SetCreditLimit")
+	defer end(&err)
+	var updated *entities.Wallet
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+		wallet, err := walletRepo.FindByUserID(ctx, userID)
+		if err != nil {
 			return err
 		}
 
+		money, err := valueobjects.NewMoney(&limit, wallet.Currency)
+		if err != nil {
+			return entities.ErrInvalidCreditLimit
+		}
+
+		if err := wallet.SetCreditLimit(money); err != nil {
+			return err
+		}
+
+		if err := walletRepo.UpdateCreditLimit(ctx, wallet); err != nil {
+			return err
+		}
+
+		updated = wallet
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
 }
 
-func (s *WalletService) GetWalletByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wallet, error) {
+func (s *WalletService) GetWalletByUserID(ctx context.Context, userID uuid.UUID) (_ *entities.Wallet, err error) {
+	ctx, end := startSpan(ctx, "GetWalletByUserID")
+	defer end(&err)
 	return s.WalletRepo.FindByUserID(ctx, userID)
 }
 
-func (s *WalletService) GetUserByID(ctx context.Context, userID uuid.UUID) (*entities.User, error) {
+func (s *WalletService) GetUserByID(ctx context.Context, userID uuid.UUID) (_ *entities.User, err error) {
+	ctx, end := startSpan(ctx, "GetUserByID")
+	defer end(&err)
 	return s.UserRepo.GetByID(ctx, userID)
 }
 
-func (s *WalletService) GetAllUsers(ctx context.Context) ([]*entities.User, error) {
+func (s *WalletService) GetAllUsers(ctx context.Context) (_ []*entities.User, err error) {
+	ctx, end := startSpan(ctx, "GetAllUsers")
+	defer end(&err)
 	return s.UserRepo.GetAll(ctx)
 }
 
 // this usecase executes in a subsciber handler
-func (s *WalletService) RefundTransaction(ctx context.Context, txID string) error {
-	return s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
+func (s *WalletService) RefundTransaction(ctx context.Context, txID string) (err error) {
+	ctx, end := startSpan(ctx, "RefundTransaction")
+	defer end(&err)
+	var refunded, parent *entities.Wallet
+	var previousBalance, parentPrevious valueobjects.Money
+	var given *valueobjects.Money
+
+	started := time.Now()
+	err = s.withTransaction(func(walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, userRepo entities.UserRepo) error {
 		originalTx, err := txRepo.FindByID(ctx, txID)
 		if err != nil {
 			return err
 		}
 
-		if originalTx.Type != entities.TransactionDebit {
+		// only sms debits are refunded, transfers and bonus expiry are not undone this way
+		if !originalTx.Refundable() {
 			return nil
 		}
 
-		wallet, err := walletRepo.FindByID(ctx, originalTx.WalletID)
+		// the child and the parent that paid part of the debit are locked in wallet ID order
+		parentID := fundingParent(originalTx)
+		IDs := []uuid.UUID{originalTx.WalletID}
+		if parentID != uuid.Nil {
+			IDs = append(IDs, parentID)
+		}
+		locked, err := lockWallets(ctx, walletRepo, IDs...)
 		if err != nil {
 			return err
 		}
+		wallet := locked[originalTx.WalletID]
+
+		// drained buckets are not loaded with the wallet, bring back the ones the debit drew from
+		for _, f := range originalTx.Funding {
+			if f.Kind != entities.BucketBonus || wallet.HasBucket(f.BucketID) {
+				continue
+			}
+			bucket, err := walletRepo.FindBucket(ctx, wallet.ID, f.BucketID)
+			if err != nil {
+				return err
+			}
+			wallet.Buckets = append(wallet.Buckets, bucket)
+		}
 
-		refundTx := entities.NewTransaction(wallet.ID, originalTx.UserID, originalTx.SMSID, originalTx.Amount, entities.TransactionCredit)
+		previousBalance = wallet.Balance
+		funding, err := wallet.Refund(originalTx.Amount, originalTx.Funding)
+		if err != nil {
+			return err
+		}
+
+		total := entities.FundingTotal(funding, wallet.Currency)
+		if total.IsZero() {
+			// everything came from bonus credit that expired in the meantime
+			return nil
+		}
+
+		if fromParent := entities.ParentFunding(funding, wallet.Currency); !fromParent.IsZero() {
+			if parentPrevious, err = s.refundParent(ctx, walletRepo, txRepo, locked[parentID], originalTx, fromParent); err != nil {
+				return err
+			}
+			parent = locked[parentID]
+		}
+
+		// like the debit, the child row only records the child's own share, the parent funding stays
+		// on it so the quota is given back
+		amount := entities.FundingTotal(entities.OwnFunding(funding), wallet.Currency)
+		refundTx := entities.NewTransaction(wallet.ID, originalTx.UserID, originalTx.SMSID, amount, entities.TransactionCredit)
+		refundTx.Category = entities.CategoryRefund
+		refundTx.ReferenceID = originalTx.ID
+		refundTx.Funding = funding
+		if err := refundTx.RefundTax(originalTx); err != nil {
+			return err
+		}
 		if err := txRepo.Create(ctx, refundTx); err != nil {
 			return err
 		}
 
-		if err := wallet.Credit(originalTx.Amount); err != nil {
+		if err := s.saveBalance(ctx, walletRepo, wallet, previousBalance, refundTx); err != nil {
 			return err
 		}
 
-		if err := walletRepo.UpdateBalance(ctx, wallet); err != nil {
+		if err := txRepo.SaveFunding(ctx, refundTx); err != nil {
 			return err
 		}
 
@@ -178,8 +449,83 @@ func (s *WalletService) RefundTransaction(ctx context.Context, txID string) erro
 		if err := txRepo.UpdateStatus(ctx, refundTx, entities.TransactionCompleted); err != nil {
 			return err
 		}
+		refunded = wallet
+		given = &total
 		return nil
 	})
+	outcome := metrics.OutcomeSuccess
+	if refunded == nil {
+		outcome = metrics.OutcomeSkipped
+	}
+	observeOperation(metrics.Refunds, metrics.OperationRefund, outcome, started, given, err)
+	if err != nil {
+		return err
+	}
+
+	if refunded != nil {
+		s.evaluateBalanceAlerts(ctx, refunded, previousBalance)
+	}
+	if parent != nil {
+		s.evaluateBalanceAlerts(ctx, parent, parentPrevious)
+	}
+	return nil
+}
+
+// creditWallet records a completed credit transaction and applies it to the wallet balance
+func (s *WalletService) creditWallet(ctx context.Context, walletRepo entities.WalletRepo, txRepo entities.TransactionRepo, wallet *entities.Wallet, transaction *entities.Transaction) error {
+	if err := txRepo.Create(ctx, transaction); err != nil {
+		return err
+	}
+
+	before := wallet.Balance
+	if err := wallet.CreditInCurrency(transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := s.saveBalance(ctx, walletRepo, wallet, before, transaction); err != nil {
+		return err
+	}
+
+	if err := transaction.MarkCompleted(); err != nil {
+		return err
+	}
+
+	return txRepo.UpdateStatus(ctx, transaction, entities.TransactionCompleted)
+}
+
+// checkSpendingLimits only queries the aggregates of the limits that are set on the wallet
+func (s *WalletService) checkSpendingLimits(ctx context.Context, txRepo entities.TransactionRepo, wallet *entities.Wallet, amount valueobjects.Money) error {
+	limits := wallet.Limits
+	if limits.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	var usage entities.SpendingUsage
+	var err error
+
+	if limits.DailyAmount != nil {
+		usage.DailyAmount, err = txRepo.SumAmountSince(ctx, wallet.ID, entities.TransactionDebit, entities.StartOfDay(now))
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.MonthlyAmount != nil {
+		usage.MonthlyAmount, err = txRepo.SumAmountSince(ctx, wallet.ID, entities.TransactionDebit, entities.StartOfMonth(now))
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.DebitsPerMinute > 0 {
+		usage.DebitsLastMinute, err = txRepo.CountSince(ctx, wallet.ID, entities.TransactionDebit, now.Add(-time.Minute))
+		if err != nil {
+			return err
+		}
+	}
+
+	return limits.Check(amount, usage)
 }
 
 // withTransaction is a helper method that provides transactional repositories
@@ -0,0 +1,158 @@
+// Package health serves liveness and readiness probes.
+//
+// Liveness only tells that the process answers, readiness runs every registered check, each with
+// its own timeout, and fails when one of them does.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CheckTimeout bounds a single readiness check
+const CheckTimeout = 2 * time.Second
+
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status == "ready"
+}
+
+type Checker struct {
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		checks: make(map[string]Check),
+	}
+}
+
+// Add registers a readiness check, a check with the same name is replaced
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// Names returns the registered checks in order
+func (c *Checker) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs the checks concurrently
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]Check, len(c.checks))
+	for name, check := range c.checks {
+		checks[name] = check
+	}
+	c.mu.RUnlock()
+
+	report := Report{Status: "ready", Checks: make(map[string]CheckResult, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != "ok" {
+				report.Status = "not_ready"
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: "ok", DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler answers 200 as long as the process serves requests
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: "ok"})
+	})
+}
+
+// ReadinessHandler answers 200 when every check passes and 503 otherwise
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// NewServer serves /healthz and /readyz, for binaries that have no http api of their own, and
+// /metrics when a metrics handler is given
+func (c *Checker) NewServer(addr string, metrics http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", c.LivenessHandler())
+	mux.Handle("/readyz", c.ReadinessHandler())
+	if metrics != nil {
+		mux.Handle("/metrics", metrics)
+	}
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
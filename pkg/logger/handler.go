@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextHandler adds the trace and span ids of the record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if traceID := GetTraceID(ctx); traceID != "" {
+			r.AddAttrs(slog.String(string(TraceIDKey), traceID))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasSpanID() {
+			r.AddAttrs(slog.String(SpanIDKey, sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Sampling keeps the first Initial records of a message per Tick, after that only every
+// Thereafter-th. Warnings and errors are never sampled
+type Sampling struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+func (s Sampling) Enabled() bool {
+	return s.Initial > 0
+}
+
+type samplingHandler struct {
+	slog.Handler
+	sampling Sampling
+	counts   *sampleCounts
+}
+
+type sampleCounts struct {
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int
+}
+
+func newSamplingHandler(handler slog.Handler, sampling Sampling) *samplingHandler {
+	if sampling.Tick <= 0 {
+		sampling.Tick = time.Second
+	}
+	return &samplingHandler{
+		Handler:  handler,
+		sampling: sampling,
+		counts:   &sampleCounts{counts: make(map[string]int)},
+	}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn || h.keep(r) {
+		return h.Handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *samplingHandler) keep(r slog.Record) bool {
+	c := h.counts
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if window := r.Time.Truncate(h.sampling.Tick); !window.Equal(c.window) {
+		c.window = window
+		clear(c.counts)
+	}
+	key := r.Level.String() + r.Message
+	c.counts[key]++
+	n := c.counts[key]
+	if n <= h.sampling.Initial {
+		return true
+	}
+	return h.sampling.Thereafter > 0 && (n-h.sampling.Initial)%h.sampling.Thereafter == 0
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampling: h.sampling, counts: h.counts}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampling: h.sampling, counts: h.counts}
+}
@@ -2,54 +2,179 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LogLevel string
 
+const (
+	LevelDebug LogLevel = "debug"
+	LevelInfo  LogLevel = "info"
+	LevelWarn  LogLevel = "warn"
+	LevelError LogLevel = "error"
+)
+
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
 type contextKey string
 
 const TraceIDKey contextKey = "trace_id"
 
+const SpanIDKey = "span_id"
+
+// Options configures a logger, the zero value logs json at info to stdout
+type Options struct {
+	Level  LogLevel
+	Format string
+	// Output is stdout, stderr or the path of a file the logs are appended to
+	Output   string
+	Sampling Sampling
+}
+
 type Logger struct {
 	*slog.Logger
+	level  *slog.LevelVar
+	output io.Closer
 }
 
+// NewLogger logs json to stdout, an unknown level falls back to info
 func NewLogger(level LogLevel) *Logger {
+	l, err := New(Options{Level: level})
+	if err != nil {
+		l, _ = New(Options{})
+	}
+	return l
+}
+
+func New(opt Options) (*Logger, error) {
+	level := new(slog.LevelVar)
+	if opt.Level != "" {
+		parsed, err := ParseLevel(opt.Level)
+		if err != nil {
+			return nil, err
+		}
+		level.Set(parsed)
+	}
+
+	var out io.Writer
+	var closer io.Closer
+	switch opt.Output {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		f, err := os.OpenFile(opt.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		out, closer = f, f
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opt.Format {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(out, handlerOptions)
+	case FormatText:
+		handler = slog.NewTextHandler(out, handlerOptions)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", opt.Format)
+	}
+	if opt.Sampling.Enabled() {
+		handler = newSamplingHandler(handler, opt.Sampling)
+	}
+
 	return &Logger{
-		Logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: slog.LevelInfo, // default log level
-		})),
+		Logger: slog.New(contextHandler{handler}),
+		level:  level,
+		output: closer,
+	}, nil
+}
+
+func ParseLevel(level LogLevel) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", level)
+	}
+	return parsed, nil
+}
+
+// Level is the level records are currently logged at
+func (l *Logger) Level() LogLevel {
+	return LogLevel(strings.ToLower(l.level.Level().String()))
+}
+
+// SetLevel changes the level of the logger and of every logger derived from it
+func (l *Logger) SetLevel(level LogLevel) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.Set(parsed)
+	return nil
+}
+
+// Close closes the log file, stdout and stderr are left open
+func (l *Logger) Close() error {
+	if l.output == nil {
+		return nil
 	}
+	return l.output.Close()
 }
 
 func GenerateTraceID() string {
 	return uuid.New().String()
 }
 
+// WithTraceID keeps the trace id of the span in ctx, without a span a new id is generated
 func WithTraceID(ctx context.Context) context.Context {
 	if ctx.Value(TraceIDKey) == nil {
-		return context.WithValue(ctx, TraceIDKey, GenerateTraceID())
+		traceID := GenerateTraceID()
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			traceID = sc.TraceID().String()
+		}
+		return context.WithValue(ctx, TraceIDKey, traceID)
 	}
 	return ctx
 }
 
+// GetTraceID prefers the trace of the current span, a context derived for a new span keeps the
+// trace id of its parent otherwise
 func GetTraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
 	if traceID, ok := ctx.Value(TraceIDKey).(string); ok {
 		return traceID
 	}
 	return ""
 }
 
+func (l *Logger) Debug(ctx context.Context, msg string, args ...any) {
+	l.Logger.DebugContext(ctx, msg, args...)
+}
+
 func (l *Logger) Info(ctx context.Context, msg string, args ...any) {
-	traceID := GetTraceID(ctx)
-	if traceID != "" {
-		args = append(args, slog.String(string(TraceIDKey), traceID))
-	}
-	l.Logger.Info(msg, args...)
+	l.Logger.InfoContext(ctx, msg, args...)
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, args ...any) {
+	l.Logger.WarnContext(ctx, msg, args...)
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, args ...any) {
+	l.Logger.ErrorContext(ctx, msg, args...)
 }
 
 func (l *Logger) ErrorWithoutContext(msg string, args ...any) {
@@ -1,10 +1,13 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -27,11 +30,44 @@ func (o DBConnOptions) PostgresDSN() string {
 }
 
 func NewPsqlGormConnection(opt DBConnOptions) (*gorm.DB, error) {
-	return gorm.Open(postgres.Open(opt.PostgresDSN()), &gorm.Config{
+	db, err := gorm.Open(postgres.Open(opt.PostgresDSN()), &gorm.Config{
 		Logger: logger.Discard,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracingPlugin{}); err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
 func Migrate(db *gorm.DB, models ...interface{}) error {
 	return db.AutoMigrate(models...)
 }
+
+// schemaMigration records each schema version the models were migrated to
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrateVersion migrates the models and records version once they are in place
+func MigrateVersion(db *gorm.DB, version int, models ...interface{}) error {
+	if err := Migrate(db, append(models, &schemaMigration{})...); err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&schemaMigration{Version: version, AppliedAt: time.Now()}).Error
+}
+
+// SchemaVersion is the highest recorded schema version, 0 when none is recorded
+func SchemaVersion(ctx context.Context, db *gorm.DB) (int, error) {
+	var version int
+	err := db.WithContext(ctx).Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var tracer = otel.Tracer("finance/pkg/postgres")
+
+// statementSpanKey marks the span the plugin started, so a span of the caller is never ended here
+type statementSpanKey struct{}
+
+// tracingPlugin opens a client span around every gorm statement, as a child of the span in the
+// statement's context
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("otel:before_create", startStatementSpan("create")),
+		cb.Create().After("gorm:create").Register("otel:after_create", endStatementSpan),
+		cb.Query().Before("gorm:query").Register("otel:before_query", startStatementSpan("query")),
+		cb.Query().After("gorm:query").Register("otel:after_query", endStatementSpan),
+		cb.Update().Before("gorm:update").Register("otel:before_update", startStatementSpan("update")),
+		cb.Update().After("gorm:update").Register("otel:after_update", endStatementSpan),
+		cb.Delete().Before("gorm:delete").Register("otel:before_delete", startStatementSpan("delete")),
+		cb.Delete().After("gorm:delete").Register("otel:after_delete", endStatementSpan),
+		cb.Row().Before("gorm:row").Register("otel:before_row", startStatementSpan("row")),
+		cb.Row().After("gorm:row").Register("otel:after_row", endStatementSpan),
+		cb.Raw().Before("gorm:raw").Register("otel:before_raw", startStatementSpan("raw")),
+		cb.Raw().After("gorm:raw").Register("otel:after_raw", endStatementSpan),
+	)
+}
+
+func startStatementSpan(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+		ctx, span := tracer.Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation.name", operation),
+			))
+		db.Statement.Context = context.WithValue(ctx, statementSpanKey{}, span)
+	}
+}
+
+func endStatementSpan(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	span, ok := db.Statement.Context.Value(statementSpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.query.text", db.Statement.SQL.String()),
+		attribute.String("db.collection.name", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -1,10 +1,19 @@
 package rabbit
 
-import "github.com/streadway/amqp"
+import (
+	"errors"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
 
 type RabbitConn struct {
 	Conn *amqp.Connection
 	Ch   *amqp.Channel
+
+	mu sync.RWMutex
+	// channelErr is set once the channel is closed, it is not reopened
+	channelErr error
 }
 
 func NewRabbitConn(uri string) *RabbitConn {
@@ -17,10 +26,33 @@ func NewRabbitConn(uri string) *RabbitConn {
 	if err != nil {
 		panic(err)
 	}
-	return &RabbitConn{
+	r := &RabbitConn{
 		Conn: conn,
 		Ch:   ch,
 	}
+	go r.watchChannel(ch.NotifyClose(make(chan *amqp.Error, 1)))
+	return r
+}
+
+func (r *RabbitConn) watchChannel(closed chan *amqp.Error) {
+	err, ok := <-closed
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ok && err != nil {
+		r.channelErr = err
+	} else {
+		r.channelErr = errors.New("rabbitmq channel closed")
+	}
+}
+
+// Check reports a closed connection or channel
+func (r *RabbitConn) Check() error {
+	if r.Conn == nil || r.Conn.IsClosed() {
+		return errors.New("rabbitmq connection closed")
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.channelErr
 }
 
 // using amq.topic exchnage (no need to declare exchange before)
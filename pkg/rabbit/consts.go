@@ -4,8 +4,21 @@ const (
 	// consumers subscribe to these queues
 	RefundQueueName = "finance_billing.refund.request"
 	DebitQueueName  = "finance_billing.debit.request"
+	// the payment service answers top-up requests here
+	TopUpSucceededQueueName = "finance_payment.topup.succeeded"
+	TopUpFailedQueueName    = "finance_payment.topup.failed"
+	// the rate feed pushes fx rates here
+	FXRateUpdatedQueueName = "finance_fx.rate.updated"
 
 	// producers publish to these queues
-	SMSBilledRouting = "billing.debit.completed"
-	Exchange         = "amq.topic"
+	SMSBilledRouting      = "billing.debit.completed"
+	SMSDebitFailedRouting = "billing.debit.failed"
+	Exchange              = "amq.topic"
+
+	// the notification service listens here for low balance and depleted wallet alerts
+	WalletBalanceAlertRouting = "notification.wallet.balance"
+	// the payment service charges the saved funding source of auto top-up rules
+	TopUpRequestedRouting = "payment.topup.requested"
+	// reconciliation reports wallets whose balance does not match their transactions
+	BalanceDiscrepancyRouting = "finance.balance.discrepancy"
 )
@@ -3,18 +3,68 @@ package rabbit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"finance/pkg/logger"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 )
 
+const (
+	QueueStarting  = "starting"
+	QueueConsuming = "consuming"
+	QueueFailed    = "failed"
+	// QueueStopped means the broker closed the deliveries, the queue gets no more messages
+	QueueStopped = "stopped"
+)
+
+// RequeueDelay is how long the consumer waits before giving a failed message back to its queue,
+// so a database outage is not retried in a tight loop
+var RequeueDelay = time.Second
+
+// dropError marks a message that fails the same way however often it is handled
+type dropError struct {
+	err error
+}
+
+func (e *dropError) Error() string { return e.err.Error() }
+
+func (e *dropError) Unwrap() error { return e.err }
+
+// Drop marks err as permanent, the consumer drops the message instead of requeueing it
+func Drop(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &dropError{err: err}
+}
+
+// IsDrop reports whether the handler asked to drop the message
+func IsDrop(err error) bool {
+	var drop *dropError
+	return errors.As(err, &drop)
+}
+
+// QueueStatus tells what the consumer of a queue is doing
+type QueueStatus struct {
+	State         string    `json:"state"`
+	Error         string    `json:"error,omitempty"`
+	Messages      int64     `json:"messages"`
+	LastMessageAt time.Time `json:"last_message_at,omitempty"`
+}
+
 type Publisher struct {
 	rabbitConn *RabbitConn
 }
 
 type Consumer struct {
 	rabbitConn *RabbitConn
-	handlers   map[string]func([]byte) error
+	log        *logger.Logger
+	handlers   map[string]func(context.Context, []byte) error
+
+	mu     sync.RWMutex
+	status map[string]QueueStatus
 }
 
 func NewPublisher(conn *RabbitConn) *Publisher {
@@ -23,45 +73,71 @@ func NewPublisher(conn *RabbitConn) *Publisher {
 	}
 }
 
-func (p *Publisher) Publish(routingKey, exchange string, body interface{}) error {
+func (p *Publisher) Publish(ctx context.Context, routingKey, exchange string, body interface{}) (err error) {
 	bodyJson, err := json.Marshal(body)
 	if err != nil {
 		return err
 	}
+	headers := amqp.Table{}
+	_, span := startPublishSpan(ctx, exchange, routingKey, headers)
+	defer func() { endSpan(span, err) }()
+
 	return p.rabbitConn.Ch.Publish(
 		exchange,
 		routingKey,
 		false,
 		false,
 		amqp.Publishing{
+			Headers:     headers,
 			ContentType: "application/json",
 			Body:        bodyJson,
 		},
 	)
 }
 
-func NewConsumer(conn *RabbitConn) *Consumer {
+func NewConsumer(conn *RabbitConn, log *logger.Logger) *Consumer {
 	return &Consumer{
 		rabbitConn: conn,
-		handlers:   make(map[string]func([]byte) error),
+		log:        log,
+		handlers:   make(map[string]func(context.Context, []byte) error),
+		status:     make(map[string]QueueStatus),
 	}
 }
 
-func (c *Consumer) Subscribe(queueName string, handler func([]byte) error) {
+func (c *Consumer) Subscribe(queueName string, handler func(context.Context, []byte) error) {
 	c.handlers[queueName] = handler
+	c.setStatus(queueName, func(s *QueueStatus) { s.State = QueueStarting })
+}
+
+// Status returns the state of every subscribed queue
+func (c *Consumer) Status() map[string]QueueStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	status := make(map[string]QueueStatus, len(c.status))
+	for queue, s := range c.status {
+		status[queue] = s
+	}
+	return status
+}
+
+func (c *Consumer) setStatus(queueName string, update func(s *QueueStatus)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.status[queueName]
+	update(&s)
+	c.status[queueName] = s
 }
 
-func (c *Consumer) StartConsume() error {
+// StartConsume hands every delivery to its handler with a context derived from ctx, carrying the
+// span of the message
+func (c *Consumer) StartConsume(ctx context.Context) error {
 	for queueName, handler := range c.handlers {
-		go c.consumeFromQueue(queueName, handler)
+		go c.consumeFromQueue(ctx, queueName, handler)
 	}
 	return nil
 }
 
-func (c *Consumer) consumeFromQueue(queueName string, handler func([]byte) error) {
-	logger := logger.NewLogger("")
-	ctx := context.Background()
-
+func (c *Consumer) consumeFromQueue(ctx context.Context, queueName string, handler func(context.Context, []byte) error) {
 	msgs, err := c.rabbitConn.Ch.Consume(
 		queueName,
 		"",
@@ -72,22 +148,44 @@ func (c *Consumer) consumeFromQueue(queueName string, handler func([]byte) error
 		nil,
 	)
 	if err != nil {
-		logger.Info(ctx, "Failed to start consuming from %s: %v", queueName, err)
+		c.log.Error(ctx, "Failed to start consuming", "queue", queueName, "error", err)
+		c.setStatus(queueName, func(s *QueueStatus) { s.State, s.Error = QueueFailed, err.Error() })
 		return
 	}
-	logger.Info(ctx, "Consumer started for queue: %s", queueName)
+	c.log.Info(ctx, "Consumer started", "queue", queueName)
+	c.setStatus(queueName, func(s *QueueStatus) { s.State = QueueConsuming })
+	defer c.setStatus(queueName, func(s *QueueStatus) { s.State = QueueStopped })
 
 	for msg := range msgs {
-		if err := handler(msg.Body); err != nil {
-
-			logger.Info(ctx, "Error handling message in %s: %v", queueName, err)
-			msg.Nack(false, false)
-		} else {
+		c.setStatus(queueName, func(s *QueueStatus) {
+			s.Messages++
+			s.LastMessageAt = time.Now()
+		})
+		msgCtx, span := startProcessSpan(ctx, queueName, msg)
+		err := handler(msgCtx, msg.Body)
+		endSpan(span, err)
+		switch {
+		case err == nil:
 			msg.Ack(false)
+		case IsDrop(err):
+			c.log.Error(msgCtx, "Dropping message", "queue", queueName, "error", err)
+			msg.Nack(false, false)
+		default:
+			c.log.Error(msgCtx, "Error handling message, requeueing it", "queue", queueName, "error", err)
+			requeue(ctx, msg)
 		}
 	}
 }
 
+// requeue gives the message back to the queue after RequeueDelay, on shutdown it goes back at once
+func requeue(ctx context.Context, msg amqp.Delivery) {
+	select {
+	case <-time.After(RequeueDelay):
+	case <-ctx.Done():
+	}
+	msg.Nack(false, true)
+}
+
 func (c *Consumer) SetQos(prefetchCount int) error {
 	return c.rabbitConn.Ch.Qos(
 		prefetchCount,
@@ -0,0 +1,75 @@
+package rabbit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("finance/pkg/rabbit")
+
+// headerCarrier carries the w3c traceparent and tracestate in the amqp message headers
+type headerCarrier amqp.Table
+
+func (h headerCarrier) Get(key string) string {
+	if v, ok := h[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// startPublishSpan starts a producer span and writes its context into headers
+func startPublishSpan(ctx context.Context, exchange, routingKey string, headers amqp.Table) (context.Context, trace.Span) {
+	ctx, span := tracer.Start(ctx, fmt.Sprintf("%s publish", routingKey),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "publish"),
+			attribute.String("messaging.destination.name", exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	return ctx, span
+}
+
+// startProcessSpan starts the consumer span of a delivery as a child of the trace the publisher
+// sent along, a message without one starts a new trace
+func startProcessSpan(ctx context.Context, queueName string, msg amqp.Delivery) (context.Context, trace.Span) {
+	if msg.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(msg.Headers))
+	}
+	return tracer.Start(ctx, fmt.Sprintf("%s process", queueName),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.destination.name", queueName),
+			attribute.String("messaging.rabbitmq.destination.routing_key", msg.RoutingKey),
+			attribute.String("messaging.message.id", msg.MessageId),
+		))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
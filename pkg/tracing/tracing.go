@@ -0,0 +1,105 @@
+// Package tracing sets up the OpenTelemetry tracer provider and the W3C trace context propagator.
+//
+// Spans go to an OTLP collector over http, or as json to stdout or a file when debugging locally.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	DefaultServiceName = "finance"
+)
+
+type Options struct {
+	Exporter string
+	// Endpoint is the host:port of the collector, empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318
+	Endpoint string
+	Insecure bool
+	File     string
+	// SampleRatio is the share of new traces that are sampled, zero samples all of them
+	SampleRatio float64
+	ServiceName string
+}
+
+// Setup installs the tracer provider globally, shutdown flushes the spans that are still buffered.
+// Without an exporter only the propagator is set, so trace ids still travel with requests and messages
+func Setup(ctx context.Context, opt Options) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var file io.Closer
+	switch opt.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{}
+		if opt.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(opt.Endpoint))
+		}
+		if opt.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		f, openErr := os.OpenFile(opt.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if openErr != nil {
+			return nil, openErr
+		}
+		file = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", opt.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := opt.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := opt.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) List(ctx context.Context, filter entities.AuditFilter) ([]*entities.AuditEvent, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entities.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepo) Chain(ctx context.Context, walletID uuid.UUID) ([]*entities.AuditEvent, error) {
+	args := m.Called(ctx, walletID)
+	return args.Get(0).([]*entities.AuditEvent), args.Error(1)
+}
+
+func (m *MockAuditRepo) WithTx(tx *gorm.DB) entities.AuditRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.AuditRepo)
+}
+
+func creditWithActor(t *testing.T, ctx context.Context, amount int64) (*entities.Wallet, *MockWalletRepo) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+	wallet, err := entities.NewWallet(uuid.New(), "USD")
+	require.NoError(t, err)
+
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, wallet).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	require.NoError(t, service.CreditUserBalance(ctx, wallet.UserID, *big.NewInt(amount)))
+	require.NoError(t, service.CreditUserBalance(ctx, wallet.UserID, *big.NewInt(amount)))
+	return wallet, mockWalletRepo
+}
+
+func TestWalletService_AuditEvents(t *testing.T) {
+	actor := entities.AuditActor{ID: "support-1", Source: entities.AuditSourceHTTP, PayloadHash: entities.PayloadHash([]byte(`{"amount":100}`))}
+	ctx := entities.WithAuditActor(logger.WithTraceID(context.Background()), actor)
+
+	t.Run("every balance change appends a chained event", func(t *testing.T) {
+		wallet, mockWalletRepo := creditWithActor(t, ctx, 100)
+
+		require.Len(t, mockWalletRepo.AuditEvents, 2)
+		first, second := mockWalletRepo.AuditEvents[0], mockWalletRepo.AuditEvents[1]
+		assert.Equal(t, wallet.ID, first.WalletID)
+		assert.Equal(t, entities.AuditCredit, first.Action)
+		assert.Equal(t, "support-1", first.Actor)
+		assert.Equal(t, entities.AuditSourceHTTP, first.Source)
+		assert.Equal(t, actor.PayloadHash, first.PayloadHash)
+		assert.Equal(t, logger.GetTraceID(ctx), first.TraceID)
+		assert.Equal(t, big.NewInt(0), first.BalanceBefore)
+		assert.Equal(t, big.NewInt(100), first.BalanceAfter)
+		assert.Equal(t, int64(1), first.Sequence)
+
+		assert.Equal(t, int64(2), second.Sequence)
+		assert.Equal(t, first.Hash, second.PrevHash)
+		assert.Equal(t, big.NewInt(100), second.BalanceBefore)
+		assert.Equal(t, big.NewInt(200), second.BalanceAfter)
+
+		assert.True(t, entities.VerifyAuditChain(wallet.ID, mockWalletRepo.AuditEvents).Valid)
+	})
+
+	t.Run("changes without an actor are attributed to the system", func(t *testing.T) {
+		_, mockWalletRepo := creditWithActor(t, context.Background(), 100)
+
+		require.NotEmpty(t, mockWalletRepo.AuditEvents)
+		assert.Equal(t, entities.AuditSourceSystem, mockWalletRepo.AuditEvents[0].Source)
+	})
+
+	t.Run("verification finds the first tampered event", func(t *testing.T) {
+		wallet, mockWalletRepo := creditWithActor(t, ctx, 100)
+		chain := mockWalletRepo.AuditEvents
+		chain[0].BalanceAfter = big.NewInt(1_000_000)
+
+		mockAuditRepo := &MockAuditRepo{}
+		mockAuditRepo.On("Chain", ctx, wallet.ID).Return(chain, nil)
+		service := usecase.NewAuditService(mockAuditRepo, logger.NewLogger("error"))
+
+		result, err := service.VerifyChain(ctx, wallet.ID)
+
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(1), result.BrokenAt)
+		assert.Equal(t, 2, result.Events)
+	})
+
+	t.Run("verification rejects a removed event", func(t *testing.T) {
+		wallet, mockWalletRepo := creditWithActor(t, ctx, 100)
+
+		result := entities.VerifyAuditChain(wallet.ID, mockWalletRepo.AuditEvents[1:])
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), result.BrokenAt)
+	})
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"finance/config"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/auth"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockAPIKeyRepo struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepo) Save(ctx context.Context, key *entities.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) FindByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) FindByID(ctx context.Context, id uuid.UUID) (*entities.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) List(ctx context.Context) ([]*entities.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepo) Revoke(ctx context.Context, key *entities.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepo) WithTx(tx *gorm.DB) entities.APIKeyRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.APIKeyRepo)
+}
+
+func TestAuthService_APIKeys(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("a created key authenticates with its role", func(t *testing.T) {
+		mockRepo := &MockAPIKeyRepo{}
+		service := usecase.NewAuthService(mockRepo, nil, logger.NewLogger("error"))
+		mockRepo.On("Save", ctx, mock.AnythingOfType("*entities.APIKey")).Return(nil)
+
+		key, secret, err := service.CreateAPIKey(ctx, "billing", entities.RoleService, uuid.Nil)
+		require.NoError(t, err)
+		assert.NotContains(t, key.Hash, secret)
+		assert.Equal(t, entities.HashAPIKey(secret), key.Hash)
+
+		mockRepo.On("FindByHash", ctx, key.Hash).Return(key, nil)
+		principal, err := service.Authenticate(ctx, secret)
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.RoleService, principal.Role)
+		assert.Equal(t, "api-key:"+key.ID.String(), principal.ID)
+	})
+
+	t.Run("customer keys need a user", func(t *testing.T) {
+		service := usecase.NewAuthService(&MockAPIKeyRepo{}, nil, logger.NewLogger("error"))
+
+		_, _, err := service.CreateAPIKey(ctx, "app", entities.RoleCustomer, uuid.Nil)
+
+		assert.ErrorIs(t, err, entities.ErrCustomerNoUser)
+	})
+
+	t.Run("unknown and revoked keys are rejected", func(t *testing.T) {
+		mockRepo := &MockAPIKeyRepo{}
+		service := usecase.NewAuthService(mockRepo, nil, logger.NewLogger("error"))
+		revoked, secret, err := entities.NewAPIKey("old", entities.RoleFinance, uuid.Nil)
+		require.NoError(t, err)
+		revoked.Revoke()
+		mockRepo.On("FindByHash", ctx, revoked.Hash).Return(revoked, nil)
+		mockRepo.On("FindByHash", ctx, entities.HashAPIKey("fk_unknown")).Return(nil, entities.ErrAPIKeyNotFound)
+
+		_, err = service.Authenticate(ctx, secret)
+		assert.ErrorIs(t, err, entities.ErrUnauthenticated)
+
+		_, err = service.Authenticate(ctx, "fk_unknown")
+		assert.ErrorIs(t, err, entities.ErrUnauthenticated)
+	})
+
+	t.Run("bearer tokens are rejected without configured keys", func(t *testing.T) {
+		service := usecase.NewAuthService(&MockAPIKeyRepo{}, nil, logger.NewLogger("error"))
+
+		_, err := service.Authenticate(ctx, "eyJhbGciOiJSUzI1NiJ9.e30.sig")
+
+		assert.ErrorIs(t, err, entities.ErrUnauthenticated)
+	})
+}
+
+func TestPrincipal_Access(t *testing.T) {
+	userID := uuid.New()
+	customer, err := entities.NewPrincipal("app", entities.RoleCustomer, userID)
+	require.NoError(t, err)
+	admin, err := entities.NewPrincipal("ops", entities.RoleAdmin, uuid.Nil)
+	require.NoError(t, err)
+
+	assert.True(t, customer.HasRole(entities.RoleFinance, entities.RoleCustomer))
+	assert.False(t, customer.HasRole(entities.RoleFinance))
+	assert.True(t, customer.CanAccessUser(userID))
+	assert.False(t, customer.CanAccessUser(uuid.New()))
+
+	assert.True(t, admin.HasRole())
+	assert.True(t, admin.CanAccessUser(uuid.New()))
+}
+
+// writeJWKS stores the public part of key as a JWKS file with the given kid
+func writeJWKS(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	data, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": kid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   encode(key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWTVerifier(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	verifier, err := auth.NewJWTVerifier(config.Auth{JWKSFile: writeJWKS(t, key, "k1"), Issuer: "https://id.example.com"})
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	t.Run("a valid customer token carries its user", func(t *testing.T) {
+		token := signToken(t, key, "k1", jwt.MapClaims{
+			"sub": userID.String(), "role": "customer", "iss": "https://id.example.com",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		principal, err := verifier.Verify(ctx, token)
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.RoleCustomer, principal.Role)
+		assert.Equal(t, userID, principal.UserID)
+	})
+
+	t.Run("expired, foreign and unknown role tokens are rejected", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		valid := jwt.MapClaims{"sub": "svc", "role": "service", "iss": "https://id.example.com", "exp": time.Now().Add(time.Hour).Unix()}
+		expired := jwt.MapClaims{"sub": "svc", "role": "service", "iss": "https://id.example.com", "exp": time.Now().Add(-time.Hour).Unix()}
+		wrongIssuer := jwt.MapClaims{"sub": "svc", "role": "service", "iss": "https://evil.example.com", "exp": time.Now().Add(time.Hour).Unix()}
+		unknownRole := jwt.MapClaims{"sub": "svc", "role": "root", "iss": "https://id.example.com", "exp": time.Now().Add(time.Hour).Unix()}
+
+		for name, token := range map[string]string{
+			"expired":      signToken(t, key, "k1", expired),
+			"wrong issuer": signToken(t, key, "k1", wrongIssuer),
+			"unknown role": signToken(t, key, "k1", unknownRole),
+			"other key":    signToken(t, other, "k1", valid),
+			"unknown kid":  signToken(t, key, "k2", valid),
+		} {
+			_, err := verifier.Verify(ctx, token)
+			assert.ErrorIs(t, err, entities.ErrUnauthenticated, name)
+		}
+	})
+
+	t.Run("no configured key means no verifier", func(t *testing.T) {
+		verifier, err := auth.NewJWTVerifier(config.Auth{})
+
+		require.NoError(t, err)
+		assert.Nil(t, verifier)
+	})
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func irr(amount int64) valueobjects.Money {
+	m, _ := valueobjects.NewMoney(big.NewInt(amount), "IRR")
+	return m
+}
+
+func newBucketWallet(t *testing.T, paid int64) *entities.Wallet {
+	wallet, err := entities.NewWallet(uuid.New(), "IRR")
+	require.NoError(t, err)
+	if paid > 0 {
+		require.NoError(t, wallet.Credit(irr(paid)))
+	}
+	return wallet
+}
+
+func TestWallet_DebitFunded(t *testing.T) {
+	t.Run("bonus is spent before paid balance", func(t *testing.T) {
+		wallet := newBucketWallet(t, 1000)
+		bucket, err := wallet.GrantBonus(irr(300), 0, nil, "promo")
+		require.NoError(t, err)
+
+		funding, err := wallet.DebitFunded(irr(500))
+
+		require.NoError(t, err)
+		require.Len(t, funding, 2)
+		assert.Equal(t, entities.BucketBonus, funding[0].Kind)
+		assert.Equal(t, bucket.ID, funding[0].BucketID)
+		assert.Equal(t, big.NewInt(300), funding[0].Amount.Amount())
+		assert.Equal(t, entities.BucketPaid, funding[1].Kind)
+		assert.Equal(t, big.NewInt(200), funding[1].Amount.Amount())
+		assert.True(t, bucket.Remaining.IsZero())
+		assert.Equal(t, big.NewInt(800), wallet.Balance.Amount())
+		assert.Equal(t, big.NewInt(800), wallet.PaidBalance().Amount())
+	})
+
+	t.Run("bucket above paid priority is spent after paid balance", func(t *testing.T) {
+		wallet := newBucketWallet(t, 100)
+		bucket, _ := wallet.GrantBonus(irr(300), entities.PaidBucketPriority+1, nil, "loyalty")
+
+		funding, err := wallet.DebitFunded(irr(150))
+
+		require.NoError(t, err)
+		require.Len(t, funding, 2)
+		assert.Equal(t, entities.BucketPaid, funding[0].Kind)
+		assert.Equal(t, big.NewInt(100), funding[0].Amount.Amount())
+		assert.Equal(t, big.NewInt(50), funding[1].Amount.Amount())
+		assert.Equal(t, big.NewInt(250), bucket.Remaining.Amount())
+	})
+
+	t.Run("bucket expiring first is spent first", func(t *testing.T) {
+		wallet := newBucketWallet(t, 0)
+		later := time.Now().Add(48 * time.Hour)
+		sooner := time.Now().Add(time.Hour)
+		late, _ := wallet.GrantBonus(irr(100), 0, &later, "")
+		soon, _ := wallet.GrantBonus(irr(100), 0, &sooner, "")
+
+		_, err := wallet.DebitFunded(irr(50))
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(50), soon.Remaining.Amount())
+		assert.Equal(t, big.NewInt(100), late.Remaining.Amount())
+	})
+
+	t.Run("expired bonus can not be spent", func(t *testing.T) {
+		wallet := newBucketWallet(t, 100)
+		bucket, _ := wallet.GrantBonus(irr(500), 0, nil, "")
+		expired := time.Now().Add(-time.Minute)
+		bucket.ExpiresAt = &expired
+
+		_, err := wallet.DebitFunded(irr(200))
+
+		assert.ErrorIs(t, err, entities.ErrInsufficientBalance)
+		assert.Equal(t, big.NewInt(600), wallet.Balance.Amount())
+	})
+}
+
+func TestWallet_RefundRestoresBuckets(t *testing.T) {
+	wallet := newBucketWallet(t, 1000)
+	bucket, _ := wallet.GrantBonus(irr(300), 0, nil, "")
+	funding, err := wallet.DebitFunded(irr(500))
+	require.NoError(t, err)
+
+	refunded, err := wallet.Refund(irr(500), funding)
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(500), entities.FundingTotal(refunded, "IRR").Amount())
+	assert.Equal(t, big.NewInt(300), bucket.Remaining.Amount())
+	assert.Equal(t, big.NewInt(1300), wallet.Balance.Amount())
+	assert.Equal(t, big.NewInt(1000), wallet.PaidBalance().Amount())
+}
+
+func TestWallet_ExpireBuckets(t *testing.T) {
+	wallet := newBucketWallet(t, 1000)
+	bucket, _ := wallet.GrantBonus(irr(300), 0, nil, "")
+	expiry := time.Now().Add(-time.Second)
+	bucket.ExpiresAt = &expiry
+
+	funding, err := wallet.ExpireBuckets(time.Now())
+
+	require.NoError(t, err)
+	require.Len(t, funding, 1)
+	assert.Equal(t, big.NewInt(300), funding[0].Amount.Amount())
+	assert.True(t, bucket.Remaining.IsZero())
+	assert.Equal(t, big.NewInt(1000), wallet.Balance.Amount())
+}
+
+func TestWalletService_ExpireBonusCredit(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+
+	ctx := context.Background()
+	now := time.Now()
+	wallet := newBucketWallet(t, 1000)
+	bucket, _ := wallet.GrantBonus(irr(300), 0, nil, "")
+	expiry := now.Add(-time.Hour)
+	bucket.ExpiresAt = &expiry
+
+	var recorded *entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindWalletIDsWithExpiredBuckets", ctx, now).Return([]uuid.UUID{wallet.ID}, nil)
+	mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*entities.Transaction) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, wallet).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	expired, err := service.ExpireBonusCredit(ctx, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	require.NotNil(t, recorded)
+	assert.Equal(t, entities.CategoryBonusExpiry, recorded.Category)
+	assert.Equal(t, entities.TransactionDebit, recorded.Type)
+	assert.Equal(t, big.NewInt(300), recorded.Amount.Amount())
+	assert.Equal(t, bucket.ID, recorded.Funding[0].BucketID)
+	assert.Equal(t, big.NewInt(1000), wallet.Balance.Amount())
+	mockWalletRepo.AssertExpectations(t)
+}
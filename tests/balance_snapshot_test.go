@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSnapshotTest(t *testing.T) (*usecase.BalanceSnapshotService, *entities.Wallet, *MockWalletRepo, *MockTransactionRepo, *MockBalanceSnapshotRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockSnapshotRepo := &MockBalanceSnapshotRepo{}
+
+	wallet, err := entities.NewWallet(uuid.New(), "IRR")
+	require.NoError(t, err)
+	mockWalletRepo.On("FindByID", mock.Anything, wallet.ID).Return(wallet, nil)
+
+	service := usecase.NewBalanceSnapshotService(mockSnapshotRepo, mockWalletRepo, mockTransactionRepo, logger.NewLogger("error"))
+	return service, wallet, mockWalletRepo, mockTransactionRepo, mockSnapshotRepo
+}
+
+func TestBalanceSnapshotService_GetBalanceAt(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC)
+
+	t.Run("replays transactions forward from the last snapshot", func(t *testing.T) {
+		service, wallet, _, mockTransactionRepo, mockSnapshotRepo := setupSnapshotTest(t)
+		snapshot := entities.NewBalanceSnapshot(wallet, big.NewInt(1000), time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC))
+		mockSnapshotRepo.On("FindLatest", ctx, wallet.ID, at).Return(snapshot, nil)
+		mockTransactionRepo.On("SumBetween", ctx, wallet.ID, "IRR", snapshot.TakenAt, at).Return(big.NewInt(-250), nil)
+
+		balance, err := service.GetBalanceAt(ctx, wallet.ID, at)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(750), balance.Balance)
+		require.NotNil(t, balance.SnapshotAt)
+		assert.Equal(t, snapshot.TakenAt, *balance.SnapshotAt)
+		mockTransactionRepo.AssertNotCalled(t, "BalanceAt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("sums every transaction without a snapshot", func(t *testing.T) {
+		service, wallet, _, mockTransactionRepo, mockSnapshotRepo := setupSnapshotTest(t)
+		mockSnapshotRepo.On("FindLatest", ctx, wallet.ID, at).Return(nil, entities.ErrSnapshotNotFound)
+		mockTransactionRepo.On("BalanceAt", ctx, wallet.ID, "IRR", at).Return(big.NewInt(300), nil)
+
+		balance, err := service.GetBalanceAt(ctx, wallet.ID, at)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(300), balance.Balance)
+		assert.Nil(t, balance.SnapshotAt)
+	})
+}
+
+func TestBalanceSnapshotService_TakeSnapshots(t *testing.T) {
+	ctx := context.Background()
+	midnight := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("builds on the previous snapshot", func(t *testing.T) {
+		service, wallet, mockWalletRepo, mockTransactionRepo, mockSnapshotRepo := setupSnapshotTest(t)
+		previous := entities.NewBalanceSnapshot(wallet, big.NewInt(1000), midnight.AddDate(0, 0, -1))
+		mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{wallet.ID}, nil)
+		mockSnapshotRepo.On("FindLatest", ctx, wallet.ID, midnight).Return(previous, nil)
+		mockTransactionRepo.On("SumBetween", ctx, wallet.ID, "IRR", previous.TakenAt, midnight).Return(big.NewInt(200), nil)
+		mockSnapshotRepo.On("Save", ctx, mock.MatchedBy(func(s *entities.BalanceSnapshot) bool {
+			return s.WalletID == wallet.ID && s.TakenAt.Equal(midnight) && s.Balance.Cmp(big.NewInt(1200)) == 0
+		})).Return(nil)
+
+		taken, err := service.TakeSnapshots(ctx, midnight)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, taken)
+		mockSnapshotRepo.AssertExpectations(t)
+	})
+
+	t.Run("a second run for the same time saves nothing", func(t *testing.T) {
+		service, wallet, mockWalletRepo, mockTransactionRepo, mockSnapshotRepo := setupSnapshotTest(t)
+		existing := entities.NewBalanceSnapshot(wallet, big.NewInt(1200), midnight)
+		mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{wallet.ID}, nil)
+		mockSnapshotRepo.On("FindLatest", ctx, wallet.ID, midnight).Return(existing, nil)
+		mockTransactionRepo.On("SumBetween", ctx, wallet.ID, "IRR", midnight, midnight).Return(big.NewInt(0), nil)
+
+		taken, err := service.TakeSnapshots(ctx, midnight)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, taken)
+		mockSnapshotRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceThreshold(t *testing.T) {
+	amount, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+	hysteresis, _ := valueobjects.NewMoney(big.NewInt(200), "IRR")
+
+	t.Run("should start armed when balance is above the threshold", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(5000), "IRR")
+		wallet.Credit(credit)
+
+		threshold, err := entities.NewBalanceThreshold(wallet, amount, hysteresis)
+
+		require.NoError(t, err)
+		assert.True(t, threshold.Armed)
+		assert.Equal(t, wallet.ID, threshold.WalletID)
+	})
+
+	t.Run("should start disarmed when balance is already below", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+
+		threshold, err := entities.NewBalanceThreshold(wallet, amount, hysteresis)
+
+		require.NoError(t, err)
+		assert.False(t, threshold.Armed)
+	})
+
+	t.Run("should reject zero threshold and other currencies", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		zero, _ := valueobjects.NewMoney(big.NewInt(0), "IRR")
+		usd, _ := valueobjects.NewMoney(big.NewInt(10), "USD")
+
+		_, err := entities.NewBalanceThreshold(wallet, zero, hysteresis)
+		assert.Equal(t, entities.ErrInvalidAmount, err)
+
+		_, err = entities.NewBalanceThreshold(wallet, usd, hysteresis)
+		assert.Equal(t, valueobjects.ErrCurrencyMismatch, err)
+	})
+
+	t.Run("crossing and recovery follow the hysteresis band", func(t *testing.T) {
+		threshold := &entities.BalanceThreshold{Amount: amount, Hysteresis: hysteresis, Armed: true}
+		below, _ := valueobjects.NewSignedMoney(big.NewInt(999), "IRR")
+		insideBand, _ := valueobjects.NewMoney(big.NewInt(1100), "IRR")
+		recovered, _ := valueobjects.NewMoney(big.NewInt(1200), "IRR")
+
+		assert.True(t, threshold.Crossed(below))
+		assert.False(t, threshold.Recovered(recovered))
+
+		threshold.Armed = false
+		assert.False(t, threshold.Crossed(below))
+		assert.False(t, threshold.Recovered(insideBand))
+		assert.True(t, threshold.Recovered(recovered))
+	})
+}
+
+func setupBalanceAlertTest() (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo, *MockThresholdRepo, *MockPublisher) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockUserRepo := &MockUserRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+	mockTopUpRepo := &MockTopUpRepo{}
+	mockTxManager := &MockTransactionManager{}
+	mockPublisher := &MockPublisher{}
+
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockTransactionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockWalletRepo.On("UpdateBalance", mock.Anything, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	mockTopUpRepo.On("FindRulesByWalletID", mock.Anything, mock.Anything).Return([]*entities.TopUpRule{}, nil).Maybe()
+
+	service := usecase.NewWalletService(
+		mockWalletRepo,
+		mockUserRepo,
+		mockTransactionRepo,
+		mockThresholdRepo,
+		mockTopUpRepo,
+		flatTariffRepo(),
+		noPlanRepo(),
+		&MockVoucherRepo{},
+		&MockTransferRepo{},
+		&MockFXRateRepo{},
+		noTaxRepo(),
+		mockTxManager,
+		mockPublisher,
+		&logger.Logger{},
+	)
+	return service, mockWalletRepo, mockTransactionRepo, mockThresholdRepo, mockPublisher
+}
+
+func TestWalletService_BalanceAlerts(t *testing.T) {
+	t.Run("debit below an armed threshold emits WalletBalanceLow once", func(t *testing.T) {
+		service, mockWalletRepo, _, mockThresholdRepo, mockPublisher := setupBalanceAlertTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1500), "IRR")
+		wallet.Credit(credit)
+
+		amount, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+		hysteresis, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+		threshold, _ := entities.NewBalanceThreshold(wallet, amount, hysteresis)
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockThresholdRepo.On("FindByWalletID", ctx, wallet.ID).Return([]*entities.BalanceThreshold{threshold}, nil)
+		mockThresholdRepo.On("SetArmed", ctx, threshold.ID, false).Return(true, nil)
+		mockPublisher.On("PublishEvent", ctx, mock.AnythingOfType("*events.WalletBalanceLow")).Return(nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(6))
+
+		require.NoError(t, err)
+		mockThresholdRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+
+		low := mockPublisher.Calls[0].Arguments.Get(1).(*events.WalletBalanceLow)
+		assert.Equal(t, int64(900), low.Balance)
+		assert.Equal(t, int64(1000), low.Threshold)
+		assert.Equal(t, threshold.ID.String(), low.ThresholdID)
+	})
+
+	t.Run("no alert when another debit already disarmed the threshold", func(t *testing.T) {
+		service, mockWalletRepo, _, mockThresholdRepo, mockPublisher := setupBalanceAlertTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1500), "IRR")
+		wallet.Credit(credit)
+
+		amount, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+		hysteresis, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+		threshold, _ := entities.NewBalanceThreshold(wallet, amount, hysteresis)
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockThresholdRepo.On("FindByWalletID", ctx, wallet.ID).Return([]*entities.BalanceThreshold{threshold}, nil)
+		mockThresholdRepo.On("SetArmed", ctx, threshold.ID, false).Return(false, nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(6))
+
+		require.NoError(t, err)
+		mockPublisher.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("debit to zero emits WalletBalanceDepleted", func(t *testing.T) {
+		service, mockWalletRepo, _, mockThresholdRepo, mockPublisher := setupBalanceAlertTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+		wallet.Credit(credit)
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockThresholdRepo.On("FindByWalletID", ctx, wallet.ID).Return([]*entities.BalanceThreshold{}, nil)
+		mockPublisher.On("PublishEvent", ctx, mock.AnythingOfType("*events.WalletBalanceDepleted")).Return(nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(1))
+
+		require.NoError(t, err)
+		mockPublisher.AssertExpectations(t)
+	})
+
+	t.Run("credit above the hysteresis band arms the threshold again", func(t *testing.T) {
+		service, mockWalletRepo, _, mockThresholdRepo, mockPublisher := setupBalanceAlertTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+
+		amount, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+		hysteresis, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+		threshold, _ := entities.NewBalanceThreshold(wallet, amount, hysteresis)
+		require.False(t, threshold.Armed)
+
+		mockWalletRepo.On("LockByUserID", ctx, userID).Return(wallet, nil)
+		mockThresholdRepo.On("FindByWalletID", ctx, wallet.ID).Return([]*entities.BalanceThreshold{threshold}, nil)
+		mockThresholdRepo.On("SetArmed", ctx, threshold.ID, true).Return(true, nil)
+
+		err := service.CreditUserBalance(ctx, userID, *big.NewInt(1100))
+
+		require.NoError(t, err)
+		mockThresholdRepo.AssertExpectations(t)
+		mockPublisher.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything)
+	})
+}
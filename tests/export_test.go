@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupExportTest(txs []*entities.Transaction) (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockTransactionRepo.On("Stream", mock.Anything, mock.Anything, mock.Anything).Return(txs, nil)
+
+	service := usecase.NewWalletService(mockWalletRepo, nil, mockTransactionRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &logger.Logger{})
+	return service, mockWalletRepo, mockTransactionRepo
+}
+
+func exportedTransaction(wallet *entities.Wallet) *entities.Transaction {
+	tx := entities.NewTransaction(wallet.ID, wallet.UserID, uuid.Nil, irr(1500), entities.TransactionDebit)
+	tx.CreatedAt = time.Date(2026, 9, 1, 20, 30, 0, 0, time.UTC)
+	return tx
+}
+
+func TestWalletService_ExportTransactions(t *testing.T) {
+	ctx := context.Background()
+	wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+	tx := exportedTransaction(wallet)
+	from, to := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("csv has a header and the chosen columns in the chosen timezone", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo := setupExportTest([]*entities.Transaction{tx})
+		mockWalletRepo.On("FindByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		tehran, err := time.LoadLocation("Asia/Tehran")
+		require.NoError(t, err)
+
+		export, err := service.ExportTransactions(ctx, wallet.UserID, usecase.ExportOptions{
+			Format: entities.ExportCSV, Columns: []string{"id", "created_at", "amount", "sms_id"}, Location: tehran, From: from, To: to,
+		})
+		require.NoError(t, err)
+		var out bytes.Buffer
+		require.NoError(t, export.Stream(ctx, &out))
+
+		assert.Equal(t, "id,created_at,amount,sms_id\n"+tx.ID.String()+",2026-09-02T00:00:00+03:30,1500,\n", out.String())
+		mockTransactionRepo.AssertCalled(t, "Stream", ctx, entities.TransactionFilter{WalletID: &wallet.ID, From: from, To: to}, mock.Anything)
+	})
+
+	t.Run("json lines keep the column order", func(t *testing.T) {
+		service, _, mockTransactionRepo := setupExportTest([]*entities.Transaction{tx, tx})
+
+		export, err := service.ExportAllTransactions(ctx, usecase.ExportOptions{
+			Format: entities.ExportJSONL, Columns: []string{"type", "amount", "currency"}, From: from, To: to,
+		})
+		require.NoError(t, err)
+		var out bytes.Buffer
+		require.NoError(t, export.Stream(ctx, &out))
+
+		line := `{"type":"debit","amount":"1500","currency":"IRR"}` + "\n"
+		assert.Equal(t, line+line, out.String())
+		mockTransactionRepo.AssertCalled(t, "Stream", ctx, entities.TransactionFilter{From: from, To: to}, mock.Anything)
+	})
+
+	t.Run("bad requests are rejected before reading", func(t *testing.T) {
+		service, _, mockTransactionRepo := setupExportTest(nil)
+
+		_, err := service.ExportAllTransactions(ctx, usecase.ExportOptions{Format: "xlsx", From: from, To: to})
+		assert.ErrorIs(t, err, entities.ErrInvalidExportFormat)
+		_, err = service.ExportAllTransactions(ctx, usecase.ExportOptions{Format: entities.ExportCSV, Columns: []string{"password"}, From: from, To: to})
+		assert.ErrorIs(t, err, entities.ErrUnknownExportColumn)
+		_, err = service.ExportAllTransactions(ctx, usecase.ExportOptions{Format: entities.ExportCSV, From: to, To: from})
+		assert.ErrorIs(t, err, entities.ErrInvalidExportPeriod)
+		mockTransactionRepo.AssertNotCalled(t, "Stream", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFXTest() (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo, *MockFXRateRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockFXRateRepo := &MockFXRateRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+	mockTxManager := &MockTransactionManager{}
+
+	mockThresholdRepo.On("FindByWalletID", mock.Anything, mock.Anything).Return([]*entities.BalanceThreshold{}, nil).Maybe()
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockFXRateRepo.On("WithTx", mock.Anything).Return(mockFXRateRepo)
+	mockUserRepo := &MockUserRepo{}
+	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
+	mockWalletRepo.On("UpdateBalance", mock.Anything, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	service := usecase.NewWalletService(
+		mockWalletRepo,
+		mockUserRepo,
+		mockTransactionRepo,
+		mockThresholdRepo,
+		&MockTopUpRepo{},
+		flatTariffRepo(),
+		noPlanRepo(),
+		&MockVoucherRepo{},
+		&MockTransferRepo{},
+		mockFXRateRepo,
+		noTaxRepo(),
+		mockTxManager,
+		&MockPublisher{},
+		&logger.Logger{},
+	)
+	return service, mockWalletRepo, mockTransactionRepo, mockFXRateRepo
+}
+
+func usdToIRR(t *testing.T) *entities.FXRate {
+	rate, err := entities.NewFXRate("usd", "irr", "42000.5", time.Time{}, nil, entities.FXSourceManual)
+	require.NoError(t, err)
+	return rate
+}
+
+func TestFXRate_Convert(t *testing.T) {
+	rate := usdToIRR(t)
+	assert.Equal(t, "USD", rate.Base)
+	assert.Equal(t, "IRR", rate.Quote)
+
+	usd, _ := valueobjects.NewMoney(big.NewInt(3), "USD")
+	converted, err := rate.Convert(usd)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(126001), converted.Amount(), "fractions of the smallest unit are rounded down")
+	assert.Equal(t, "IRR", converted.Currency())
+
+	back, err := rate.Inverse().Convert(converted)
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(2), back.Amount())
+
+	_, err = rate.Convert(irr(10))
+	assert.ErrorIs(t, err, valueobjects.ErrCurrencyMismatch)
+
+	for _, r := range []string{"0", "-1", "abc"} {
+		_, err := entities.NewFXRate("USD", "IRR", r, time.Time{}, nil, entities.FXSourceManual)
+		assert.ErrorIs(t, err, entities.ErrInvalidFXRate)
+	}
+	_, err = entities.NewFXRate("USD", "USD", "1", time.Time{}, nil, entities.FXSourceManual)
+	assert.ErrorIs(t, err, entities.ErrInvalidFXRate)
+}
+
+func TestWalletService_CreditUserBalanceIn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("foreign credit is converted and the rate is recorded", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 0)
+		rate := usdToIRR(t)
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockFXRateRepo.On("FindValid", ctx, "USD", "IRR", mock.AnythingOfType("time.Time")).Return(rate, nil)
+
+		transaction, err := service.CreditUserBalanceIn(ctx, wallet.UserID, big.NewInt(10), "usd")
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(420005), wallet.Balance.Amount())
+		assert.Equal(t, "IRR", transaction.Amount.Currency())
+		assert.Equal(t, big.NewInt(10), transaction.SourceAmount.Amount())
+		assert.Equal(t, "USD", transaction.SourceAmount.Currency())
+		assert.Equal(t, "84001/2", transaction.FXRate)
+		assert.Equal(t, rate.ID, transaction.FXRateID)
+		mockTransactionRepo.AssertCalled(t, "Create", ctx, transaction)
+	})
+
+	t.Run("inverse rate is used when only the other direction is stored", func(t *testing.T) {
+		service, mockWalletRepo, _, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 0)
+		rate := usdToIRR(t)
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockFXRateRepo.On("FindValid", ctx, "EUR", "IRR", mock.Anything).Return(nil, entities.ErrFXRateNotFound)
+		mockFXRateRepo.On("FindValid", ctx, "IRR", "EUR", mock.Anything).Return(&entities.FXRate{
+			ID: rate.ID, Base: "IRR", Quote: "EUR", Rate: big.NewRat(1, 50000),
+		}, nil)
+
+		transaction, err := service.CreditUserBalanceIn(ctx, wallet.UserID, big.NewInt(2), "EUR")
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(100000), wallet.Balance.Amount())
+		assert.Equal(t, "50000", transaction.FXRate)
+	})
+
+	t.Run("missing rate rejects the credit", func(t *testing.T) {
+		service, mockWalletRepo, _, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 0)
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockFXRateRepo.On("FindValid", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, entities.ErrFXRateNotFound)
+
+		_, err := service.CreditUserBalanceIn(ctx, wallet.UserID, big.NewInt(2), "EUR")
+
+		assert.ErrorIs(t, err, entities.ErrFXRateNotFound)
+		assert.True(t, wallet.Balance.IsZero())
+	})
+
+	t.Run("credit in a sub-balance currency is kept apart", func(t *testing.T) {
+		service, mockWalletRepo, _, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 100)
+		sub, err := wallet.AddSubBalance("USD")
+		require.NoError(t, err)
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+
+		transaction, err := service.CreditUserBalanceIn(ctx, wallet.UserID, big.NewInt(25), "USD")
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), wallet.Balance.Amount())
+		assert.Equal(t, big.NewInt(25), sub.Balance.Amount())
+		assert.Equal(t, "USD", transaction.Amount.Currency())
+		assert.Empty(t, transaction.FXRate)
+		mockFXRateRepo.AssertNotCalled(t, "FindValid", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestWalletService_ConvertSubBalance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("moves money into the wallet balance", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 0)
+		sub, _ := wallet.AddSubBalance("USD")
+		usd, _ := valueobjects.NewMoney(big.NewInt(10), "USD")
+		require.NoError(t, wallet.CreditInCurrency(usd))
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockFXRateRepo.On("FindValid", ctx, "USD", "IRR", mock.Anything).Return(usdToIRR(t), nil)
+
+		transaction, err := service.ConvertSubBalance(ctx, wallet.UserID, "USD", big.NewInt(4))
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(6), sub.Balance.Amount())
+		assert.Equal(t, big.NewInt(168002), wallet.Balance.Amount())
+		assert.Equal(t, entities.CategoryFXConversion, transaction.Category)
+		assert.Equal(t, big.NewInt(4), transaction.SourceAmount.Amount())
+
+		var recorded []*entities.Transaction
+		for _, call := range mockTransactionRepo.Calls {
+			if call.Method == "Create" {
+				recorded = append(recorded, call.Arguments.Get(1).(*entities.Transaction))
+			}
+		}
+		require.Len(t, recorded, 2)
+		debit := recorded[0]
+		assert.Equal(t, entities.TransactionDebit, debit.Type)
+		assert.Equal(t, entities.CategoryFXConversion, debit.Category)
+		assert.Equal(t, "USD", debit.Amount.Currency())
+		assert.Equal(t, big.NewInt(4), debit.Amount.Amount())
+		assert.Equal(t, transaction.ID, debit.ReferenceID)
+		assert.Equal(t, entities.TransactionCompleted, debit.Status)
+	})
+
+	t.Run("can not convert more than the sub-balance holds", func(t *testing.T) {
+		service, mockWalletRepo, _, mockFXRateRepo := setupFXTest()
+		wallet := newBucketWallet(t, 0)
+		_, _ = wallet.AddSubBalance("USD")
+		mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockFXRateRepo.On("FindValid", ctx, "USD", "IRR", mock.Anything).Return(usdToIRR(t), nil)
+
+		_, err := service.ConvertSubBalance(ctx, wallet.UserID, "USD", big.NewInt(4))
+
+		assert.ErrorIs(t, err, entities.ErrInsufficientBalance)
+	})
+
+	t.Run("sub-balance must be opened first", func(t *testing.T) {
+		wallet := newBucketWallet(t, 0)
+		usd, _ := valueobjects.NewMoney(big.NewInt(4), "USD")
+		assert.ErrorIs(t, wallet.DebitSubBalance(usd), entities.ErrSubBalanceNotFound)
+
+		_, err := wallet.AddSubBalance("IRR")
+		assert.ErrorIs(t, err, entities.ErrSubBalanceExists)
+		_, err = wallet.AddSubBalance("USD")
+		require.NoError(t, err)
+		_, err = wallet.AddSubBalance("USD")
+		assert.ErrorIs(t, err, entities.ErrSubBalanceExists)
+	})
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"finance/pkg/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Run(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Add("database", func(ctx context.Context) error { return nil })
+	checker.Add("rabbitmq", func(ctx context.Context) error { return nil })
+
+	report := checker.Run(context.Background())
+
+	assert.True(t, report.Ready())
+	assert.Equal(t, []string{"database", "rabbitmq"}, checker.Names())
+	assert.Equal(t, "ok", report.Checks["database"].Status)
+
+	checker.Add("rabbitmq", func(ctx context.Context) error { return errors.New("rabbitmq channel closed") })
+	report = checker.Run(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, "ok", report.Checks["database"].Status)
+	assert.Equal(t, "error", report.Checks["rabbitmq"].Status)
+	assert.Equal(t, "rabbitmq channel closed", report.Checks["rabbitmq"].Error)
+}
+
+func TestChecker_RunTimesOut(t *testing.T) {
+	checker := health.NewChecker()
+	block := make(chan struct{})
+	defer close(block)
+	checker.Add("database", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report := checker.Run(ctx)
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
+
+func TestChecker_Handlers(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Add("migrations", func(ctx context.Context) error { return errors.New("schema version 0, want 1") })
+
+	rec := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "not_ready", report.Status)
+	assert.Equal(t, "schema version 0, want 1", report.Checks["migrations"].Error)
+
+	checker.Add("migrations", func(ctx context.Context) error { return nil })
+	rec = httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
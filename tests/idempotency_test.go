@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockIdempotencyRepo struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepo) Acquire(ctx context.Context, record *entities.IdempotencyRecord) (*entities.IdempotencyRecord, error) {
+	args := m.Called(ctx, record)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepo) Save(ctx context.Context, record *entities.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepo) Release(ctx context.Context, record *entities.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockIdempotencyRepo) WithTx(tx *gorm.DB) entities.IdempotencyRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.IdempotencyRepo)
+}
+
+func setupIdempotencyTest() (*usecase.IdempotencyService, *MockIdempotencyRepo) {
+	mockRepo := &MockIdempotencyRepo{}
+	mockTxManager := &MockTransactionManager{}
+	mockRepo.On("WithTx", mock.Anything).Return(mockRepo)
+	mockTxManager.On("WithTransaction", mock.Anything).Return(nil)
+	return usecase.NewIdempotencyService(mockRepo, mockTxManager, logger.NewLogger("error")), mockRepo
+}
+
+func creditRequest(t *testing.T, hash string) *entities.IdempotencyRecord {
+	record, err := entities.NewIdempotencyRecord("retry-1", "POST /api/v1/wallet", "signing-key:payment-gateway", hash)
+	require.NoError(t, err)
+	return record
+}
+
+func TestIdempotencyService_Execute(t *testing.T) {
+	ctx := context.Background()
+	created := &entities.IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"success":true}`)}
+
+	t.Run("a new key runs the request and stores its response", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		record := creditRequest(t, "hash-a")
+		mockRepo.On("Acquire", ctx, record).Return(record, nil)
+		mockRepo.On("Save", ctx, record).Return(nil)
+		runs := 0
+
+		response, replayed, err := service.Execute(ctx, record, func() (*entities.IdempotentResponse, error) {
+			runs++
+			// the claim is committed before the request runs
+			mockRepo.AssertNumberOfCalls(t, "Save", 1)
+			assert.True(t, record.InProgress(time.Now()))
+			return created, nil
+		})
+
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, created, response)
+		assert.Equal(t, 1, runs)
+		mockRepo.AssertNumberOfCalls(t, "Save", 2)
+		mockRepo.AssertNumberOfCalls(t, "WithTx", 2)
+		assert.WithinDuration(t, time.Now().Add(entities.IdempotencyTTL), record.ExpiresAt, time.Minute)
+	})
+
+	t.Run("a repeated request replays the stored response", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		stored := creditRequest(t, "hash-a")
+		stored.Complete("hash-a", created, time.Now())
+		retry := creditRequest(t, "hash-a")
+		mockRepo.On("Acquire", ctx, retry).Return(stored, nil)
+
+		response, replayed, err := service.Execute(ctx, retry, func() (*entities.IdempotentResponse, error) {
+			t.Fatal("a replayed request must not run")
+			return nil, nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, replayed)
+		assert.Equal(t, created, response)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("the same key with another body conflicts", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		stored := creditRequest(t, "hash-a")
+		stored.Complete("hash-a", created, time.Now())
+		other := creditRequest(t, "hash-b")
+		mockRepo.On("Acquire", ctx, other).Return(stored, nil)
+
+		_, _, err := service.Execute(ctx, other, func() (*entities.IdempotentResponse, error) {
+			t.Fatal("a conflicting request must not run")
+			return nil, nil
+		})
+
+		assert.ErrorIs(t, err, entities.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("a key whose request is running conflicts", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		running := creditRequest(t, "hash-a")
+		running.Claim("hash-a", time.Now())
+		retry := creditRequest(t, "hash-a")
+		mockRepo.On("Acquire", ctx, retry).Return(running, nil)
+
+		_, _, err := service.Execute(ctx, retry, func() (*entities.IdempotentResponse, error) {
+			t.Fatal("a request must not run while another one holds the key")
+			return nil, nil
+		})
+
+		assert.ErrorIs(t, err, entities.ErrIdempotencyKeyInProgress)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("a lapsed claim is taken over", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		abandoned := creditRequest(t, "hash-a")
+		abandoned.Claim("hash-a", time.Now().Add(-entities.IdempotencyLease-time.Second))
+		retry := creditRequest(t, "hash-a")
+		mockRepo.On("Acquire", ctx, retry).Return(abandoned, nil)
+		mockRepo.On("Save", ctx, abandoned).Return(nil)
+
+		response, replayed, err := service.Execute(ctx, retry, func() (*entities.IdempotentResponse, error) {
+			return created, nil
+		})
+
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, created, response)
+	})
+
+	t.Run("a failed request releases the key and its error is kept", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		record := creditRequest(t, "hash-a")
+		mockRepo.On("Acquire", ctx, record).Return(record, nil)
+		mockRepo.On("Save", ctx, record).Return(nil)
+		mockRepo.On("Release", ctx, record).Return(nil)
+		failure := errors.New("wallet not found")
+
+		_, replayed, err := service.Execute(ctx, record, func() (*entities.IdempotentResponse, error) {
+			return nil, failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		assert.False(t, replayed)
+		mockRepo.AssertNumberOfCalls(t, "Save", 1)
+		mockRepo.AssertCalled(t, "Release", ctx, record)
+		assert.Nil(t, record.Response)
+	})
+
+	t.Run("an expired response runs the request again", func(t *testing.T) {
+		service, mockRepo := setupIdempotencyTest()
+		stored := creditRequest(t, "hash-a")
+		stored.Complete("hash-a", created, time.Now().Add(-entities.IdempotencyTTL-time.Minute))
+		retry := creditRequest(t, "hash-b")
+		mockRepo.On("Acquire", ctx, retry).Return(stored, nil)
+		mockRepo.On("Save", ctx, stored).Return(nil)
+
+		_, replayed, err := service.Execute(ctx, retry, func() (*entities.IdempotentResponse, error) {
+			return created, nil
+		})
+
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		assert.Equal(t, "hash-b", stored.RequestHash)
+	})
+
+	t.Run("overlong keys are rejected", func(t *testing.T) {
+		_, err := entities.NewIdempotencyRecord(string(make([]byte, entities.MaxIdempotencyKeyLength+1)), "POST /", "caller", "hash")
+
+		assert.ErrorIs(t, err, entities.ErrInvalidIdempotencyKey)
+	})
+}
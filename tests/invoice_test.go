@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func invoiceLine(txType entities.TransactionType, category entities.TransactionCategory, amount, tax int64) entities.InvoiceLine {
+	return entities.InvoiceLine{
+		Day:      time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC),
+		Type:     txType,
+		Category: category,
+		Count:    1,
+		Amount:   big.NewInt(amount),
+		Tax:      big.NewInt(tax),
+	}
+}
+
+func TestNewInvoice(t *testing.T) {
+	wallet, err := entities.NewWallet(uuid.New(), "IRR")
+	require.NoError(t, err)
+	from, to := entities.MonthPeriod(time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC))
+
+	t.Run("sums the lines into the closing balance", func(t *testing.T) {
+		lines := []entities.InvoiceLine{
+			invoiceLine(entities.TransactionCredit, entities.CategoryTopUp, 1000, 0),
+			invoiceLine(entities.TransactionDebit, entities.CategorySMS, 330, 30),
+			invoiceLine(entities.TransactionCredit, entities.CategoryRefund, 110, 10),
+		}
+
+		invoice, err := entities.NewInvoice(wallet, from, to, big.NewInt(500), lines)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(500), invoice.OpeningBalance)
+		assert.Equal(t, big.NewInt(1000), invoice.Credits)
+		assert.Equal(t, big.NewInt(330), invoice.Debits)
+		assert.Equal(t, big.NewInt(110), invoice.Refunds)
+		assert.Equal(t, big.NewInt(20), invoice.Tax, "refunded tax is given back")
+		assert.Equal(t, big.NewInt(1280), invoice.ClosingBalance)
+		assert.Equal(t, wallet.ID, invoice.WalletID)
+	})
+
+	t.Run("numbers are zero padded", func(t *testing.T) {
+		invoice, err := entities.NewInvoice(wallet, from, to, big.NewInt(0), nil)
+		require.NoError(t, err)
+
+		invoice.AssignNumber(42)
+
+		assert.Equal(t, "INV-00000042", invoice.Number)
+		assert.Equal(t, int64(42), invoice.Sequence)
+	})
+
+	t.Run("empty period is rejected", func(t *testing.T) {
+		_, err := entities.NewInvoice(wallet, from, from, big.NewInt(0), nil)
+		assert.ErrorIs(t, err, entities.ErrInvalidInvoicePeriod)
+	})
+}
+
+func setupInvoiceTest() (*usecase.InvoiceService, *MockWalletRepo, *MockTransactionRepo, *MockInvoiceRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockInvoiceRepo := &MockInvoiceRepo{}
+	mockTxManager := &MockTransactionManager{}
+
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockInvoiceRepo.On("WithTx", mock.Anything).Return(mockInvoiceRepo)
+	mockTxManager.On("WithTransaction", mock.Anything).Return(nil)
+
+	service := usecase.NewInvoiceService(mockInvoiceRepo, mockWalletRepo, &MockUserRepo{}, mockTransactionRepo, mockTxManager, &logger.Logger{})
+	return service, mockWalletRepo, mockTransactionRepo, mockInvoiceRepo
+}
+
+func TestInvoiceService_GenerateInvoice(t *testing.T) {
+	ctx := context.Background()
+	lastMonth := entities.StartOfMonth(time.Now()).AddDate(0, -1, 0)
+	from, to := entities.MonthPeriod(lastMonth)
+
+	t.Run("builds and numbers a new invoice", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockInvoiceRepo := setupInvoiceTest()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		mockWalletRepo.On("FindByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockInvoiceRepo.On("FindByPeriod", ctx, wallet.ID, from).Return(nil, entities.ErrInvoiceNotFound)
+		mockTransactionRepo.On("BalanceAt", ctx, wallet.ID, "IRR", from).Return(big.NewInt(100), nil)
+		mockTransactionRepo.On("StatementLines", ctx, wallet.ID, "IRR", from, to).Return([]entities.InvoiceLine{
+			invoiceLine(entities.TransactionDebit, entities.CategorySMS, 40, 0),
+		}, nil)
+		mockInvoiceRepo.On("NextSequence", ctx).Return(int64(7), nil)
+		mockInvoiceRepo.On("Create", ctx, mock.AnythingOfType("*entities.Invoice")).Return(nil)
+
+		invoice, err := service.GenerateInvoice(ctx, wallet.UserID, lastMonth)
+
+		require.NoError(t, err)
+		assert.Equal(t, "INV-00000007", invoice.Number)
+		assert.Equal(t, big.NewInt(60), invoice.ClosingBalance)
+		mockInvoiceRepo.AssertExpectations(t)
+	})
+
+	t.Run("returns the stored invoice of the period", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockInvoiceRepo := setupInvoiceTest()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		existing := &entities.Invoice{ID: uuid.New(), Number: "INV-00000001", WalletID: wallet.ID}
+		mockWalletRepo.On("FindByUserID", ctx, wallet.UserID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockInvoiceRepo.On("FindByPeriod", ctx, wallet.ID, from).Return(existing, nil)
+
+		invoice, err := service.GenerateInvoice(ctx, wallet.UserID, lastMonth)
+
+		require.NoError(t, err)
+		assert.Equal(t, existing, invoice)
+		mockInvoiceRepo.AssertNotCalled(t, "NextSequence", mock.Anything)
+		mockInvoiceRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		mockTransactionRepo.AssertNotCalled(t, "StatementLines", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("current month is not invoiced", func(t *testing.T) {
+		service, _, _, _ := setupInvoiceTest()
+
+		_, err := service.GenerateInvoice(ctx, uuid.New(), time.Now())
+
+		assert.ErrorIs(t, err, entities.ErrPeriodNotClosed)
+	})
+}
+
+func TestInvoiceService_GetUserInvoice(t *testing.T) {
+	ctx := context.Background()
+	service, mockWalletRepo, _, mockInvoiceRepo := setupInvoiceTest()
+	wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+	invoice := &entities.Invoice{ID: uuid.New(), Number: "INV-00000001", UserID: wallet.UserID, WalletID: wallet.ID}
+	mockInvoiceRepo.On("FindByID", ctx, invoice.ID).Return(invoice, nil)
+	mockWalletRepo.On("FindByID", ctx, wallet.ID).Return(wallet, nil)
+
+	t.Run("the owner of the wallet gets the invoice", func(t *testing.T) {
+		found, err := service.GetUserInvoice(ctx, wallet.UserID, invoice.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, invoice, found)
+	})
+
+	t.Run("another user does not find it", func(t *testing.T) {
+		_, err := service.GetUserInvoice(ctx, uuid.New(), invoice.ID)
+
+		assert.ErrorIs(t, err, entities.ErrInvoiceNotFound)
+	})
+}
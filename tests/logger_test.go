@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"finance/pkg/logger"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileLogger logs to a file in the test's temp dir, read returns the records written so far
+func newFileLogger(t *testing.T, opt logger.Options) (*logger.Logger, func() []map[string]any) {
+	t.Helper()
+	opt.Output = filepath.Join(t.TempDir(), "finance.log")
+	log, err := logger.New(opt)
+	require.NoError(t, err)
+	t.Cleanup(func() { log.Close() })
+
+	return log, func() []map[string]any {
+		f, err := os.Open(opt.Output)
+		require.NoError(t, err)
+		defer f.Close()
+
+		var records []map[string]any
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestLogger_Level(t *testing.T) {
+	ctx := context.Background()
+	log, read := newFileLogger(t, logger.Options{Level: logger.LevelWarn})
+
+	log.Info(ctx, "hidden")
+	log.Warn(ctx, "shown")
+	require.NoError(t, log.SetLevel(logger.LevelDebug))
+	log.Debug(ctx, "debug shown")
+
+	records := read()
+	require.Len(t, records, 2)
+	assert.Equal(t, "shown", records[0]["msg"])
+	assert.Equal(t, "debug shown", records[1]["msg"])
+	assert.Equal(t, logger.LevelDebug, log.Level())
+
+	assert.Error(t, log.SetLevel("verbose"))
+	assert.Equal(t, logger.LevelDebug, log.Level())
+
+	_, err := logger.New(logger.Options{Level: "verbose"})
+	assert.Error(t, err)
+	_, err = logger.New(logger.Options{Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestLogger_TraceFields(t *testing.T) {
+	log, read := newFileLogger(t, logger.Options{})
+
+	ctx, span := testTracer().Start(context.Background(), "request")
+	defer span.End()
+	log.Error(ctx, "debit failed", "error", "insufficient balance")
+	log.Info(logger.WithTraceID(context.Background()), "no span")
+
+	records := read()
+	require.Len(t, records, 2)
+	assert.Equal(t, span.SpanContext().TraceID().String(), records[0]["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), records[0]["span_id"])
+	assert.NotEmpty(t, records[1]["trace_id"])
+	assert.NotContains(t, records[1], "span_id")
+}
+
+func TestLogger_Sampling(t *testing.T) {
+	ctx := context.Background()
+	log, read := newFileLogger(t, logger.Options{Sampling: logger.Sampling{Initial: 2, Thereafter: 3, Tick: time.Hour}})
+
+	for i := 0; i < 8; i++ {
+		log.Info(ctx, "message consumed", "n", i)
+	}
+	log.Error(ctx, "message failed")
+	log.Error(ctx, "message failed")
+	log.Error(ctx, "message failed")
+
+	var consumed []float64
+	failed := 0
+	for _, record := range read() {
+		if record["msg"] == "message consumed" {
+			consumed = append(consumed, record["n"].(float64))
+		}
+		if record["msg"] == "message failed" {
+			failed++
+		}
+	}
+	assert.Equal(t, []float64{0, 1, 4, 7}, consumed)
+	assert.Equal(t, 3, failed, "errors are never sampled")
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/metrics"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Debit(t *testing.T) {
+	t.Run("counts a debit and its amount", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+		ctx := context.Background()
+		userID := uuid.New()
+
+		wallet, _ := entities.NewWallet(userID, "USD")
+		initialAmount, _ := valueobjects.NewMoney(big.NewInt(200), "USD")
+		wallet.Credit(initialAmount)
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+		debits := testutil.ToFloat64(metrics.Debits.WithLabelValues(metrics.OutcomeSuccess, ""))
+		amount := testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationDebit, "USD"))
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(1))
+
+		require.NoError(t, err)
+		assert.Equal(t, debits+1, testutil.ToFloat64(metrics.Debits.WithLabelValues(metrics.OutcomeSuccess, "")))
+		assert.Equal(t, amount+100, testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationDebit, "USD")))
+	})
+
+	t.Run("counts a rejected debit by reason", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+		ctx := context.Background()
+		userID := uuid.New()
+
+		wallet, _ := entities.NewWallet(userID, "USD")
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrInsufficientBalance)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+
+		rejected := testutil.ToFloat64(metrics.Debits.WithLabelValues(metrics.OutcomeFailure, "insufficient_balance"))
+		amount := testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationDebit, "USD"))
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(1))
+
+		assert.ErrorIs(t, err, entities.ErrInsufficientBalance)
+		assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.Debits.WithLabelValues(metrics.OutcomeFailure, "insufficient_balance")))
+		assert.Equal(t, amount, testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationDebit, "USD")))
+	})
+}
+
+func TestMetrics_RefundSkipped(t *testing.T) {
+	service, _, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+	ctx := context.Background()
+	txID := uuid.New().String()
+
+	amount, _ := valueobjects.NewMoney(big.NewInt(100), "USD")
+	credit := entities.NewTransaction(uuid.New(), uuid.New(), uuid.New(), amount, entities.TransactionCredit)
+
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockTransactionRepo.On("FindByID", ctx, txID).Return(credit, nil)
+
+	skipped := testutil.ToFloat64(metrics.Refunds.WithLabelValues(metrics.OutcomeSkipped, ""))
+
+	require.NoError(t, service.RefundTransaction(ctx, txID))
+	assert.Equal(t, skipped+1, testutil.ToFloat64(metrics.Refunds.WithLabelValues(metrics.OutcomeSkipped, "")))
+}
+
+func TestMetrics_PublishFailure(t *testing.T) {
+	service, _, _, _, _, mockPublisher := setupWalletServiceTest()
+	ctx := context.Background()
+	event := &events.SMSDebitFailed{UserID: uuid.New().String(), SMSID: uuid.New().String()}
+
+	mockPublisher.On("PublishEvent", ctx, event).Return(errors.New("channel closed")).Once()
+	mockPublisher.On("PublishEvent", ctx, event).Return(nil).Once()
+
+	failures := testutil.ToFloat64(metrics.PublishFailures.WithLabelValues(string(events.EventTypeSMSDebitFailed)))
+
+	assert.Error(t, service.Publish(ctx, event))
+	assert.NoError(t, service.Publish(ctx, event))
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.PublishFailures.WithLabelValues(string(events.EventTypeSMSDebitFailed))))
+}
@@ -192,3 +192,42 @@ func TestMoney_IsNegative(t *testing.T) {
 		assert.False(t, money.IsNegative())
 	})
 }
+
+func TestNewSignedMoney(t *testing.T) {
+	t.Run("should accept negative amount", func(t *testing.T) {
+		money, err := valueobjects.NewSignedMoney(big.NewInt(-100), "irr")
+
+		require.NoError(t, err)
+		assert.True(t, money.IsNegative())
+		assert.Equal(t, "IRR", money.Currency())
+		assert.Equal(t, big.NewInt(-100), money.Amount())
+	})
+
+	t.Run("should fail with nil amount", func(t *testing.T) {
+		_, err := valueobjects.NewSignedMoney(nil, "IRR")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMoney_SubtractSigned(t *testing.T) {
+	t.Run("result can go below zero", func(t *testing.T) {
+		money1, _ := valueobjects.NewMoney(big.NewInt(30), "USD")
+		money2, _ := valueobjects.NewMoney(big.NewInt(100), "USD")
+
+		result, err := money1.SubtractSigned(money2)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(-70), result.Amount())
+		assert.Equal(t, big.NewInt(70), result.Neg().Amount())
+	})
+
+	t.Run("should fail with different currencies", func(t *testing.T) {
+		money1, _ := valueobjects.NewMoney(big.NewInt(30), "USD")
+		money2, _ := valueobjects.NewMoney(big.NewInt(10), "EUR")
+
+		_, err := money1.SubtractSigned(money2)
+
+		assert.Equal(t, valueobjects.ErrCurrencyMismatch, err)
+	})
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newVolumePlan(t *testing.T) *entities.Plan {
+	plan, err := entities.NewPlan("enterprise", "Enterprise", []entities.PlanTier{
+		{UpTo: 10000, DiscountBPS: 0},
+		{UpTo: 100000, DiscountBPS: 1000},
+		{UpTo: 0, DiscountBPS: 2500},
+	})
+	require.NoError(t, err)
+	return plan
+}
+
+func TestPlan_TierFor(t *testing.T) {
+	plan := newVolumePlan(t)
+
+	assert.Equal(t, int64(0), plan.TierFor(1).DiscountBPS)
+	assert.Equal(t, int64(0), plan.TierFor(10000).DiscountBPS)
+	assert.Equal(t, int64(1000), plan.TierFor(10001).DiscountBPS)
+	assert.Equal(t, int64(1000), plan.TierFor(100000).DiscountBPS)
+	assert.Equal(t, int64(2500), plan.TierFor(100001).DiscountBPS)
+}
+
+func TestPlan_Validation(t *testing.T) {
+	t.Run("should reject tiers out of order", func(t *testing.T) {
+		_, err := entities.NewPlan("bad", "", []entities.PlanTier{{UpTo: 100}, {UpTo: 50}})
+		assert.ErrorIs(t, err, entities.ErrInvalidPlan)
+	})
+
+	t.Run("should reject unbounded tier before the last one", func(t *testing.T) {
+		_, err := entities.NewPlan("bad", "", []entities.PlanTier{{UpTo: 0}, {UpTo: 50}})
+		assert.ErrorIs(t, err, entities.ErrInvalidPlan)
+	})
+
+	t.Run("should reject discount above 100%", func(t *testing.T) {
+		_, err := entities.NewPlan("bad", "", []entities.PlanTier{{DiscountBPS: entities.MaxDiscountBPS + 1}})
+		assert.ErrorIs(t, err, entities.ErrInvalidPlan)
+	})
+
+	t.Run("should reject a 100% discount", func(t *testing.T) {
+		_, err := entities.NewPlan("bad", "", []entities.PlanTier{{DiscountBPS: entities.MaxDiscountBPS}})
+		assert.ErrorIs(t, err, entities.ErrInvalidPlan)
+	})
+}
+
+func TestPlanTier_Apply(t *testing.T) {
+	price, _ := valueobjects.NewMoney(big.NewInt(999), "IRR")
+
+	discounted, err := entities.PlanTier{DiscountBPS: 1000}.Apply(price)
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(899), discounted.Amount())
+	assert.Equal(t, "IRR", discounted.Currency())
+}
+
+func TestPlanTier_ApplyChargesAtLeastOneUnit(t *testing.T) {
+	price, _ := valueobjects.NewMoney(big.NewInt(5), "IRR")
+
+	discounted, err := entities.PlanTier{DiscountBPS: entities.MaxDiscountBPS - 1}.Apply(price)
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), discounted.Amount())
+}
+
+func TestWalletService_DebitAppliesPlanTier(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+	mockPlanRepo := &MockPlanRepo{}
+	mockPlanRepo.On("WithTx", mock.Anything).Return(mockPlanRepo)
+	service.PlanRepo = mockPlanRepo
+
+	userID := uuid.New()
+	ctx := context.Background()
+	wallet, _ := entities.NewWallet(userID, "USD")
+	credit, _ := valueobjects.NewMoney(big.NewInt(1000), "USD")
+	wallet.Credit(credit)
+	plan := newVolumePlan(t)
+
+	var recorded *entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+	mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+	mockPlanRepo.On("FindActivePlan", ctx, userID).Return(plan, nil)
+	mockTransactionRepo.On("CountSince", ctx, wallet.ID, entities.TransactionDebit, mock.AnythingOfType("time.Time")).Return(int64(10000), nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*entities.Transaction) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	// the 10001st sms of the month falls into the 10% tier
+	event, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(2))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(180), event.Amount)
+	require.NotNil(t, recorded)
+	assert.Equal(t, plan.ID, recorded.PlanID)
+	assert.Equal(t, int64(1000), recorded.DiscountBPS)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestPlanService_DeletePlan(t *testing.T) {
+	mockPlanRepo := &MockPlanRepo{}
+	mockTxManager := &MockTransactionManager{}
+	service := usecase.NewPlanService(mockPlanRepo, nil, mockTxManager, &logger.Logger{})
+
+	planID := uuid.New()
+	ctx := context.Background()
+	mockPlanRepo.On("WithTx", mock.Anything).Return(mockPlanRepo)
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockPlanRepo.On("CountActiveAssignments", ctx, planID).Return(int64(2), nil)
+
+	err := service.DeletePlan(ctx, planID)
+
+	assert.ErrorIs(t, err, entities.ErrPlanInUse)
+	mockPlanRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
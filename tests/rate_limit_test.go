@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/ratelimit"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, limit entities.RateLimit, now time.Time) (entities.RateLimitResult, error) {
+	return entities.RateLimitResult{}, errors.New("connection refused")
+}
+
+func TestTokenBucket_Take(t *testing.T) {
+	limit := entities.RateLimit{RequestsPerMinute: 60, Burst: 2}
+	start := time.Now()
+	bucket := entities.NewTokenBucket(limit, start)
+
+	first := bucket.Take(limit, start)
+	second := bucket.Take(limit, start)
+	third := bucket.Take(limit, start)
+
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, 2, third.Limit)
+	assert.Equal(t, time.Second, third.RetryAfter)
+	assert.Equal(t, 2*time.Second, third.Reset)
+
+	refilled := bucket.Take(limit, start.Add(time.Second))
+	assert.True(t, refilled.Allowed, "one token comes back every second at 60 a minute")
+	assert.False(t, bucket.Take(limit, start.Add(time.Second)).Allowed)
+	assert.True(t, bucket.Take(limit, start.Add(time.Hour)).Allowed)
+	assert.Equal(t, 1, bucket.Take(limit, start.Add(2*time.Hour)).Remaining, "the bucket never holds more than burst")
+}
+
+func TestRateLimitRule_Matches(t *testing.T) {
+	rule := entities.RateLimitRule{Method: "GET", Path: "/api/v1/wallet/user/:user_id"}
+
+	assert.True(t, rule.Matches("any", "GET", "/api/v1/wallet/user/:user_id"))
+	assert.True(t, rule.Matches("any", "get", "/api/v1/wallet/user/:id/"))
+	assert.False(t, rule.Matches("any", "POST", "/api/v1/wallet/user/:user_id"))
+	assert.False(t, rule.Matches("any", "GET", "/api/v1/wallet/user/:user_id/limits"))
+	assert.False(t, rule.Matches("any", "GET", "/api/v1/wallet/user/42"), "rules match routes, not urls")
+	assert.True(t, entities.RateLimitRule{}.Matches("any", "DELETE", "/anything"))
+
+	invoice := entities.RateLimitRule{Path: "/api/v1/invoices/:invoice_id"}
+	assert.False(t, invoice.Matches("any", "POST", "/api/v1/invoices/generate"), "a parameter does not match a fixed segment")
+}
+
+func TestMemoryStore_DropsRefilledBuckets(t *testing.T) {
+	ctx := context.Background()
+	store := ratelimit.NewMemoryStore()
+	// a token comes back every minute
+	limit := entities.RateLimit{RequestsPerMinute: 1, Burst: 10}
+	start := time.Now()
+
+	_, err := store.Take(ctx, "api-key:a", limit, start)
+	require.NoError(t, err)
+	_, err = store.Take(ctx, "api-key:b", limit, start.Add(50*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.Len())
+
+	// a has refilled by now, b still misses tokens
+	result, err := store.Take(ctx, "api-key:c", limit, start.Add(100*time.Second))
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, store.Len())
+
+	result, err = store.Take(ctx, "api-key:c", limit, start.Add(10*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 9, result.Remaining)
+	assert.Equal(t, 1, store.Len(), "only the bucket just used is kept")
+}
+
+func TestRateLimitService_Allow(t *testing.T) {
+	ctx := context.Background()
+	walletRoute := entities.RateLimitRule{Method: "GET", Path: "/api/v1/wallet/user/:user_id", Limit: entities.RateLimit{RequestsPerMinute: 60, Burst: 1}}
+	gateway := entities.RateLimitRule{Client: "signing-key:payment-gateway", Limit: entities.RateLimit{RequestsPerMinute: 6000, Burst: 1000}}
+	newService := func() *usecase.RateLimitService {
+		return usecase.NewRateLimitService(ratelimit.NewMemoryStore(), entities.RateLimit{RequestsPerMinute: 600, Burst: 100},
+			entities.RateLimit{RequestsPerMinute: 60, Burst: 2}, []entities.RateLimitRule{walletRoute, gateway}, logger.NewLogger("error"))
+	}
+
+	t.Run("a route rule limits a client hammering it", func(t *testing.T) {
+		service := newService()
+
+		first, limited := service.Allow(ctx, "api-key:app", "GET", "/api/v1/wallet/user/:user_id")
+		require.True(t, limited)
+		assert.True(t, first.Allowed)
+
+		second, _ := service.Allow(ctx, "api-key:app", "GET", "/api/v1/wallet/user/:user_id")
+		assert.False(t, second.Allowed, "the route has one bucket per client")
+
+		other, _ := service.Allow(ctx, "api-key:other", "GET", "/api/v1/wallet/user/:user_id")
+		assert.True(t, other.Allowed, "every client has its own bucket")
+
+		elsewhere, _ := service.Allow(ctx, "api-key:app", "GET", "/api/v1/tariffs")
+		assert.True(t, elsewhere.Allowed)
+		assert.Equal(t, 100, elsewhere.Limit, "other routes use the default limit")
+	})
+
+	t.Run("the most specific rule wins", func(t *testing.T) {
+		service := newService()
+		both := entities.RateLimitRule{Client: "signing-key:payment-gateway", Path: "/api/v1/wallet", Limit: entities.RateLimit{RequestsPerMinute: 10}}
+		service.Rules = append(service.Rules, both)
+
+		result, _ := service.Allow(ctx, "signing-key:payment-gateway", "POST", "/api/v1/wallet")
+		assert.Equal(t, 10, result.Limit)
+
+		result, _ = service.Allow(ctx, "signing-key:payment-gateway", "GET", "/api/v1/wallet/user/:user_id")
+		assert.Equal(t, 1, result.Limit, "method and path outweigh the client")
+	})
+
+	t.Run("no limit and a failing store let requests through", func(t *testing.T) {
+		unlimited := usecase.NewRateLimitService(ratelimit.NewMemoryStore(), entities.RateLimit{}, entities.RateLimit{}, nil, logger.NewLogger("error"))
+		_, limited := unlimited.Allow(ctx, "api-key:app", "GET", "/api/v1/tariffs")
+		assert.False(t, limited)
+
+		failing := usecase.NewRateLimitService(failingRateLimitStore{}, entities.RateLimit{RequestsPerMinute: 1}, entities.RateLimit{}, nil, logger.NewLogger("error"))
+		_, limited = failing.Allow(ctx, "api-key:app", "GET", "/api/v1/tariffs")
+		assert.False(t, limited)
+		_, limited = unlimited.AllowIP(ctx, "10.0.0.1")
+		assert.False(t, limited)
+	})
+
+	t.Run("addresses have their own bucket ahead of the caller's", func(t *testing.T) {
+		service := newService()
+
+		for i := 0; i < 2; i++ {
+			result, limited := service.AllowIP(ctx, "10.0.0.1")
+			require.True(t, limited)
+			assert.True(t, result.Allowed)
+		}
+		result, _ := service.AllowIP(ctx, "10.0.0.1")
+		assert.False(t, result.Allowed)
+
+		other, _ := service.AllowIP(ctx, "10.0.0.2")
+		assert.True(t, other.Allowed)
+		caller, _ := service.Allow(ctx, "api-key:app", "GET", "/api/v1/tariffs")
+		assert.True(t, caller.Allowed, "the address bucket is not the caller's")
+	})
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupReconcileTest() (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo, *MockPublisher) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockUserRepo := &MockUserRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockTxManager := &MockTransactionManager{}
+	mockPublisher := &MockPublisher{}
+
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockTxManager.On("WithTransaction", mock.Anything).Return(nil)
+	mockPublisher.On("PublishEvent", mock.Anything, mock.Anything).Return(nil)
+
+	service := usecase.NewWalletService(mockWalletRepo, mockUserRepo, mockTransactionRepo, nil, nil, nil, nil, nil, nil, nil, nil, mockTxManager, mockPublisher, logger.NewLogger("error"))
+	return service, mockWalletRepo, mockTransactionRepo, mockPublisher
+}
+
+func reconciledWallet(t *testing.T, mockWalletRepo *MockWalletRepo, mockTransactionRepo *MockTransactionRepo, stored, computed int64) *entities.Wallet {
+	wallet, err := entities.NewWallet(uuid.New(), "IRR")
+	require.NoError(t, err)
+	wallet.Balance = irr(stored)
+	mockWalletRepo.On("LockByID", mock.Anything, wallet.ID).Return(wallet, nil)
+	mockTransactionRepo.On("SumBalance", mock.Anything, wallet.ID, "IRR").Return(big.NewInt(computed), nil)
+	return wallet
+}
+
+func TestWalletService_ReconcileBalances(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dry run reports mismatches without touching balances", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockPublisher := setupReconcileTest()
+		ok := reconciledWallet(t, mockWalletRepo, mockTransactionRepo, 500, 500)
+		broken := reconciledWallet(t, mockWalletRepo, mockTransactionRepo, 700, 650)
+		mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{ok.ID, broken.ID}, nil)
+
+		report, err := service.ReconcileBalances(ctx, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Checked)
+		require.Len(t, report.Discrepancies, 1)
+		discrepancy := report.Discrepancies[0]
+		assert.Equal(t, broken.ID, discrepancy.WalletID)
+		assert.Equal(t, big.NewInt(50), discrepancy.Difference)
+		assert.False(t, discrepancy.Repaired)
+		mockWalletRepo.AssertNotCalled(t, "UpdateBalance", mock.Anything, mock.Anything)
+		mockPublisher.AssertCalled(t, "PublishEvent", ctx, mock.MatchedBy(func(e *events.BalanceDiscrepancyDetected) bool {
+			return e.WalletID == broken.ID.String() && e.Difference == "50" && !e.Repaired
+		}))
+	})
+
+	t.Run("repair sets the balance to the transaction sum", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, _ := setupReconcileTest()
+		broken := reconciledWallet(t, mockWalletRepo, mockTransactionRepo, 100, -20)
+		mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{broken.ID}, nil)
+		mockWalletRepo.On("UpdateBalance", ctx, broken).Return(nil)
+
+		report, err := service.ReconcileBalances(ctx, true)
+
+		require.NoError(t, err)
+		require.Len(t, report.Discrepancies, 1)
+		assert.True(t, report.Discrepancies[0].Repaired)
+		assert.Equal(t, big.NewInt(-20), broken.Balance.Amount())
+		mockWalletRepo.AssertCalled(t, "UpdateBalance", ctx, broken)
+	})
+
+	t.Run("a failing wallet is counted and skipped", func(t *testing.T) {
+		service, mockWalletRepo, _, _ := setupReconcileTest()
+		missing := uuid.New()
+		mockWalletRepo.On("LockByID", mock.Anything, missing).Return(nil, errors.New("db down"))
+		mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{missing}, nil)
+
+		report, err := service.ReconcileBalances(ctx, false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, report.Checked)
+		assert.Equal(t, 1, report.Failed)
+		assert.Empty(t, report.Discrepancies)
+	})
+}
+
+func TestWalletService_ReconcileParentFundedChild(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, mockPublisher := setupWalletServiceTest()
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 1000)
+	child := childOf(t, parent, 30, nil)
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, child.UserID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, parent.ID).Return(parent, nil)
+	mockWalletRepo.On("LockByID", ctx, child.ID).Return(child, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	mockPublisher.On("PublishEvent", ctx, mock.Anything).Return(nil)
+
+	_, err := service.DebitUserbalance(ctx, child.UserID, uuid.New(), testSMS(1))
+	require.NoError(t, err)
+
+	mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{child.ID, parent.ID}, nil)
+	mockTransactionRepo.On("SumBalance", ctx, child.ID, "IRR").Return(ledgerSum(30, child.ID, recorded), nil)
+	mockTransactionRepo.On("SumBalance", ctx, parent.ID, "IRR").Return(ledgerSum(1000, parent.ID, recorded), nil)
+
+	report, err := service.ReconcileBalances(ctx, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	assert.Empty(t, report.Discrepancies, "the parent's share is not a discrepancy of the child")
+	assert.True(t, child.Balance.IsZero())
+	assert.Equal(t, big.NewInt(930), parent.Balance.Amount())
+}
+
+func TestWalletService_ReconcileTaxedParentFundedChild(t *testing.T) {
+	service, mockWalletRepo, mockUserRepo, mockTransactionRepo, mockTxManager, mockPublisher := setupWalletServiceTest()
+	service.TaxRuleRepo = taxRepoWith(entities.TaxOnDebit, vatRule(t, "", entities.TaxOnDebit, 1000, false))
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 1000)
+	child := childOf(t, parent, 30, nil)
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, child.UserID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, parent.ID).Return(parent, nil)
+	mockWalletRepo.On("LockByID", ctx, child.ID).Return(child, nil)
+	mockUserRepo.On("GetByID", ctx, child.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	mockPublisher.On("PublishEvent", ctx, mock.Anything).Return(nil)
+
+	_, err := service.DebitUserbalance(ctx, child.UserID, uuid.New(), testSMS(1))
+	require.NoError(t, err)
+
+	mockWalletRepo.On("ListIDs", ctx, uuid.Nil, entities.ReconcileBatchSize).Return([]uuid.UUID{child.ID, parent.ID}, nil)
+	mockTransactionRepo.On("SumBalance", ctx, child.ID, "IRR").Return(ledgerSum(30, child.ID, recorded), nil)
+	mockTransactionRepo.On("SumBalance", ctx, parent.ID, "IRR").Return(ledgerSum(1000, parent.ID, recorded), nil)
+
+	report, err := service.ReconcileBalances(ctx, true)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Checked)
+	assert.Empty(t, report.Discrepancies, "the tax on the parent's share is not a discrepancy of the child")
+	assert.True(t, child.Balance.IsZero())
+	assert.Equal(t, "920", parent.Balance.Amount().String())
+}
@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/infra/auth"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"finance/pkg/signing"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type MockSigningKeyRepo struct {
+	mock.Mock
+}
+
+func (m *MockSigningKeyRepo) Save(ctx context.Context, key *entities.SigningKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSigningKeyRepo) FindByID(ctx context.Context, id uuid.UUID) (*entities.SigningKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.SigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepo) ListByClient(ctx context.Context, client string) ([]*entities.SigningKey, error) {
+	args := m.Called(ctx, client)
+	return args.Get(0).([]*entities.SigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepo) Update(ctx context.Context, key *entities.SigningKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockSigningKeyRepo) WithTx(tx *gorm.DB) entities.SigningKeyRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.SigningKeyRepo)
+}
+
+func setupSigningTest(t *testing.T) (*usecase.SigningService, *MockSigningKeyRepo, *entities.SigningKey) {
+	mockRepo := &MockSigningKeyRepo{}
+	mockTxManager := &MockTransactionManager{}
+	mockRepo.On("WithTx", mock.Anything).Return(mockRepo)
+	mockTxManager.On("WithTransaction", mock.Anything).Return(nil)
+
+	key, err := entities.NewSigningKey("sms-dispatcher", entities.RoleService)
+	require.NoError(t, err)
+	mockRepo.On("FindByID", mock.Anything, key.ID).Return(key, nil)
+
+	service := usecase.NewSigningService(mockRepo, auth.NewMemoryNonceCache(), mockTxManager, logger.NewLogger("error"))
+	return service, mockRepo, key
+}
+
+func signedRequest(r *http.Request) entities.SignedRequest {
+	var body []byte
+	if r.Body != nil {
+		body, _ = io.ReadAll(r.Body)
+	}
+	return entities.SignedRequest{
+		KeyID:     r.Header.Get(signing.HeaderKeyID),
+		Timestamp: r.Header.Get(signing.HeaderTimestamp),
+		Nonce:     r.Header.Get(signing.HeaderNonce),
+		Digest:    r.Header.Get(signing.HeaderDigest),
+		Signature: r.Header.Get(signing.HeaderSignature),
+		Method:    r.Method,
+		URI:       r.URL.RequestURI(),
+		Body:      body,
+	}
+}
+
+func newSignedRequest(t *testing.T, key *entities.SigningKey, body string, at time.Time) entities.SignedRequest {
+	req, err := http.NewRequest(http.MethodPost, "http://finance/api/v1/wallet?source=sms", strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, signing.Sign(req, key.ID.String(), []byte(key.Secret), at))
+	return signedRequest(req)
+}
+
+func TestSigningService_Verify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("the client transport signs requests the service accepts", func(t *testing.T) {
+		service, _, key := setupSigningTest(t)
+		var principal entities.Principal
+		var verifyErr error
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, verifyErr = service.Verify(r.Context(), signedRequest(r))
+		}))
+		defer server.Close()
+
+		client := signing.NewClient(key.ID.String(), []byte(key.Secret))
+		res, err := client.Post(server.URL+"/api/v1/wallet", "application/json", strings.NewReader(`{"amount":100}`))
+		require.NoError(t, err)
+		res.Body.Close()
+
+		require.NoError(t, verifyErr)
+		assert.Equal(t, entities.RoleService, principal.Role)
+		assert.Equal(t, "signing-key:sms-dispatcher", principal.ID)
+	})
+
+	t.Run("a replayed request is rejected", func(t *testing.T) {
+		service, _, key := setupSigningTest(t)
+		req := newSignedRequest(t, key, `{"amount":100}`, time.Now())
+
+		_, err := service.Verify(ctx, req)
+		require.NoError(t, err)
+
+		_, err = service.Verify(ctx, req)
+		assert.ErrorIs(t, err, entities.ErrReplayedRequest)
+	})
+
+	t.Run("a request outside the window is rejected", func(t *testing.T) {
+		service, _, key := setupSigningTest(t)
+		req := newSignedRequest(t, key, `{}`, time.Now().Add(-entities.SignatureWindow-time.Minute))
+
+		_, err := service.Verify(ctx, req)
+
+		assert.ErrorIs(t, err, entities.ErrSignatureExpired)
+	})
+
+	t.Run("changed body, uri or signature is rejected", func(t *testing.T) {
+		service, _, key := setupSigningTest(t)
+
+		body := newSignedRequest(t, key, `{"amount":100}`, time.Now())
+		body.Body = []byte(`{"amount":100000}`)
+		uri := newSignedRequest(t, key, `{"amount":100}`, time.Now())
+		uri.URI = "/api/v1/wallet/redeem"
+		wrongSecret := newSignedRequest(t, &entities.SigningKey{ID: key.ID, Secret: "guessed"}, `{}`, time.Now())
+		badTimestamp := newSignedRequest(t, key, `{}`, time.Now())
+		badTimestamp.Timestamp = "yesterday"
+
+		for name, req := range map[string]entities.SignedRequest{
+			"body": body, "uri": uri, "secret": wrongSecret, "timestamp": badTimestamp,
+		} {
+			_, err := service.Verify(ctx, req)
+			assert.ErrorIs(t, err, entities.ErrInvalidSignature, name)
+		}
+	})
+
+	t.Run("unknown, revoked and expired keys are rejected", func(t *testing.T) {
+		service, mockRepo, key := setupSigningTest(t)
+		unknown := &entities.SigningKey{ID: uuid.New(), Secret: key.Secret}
+		mockRepo.On("FindByID", mock.Anything, unknown.ID).Return(nil, entities.ErrSigningKeyNotFound)
+
+		_, err := service.Verify(ctx, newSignedRequest(t, unknown, `{}`, time.Now()))
+		assert.ErrorIs(t, err, entities.ErrInvalidSignature)
+
+		key.ExpireAt(time.Now().Add(-time.Second))
+		_, err = service.Verify(ctx, newSignedRequest(t, key, `{}`, time.Now()))
+		assert.ErrorIs(t, err, entities.ErrInvalidSignature)
+
+		key.ExpiresAt = nil
+		key.Revoke()
+		_, err = service.Verify(ctx, newSignedRequest(t, key, `{}`, time.Now()))
+		assert.ErrorIs(t, err, entities.ErrInvalidSignature)
+	})
+}
+
+func TestSigningService_RotateClientKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("old keys keep working for the grace period", func(t *testing.T) {
+		service, mockRepo, old := setupSigningTest(t)
+		mockRepo.On("ListByClient", ctx, "sms-dispatcher").Return([]*entities.SigningKey{old}, nil)
+		mockRepo.On("Update", ctx, old).Return(nil)
+		mockRepo.On("Save", ctx, mock.AnythingOfType("*entities.SigningKey")).Return(nil)
+
+		created, err := service.RotateClientKey(ctx, "sms-dispatcher", time.Hour)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, old.Secret, created.Secret)
+		assert.Equal(t, entities.RoleService, created.Role)
+		require.NotNil(t, old.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *old.ExpiresAt, time.Minute)
+		assert.True(t, old.Usable(time.Now()))
+		assert.False(t, old.Usable(time.Now().Add(2*time.Hour)))
+
+		_, err = service.Verify(ctx, newSignedRequest(t, old, `{}`, time.Now()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("a client without usable keys can not rotate", func(t *testing.T) {
+		service, mockRepo, _ := setupSigningTest(t)
+		mockRepo.On("ListByClient", ctx, "unknown").Return([]*entities.SigningKey{}, nil)
+
+		_, err := service.RotateClientKey(ctx, "unknown", time.Hour)
+
+		assert.ErrorIs(t, err, entities.ErrSigningKeyNotFound)
+	})
+}
+
+func TestMemoryNonceCache(t *testing.T) {
+	cache := auth.NewMemoryNonceCache()
+	ctx := context.Background()
+	remember := func(key string, until time.Time) bool {
+		fresh, err := cache.Remember(ctx, key, until)
+		require.NoError(t, err)
+		return fresh
+	}
+
+	assert.True(t, remember("k:1", time.Now().Add(time.Minute)))
+	assert.False(t, remember("k:1", time.Now().Add(time.Minute)))
+	assert.True(t, remember("k:2", time.Now().Add(-time.Second)))
+	assert.True(t, remember("k:2", time.Now().Add(time.Minute)), "an expired nonce can be used again")
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendingLimits_Check(t *testing.T) {
+	amount, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+
+	t.Run("should pass when no limit is set", func(t *testing.T) {
+		limits := entities.SpendingLimits{}
+
+		assert.True(t, limits.IsZero())
+		assert.NoError(t, limits.Check(amount, entities.SpendingUsage{}))
+	})
+
+	t.Run("should reject debit above max single debit", func(t *testing.T) {
+		limits := entities.SpendingLimits{MaxDebit: big.NewInt(99)}
+
+		err := limits.Check(amount, entities.SpendingUsage{})
+
+		var limitErr *entities.LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+		assert.ErrorIs(t, err, entities.ErrLimitExceeded)
+		assert.Equal(t, entities.LimitMaxDebit, limitErr.Kind)
+		assert.Contains(t, err.Error(), "max_debit")
+	})
+
+	t.Run("should allow debit reaching daily cap exactly", func(t *testing.T) {
+		limits := entities.SpendingLimits{DailyAmount: big.NewInt(1000)}
+
+		err := limits.Check(amount, entities.SpendingUsage{DailyAmount: big.NewInt(900)})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject debit over monthly cap", func(t *testing.T) {
+		limits := entities.SpendingLimits{MonthlyAmount: big.NewInt(1000)}
+
+		err := limits.Check(amount, entities.SpendingUsage{MonthlyAmount: big.NewInt(901)})
+
+		var limitErr *entities.LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, entities.LimitMonthlyAmount, limitErr.Kind)
+		assert.Equal(t, big.NewInt(1001), limitErr.Attempted)
+	})
+
+	t.Run("should reject debit over velocity limit", func(t *testing.T) {
+		limits := entities.SpendingLimits{DebitsPerMinute: 3}
+
+		err := limits.Check(amount, entities.SpendingUsage{DebitsLastMinute: 3})
+
+		var limitErr *entities.LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, entities.LimitDebitsPerMinute, limitErr.Kind)
+	})
+}
+
+func TestSpendingLimits_Validate(t *testing.T) {
+	assert.NoError(t, entities.SpendingLimits{MaxDebit: big.NewInt(1)}.Validate())
+	assert.NoError(t, entities.SpendingLimits{}.Validate())
+	assert.ErrorIs(t, entities.SpendingLimits{DailyAmount: big.NewInt(-1)}.Validate(), entities.ErrInvalidLimit)
+	assert.ErrorIs(t, entities.SpendingLimits{MaxDebit: big.NewInt(0)}.Validate(), entities.ErrInvalidLimit)
+	assert.ErrorIs(t, entities.SpendingLimits{DebitsPerMinute: -1}.Validate(), entities.ErrInvalidLimit)
+}
+
+func TestLimitWindows(t *testing.T) {
+	now := time.Date(2026, 3, 31, 23, 59, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), entities.StartOfDay(now))
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), entities.StartOfMonth(now))
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTariff(t *testing.T) *entities.Tariff {
+	tariff, err := entities.NewTariff("2025", "irr", []entities.TariffRate{
+		{Prefix: "98", MessageType: entities.MessageNormal, PricePerSegment: big.NewInt(100)},
+		{Prefix: "98912", Operator: "MCI", MessageType: entities.MessageNormal, PricePerSegment: big.NewInt(80)},
+		{Prefix: "98912", MessageType: entities.MessageNormal, Plan: "enterprise", PricePerSegment: big.NewInt(60)},
+		{Prefix: "98", MessageType: entities.MessageUnicode, PricePerSegment: big.NewInt(150)},
+		{Prefix: "98", MessageType: entities.MessageFlash, PricePerSegment: big.NewInt(200)},
+	})
+	require.NoError(t, err)
+	return tariff
+}
+
+func TestTariff_Price(t *testing.T) {
+	tariff := newTestTariff(t)
+
+	t.Run("longest prefix wins", func(t *testing.T) {
+		price, err := tariff.Price(entities.SMSMetadata{Receiver: "+989121234567", Segments: 1, Operator: "MCI"}, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(80), price.Amount())
+		assert.Equal(t, "IRR", price.Currency())
+	})
+
+	t.Run("operator rate only matches its operator", func(t *testing.T) {
+		price, err := tariff.Price(entities.SMSMetadata{Receiver: "+989121234567", Segments: 1, Operator: "Irancell"}, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), price.Amount())
+	})
+
+	t.Run("operator rate wins over a longer prefix for any operator", func(t *testing.T) {
+		ported, err := entities.NewTariff("ported", "irr", []entities.TariffRate{
+			{Prefix: "98912", MessageType: entities.MessageNormal, PricePerSegment: big.NewInt(80)},
+			{Prefix: "98", Operator: "Irancell", MessageType: entities.MessageNormal, PricePerSegment: big.NewInt(90)},
+		})
+		require.NoError(t, err)
+
+		price, err := ported.Price(entities.SMSMetadata{Receiver: "989121234567", Segments: 1, Operator: "irancell"}, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(90), price.Amount())
+	})
+
+	t.Run("price is multiplied by segments", func(t *testing.T) {
+		price, err := tariff.Price(entities.SMSMetadata{Receiver: "00989351234567", Segments: 3}, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(300), price.Amount())
+	})
+
+	t.Run("plan rate wins over generic rate of the same prefix", func(t *testing.T) {
+		price, err := tariff.Price(entities.SMSMetadata{Receiver: "989121234567", Segments: 2}, "enterprise")
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(120), price.Amount())
+	})
+
+	t.Run("ucs2 encoding is priced as unicode", func(t *testing.T) {
+		price, err := tariff.Price(entities.SMSMetadata{Receiver: "989121234567", Segments: 1, Encoding: entities.EncodingUCS2}, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(150), price.Amount())
+	})
+
+	t.Run("explicit message type wins over encoding", func(t *testing.T) {
+		sms := entities.SMSMetadata{Receiver: "989121234567", Segments: 1, Encoding: entities.EncodingGSM7, MessageType: entities.MessageFlash}
+		price, err := tariff.Price(sms, entities.DefaultPlan)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(200), price.Amount())
+	})
+
+	t.Run("should fail when no rate matches the destination", func(t *testing.T) {
+		_, err := tariff.Price(entities.SMSMetadata{Receiver: "+14155550100", Segments: 1}, entities.DefaultPlan)
+
+		assert.ErrorIs(t, err, entities.ErrNoMatchingRate)
+	})
+
+	t.Run("should reject sms without segments", func(t *testing.T) {
+		_, err := tariff.Price(entities.SMSMetadata{Receiver: "989121234567"}, entities.DefaultPlan)
+
+		assert.ErrorIs(t, err, entities.ErrInvalidSMSMetadata)
+	})
+}
+
+func TestNewTariff(t *testing.T) {
+	t.Run("should reject rate without price", func(t *testing.T) {
+		_, err := entities.NewTariff("broken", "IRR", []entities.TariffRate{
+			{Prefix: "98", MessageType: entities.MessageNormal},
+		})
+
+		assert.ErrorIs(t, err, entities.ErrInvalidTariff)
+	})
+
+	t.Run("should reject unknown message type", func(t *testing.T) {
+		_, err := entities.NewTariff("broken", "IRR", []entities.TariffRate{
+			{Prefix: "98", MessageType: "binary", PricePerSegment: big.NewInt(10)},
+		})
+
+		assert.ErrorIs(t, err, entities.ErrInvalidTariff)
+	})
+}
+
+func TestWalletService_DebitUsesActiveTariff(t *testing.T) {
+	t.Run("debit records tariff version and sms details", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+		mockTariffRepo := &MockTariffRepo{}
+		mockTariffRepo.On("WithTx", mock.Anything).Return(mockTariffRepo)
+		service.TariffRepo = mockTariffRepo
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+		wallet.Credit(credit)
+
+		tariff := newTestTariff(t)
+		tariff.Version = 7
+
+		var recorded *entities.Transaction
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTariffRepo.On("FindActive", ctx, "IRR").Return(tariff, nil)
+		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+			Run(func(args mock.Arguments) { recorded = args.Get(1).(*entities.Transaction) }).
+			Return(nil)
+		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+		sms := entities.SMSMetadata{Receiver: "+989121234567", Segments: 2, Encoding: entities.EncodingUCS2}
+		event, err := service.DebitUserbalance(ctx, userID, uuid.New(), sms)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(300), event.Amount)
+		assert.Equal(t, int64(7), event.TariffVersion)
+		require.NotNil(t, recorded)
+		assert.Equal(t, int64(7), recorded.TariffVersion)
+		assert.Equal(t, entities.MessageUnicode, recorded.MessageType)
+		assert.Equal(t, int64(2), recorded.Segments)
+		assert.Equal(t, big.NewInt(700), wallet.Balance.Amount())
+		mockTariffRepo.AssertExpectations(t)
+	})
+
+	t.Run("should fail without an active tariff", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+		mockTariffRepo := &MockTariffRepo{}
+		mockTariffRepo.On("WithTx", mock.Anything).Return(mockTariffRepo)
+		service.TariffRepo = mockTariffRepo
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTariffRepo.On("FindActive", ctx, "IRR").Return(nil, entities.ErrTariffNotFound)
+
+		event, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(1))
+
+		assert.Nil(t, event)
+		assert.ErrorIs(t, err, entities.ErrTariffNotFound)
+		mockTransactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func vatRule(t *testing.T, customerType entities.CustomerType, base entities.TaxBase, bps int64, inclusive bool) *entities.TaxRule {
+	rule, err := entities.NewTaxRule("vat", customerType, base, bps, inclusive, time.Now().Add(-time.Hour), nil)
+	require.NoError(t, err)
+	return rule
+}
+
+func taxRepoWith(base entities.TaxBase, rules ...*entities.TaxRule) *MockTaxRuleRepo {
+	m := &MockTaxRuleRepo{}
+	m.On("WithTx", mock.Anything).Return(m).Maybe()
+	m.On("FindValid", mock.Anything, base, mock.Anything).Return(rules, nil).Maybe()
+	m.On("FindValid", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.TaxRule{}, nil).Maybe()
+	return m
+}
+
+func TestTaxRule_Apply(t *testing.T) {
+	t.Run("exclusive rule adds the tax on top", func(t *testing.T) {
+		tax, err := vatRule(t, "", entities.TaxOnDebit, 1000, false).Apply(irr(105))
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(105), tax.Net.Amount())
+		assert.Equal(t, big.NewInt(11), tax.Tax.Amount(), "10.5 is rounded half up")
+		assert.Equal(t, big.NewInt(116), tax.Gross.Amount())
+	})
+
+	t.Run("inclusive rule takes the tax out", func(t *testing.T) {
+		tax, err := vatRule(t, "", entities.TaxOnCredit, 1000, true).Apply(irr(1100))
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1000), tax.Net.Amount())
+		assert.Equal(t, big.NewInt(100), tax.Tax.Amount())
+		assert.Equal(t, big.NewInt(1100), tax.Gross.Amount())
+	})
+
+	t.Run("invalid rules are rejected", func(t *testing.T) {
+		_, err := entities.NewTaxRule("vat", "", entities.TaxOnDebit, 10001, false, time.Time{}, nil)
+		assert.ErrorIs(t, err, entities.ErrInvalidTaxRule)
+		_, err = entities.NewTaxRule("vat", "", "sms", 900, false, time.Time{}, nil)
+		assert.ErrorIs(t, err, entities.ErrInvalidTaxRule)
+		_, err = entities.NewTaxRule("vat", "government", entities.TaxOnDebit, 900, false, time.Time{}, nil)
+		assert.ErrorIs(t, err, entities.ErrInvalidCustomerType)
+	})
+}
+
+func TestSelectTaxRule(t *testing.T) {
+	general := vatRule(t, "", entities.TaxOnDebit, 900, false)
+	business := vatRule(t, entities.CustomerBusiness, entities.TaxOnDebit, 1000, false)
+	newer, _ := entities.NewTaxRule("vat 2", "", entities.TaxOnDebit, 1000, false, time.Now(), nil)
+
+	assert.Equal(t, business, entities.SelectTaxRule([]*entities.TaxRule{general, business}, entities.CustomerBusiness))
+	assert.Equal(t, general, entities.SelectTaxRule([]*entities.TaxRule{general, business}, entities.CustomerIndividual))
+	assert.Equal(t, newer, entities.SelectTaxRule([]*entities.TaxRule{general, newer}, entities.CustomerIndividual))
+	assert.Nil(t, entities.SelectTaxRule([]*entities.TaxRule{business}, entities.CustomerIndividual))
+}
+
+func TestWalletService_DebitAddsTax(t *testing.T) {
+	service, mockWalletRepo, mockUserRepo, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+	rule := vatRule(t, entities.CustomerBusiness, entities.TaxOnDebit, 1000, false)
+	service.TaxRuleRepo = taxRepoWith(entities.TaxOnDebit, rule)
+
+	ctx := context.Background()
+	wallet := newBucketWallet(t, 500)
+	var recorded *entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, wallet.UserID).Return(wallet, nil)
+	mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+	mockUserRepo.On("GetByID", ctx, wallet.UserID).Return(&entities.User{ID: wallet.UserID, CustomerType: entities.CustomerBusiness}, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = args.Get(1).(*entities.Transaction) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	event, err := service.DebitUserbalance(ctx, wallet.UserID, uuid.New(), testSMS(1))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(110), event.Amount)
+	assert.Equal(t, big.NewInt(390), wallet.Balance.Amount())
+	require.NotNil(t, recorded)
+	assert.Equal(t, big.NewInt(100), recorded.NetAmount.Amount())
+	assert.Equal(t, big.NewInt(10), recorded.TaxAmount.Amount())
+	assert.Equal(t, big.NewInt(110), recorded.GrossAmount.Amount())
+	assert.Equal(t, rule.ID, recorded.TaxRuleID)
+}
+
+func TestWalletService_CreditTakesOutTax(t *testing.T) {
+	service, mockWalletRepo, mockTransactionRepo, _ := setupFXTest()
+	mockUserRepo := &MockUserRepo{}
+	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
+	service.UserRepo = mockUserRepo
+	service.TaxRuleRepo = taxRepoWith(entities.TaxOnCredit, vatRule(t, "", entities.TaxOnCredit, 900, true))
+
+	ctx := context.Background()
+	wallet := newBucketWallet(t, 0)
+	mockWalletRepo.On("LockByUserID", ctx, wallet.UserID).Return(wallet, nil)
+	mockUserRepo.On("GetByID", ctx, wallet.UserID).Return(nil, gorm.ErrRecordNotFound)
+
+	transaction, err := service.CreditUserBalanceIn(ctx, wallet.UserID, big.NewInt(1090), "")
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000), wallet.Balance.Amount())
+	assert.Equal(t, big.NewInt(1000), transaction.Amount.Amount())
+	assert.Equal(t, big.NewInt(90), transaction.TaxAmount.Amount())
+	assert.Equal(t, big.NewInt(1090), transaction.GrossAmount.Amount())
+	mockTransactionRepo.AssertCalled(t, "Create", ctx, transaction)
+}
+
+func TestWalletService_RefundGivesBackTax(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+
+	ctx := context.Background()
+	wallet := newBucketWallet(t, 0)
+	originalTx := entities.NewTransaction(wallet.ID, wallet.UserID, uuid.New(), irr(100), entities.TransactionDebit)
+	tax, err := vatRule(t, "", entities.TaxOnDebit, 1000, false).Apply(irr(100))
+	require.NoError(t, err)
+	originalTx.SetTax(tax)
+	originalTx.Funding = []entities.Funding{{Kind: entities.BucketPaid, Amount: irr(110)}}
+	require.NoError(t, originalTx.MarkCompleted())
+
+	var refund *entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockTransactionRepo.On("FindByID", ctx, originalTx.ID.String()).Return(originalTx, nil)
+	mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { refund = args.Get(1).(*entities.Transaction) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	require.NoError(t, service.RefundTransaction(ctx, originalTx.ID.String()))
+
+	require.NotNil(t, refund)
+	assert.Equal(t, big.NewInt(110), wallet.Balance.Amount())
+	assert.Equal(t, big.NewInt(10), refund.TaxAmount.Amount())
+	assert.Equal(t, big.NewInt(100), refund.NetAmount.Amount())
+	assert.Equal(t, originalTx.TaxRuleID, refund.TaxRuleID)
+}
+
+func TestTaxService_Report(t *testing.T) {
+	mockTransactionRepo := &MockTransactionRepo{}
+	service := usecase.NewTaxService(&MockTaxRuleRepo{}, mockTransactionRepo, &MockUserRepo{}, &MockTransactionManager{}, &logger.Logger{})
+
+	ctx := context.Background()
+	from := entities.StartOfMonth(time.Now())
+	to := from.AddDate(0, 1, 0)
+	mockTransactionRepo.On("SumTax", ctx, from, to).Return([]entities.TaxTotal{
+		{Type: entities.TransactionDebit, Currency: "IRR", Count: 3, Net: big.NewInt(300), Tax: big.NewInt(30), Gross: big.NewInt(330)},
+		{Type: entities.TransactionCredit, Category: entities.CategoryRefund, Currency: "IRR", Count: 1, Net: big.NewInt(100), Tax: big.NewInt(10), Gross: big.NewInt(110)},
+		{Type: entities.TransactionCredit, Currency: "USD", Count: 1, Net: big.NewInt(100), Tax: big.NewInt(9), Gross: big.NewInt(109)},
+	}, nil)
+
+	report, err := service.Report(ctx, from, to)
+
+	require.NoError(t, err)
+	assert.Len(t, report.Totals, 3)
+	assert.Equal(t, big.NewInt(20), report.Due["IRR"])
+	assert.Equal(t, big.NewInt(9), report.Due["USD"])
+
+	_, err = service.Report(ctx, to, from)
+	assert.ErrorIs(t, err, entities.ErrInvalidReportPeriod)
+}
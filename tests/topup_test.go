@@ -0,0 +1,341 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"finance/config"
+	"finance/internal/api/handlers/messaging"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/events"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/infra/metrics"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"finance/pkg/rabbit"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopUpRule(t *testing.T) {
+	threshold, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+	amount, _ := valueobjects.NewMoney(big.NewInt(5000), "IRR")
+
+	t.Run("successful rule creation", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+
+		rule, err := entities.NewTopUpRule(wallet, threshold, amount, 3, "card-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, wallet.ID, rule.WalletID)
+		assert.Equal(t, wallet.UserID, rule.UserID)
+		assert.True(t, rule.Enabled)
+	})
+
+	t.Run("should reject rule without funding source or daily cap", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+
+		_, err := entities.NewTopUpRule(wallet, threshold, amount, 3, "")
+		assert.Equal(t, entities.ErrInvalidTopUpRule, err)
+
+		_, err = entities.NewTopUpRule(wallet, threshold, amount, 0, "card-1")
+		assert.Equal(t, entities.ErrInvalidTopUpRule, err)
+	})
+
+	t.Run("should reject zero top-up amount", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		zero, _ := valueobjects.NewMoney(big.NewInt(0), "IRR")
+
+		_, err := entities.NewTopUpRule(wallet, threshold, zero, 3, "card-1")
+
+		assert.Equal(t, entities.ErrInvalidAmount, err)
+	})
+
+	t.Run("fires only below the threshold while enabled", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 3, "card-1")
+		low, _ := valueobjects.NewMoney(big.NewInt(999), "IRR")
+
+		assert.True(t, rule.ShouldFire(low))
+		assert.False(t, rule.ShouldFire(threshold))
+
+		rule.Enabled = false
+		assert.False(t, rule.ShouldFire(low))
+	})
+
+	t.Run("request succeeds only once", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 3, "card-1")
+		request := entities.NewTopUpRequest(rule)
+
+		require.NoError(t, request.MarkSucceeded())
+		assert.Equal(t, entities.ErrInvalidTransactionState, request.MarkSucceeded())
+	})
+}
+
+func setupTopUpTest() (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo, *MockTopUpRepo, *MockPublisher) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockUserRepo := &MockUserRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+	mockTopUpRepo := &MockTopUpRepo{}
+	mockTxManager := &MockTransactionManager{}
+	mockPublisher := &MockPublisher{}
+
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockTopUpRepo.On("WithTx", mock.Anything).Return(mockTopUpRepo)
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockThresholdRepo.On("FindByWalletID", mock.Anything, mock.Anything).Return([]*entities.BalanceThreshold{}, nil)
+	mockTransactionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockWalletRepo.On("UpdateBalance", mock.Anything, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	service := usecase.NewWalletService(
+		mockWalletRepo,
+		mockUserRepo,
+		mockTransactionRepo,
+		mockThresholdRepo,
+		mockTopUpRepo,
+		flatTariffRepo(),
+		noPlanRepo(),
+		&MockVoucherRepo{},
+		&MockTransferRepo{},
+		&MockFXRateRepo{},
+		noTaxRepo(),
+		mockTxManager,
+		mockPublisher,
+		&logger.Logger{},
+	)
+	return service, mockWalletRepo, mockTransactionRepo, mockTopUpRepo, mockPublisher
+}
+
+func TestWalletService_TopUpRules(t *testing.T) {
+	threshold, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+	amount, _ := valueobjects.NewMoney(big.NewInt(5000), "IRR")
+
+	t.Run("debit below the threshold requests a top-up", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTopUpRepo, mockPublisher := setupTopUpTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1200), "IRR")
+		wallet.Credit(credit)
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTopUpRepo.On("FindRulesByWalletID", ctx, wallet.ID).Return([]*entities.TopUpRule{rule}, nil)
+		mockTopUpRepo.On("LockRule", ctx, rule.ID).Return(rule, nil)
+		mockTopUpRepo.On("HasPendingRequestSince", ctx, rule.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
+		mockTopUpRepo.On("CountRequestsSince", ctx, rule.ID, mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+		mockTopUpRepo.On("CreateRequest", ctx, mock.AnythingOfType("*entities.TopUpRequest")).Return(nil)
+		mockPublisher.On("PublishEvent", ctx, mock.AnythingOfType("*events.TopUpRequested")).Return(nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(3))
+
+		require.NoError(t, err)
+		mockTopUpRepo.AssertExpectations(t)
+		mockPublisher.AssertExpectations(t)
+
+		requested := mockPublisher.Calls[0].Arguments.Get(1).(*events.TopUpRequested)
+		assert.Equal(t, rule.ID.String(), requested.RuleID)
+		assert.Equal(t, int64(5000), requested.Amount)
+		assert.Equal(t, "card-1", requested.FundingSourceID)
+	})
+
+	t.Run("rule does not fire past its daily cap", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTopUpRepo, mockPublisher := setupTopUpTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1200), "IRR")
+		wallet.Credit(credit)
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTopUpRepo.On("FindRulesByWalletID", ctx, wallet.ID).Return([]*entities.TopUpRule{rule}, nil)
+		mockTopUpRepo.On("LockRule", ctx, rule.ID).Return(rule, nil)
+		mockTopUpRepo.On("HasPendingRequestSince", ctx, rule.ID, mock.AnythingOfType("time.Time")).Return(false, nil)
+		mockTopUpRepo.On("CountRequestsSince", ctx, rule.ID, mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(3))
+
+		require.NoError(t, err)
+		mockTopUpRepo.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+		mockPublisher.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rule waits while a previous request is pending", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTopUpRepo, mockPublisher := setupTopUpTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		credit, _ := valueobjects.NewMoney(big.NewInt(1200), "IRR")
+		wallet.Credit(credit)
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTopUpRepo.On("FindRulesByWalletID", ctx, wallet.ID).Return([]*entities.TopUpRule{rule}, nil)
+		mockTopUpRepo.On("LockRule", ctx, rule.ID).Return(rule, nil)
+		mockTopUpRepo.On("HasPendingRequestSince", ctx, rule.ID, mock.AnythingOfType("time.Time")).Return(true, nil)
+
+		_, err := service.DebitUserbalance(ctx, userID, uuid.New(), testSMS(3))
+
+		require.NoError(t, err)
+		mockTopUpRepo.AssertNotCalled(t, "CreateRequest", mock.Anything, mock.Anything)
+		mockPublisher.AssertNotCalled(t, "PublishEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("succeeded top-up credits the wallet", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockTopUpRepo, _ := setupTopUpTest()
+
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+		request := entities.NewTopUpRequest(rule)
+
+		mockTopUpRepo.On("LockRequest", ctx, request.ID).Return(request, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTopUpRepo.On("UpdateRequestStatus", ctx, request).Return(nil)
+
+		credits := testutil.ToFloat64(metrics.Credits.WithLabelValues(metrics.OutcomeSuccess, ""))
+		credited := testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationCredit, "IRR"))
+
+		err := service.CompleteTopUp(ctx, request.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(5000), wallet.Balance.Amount())
+		assert.Equal(t, entities.TopUpSucceeded, request.Status)
+		assert.Equal(t, credits+1, testutil.ToFloat64(metrics.Credits.WithLabelValues(metrics.OutcomeSuccess, "")))
+		assert.Equal(t, credited+5000, testutil.ToFloat64(metrics.Amounts.WithLabelValues(metrics.OperationCredit, "IRR")))
+
+		created := calledWith(&mockTransactionRepo.Mock, "Create").Get(1).(*entities.Transaction)
+		assert.Equal(t, entities.CategoryTopUp, created.Category)
+		assert.Equal(t, request.ID, created.ReferenceID)
+	})
+
+	t.Run("duplicated success message is ignored", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockTopUpRepo, _ := setupTopUpTest()
+
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+		request := entities.NewTopUpRequest(rule)
+		request.MarkSucceeded()
+
+		mockTopUpRepo.On("LockRequest", ctx, request.ID).Return(request, nil)
+
+		err := service.CompleteTopUp(ctx, request.ID)
+
+		require.NoError(t, err)
+		mockWalletRepo.AssertNotCalled(t, "LockByID", mock.Anything, mock.Anything)
+		mockTransactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed top-up frees the rule", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockTopUpRepo, _ := setupTopUpTest()
+
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+		request := entities.NewTopUpRequest(rule)
+
+		mockTopUpRepo.On("LockRequest", ctx, request.ID).Return(request, nil)
+		mockTopUpRepo.On("UpdateRequestStatus", ctx, request).Return(nil)
+
+		err := service.FailTopUp(ctx, request.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.TopUpFailed, request.Status)
+		mockTopUpRepo.AssertCalled(t, "UpdateRequestStatus", ctx, request)
+		mockWalletRepo.AssertNotCalled(t, "LockByID", mock.Anything, mock.Anything)
+		mockTransactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("late failure does not undo a success", func(t *testing.T) {
+		service, _, _, mockTopUpRepo, _ := setupTopUpTest()
+
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+		request := entities.NewTopUpRequest(rule)
+		request.MarkSucceeded()
+
+		mockTopUpRepo.On("LockRequest", ctx, request.ID).Return(request, nil)
+
+		err := service.FailTopUp(ctx, request.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.TopUpSucceeded, request.Status)
+		mockTopUpRepo.AssertNotCalled(t, "UpdateRequestStatus", mock.Anything, mock.Anything)
+	})
+}
+
+// calledWith returns the arguments of the first call to method
+func calledWith(m *mock.Mock, method string) mock.Arguments {
+	for _, call := range m.Calls {
+		if call.Method == method {
+			return call.Arguments
+		}
+	}
+	return nil
+}
+
+func TestConsumerHandler_TopUpResults(t *testing.T) {
+	threshold, _ := valueobjects.NewMoney(big.NewInt(1000), "IRR")
+	amount, _ := valueobjects.NewMoney(big.NewInt(5000), "IRR")
+	setup := func() (*messaging.ConsumerHandler, *MockTopUpRepo) {
+		service, _, _, mockTopUpRepo, _ := setupTopUpTest()
+		handler := messaging.NewConsumerHandler(service, nil, config.Config{}, &rabbit.RabbitConn{}, logger.NewLogger("error"))
+		return handler, mockTopUpRepo
+	}
+	message := func(requestID string) []byte {
+		return []byte(`{"request_id":"` + requestID + `","payment_id":"pay-1"}`)
+	}
+
+	t.Run("a message that does not parse is dropped", func(t *testing.T) {
+		handler, _ := setup()
+
+		err := handler.HandleTopUpSucceeded(context.Background(), []byte("{"))
+
+		assert.True(t, rabbit.IsDrop(err))
+	})
+
+	t.Run("an unknown request is dropped", func(t *testing.T) {
+		handler, mockTopUpRepo := setup()
+		requestID := uuid.New()
+		mockTopUpRepo.On("LockRequest", mock.Anything, requestID).Return(nil, entities.ErrTopUpRequestNotFound)
+
+		err := handler.HandleTopUpFailed(context.Background(), message(requestID.String()))
+
+		assert.ErrorIs(t, err, entities.ErrTopUpRequestNotFound)
+		assert.True(t, rabbit.IsDrop(err))
+	})
+
+	t.Run("a database error is requeued", func(t *testing.T) {
+		handler, mockTopUpRepo := setup()
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		rule, _ := entities.NewTopUpRule(wallet, threshold, amount, 2, "card-1")
+		request := entities.NewTopUpRequest(rule)
+		outage := errors.New("connection refused")
+		mockTopUpRepo.On("LockRequest", mock.Anything, request.ID).Return(nil, outage)
+
+		err := handler.HandleTopUpSucceeded(context.Background(), message(request.ID.String()))
+
+		assert.ErrorIs(t, err, outage)
+		assert.False(t, rabbit.IsDrop(err))
+	})
+}
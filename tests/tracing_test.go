@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/pkg/logger"
+	"finance/pkg/tracing"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanRecorder    = tracetest.NewSpanRecorder()
+	recordSpansOnce sync.Once
+)
+
+// recordSpans installs a global provider that only samples children of testTracer spans, so the
+// tests that expect their own context in the repo mocks are unaffected. The global provider can
+// only be delegated to once, all tests share the recorder
+func recordSpans() *tracetest.SpanRecorder {
+	recordSpansOnce.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())),
+			sdktrace.WithSpanProcessor(spanRecorder),
+		))
+	})
+	return spanRecorder
+}
+
+// testTracer starts the sampled root spans of a test
+func testTracer() trace.Tracer {
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)).Tracer("tests")
+}
+
+func spansNamed(spans []sdktrace.ReadOnlySpan, name string) []sdktrace.ReadOnlySpan {
+	var found []sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if span.Name() == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
+func TestTracing_WalletServiceSpans(t *testing.T) {
+	recorder := recordSpans()
+	service, mockWalletRepo, _, _, _, _ := setupWalletServiceTest()
+	userID := uuid.New()
+
+	ctx, parent := testTracer().Start(context.Background(), "request")
+	mockWalletRepo.On("FindByUserID", mock.Anything, userID).Return(nil, entities.ErrWalletNotFound)
+
+	_, err := service.GetWalletByUserID(ctx, userID)
+	parent.End()
+
+	assert.ErrorIs(t, err, entities.ErrWalletNotFound)
+	var span sdktrace.ReadOnlySpan
+	for _, s := range spansNamed(recorder.Ended(), "WalletService.GetWalletByUserID") {
+		if s.SpanContext().TraceID() == parent.SpanContext().TraceID() {
+			span = s
+		}
+	}
+	require.NotNil(t, span)
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, entities.ErrWalletNotFound.Error(), span.Status().Description)
+}
+
+func TestTracing_LoggerTraceID(t *testing.T) {
+	ctx := logger.WithTraceID(context.Background())
+	_, err := uuid.Parse(logger.GetTraceID(ctx))
+	assert.NoError(t, err, "without a span the trace id is generated")
+
+	ctx, span := testTracer().Start(context.Background(), "message")
+	defer span.End()
+	ctx = logger.WithTraceID(ctx)
+
+	assert.Equal(t, span.SpanContext().TraceID().String(), logger.GetTraceID(ctx))
+}
+
+func TestTracing_Setup(t *testing.T) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = tracing.Setup(context.Background(), tracing.Options{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
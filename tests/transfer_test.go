@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTransferTest() (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo, *MockTransferRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockTransferRepo := &MockTransferRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+	mockTopUpRepo := &MockTopUpRepo{}
+	mockTxManager := &MockTransactionManager{}
+
+	mockThresholdRepo.On("FindByWalletID", mock.Anything, mock.Anything).Return([]*entities.BalanceThreshold{}, nil).Maybe()
+	mockTopUpRepo.On("FindRulesByWalletID", mock.Anything, mock.Anything).Return([]*entities.TopUpRule{}, nil).Maybe()
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockTransferRepo.On("WithTx", mock.Anything).Return(mockTransferRepo)
+
+	service := usecase.NewWalletService(
+		mockWalletRepo,
+		&MockUserRepo{},
+		mockTransactionRepo,
+		mockThresholdRepo,
+		mockTopUpRepo,
+		flatTariffRepo(),
+		noPlanRepo(),
+		&MockVoucherRepo{},
+		mockTransferRepo,
+		&MockFXRateRepo{},
+		noTaxRepo(),
+		mockTxManager,
+		&MockPublisher{},
+		&logger.Logger{},
+	)
+	return service, mockWalletRepo, mockTransactionRepo, mockTransferRepo
+}
+
+func transferWallets(t *testing.T, mockWalletRepo *MockWalletRepo, ctx context.Context, fromCurrency, toCurrency string, balance int64) (*entities.Wallet, *entities.Wallet) {
+	from, _ := entities.NewWallet(uuid.New(), fromCurrency)
+	to, _ := entities.NewWallet(uuid.New(), toCurrency)
+	if balance > 0 {
+		money, _ := valueobjects.NewMoney(big.NewInt(balance), fromCurrency)
+		require.NoError(t, from.Credit(money))
+	}
+	mockWalletRepo.On("FindByUserID", ctx, from.UserID).Return(from, nil)
+	mockWalletRepo.On("FindByUserID", ctx, to.UserID).Return(to, nil)
+	return from, to
+}
+
+func TestWalletService_Transfer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("moves paid balance with linked transactions", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockTransferRepo := setupTransferTest()
+		from, to := transferWallets(t, mockWalletRepo, ctx, "IRR", "IRR", 1000)
+
+		var locked []uuid.UUID
+		for _, w := range []*entities.Wallet{from, to} {
+			mockWalletRepo.On("LockByID", ctx, w.ID).
+				Run(func(args mock.Arguments) { locked = append(locked, args.Get(1).(uuid.UUID)) }).
+				Return(w, nil)
+		}
+		var recorded []*entities.Transaction
+		mockTransferRepo.On("FindByIdempotencyKey", ctx, from.UserID, "key-1").Return(nil, entities.ErrTransferNotFound)
+		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+			Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+			Return(nil)
+		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+		mockTransferRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transfer")).Return(nil)
+
+		transfer, err := service.Transfer(ctx, usecase.TransferInput{
+			FromUserID:     from.UserID,
+			ToUserID:       to.UserID,
+			Amount:         *big.NewInt(400),
+			IdempotencyKey: "key-1",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(600), from.Balance.Amount())
+		assert.Equal(t, big.NewInt(400), to.Balance.Amount())
+
+		require.Len(t, recorded, 2)
+		debit, credit := recorded[0], recorded[1]
+		assert.Equal(t, entities.TransactionDebit, debit.Type)
+		assert.Equal(t, entities.TransactionCredit, credit.Type)
+		for _, tx := range recorded {
+			assert.Equal(t, entities.CategoryTransfer, tx.Category)
+			assert.Equal(t, transfer.ID, tx.ReferenceID)
+		}
+		assert.Equal(t, debit.ID, transfer.DebitTransactionID)
+		assert.Equal(t, credit.ID, transfer.CreditTransactionID)
+		assert.False(t, debit.Refundable())
+
+		require.Len(t, locked, 2)
+		assert.Negative(t, bytes.Compare(locked[0][:], locked[1][:]), "wallets must be locked in id order")
+	})
+
+	t.Run("retry with the same key returns the first transfer", func(t *testing.T) {
+		service, mockWalletRepo, mockTransactionRepo, mockTransferRepo := setupTransferTest()
+		from, to := transferWallets(t, mockWalletRepo, ctx, "IRR", "IRR", 1000)
+		mockWalletRepo.On("LockByID", ctx, from.ID).Return(from, nil)
+		mockWalletRepo.On("LockByID", ctx, to.ID).Return(to, nil)
+
+		amount, _ := valueobjects.NewMoney(big.NewInt(400), "IRR")
+		existing, err := entities.NewTransfer(from, to, amount, "key-1")
+		require.NoError(t, err)
+		mockTransferRepo.On("FindByIdempotencyKey", ctx, from.UserID, "key-1").Return(existing, nil)
+
+		transfer, err := service.Transfer(ctx, usecase.TransferInput{
+			FromUserID: from.UserID, ToUserID: to.UserID, Amount: *big.NewInt(400), IdempotencyKey: "key-1",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, transfer.ID)
+		assert.Equal(t, big.NewInt(1000), from.Balance.Amount())
+		mockTransactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+		_, err = service.Transfer(ctx, usecase.TransferInput{
+			FromUserID: from.UserID, ToUserID: to.UserID, Amount: *big.NewInt(500), IdempotencyKey: "key-1",
+		})
+		assert.ErrorIs(t, err, entities.ErrIdempotencyKeyReused)
+	})
+
+	t.Run("wallets in different currencies", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransferRepo := setupTransferTest()
+		from, to := transferWallets(t, mockWalletRepo, ctx, "IRR", "USD", 1000)
+		mockWalletRepo.On("LockByID", ctx, from.ID).Return(from, nil)
+		mockWalletRepo.On("LockByID", ctx, to.ID).Return(to, nil)
+		mockTransferRepo.On("FindByIdempotencyKey", ctx, from.UserID, "key-1").Return(nil, entities.ErrTransferNotFound)
+
+		_, err := service.Transfer(ctx, usecase.TransferInput{
+			FromUserID: from.UserID, ToUserID: to.UserID, Amount: *big.NewInt(100), IdempotencyKey: "key-1",
+		})
+
+		assert.ErrorIs(t, err, valueobjects.ErrCurrencyMismatch)
+		assert.Equal(t, big.NewInt(1000), from.Balance.Amount())
+	})
+
+	t.Run("missing idempotency key", func(t *testing.T) {
+		service, _, _, _ := setupTransferTest()
+
+		_, err := service.Transfer(ctx, usecase.TransferInput{
+			FromUserID: uuid.New(), ToUserID: uuid.New(), Amount: *big.NewInt(100),
+		})
+
+		assert.ErrorIs(t, err, entities.ErrInvalidTransfer)
+	})
+}
+
+func TestWallet_DebitPaid(t *testing.T) {
+	wallet := newBucketWallet(t, 100)
+	_, err := wallet.GrantBonus(irr(500), 0, nil, "")
+	require.NoError(t, err)
+
+	_, err = wallet.DebitPaid(irr(200))
+	assert.ErrorIs(t, err, entities.ErrInsufficientBalance)
+
+	funding, err := wallet.DebitPaid(irr(100))
+	require.NoError(t, err)
+	assert.Equal(t, entities.BucketPaid, funding[0].Kind)
+	assert.Equal(t, big.NewInt(500), wallet.Balance.Amount())
+	assert.Equal(t, big.NewInt(500), wallet.BonusBalance(wallet.UpdatedAt).Amount())
+}
@@ -0,0 +1,260 @@
+package tests
+
+import (
+	"context"
+	"finance/internal/domain/entities"
+	"finance/internal/domain/valueobjects"
+	"finance/internal/usecase"
+	"finance/pkg/logger"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// serialTxManager runs one transaction at a time, like the row lock on the voucher does
+type serialTxManager struct {
+	mu sync.Mutex
+}
+
+func (m *serialTxManager) WithTransaction(fn func(tx *gorm.DB) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(nil)
+}
+
+// memVoucherRepo keeps one voucher and hands out copies, as reading the row would
+type memVoucherRepo struct {
+	voucher     entities.Voucher
+	redemptions []*entities.VoucherRedemption
+}
+
+func (r *memVoucherRepo) CreateBatch(ctx context.Context, vouchers []*entities.Voucher) error {
+	return nil
+}
+
+func (r *memVoucherRepo) FindByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	return r.LockByCode(ctx, code)
+}
+
+func (r *memVoucherRepo) List(ctx context.Context, campaign string) ([]*entities.Voucher, error) {
+	voucher := r.voucher
+	return []*entities.Voucher{&voucher}, nil
+}
+
+func (r *memVoucherRepo) LockByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	if code != r.voucher.Code {
+		return nil, entities.ErrVoucherNotFound
+	}
+	voucher := r.voucher
+	return &voucher, nil
+}
+
+func (r *memVoucherRepo) CountUserRedemptions(ctx context.Context, voucherID, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, redemption := range r.redemptions {
+		if redemption.VoucherID == voucherID && redemption.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memVoucherRepo) Redeem(ctx context.Context, voucher *entities.Voucher, redemption *entities.VoucherRedemption) error {
+	r.voucher.Redeemed = voucher.Redeemed
+	r.redemptions = append(r.redemptions, redemption)
+	return nil
+}
+
+func (r *memVoucherRepo) WithTx(tx *gorm.DB) entities.VoucherRepo {
+	return r
+}
+
+func newTestVoucher(t *testing.T, amount int64, maxRedemptions, perUserLimit int64) *entities.Voucher {
+	money, _ := valueobjects.NewMoney(big.NewInt(amount), "IRR")
+	vouchers, err := entities.NewVoucherBatch(1, money, maxRedemptions, perUserLimit, nil, nil, "spring")
+	require.NoError(t, err)
+	return vouchers[0]
+}
+
+func setupVoucherTest(voucherRepo entities.VoucherRepo) (*usecase.WalletService, *MockWalletRepo, *MockTransactionRepo) {
+	mockWalletRepo := &MockWalletRepo{}
+	mockTransactionRepo := &MockTransactionRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+
+	mockThresholdRepo.On("FindByWalletID", mock.Anything, mock.Anything).Return([]*entities.BalanceThreshold{}, nil).Maybe()
+	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
+	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
+	mockTransactionRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockWalletRepo.On("UpdateBalance", mock.Anything, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	service := usecase.NewWalletService(
+		mockWalletRepo,
+		&MockUserRepo{},
+		mockTransactionRepo,
+		mockThresholdRepo,
+		&MockTopUpRepo{},
+		flatTariffRepo(),
+		noPlanRepo(),
+		voucherRepo,
+		&MockTransferRepo{},
+		&MockFXRateRepo{},
+		noTaxRepo(),
+		&serialTxManager{},
+		&MockPublisher{},
+		&logger.Logger{},
+	)
+	return service, mockWalletRepo, mockTransactionRepo
+}
+
+func TestNewVoucherBatch(t *testing.T) {
+	amount, _ := valueobjects.NewMoney(big.NewInt(5000), "IRR")
+
+	t.Run("codes are unique and readable", func(t *testing.T) {
+		vouchers, err := entities.NewVoucherBatch(200, amount, 0, 1, nil, nil, "launch")
+
+		require.NoError(t, err)
+		require.Len(t, vouchers, 200)
+		codes := make(map[string]struct{})
+		for _, v := range vouchers {
+			assert.Regexp(t, `^[A-HJ-NP-Z2-9]{4}-[A-HJ-NP-Z2-9]{4}-[A-HJ-NP-Z2-9]{4}$`, v.Code)
+			codes[v.Code] = struct{}{}
+		}
+		assert.Len(t, codes, 200)
+	})
+
+	t.Run("invalid terms are rejected", func(t *testing.T) {
+		from := time.Now()
+		until := from.Add(-time.Hour)
+		zero, _ := valueobjects.NewMoney(big.NewInt(0), "IRR")
+
+		_, err := entities.NewVoucherBatch(0, amount, 0, 1, nil, nil, "")
+		assert.ErrorIs(t, err, entities.ErrInvalidVoucher)
+		_, err = entities.NewVoucherBatch(1, zero, 0, 1, nil, nil, "")
+		assert.ErrorIs(t, err, entities.ErrInvalidVoucher)
+		_, err = entities.NewVoucherBatch(1, amount, 0, 0, nil, nil, "")
+		assert.ErrorIs(t, err, entities.ErrInvalidVoucher)
+		_, err = entities.NewVoucherBatch(1, amount, 0, 1, &from, &until, "")
+		assert.ErrorIs(t, err, entities.ErrInvalidVoucher)
+	})
+}
+
+func TestVoucher_Redeem(t *testing.T) {
+	now := time.Now()
+
+	t.Run("outside the validity window", func(t *testing.T) {
+		voucher := newTestVoucher(t, 1000, 0, 1)
+		from := now.Add(time.Hour)
+		voucher.ValidFrom = &from
+
+		_, err := voucher.Redeem(uuid.New(), 0, now)
+		assert.ErrorIs(t, err, entities.ErrVoucherNotActive)
+
+		until := now.Add(-time.Minute)
+		voucher.ValidFrom, voucher.ValidUntil = nil, &until
+		_, err = voucher.Redeem(uuid.New(), 0, now)
+		assert.ErrorIs(t, err, entities.ErrVoucherNotActive)
+	})
+
+	t.Run("redemption limits", func(t *testing.T) {
+		voucher := newTestVoucher(t, 1000, 2, 1)
+
+		_, err := voucher.Redeem(uuid.New(), 1, now)
+		assert.ErrorIs(t, err, entities.ErrVoucherLimitReached)
+
+		_, err = voucher.Redeem(uuid.New(), 0, now)
+		require.NoError(t, err)
+		_, err = voucher.Redeem(uuid.New(), 0, now)
+		require.NoError(t, err)
+		_, err = voucher.Redeem(uuid.New(), 0, now)
+		assert.ErrorIs(t, err, entities.ErrVoucherExhausted)
+		assert.Equal(t, int64(2), voucher.Redeemed)
+	})
+}
+
+func TestWalletService_RedeemVoucher(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("credits the voucher amount and references the voucher", func(t *testing.T) {
+		voucher := newTestVoucher(t, 2500, 0, 1)
+		voucherRepo := &memVoucherRepo{voucher: *voucher}
+		service, mockWalletRepo, _ := setupVoucherTest(voucherRepo)
+
+		userID := uuid.New()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+		mockWalletRepo.On("LockByUserID", ctx, userID).Return(wallet, nil)
+
+		transaction, err := service.RedeemVoucher(ctx, userID, " "+strings.ToLower(voucher.Code))
+
+		require.NoError(t, err)
+		assert.Equal(t, entities.CategoryVoucher, transaction.Category)
+		assert.Equal(t, voucher.ID, transaction.ReferenceID)
+		assert.Equal(t, entities.TransactionCompleted, transaction.Status)
+		assert.Equal(t, big.NewInt(2500), wallet.Balance.Amount())
+		require.Len(t, voucherRepo.redemptions, 1)
+		assert.Equal(t, transaction.ID, voucherRepo.redemptions[0].TransactionID)
+
+		_, err = service.RedeemVoucher(ctx, userID, voucher.Code)
+		assert.ErrorIs(t, err, entities.ErrVoucherLimitReached)
+		assert.Equal(t, big.NewInt(2500), wallet.Balance.Amount())
+	})
+
+	t.Run("voucher in another currency", func(t *testing.T) {
+		voucher := newTestVoucher(t, 2500, 0, 1)
+		service, mockWalletRepo, _ := setupVoucherTest(&memVoucherRepo{voucher: *voucher})
+
+		userID := uuid.New()
+		wallet, _ := entities.NewWallet(userID, "USD")
+		mockWalletRepo.On("LockByUserID", ctx, userID).Return(wallet, nil)
+
+		_, err := service.RedeemVoucher(ctx, userID, voucher.Code)
+
+		assert.ErrorIs(t, err, valueobjects.ErrCurrencyMismatch)
+		assert.True(t, wallet.Balance.IsZero())
+	})
+
+	t.Run("concurrent redemptions stay under the limit", func(t *testing.T) {
+		voucher := newTestVoucher(t, 100, 5, 1)
+		voucherRepo := &memVoucherRepo{voucher: *voucher}
+		service, mockWalletRepo, _ := setupVoucherTest(voucherRepo)
+
+		users := make([]uuid.UUID, 20)
+		for i := range users {
+			users[i] = uuid.New()
+			wallet, _ := entities.NewWallet(users[i], "IRR")
+			mockWalletRepo.On("LockByUserID", ctx, users[i]).Return(wallet, nil)
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var redeemed, exhausted int
+		for _, userID := range users {
+			wg.Add(1)
+			go func(userID uuid.UUID) {
+				defer wg.Done()
+				_, err := service.RedeemVoucher(ctx, userID, voucher.Code)
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					redeemed++
+				} else if assert.ErrorIs(t, err, entities.ErrVoucherExhausted) {
+					exhausted++
+				}
+			}(userID)
+		}
+		wg.Wait()
+
+		assert.Equal(t, 5, redeemed)
+		assert.Equal(t, 15, exhausted)
+		assert.Equal(t, int64(5), voucherRepo.voucher.Redeemed)
+		assert.Len(t, voucherRepo.redemptions, 5)
+	})
+}
@@ -0,0 +1,324 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"finance/internal/domain/entities"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func childOf(t *testing.T, parent *entities.Wallet, balance int64, quota *big.Int) *entities.Wallet {
+	child := newBucketWallet(t, balance)
+	require.NoError(t, child.SetParent(parent, quota))
+	return child
+}
+
+func TestWallet_SplitWithParent(t *testing.T) {
+	parent := newBucketWallet(t, 1000)
+
+	t.Run("child pays what it has and the parent the rest", func(t *testing.T) {
+		child := childOf(t, parent, 30, nil)
+
+		own, fromParent, err := child.SplitWithParent(irr(100), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(30), own.Amount())
+		assert.Equal(t, big.NewInt(70), fromParent.Amount())
+	})
+
+	t.Run("child with enough balance pays alone", func(t *testing.T) {
+		child := childOf(t, parent, 500, nil)
+
+		own, fromParent, err := child.SplitWithParent(irr(100), nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), own.Amount())
+		assert.True(t, fromParent.IsZero())
+	})
+
+	t.Run("draw beyond the monthly quota", func(t *testing.T) {
+		child := childOf(t, parent, 0, big.NewInt(150))
+
+		_, _, err := child.SplitWithParent(irr(100), big.NewInt(60))
+
+		assert.ErrorIs(t, err, entities.ErrParentQuotaReached)
+	})
+
+	t.Run("invalid parents", func(t *testing.T) {
+		child := newBucketWallet(t, 0)
+		usd, _ := entities.NewWallet(uuid.New(), "USD")
+
+		assert.ErrorIs(t, child.SetParent(child, nil), entities.ErrInvalidParent)
+		assert.ErrorIs(t, child.SetParent(usd, nil), entities.ErrInvalidParent)
+		assert.ErrorIs(t, child.SetParent(parent, big.NewInt(-1)), entities.ErrInvalidParent)
+	})
+}
+
+func TestWalletService_DebitFallsThroughToParent(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, mockPublisher := setupWalletServiceTest()
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 1000)
+	child := childOf(t, parent, 30, big.NewInt(500))
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, child.UserID).Return(child, nil)
+	var locked []uuid.UUID
+	for _, w := range []*entities.Wallet{child, parent} {
+		mockWalletRepo.On("LockByID", ctx, w.ID).
+			Run(func(args mock.Arguments) { locked = append(locked, args.Get(1).(uuid.UUID)) }).
+			Return(w, nil)
+	}
+	mockTransactionRepo.On("SumParentFundingSince", ctx, child.ID, mock.AnythingOfType("time.Time")).Return(big.NewInt(0), nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	// the child wallet is empty after the debit
+	mockPublisher.On("PublishEvent", ctx, mock.AnythingOfType("*events.WalletBalanceDepleted")).Return(nil)
+
+	_, err := service.DebitUserbalance(ctx, child.UserID, uuid.New(), testSMS(1))
+
+	require.NoError(t, err)
+	assert.True(t, child.Balance.IsZero())
+	assert.Equal(t, big.NewInt(930), parent.Balance.Amount())
+
+	require.Len(t, recorded, 2)
+	childTx, parentTx := recorded[0], recorded[1]
+	assert.Equal(t, big.NewInt(30), childTx.Amount.Amount(), "the child row only records the child's share")
+	assert.Equal(t, big.NewInt(70), entities.ParentFunding(childTx.Funding, "IRR").Amount())
+	assert.Equal(t, entities.CategoryDelegated, parentTx.Category)
+	assert.Equal(t, parent.ID, parentTx.WalletID)
+	assert.Equal(t, childTx.ID, parentTx.ReferenceID)
+	assert.Equal(t, big.NewInt(70), parentTx.Amount.Amount())
+
+	require.Len(t, locked, 2)
+	assert.Negative(t, bytes.Compare(locked[0][:], locked[1][:]), "the child and the parent must be locked in id order")
+}
+
+func TestWalletService_RefundReturnsParentShare(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 930)
+	child := childOf(t, parent, 0, nil)
+
+	originalTx := entities.NewTransaction(child.ID, child.UserID, uuid.New(), irr(30), entities.TransactionDebit)
+	originalTx.Funding = []entities.Funding{
+		{Kind: entities.BucketPaid, Amount: irr(30)},
+		{BucketID: parent.ID, Kind: entities.BucketParent, Amount: irr(70)},
+	}
+	require.NoError(t, originalTx.MarkCompleted())
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockTransactionRepo.On("FindByID", ctx, originalTx.ID.String()).Return(originalTx, nil)
+	mockWalletRepo.On("LockByID", ctx, child.ID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, parent.ID).Return(parent, nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+	err := service.RefundTransaction(ctx, originalTx.ID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(30), child.Balance.Amount())
+	assert.Equal(t, big.NewInt(1000), parent.Balance.Amount())
+	require.Len(t, recorded, 2)
+	assert.Equal(t, parent.ID, recorded[0].WalletID)
+	assert.Equal(t, entities.CategoryRefund, recorded[0].Category)
+	assert.Equal(t, originalTx.ID, recorded[0].ReferenceID)
+	assert.Equal(t, big.NewInt(70), recorded[0].Amount.Amount())
+	assert.Equal(t, child.ID, recorded[1].WalletID)
+	assert.Equal(t, big.NewInt(30), recorded[1].Amount.Amount())
+}
+
+// ledgerSum is what SumBalance computes from the completed rows of a wallet
+func ledgerSum(opening int64, walletID uuid.UUID, recorded []*entities.Transaction) *big.Int {
+	sum := big.NewInt(opening)
+	for _, tx := range recorded {
+		if tx.WalletID != walletID || tx.Status != entities.TransactionCompleted {
+			continue
+		}
+		if tx.Type == entities.TransactionCredit {
+			sum.Add(sum, tx.Amount.Amount())
+		} else {
+			sum.Sub(sum, tx.Amount.Amount())
+		}
+	}
+	return sum
+}
+
+func TestWalletService_ParentFundedLedgerMatchesBalance(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, mockPublisher := setupWalletServiceTest()
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 1000)
+	child := childOf(t, parent, 30, big.NewInt(500))
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, child.UserID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, child.ID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, parent.ID).Return(parent, nil)
+	mockTransactionRepo.On("SumParentFundingSince", ctx, child.ID, mock.AnythingOfType("time.Time")).Return(big.NewInt(0), nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	mockPublisher.On("PublishEvent", ctx, mock.Anything).Return(nil)
+
+	event, err := service.DebitUserbalance(ctx, child.UserID, uuid.New(), testSMS(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), event.Amount, "the sms is still charged at its full price")
+	assert.Equal(t, child.Balance.Amount().String(), ledgerSum(30, child.ID, recorded).String())
+	assert.Equal(t, parent.Balance.Amount().String(), ledgerSum(1000, parent.ID, recorded).String())
+
+	childTx := recorded[0]
+	mockTransactionRepo.On("FindByID", ctx, childTx.ID.String()).Return(childTx, nil)
+	require.NoError(t, service.RefundTransaction(ctx, childTx.ID.String()))
+
+	assert.Equal(t, big.NewInt(30), child.Balance.Amount())
+	assert.Equal(t, big.NewInt(1000), parent.Balance.Amount())
+	assert.Equal(t, child.Balance.Amount().String(), ledgerSum(30, child.ID, recorded).String())
+	assert.Equal(t, parent.Balance.Amount().String(), ledgerSum(1000, parent.ID, recorded).String())
+}
+
+func TestWalletService_SetParentWallet(t *testing.T) {
+	service, mockWalletRepo, _, _, mockTxManager, _ := setupWalletServiceTest()
+
+	ctx := context.Background()
+	root := newBucketWallet(t, 0)
+	middle := childOf(t, root, 0, nil)
+	other := newBucketWallet(t, 0)
+
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, root.UserID).Return(root, nil)
+	mockWalletRepo.On("FindByUserID", ctx, middle.UserID).Return(middle, nil)
+	mockWalletRepo.On("FindByUserID", ctx, other.UserID).Return(other, nil)
+	mockWalletRepo.On("FindChildren", ctx, root.ID).Return([]*entities.Wallet{middle}, nil)
+	mockWalletRepo.On("UpdateParent", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+
+	t.Run("a wallet can not become the child of its own child", func(t *testing.T) {
+		_, err := service.SetParentWallet(ctx, root.UserID, middle.UserID, *big.NewInt(0))
+
+		assert.ErrorIs(t, err, entities.ErrInvalidParent)
+		assert.False(t, root.HasParent())
+	})
+
+	t.Run("a child can not be a parent", func(t *testing.T) {
+		_, err := service.SetParentWallet(ctx, other.UserID, middle.UserID, *big.NewInt(0))
+
+		assert.ErrorIs(t, err, entities.ErrInvalidParent)
+		assert.False(t, other.HasParent())
+	})
+
+	t.Run("a parent can not become a child", func(t *testing.T) {
+		_, err := service.SetParentWallet(ctx, root.UserID, other.UserID, *big.NewInt(0))
+
+		assert.ErrorIs(t, err, entities.ErrInvalidParent)
+		assert.False(t, root.HasParent())
+	})
+
+	t.Run("new child gets the parent and quota", func(t *testing.T) {
+		leaf := newBucketWallet(t, 0)
+		mockWalletRepo.On("FindByUserID", ctx, leaf.UserID).Return(leaf, nil)
+		mockWalletRepo.On("FindChildren", ctx, leaf.ID).Return([]*entities.Wallet{}, nil)
+
+		wallet, err := service.SetParentWallet(ctx, leaf.UserID, root.UserID, *big.NewInt(500))
+
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, wallet.ParentID)
+		assert.Equal(t, big.NewInt(500), wallet.ParentQuota)
+	})
+}
+
+func TestWalletService_GetSpendingReport(t *testing.T) {
+	service, mockWalletRepo, _, mockTransactionRepo, _, _ := setupWalletServiceTest()
+
+	ctx := context.Background()
+	since := entities.StartOfMonth(time.Now())
+	root := newBucketWallet(t, 0)
+	first := childOf(t, root, 0, nil)
+	second := childOf(t, root, 0, nil)
+
+	mockWalletRepo.On("FindByUserID", ctx, root.UserID).Return(root, nil)
+	mockWalletRepo.On("FindChildren", ctx, root.ID).Return([]*entities.Wallet{first, second}, nil)
+	mockTransactionRepo.On("SumAmountSince", ctx, root.ID, entities.TransactionDebit, since).Return(big.NewInt(100), nil)
+	mockTransactionRepo.On("SumAmountSince", ctx, first.ID, entities.TransactionDebit, since).Return(big.NewInt(50), nil)
+	mockTransactionRepo.On("SumAmountSince", ctx, second.ID, entities.TransactionDebit, since).Return(big.NewInt(25), nil)
+
+	report, err := service.GetSpendingReport(ctx, root.UserID, since)
+
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), report.Own)
+	assert.Equal(t, big.NewInt(175), report.Total)
+	require.Len(t, report.Children, 2)
+	assert.Equal(t, big.NewInt(50), report.Children[0].Total)
+	assert.Equal(t, big.NewInt(25), report.Children[1].Total)
+}
+
+func TestWalletService_TaxedParentFundedLedgerMatchesBalance(t *testing.T) {
+	service, mockWalletRepo, mockUserRepo, mockTransactionRepo, mockTxManager, mockPublisher := setupWalletServiceTest()
+	service.TaxRuleRepo = taxRepoWith(entities.TaxOnDebit, vatRule(t, "", entities.TaxOnDebit, 1000, false))
+
+	ctx := context.Background()
+	parent := newBucketWallet(t, 1000)
+	child := childOf(t, parent, 30, big.NewInt(500))
+
+	var recorded []*entities.Transaction
+	mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+	mockWalletRepo.On("FindByUserID", ctx, child.UserID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, child.ID).Return(child, nil)
+	mockWalletRepo.On("LockByID", ctx, parent.ID).Return(parent, nil)
+	mockUserRepo.On("GetByID", ctx, child.UserID).Return(nil, gorm.ErrRecordNotFound)
+	mockTransactionRepo.On("SumParentFundingSince", ctx, child.ID, mock.AnythingOfType("time.Time")).Return(big.NewInt(0), nil)
+	mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).
+		Run(func(args mock.Arguments) { recorded = append(recorded, args.Get(1).(*entities.Transaction)) }).
+		Return(nil)
+	mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+	mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+	mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+	mockPublisher.On("PublishEvent", ctx, mock.Anything).Return(nil)
+
+	event, err := service.DebitUserbalance(ctx, child.UserID, uuid.New(), testSMS(1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(110), event.Amount)
+	require.Len(t, recorded, 2)
+	childTx, parentTx := recorded[0], recorded[1]
+	assert.Equal(t, "30", childTx.Amount.Amount().String(), "the child row only records the child's share")
+	assert.Equal(t, "80", parentTx.Amount.Amount().String())
+	assert.Equal(t, "10", new(big.Int).Add(childTx.TaxAmount.Amount(), parentTx.TaxAmount.Amount()).String())
+	assert.Equal(t, child.Balance.Amount().String(), ledgerSum(30, child.ID, recorded).String())
+	assert.Equal(t, parent.Balance.Amount().String(), ledgerSum(1000, parent.ID, recorded).String())
+
+	mockTransactionRepo.On("FindByID", ctx, childTx.ID.String()).Return(childTx, nil)
+	mockTransactionRepo.On("FindByReference", ctx, parent.ID, childTx.ID).Return(parentTx, nil)
+	require.NoError(t, service.RefundTransaction(ctx, childTx.ID.String()))
+
+	assert.Equal(t, "30", child.Balance.Amount().String())
+	assert.Equal(t, "1000", parent.Balance.Amount().String())
+	assert.Equal(t, child.Balance.Amount().String(), ledgerSum(30, child.ID, recorded).String())
+	assert.Equal(t, parent.Balance.Amount().String(), ledgerSum(1000, parent.ID, recorded).String())
+	refundedTax := new(big.Int)
+	for _, tx := range recorded[2:] {
+		refundedTax.Add(refundedTax, tx.TaxAmount.Amount())
+	}
+	assert.Equal(t, "10", refundedTax.String(), "the refunds give back the whole tax")
+}
@@ -10,6 +10,7 @@ import (
 	"finance/pkg/logger"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +21,9 @@ import (
 
 type MockWalletRepo struct {
 	mock.Mock
+	// AuditEvents are the appended audit events, recorded without expectations so every
+	// balance change does not need one
+	AuditEvents []*entities.AuditEvent
 }
 
 func (m *MockWalletRepo) Save(ctx context.Context, wallet *entities.Wallet) error {
@@ -43,66 +47,692 @@ func (m *MockWalletRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*e
 	return args.Get(0).(*entities.Wallet), args.Error(1)
 }
 
+func (m *MockWalletRepo) LockByID(ctx context.Context, ID uuid.UUID) (*entities.Wallet, error) {
+	args := m.Called(ctx, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) LockByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepo) UpdateParent(ctx context.Context, wallet *entities.Wallet) error {
+	args := m.Called(ctx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) FindChildren(ctx context.Context, parentID uuid.UUID) ([]*entities.Wallet, error) {
+	args := m.Called(ctx, parentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Wallet), args.Error(1)
+}
+
 func (m *MockWalletRepo) UpdateBalance(ctx context.Context, wallet *entities.Wallet) error {
 	args := m.Called(ctx, wallet)
 	return args.Error(0)
 }
 
-func (m *MockWalletRepo) WithTx(tx *gorm.DB) entities.WalletRepo {
+func (m *MockWalletRepo) LastAuditEvent(ctx context.Context, walletID uuid.UUID) (*entities.AuditEvent, error) {
+	for i := len(m.AuditEvents) - 1; i >= 0; i-- {
+		if m.AuditEvents[i].WalletID == walletID {
+			return m.AuditEvents[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockWalletRepo) AppendAuditEvent(ctx context.Context, event *entities.AuditEvent) error {
+	m.AuditEvents = append(m.AuditEvents, event)
+	return nil
+}
+
+func (m *MockWalletRepo) UpdateLimits(ctx context.Context, wallet *entities.Wallet) error {
+	args := m.Called(ctx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) UpdateCreditLimit(ctx context.Context, wallet *entities.Wallet) error {
+	args := m.Called(ctx, wallet)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepo) FindBucket(ctx context.Context, walletID, ID uuid.UUID) (*entities.BalanceBucket, error) {
+	args := m.Called(ctx, walletID, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BalanceBucket), args.Error(1)
+}
+
+func (m *MockWalletRepo) FindWalletIDsWithExpiredBuckets(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	args := m.Called(ctx, before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockWalletRepo) ListIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	args := m.Called(ctx, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockWalletRepo) WithTx(tx *gorm.DB) entities.WalletRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.WalletRepo)
+}
+
+type MockUserRepo struct {
+	mock.Mock
+}
+
+func (m *MockUserRepo) GetByID(ctx context.Context, ID uuid.UUID) (*entities.User, error) {
+	args := m.Called(ctx, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockUserRepo) GetAll(ctx context.Context) ([]*entities.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockUserRepo) UpdateCustomerType(ctx context.Context, user *entities.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepo) WithTx(tx *gorm.DB) entities.UserRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.UserRepo)
+}
+
+type MockTransactionRepo struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepo) Create(ctx context.Context, tx *entities.Transaction) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepo) FindByID(ctx context.Context, id string) (*entities.Transaction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepo) FindByReference(ctx context.Context, walletID, referenceID uuid.UUID) (*entities.Transaction, error) {
+	args := m.Called(ctx, walletID, referenceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepo) UpdateStatus(ctx context.Context, tx *entities.Transaction, status entities.TransactionStatus) error {
+	args := m.Called(ctx, tx, status)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepo) SaveFunding(ctx context.Context, tx *entities.Transaction) error {
+	args := m.Called(ctx, tx)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepo) SumAmountSince(ctx context.Context, walletID uuid.UUID, txType entities.TransactionType, since time.Time) (*big.Int, error) {
+	args := m.Called(ctx, walletID, txType, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockTransactionRepo) CountSince(ctx context.Context, walletID uuid.UUID, txType entities.TransactionType, since time.Time) (int64, error) {
+	args := m.Called(ctx, walletID, txType, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTransactionRepo) SumTax(ctx context.Context, from, to time.Time) ([]entities.TaxTotal, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.TaxTotal), args.Error(1)
+}
+
+func (m *MockTransactionRepo) BalanceAt(ctx context.Context, walletID uuid.UUID, currency string, at time.Time) (*big.Int, error) {
+	args := m.Called(ctx, walletID, currency, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockTransactionRepo) SumBetween(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) (*big.Int, error) {
+	args := m.Called(ctx, walletID, currency, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockTransactionRepo) SumBalance(ctx context.Context, walletID uuid.UUID, currency string) (*big.Int, error) {
+	args := m.Called(ctx, walletID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockTransactionRepo) StatementLines(ctx context.Context, walletID uuid.UUID, currency string, from, to time.Time) ([]entities.InvoiceLine, error) {
+	args := m.Called(ctx, walletID, currency, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.InvoiceLine), args.Error(1)
+}
+
+func (m *MockTransactionRepo) Stream(ctx context.Context, filter entities.TransactionFilter, fn func(*entities.Transaction) error) error {
+	args := m.Called(ctx, filter, fn)
+	if txs, ok := args.Get(0).([]*entities.Transaction); ok {
+		for _, tx := range txs {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockTransactionRepo) SumParentFundingSince(ctx context.Context, walletID uuid.UUID, since time.Time) (*big.Int, error) {
+	args := m.Called(ctx, walletID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*big.Int), args.Error(1)
+}
+
+func (m *MockTransactionRepo) WithTx(tx *gorm.DB) entities.TransactionRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.TransactionRepo)
+}
+
+type MockThresholdRepo struct {
+	mock.Mock
+}
+
+func (m *MockThresholdRepo) Create(ctx context.Context, threshold *entities.BalanceThreshold) error {
+	args := m.Called(ctx, threshold)
+	return args.Error(0)
+}
+
+func (m *MockThresholdRepo) FindByWalletID(ctx context.Context, walletID uuid.UUID) ([]*entities.BalanceThreshold, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.BalanceThreshold), args.Error(1)
+}
+
+func (m *MockThresholdRepo) Delete(ctx context.Context, walletID, ID uuid.UUID) error {
+	args := m.Called(ctx, walletID, ID)
+	return args.Error(0)
+}
+
+func (m *MockThresholdRepo) SetArmed(ctx context.Context, ID uuid.UUID, armed bool) (bool, error) {
+	args := m.Called(ctx, ID, armed)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockThresholdRepo) WithTx(tx *gorm.DB) entities.BalanceThresholdRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.BalanceThresholdRepo)
+}
+
+type MockTopUpRepo struct {
+	mock.Mock
+}
+
+func (m *MockTopUpRepo) CreateRule(ctx context.Context, rule *entities.TopUpRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockTopUpRepo) UpdateRule(ctx context.Context, rule *entities.TopUpRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockTopUpRepo) DeleteRule(ctx context.Context, walletID, ID uuid.UUID) error {
+	args := m.Called(ctx, walletID, ID)
+	return args.Error(0)
+}
+
+func (m *MockTopUpRepo) FindRuleByID(ctx context.Context, walletID, ID uuid.UUID) (*entities.TopUpRule, error) {
+	args := m.Called(ctx, walletID, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TopUpRule), args.Error(1)
+}
+
+func (m *MockTopUpRepo) FindRulesByWalletID(ctx context.Context, walletID uuid.UUID) ([]*entities.TopUpRule, error) {
+	args := m.Called(ctx, walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.TopUpRule), args.Error(1)
+}
+
+func (m *MockTopUpRepo) LockRule(ctx context.Context, ID uuid.UUID) (*entities.TopUpRule, error) {
+	args := m.Called(ctx, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TopUpRule), args.Error(1)
+}
+
+func (m *MockTopUpRepo) CreateRequest(ctx context.Context, request *entities.TopUpRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockTopUpRepo) LockRequest(ctx context.Context, ID uuid.UUID) (*entities.TopUpRequest, error) {
+	args := m.Called(ctx, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.TopUpRequest), args.Error(1)
+}
+
+func (m *MockTopUpRepo) UpdateRequestStatus(ctx context.Context, request *entities.TopUpRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockTopUpRepo) CountRequestsSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (int64, error) {
+	args := m.Called(ctx, ruleID, since)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTopUpRepo) HasPendingRequestSince(ctx context.Context, ruleID uuid.UUID, since time.Time) (bool, error) {
+	args := m.Called(ctx, ruleID, since)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTopUpRepo) WithTx(tx *gorm.DB) entities.TopUpRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.TopUpRepo)
+}
+
+type MockTariffRepo struct {
+	mock.Mock
+}
+
+func (m *MockTariffRepo) Create(ctx context.Context, tariff *entities.Tariff) error {
+	args := m.Called(ctx, tariff)
+	return args.Error(0)
+}
+
+func (m *MockTariffRepo) FindByVersion(ctx context.Context, version int64) (*entities.Tariff, error) {
+	args := m.Called(ctx, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tariff), args.Error(1)
+}
+
+func (m *MockTariffRepo) FindActive(ctx context.Context, currency string) (*entities.Tariff, error) {
+	args := m.Called(ctx, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Tariff), args.Error(1)
+}
+
+func (m *MockTariffRepo) List(ctx context.Context) ([]*entities.Tariff, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Tariff), args.Error(1)
+}
+
+func (m *MockTariffRepo) Activate(ctx context.Context, tariff *entities.Tariff) error {
+	args := m.Called(ctx, tariff)
+	return args.Error(0)
+}
+
+func (m *MockTariffRepo) WithTx(tx *gorm.DB) entities.TariffRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.TariffRepo)
+}
+
+// flatTariffRepo prices every normal sms at 100 per segment, so an sms of n segments costs n*100
+func flatTariffRepo() *MockTariffRepo {
+	m := &MockTariffRepo{}
+	for _, currency := range []string{"USD", "IRR"} {
+		tariff, _ := entities.NewTariff("flat", currency, []entities.TariffRate{
+			{Prefix: "", MessageType: entities.MessageNormal, PricePerSegment: big.NewInt(100)},
+		})
+		tariff.Version = 1
+		m.On("FindActive", mock.Anything, currency).Return(tariff, nil).Maybe()
+	}
+	m.On("WithTx", mock.Anything).Return(m).Maybe()
+	return m
+}
+
+func testSMS(segments int64) entities.SMSMetadata {
+	return entities.SMSMetadata{
+		Receiver: "+989121234567",
+		Segments: segments,
+		Encoding: entities.EncodingGSM7,
+	}
+}
+
+type MockPlanRepo struct {
+	mock.Mock
+}
+
+func (m *MockPlanRepo) Create(ctx context.Context, plan *entities.Plan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *MockPlanRepo) Update(ctx context.Context, plan *entities.Plan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *MockPlanRepo) Delete(ctx context.Context, ID uuid.UUID) error {
+	args := m.Called(ctx, ID)
+	return args.Error(0)
+}
+
+func (m *MockPlanRepo) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Plan, error) {
+	args := m.Called(ctx, ID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Plan), args.Error(1)
+}
+
+func (m *MockPlanRepo) List(ctx context.Context) ([]*entities.Plan, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Plan), args.Error(1)
+}
+
+func (m *MockPlanRepo) CountActiveAssignments(ctx context.Context, planID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, planID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPlanRepo) Assign(ctx context.Context, assignment *entities.PlanAssignment) error {
+	args := m.Called(ctx, assignment)
+	return args.Error(0)
+}
+
+func (m *MockPlanRepo) FindActivePlan(ctx context.Context, userID uuid.UUID) (*entities.Plan, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Plan), args.Error(1)
+}
+
+func (m *MockPlanRepo) ListAssignments(ctx context.Context, userID uuid.UUID) ([]*entities.PlanAssignment, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.PlanAssignment), args.Error(1)
+}
+
+func (m *MockPlanRepo) WithTx(tx *gorm.DB) entities.PlanRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.PlanRepo)
+}
+
+// noPlanRepo leaves every user without a plan, so debits are priced from the tariff alone
+func noPlanRepo() *MockPlanRepo {
+	m := &MockPlanRepo{}
+	m.On("FindActivePlan", mock.Anything, mock.Anything).Return(nil, entities.ErrPlanNotAssigned).Maybe()
+	m.On("WithTx", mock.Anything).Return(m).Maybe()
+	return m
+}
+
+type MockVoucherRepo struct {
+	mock.Mock
+}
+
+func (m *MockVoucherRepo) CreateBatch(ctx context.Context, vouchers []*entities.Voucher) error {
+	args := m.Called(ctx, vouchers)
+	return args.Error(0)
+}
+
+func (m *MockVoucherRepo) FindByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Voucher), args.Error(1)
+}
+
+func (m *MockVoucherRepo) List(ctx context.Context, campaign string) ([]*entities.Voucher, error) {
+	args := m.Called(ctx, campaign)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Voucher), args.Error(1)
+}
+
+func (m *MockVoucherRepo) LockByCode(ctx context.Context, code string) (*entities.Voucher, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Voucher), args.Error(1)
+}
+
+func (m *MockVoucherRepo) CountUserRedemptions(ctx context.Context, voucherID, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, voucherID, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockVoucherRepo) Redeem(ctx context.Context, voucher *entities.Voucher, redemption *entities.VoucherRedemption) error {
+	args := m.Called(ctx, voucher, redemption)
+	return args.Error(0)
+}
+
+func (m *MockVoucherRepo) WithTx(tx *gorm.DB) entities.VoucherRepo {
 	args := m.Called(tx)
-	return args.Get(0).(entities.WalletRepo)
+	return args.Get(0).(entities.VoucherRepo)
 }
 
-type MockUserRepo struct {
+type MockTransferRepo struct {
 	mock.Mock
 }
 
-func (m *MockUserRepo) GetByID(ctx context.Context, ID uuid.UUID) (*entities.User, error) {
+func (m *MockTransferRepo) Create(ctx context.Context, transfer *entities.Transfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepo) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Transfer, error) {
 	args := m.Called(ctx, ID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entities.User), args.Error(1)
+	return args.Get(0).(*entities.Transfer), args.Error(1)
 }
 
-func (m *MockUserRepo) GetAll(ctx context.Context) ([]*entities.User, error) {
+func (m *MockTransferRepo) FindByIdempotencyKey(ctx context.Context, fromUserID uuid.UUID, key string) (*entities.Transfer, error) {
+	args := m.Called(ctx, fromUserID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepo) WithTx(tx *gorm.DB) entities.TransferRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.TransferRepo)
+}
+
+type MockFXRateRepo struct {
+	mock.Mock
+}
+
+func (m *MockFXRateRepo) Create(ctx context.Context, rate *entities.FXRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+func (m *MockFXRateRepo) FindValid(ctx context.Context, base, quote string, at time.Time) (*entities.FXRate, error) {
+	args := m.Called(ctx, base, quote, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepo) List(ctx context.Context, base, quote string) ([]*entities.FXRate, error) {
+	args := m.Called(ctx, base, quote)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepo) WithTx(tx *gorm.DB) entities.FXRateRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.FXRateRepo)
+}
+
+type MockTaxRuleRepo struct {
+	mock.Mock
+}
+
+func (m *MockTaxRuleRepo) Create(ctx context.Context, rule *entities.TaxRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockTaxRuleRepo) List(ctx context.Context) ([]*entities.TaxRule, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*entities.User), args.Error(1)
+	return args.Get(0).([]*entities.TaxRule), args.Error(1)
 }
 
-func (m *MockUserRepo) WithTx(tx *gorm.DB) entities.UserRepo {
+func (m *MockTaxRuleRepo) FindValid(ctx context.Context, base entities.TaxBase, at time.Time) ([]*entities.TaxRule, error) {
+	args := m.Called(ctx, base, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.TaxRule), args.Error(1)
+}
+
+func (m *MockTaxRuleRepo) WithTx(tx *gorm.DB) entities.TaxRuleRepo {
 	args := m.Called(tx)
-	return args.Get(0).(entities.UserRepo)
+	return args.Get(0).(entities.TaxRuleRepo)
 }
 
-type MockTransactionRepo struct {
+type MockInvoiceRepo struct {
 	mock.Mock
 }
 
-func (m *MockTransactionRepo) Create(ctx context.Context, tx *entities.Transaction) error {
-	args := m.Called(ctx, tx)
+func (m *MockInvoiceRepo) Create(ctx context.Context, invoice *entities.Invoice) error {
+	args := m.Called(ctx, invoice)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepo) FindByID(ctx context.Context, id string) (*entities.Transaction, error) {
-	args := m.Called(ctx, id)
+func (m *MockInvoiceRepo) FindByID(ctx context.Context, ID uuid.UUID) (*entities.Invoice, error) {
+	args := m.Called(ctx, ID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*entities.Transaction), args.Error(1)
+	return args.Get(0).(*entities.Invoice), args.Error(1)
 }
 
-func (m *MockTransactionRepo) UpdateStatus(ctx context.Context, tx *entities.Transaction, status entities.TransactionStatus) error {
-	args := m.Called(ctx, tx, status)
+func (m *MockInvoiceRepo) FindByPeriod(ctx context.Context, walletID uuid.UUID, periodStart time.Time) (*entities.Invoice, error) {
+	args := m.Called(ctx, walletID, periodStart)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepo) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Invoice, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepo) NextSequence(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockInvoiceRepo) WithTx(tx *gorm.DB) entities.InvoiceRepo {
+	args := m.Called(tx)
+	return args.Get(0).(entities.InvoiceRepo)
+}
+
+type MockBalanceSnapshotRepo struct {
+	mock.Mock
+}
+
+func (m *MockBalanceSnapshotRepo) Save(ctx context.Context, snapshot *entities.BalanceSnapshot) error {
+	args := m.Called(ctx, snapshot)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepo) WithTx(tx *gorm.DB) entities.TransactionRepo {
+func (m *MockBalanceSnapshotRepo) FindLatest(ctx context.Context, walletID uuid.UUID, at time.Time) (*entities.BalanceSnapshot, error) {
+	args := m.Called(ctx, walletID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BalanceSnapshot), args.Error(1)
+}
+
+func (m *MockBalanceSnapshotRepo) WithTx(tx *gorm.DB) entities.BalanceSnapshotRepo {
 	args := m.Called(tx)
-	return args.Get(0).(entities.TransactionRepo)
+	return args.Get(0).(entities.BalanceSnapshotRepo)
+}
+
+// noTaxRepo has no tax rules, so debits and credits stay untaxed
+func noTaxRepo() *MockTaxRuleRepo {
+	m := &MockTaxRuleRepo{}
+	m.On("WithTx", mock.Anything).Return(m).Maybe()
+	m.On("FindValid", mock.Anything, mock.Anything, mock.Anything).Return([]*entities.TaxRule{}, nil).Maybe()
+	return m
 }
 
 type MockTransactionManager struct {
@@ -130,10 +760,15 @@ func setupWalletServiceTest() (*usecase.WalletService, *MockWalletRepo, *MockUse
 	mockWalletRepo := &MockWalletRepo{}
 	mockUserRepo := &MockUserRepo{}
 	mockTransactionRepo := &MockTransactionRepo{}
+	mockThresholdRepo := &MockThresholdRepo{}
+	mockTopUpRepo := &MockTopUpRepo{}
 	mockTxManager := &MockTransactionManager{}
 	mockPublisher := &MockPublisher{}
 	mockLogger := &logger.Logger{}
 
+	mockThresholdRepo.On("FindByWalletID", mock.Anything, mock.Anything).Return([]*entities.BalanceThreshold{}, nil).Maybe()
+	mockTopUpRepo.On("FindRulesByWalletID", mock.Anything, mock.Anything).Return([]*entities.TopUpRule{}, nil).Maybe()
+
 	mockWalletRepo.On("WithTx", mock.Anything).Return(mockWalletRepo)
 	mockUserRepo.On("WithTx", mock.Anything).Return(mockUserRepo)
 	mockTransactionRepo.On("WithTx", mock.Anything).Return(mockTransactionRepo)
@@ -142,6 +777,14 @@ func setupWalletServiceTest() (*usecase.WalletService, *MockWalletRepo, *MockUse
 		mockWalletRepo,
 		mockUserRepo,
 		mockTransactionRepo,
+		mockThresholdRepo,
+		mockTopUpRepo,
+		flatTariffRepo(),
+		noPlanRepo(),
+		&MockVoucherRepo{},
+		&MockTransferRepo{},
+		&MockFXRateRepo{},
+		noTaxRepo(),
 		mockTxManager,
 		mockPublisher,
 		mockLogger,
@@ -156,7 +799,7 @@ func TestWalletService_DebitUserBalance(t *testing.T) {
 
 		userID := uuid.New()
 		smsID := uuid.New()
-		amount := *big.NewInt(100)
+		sms := testSMS(1)
 		ctx := context.Background()
 
 		wallet, _ := entities.NewWallet(userID, "USD")
@@ -165,17 +808,20 @@ func TestWalletService_DebitUserBalance(t *testing.T) {
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
 		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
 		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
 
-		event, err := service.DebitUserbalance(ctx, userID, smsID, amount)
+		event, err := service.DebitUserbalance(ctx, userID, smsID, sms)
 
 		require.NoError(t, err)
 		require.NotNil(t, event)
 		assert.Equal(t, userID.String(), event.UserID)
 		assert.Equal(t, smsID.String(), event.SMSID)
 		assert.Equal(t, int64(100), event.Amount)
+		assert.Equal(t, int64(1), event.TariffVersion)
 		assert.NotEmpty(t, event.TransactionID)
 
 		mockWalletRepo.AssertExpectations(t)
@@ -188,13 +834,13 @@ func TestWalletService_DebitUserBalance(t *testing.T) {
 
 		userID := uuid.New()
 		smsID := uuid.New()
-		amount := *big.NewInt(100)
+		sms := testSMS(1)
 		ctx := context.Background()
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrWalletNotFound)
 		mockWalletRepo.On("FindByUserID", ctx, userID).Return(nil, entities.ErrWalletNotFound)
 
-		event, err := service.DebitUserbalance(ctx, userID, smsID, amount)
+		event, err := service.DebitUserbalance(ctx, userID, smsID, sms)
 
 		assert.Error(t, err)
 		assert.Nil(t, event)
@@ -209,16 +855,17 @@ func TestWalletService_DebitUserBalance(t *testing.T) {
 
 		userID := uuid.New()
 		smsID := uuid.New()
-		amount := *big.NewInt(100)
+		sms := testSMS(1)
 		ctx := context.Background()
 
 		wallet, _ := entities.NewWallet(userID, "USD")
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrInsufficientBalance)
 		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
 		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 
-		event, err := service.DebitUserbalance(ctx, userID, smsID, amount)
+		event, err := service.DebitUserbalance(ctx, userID, smsID, sms)
 
 		assert.Error(t, err)
 		assert.Nil(t, event)
@@ -228,6 +875,110 @@ func TestWalletService_DebitUserBalance(t *testing.T) {
 		mockTransactionRepo.AssertExpectations(t)
 		mockTxManager.AssertExpectations(t)
 	})
+
+	t.Run("should fail when daily limit is exceeded", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		smsID := uuid.New()
+		sms := testSMS(1)
+		ctx := context.Background()
+
+		wallet, _ := entities.NewWallet(userID, "USD")
+		initialAmount, _ := valueobjects.NewMoney(big.NewInt(1000), "USD")
+		wallet.Credit(initialAmount)
+		wallet.Limits = entities.SpendingLimits{DailyAmount: big.NewInt(500)}
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrLimitExceeded)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTransactionRepo.On("SumAmountSince", ctx, wallet.ID, entities.TransactionDebit, mock.AnythingOfType("time.Time")).Return(big.NewInt(450), nil)
+
+		event, err := service.DebitUserbalance(ctx, userID, smsID, sms)
+
+		assert.Nil(t, event)
+		assert.ErrorIs(t, err, entities.ErrLimitExceeded)
+
+		var limitErr *entities.LimitExceededError
+		require.ErrorAs(t, err, &limitErr)
+		assert.Equal(t, entities.LimitDailyAmount, limitErr.Kind)
+
+		mockWalletRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should debit when velocity limit is not reached", func(t *testing.T) {
+		service, mockWalletRepo, _, mockTransactionRepo, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		smsID := uuid.New()
+		sms := testSMS(1)
+		ctx := context.Background()
+
+		wallet, _ := entities.NewWallet(userID, "USD")
+		initialAmount, _ := valueobjects.NewMoney(big.NewInt(1000), "USD")
+		wallet.Credit(initialAmount)
+		wallet.Limits = entities.SpendingLimits{DebitsPerMinute: 5}
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, wallet.ID).Return(wallet, nil)
+		mockTransactionRepo.On("CountSince", ctx, wallet.ID, entities.TransactionDebit, mock.AnythingOfType("time.Time")).Return(int64(4), nil)
+		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
+		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
+
+		event, err := service.DebitUserbalance(ctx, userID, smsID, sms)
+
+		require.NoError(t, err)
+		require.NotNil(t, event)
+		assert.Equal(t, big.NewInt(900), wallet.Balance.Amount())
+
+		mockWalletRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+}
+
+func TestWalletService_SetSpendingLimits(t *testing.T) {
+	t.Run("successful limits update", func(t *testing.T) {
+		service, mockWalletRepo, _, _, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "USD")
+		limits := entities.SpendingLimits{MaxDebit: big.NewInt(50), DebitsPerMinute: 10}
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("UpdateLimits", ctx, wallet).Return(nil)
+
+		updated, err := service.SetSpendingLimits(ctx, userID, limits)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(50), updated.Limits.MaxDebit)
+		assert.Equal(t, int64(10), updated.Limits.DebitsPerMinute)
+
+		mockWalletRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject negative limits", func(t *testing.T) {
+		service, mockWalletRepo, _, _, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "USD")
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrInvalidLimit)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+
+		updated, err := service.SetSpendingLimits(ctx, userID, entities.SpendingLimits{DailyAmount: big.NewInt(-1)})
+
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, entities.ErrInvalidLimit)
+		mockWalletRepo.AssertNotCalled(t, "UpdateLimits", mock.Anything, mock.Anything)
+	})
 }
 
 func TestWalletService_CreditUserBalance(t *testing.T) {
@@ -241,7 +992,7 @@ func TestWalletService_CreditUserBalance(t *testing.T) {
 		wallet, _ := entities.NewWallet(userID, "USD")
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
-		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("LockByUserID", ctx, userID).Return(wallet, nil)
 		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
 		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
@@ -263,7 +1014,7 @@ func TestWalletService_CreditUserBalance(t *testing.T) {
 		ctx := context.Background()
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrWalletNotFound)
-		mockWalletRepo.On("FindByUserID", ctx, userID).Return(nil, entities.ErrWalletNotFound)
+		mockWalletRepo.On("LockByUserID", ctx, userID).Return(nil, entities.ErrWalletNotFound)
 
 		err := service.CreditUserBalance(ctx, userID, amount)
 
@@ -275,6 +1026,43 @@ func TestWalletService_CreditUserBalance(t *testing.T) {
 	})
 }
 
+func TestWalletService_SetCreditLimit(t *testing.T) {
+	t.Run("successful credit limit update", func(t *testing.T) {
+		service, mockWalletRepo, _, _, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+		mockWalletRepo.On("UpdateCreditLimit", ctx, wallet).Return(nil)
+
+		updated, err := service.SetCreditLimit(ctx, userID, *big.NewInt(1000))
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(1000), updated.CreditLimit.Amount())
+		mockWalletRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject negative credit limit", func(t *testing.T) {
+		service, mockWalletRepo, _, _, mockTxManager, _ := setupWalletServiceTest()
+
+		userID := uuid.New()
+		ctx := context.Background()
+		wallet, _ := entities.NewWallet(userID, "IRR")
+
+		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(entities.ErrInvalidCreditLimit)
+		mockWalletRepo.On("FindByUserID", ctx, userID).Return(wallet, nil)
+
+		updated, err := service.SetCreditLimit(ctx, userID, *big.NewInt(-1))
+
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, entities.ErrInvalidCreditLimit)
+		mockWalletRepo.AssertNotCalled(t, "UpdateCreditLimit", mock.Anything, mock.Anything)
+	})
+}
+
 func TestWalletService_GetWalletByUserID(t *testing.T) {
 	t.Run("successful wallet retrieval", func(t *testing.T) {
 		mockWalletRepo := &MockWalletRepo{}
@@ -286,6 +1074,14 @@ func TestWalletService_GetWalletByUserID(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			mockLogger,
 		)
 
@@ -313,6 +1109,14 @@ func TestWalletService_GetWalletByUserID(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			mockLogger,
 		)
 
@@ -342,6 +1146,14 @@ func TestWalletService_GetUserByID(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			mockLogger,
 		)
 
@@ -376,6 +1188,14 @@ func TestWalletService_GetAllUsers(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			mockLogger,
 		)
 
@@ -425,9 +1245,10 @@ func TestWalletService_RefundTransaction(t *testing.T) {
 
 		mockTxManager.On("WithTransaction", mock.AnythingOfType("func(*gorm.DB) error")).Return(nil)
 		mockTransactionRepo.On("FindByID", ctx, txID).Return(originalTx, nil)
-		mockWalletRepo.On("FindByID", ctx, walletID).Return(wallet, nil)
+		mockWalletRepo.On("LockByID", ctx, walletID).Return(wallet, nil)
 		mockTransactionRepo.On("Create", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 		mockWalletRepo.On("UpdateBalance", ctx, mock.AnythingOfType("*entities.Wallet")).Return(nil)
+		mockTransactionRepo.On("SaveFunding", ctx, mock.AnythingOfType("*entities.Transaction")).Return(nil)
 		mockTransactionRepo.On("UpdateStatus", ctx, mock.AnythingOfType("*entities.Transaction"), entities.TransactionCompleted).Return(nil)
 
 		err := service.RefundTransaction(ctx, txID)
@@ -225,3 +225,69 @@ func TestWallet_Debit(t *testing.T) {
 		assert.True(t, wallet.Balance.IsZero())
 	})
 }
+
+func TestWallet_CreditLine(t *testing.T) {
+	t.Run("should debit below zero within credit limit", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		limit, _ := valueobjects.NewMoney(big.NewInt(500), "IRR")
+		require.NoError(t, wallet.SetCreditLimit(limit))
+
+		debitAmount, _ := valueobjects.NewMoney(big.NewInt(300), "IRR")
+		err := wallet.Debit(debitAmount)
+
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(-300), wallet.Balance.Amount())
+		assert.Equal(t, big.NewInt(300), wallet.AmountOwed().Amount())
+
+		available, err := wallet.AvailableBalance()
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(200), available.Amount())
+	})
+
+	t.Run("should allow debit down to exactly the credit limit", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		limit, _ := valueobjects.NewMoney(big.NewInt(500), "IRR")
+		wallet.SetCreditLimit(limit)
+
+		debitAmount, _ := valueobjects.NewMoney(big.NewInt(500), "IRR")
+
+		require.NoError(t, wallet.Debit(debitAmount))
+		assert.Equal(t, big.NewInt(-500), wallet.Balance.Amount())
+	})
+
+	t.Run("should fail when debit goes past the credit limit", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		limit, _ := valueobjects.NewMoney(big.NewInt(500), "IRR")
+		wallet.SetCreditLimit(limit)
+
+		debitAmount, _ := valueobjects.NewMoney(big.NewInt(501), "IRR")
+		err := wallet.Debit(debitAmount)
+
+		assert.Equal(t, entities.ErrInsufficientBalance, err)
+		assert.True(t, wallet.Balance.IsZero())
+	})
+
+	t.Run("credit pays back the amount owed", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		limit, _ := valueobjects.NewMoney(big.NewInt(500), "IRR")
+		wallet.SetCreditLimit(limit)
+		debitAmount, _ := valueobjects.NewMoney(big.NewInt(400), "IRR")
+		wallet.Debit(debitAmount)
+
+		creditAmount, _ := valueobjects.NewMoney(big.NewInt(100), "IRR")
+		require.NoError(t, wallet.Credit(creditAmount))
+
+		assert.Equal(t, big.NewInt(-300), wallet.Balance.Amount())
+		assert.Equal(t, big.NewInt(300), wallet.AmountOwed().Amount())
+	})
+
+	t.Run("should reject credit limit in another currency", func(t *testing.T) {
+		wallet, _ := entities.NewWallet(uuid.New(), "IRR")
+		limit, _ := valueobjects.NewMoney(big.NewInt(500), "USD")
+
+		err := wallet.SetCreditLimit(limit)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "currency mismatch")
+	})
+}